@@ -0,0 +1,176 @@
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+	"github.com/sirupsen/logrus"
+)
+
+// the names of the files that can contain identities or recipients. Encrypted identity
+// files are only read from the configuration directories
+var keyFilePatterns = []string{"*.txt", "recipients", "*.recipients", ".age-recipients"}
+
+const encryptedFilePattern = "*.age"
+
+// Access returns a key access that lists the identities and recipients in the configuration directories
+// of age and sops, and the recipient files in the given project directories. Identities are listed together
+// with their recipients. Identity files encrypted with a passphrase are decrypted with the password provider
+func Access(l logrus.FieldLogger, projectDirectories ...string) api.KeyAccess {
+	home, _ := os.UserHomeDir()
+	return &access{
+		log: l.WithField("component", "age"),
+		configDirectories: []string{
+			filepath.Join(home, ".config", "age"),
+			filepath.Join(home, ".config", "sops", "age"),
+		},
+		projectDirectories: projectDirectories,
+		knownPasswords:     map[string][]byte{},
+		declinedFiles:      map[string]bool{},
+	}
+}
+
+// access remembers the passphrases of encrypted identity files, and the files the user didn't want
+// to give a passphrase for, so the user isn't asked again every time the keys are listed
+type access struct {
+	log                logrus.Ext1FieldLogger
+	configDirectories  []string
+	projectDirectories []string
+	passwords          api.PasswordProvider
+	knownPasswords     map[string][]byte
+	declinedFiles      map[string]bool
+}
+
+// SetPasswordProvider implements the api.PasswordProviderUser interface
+func (a *access) SetPasswordProvider(p api.PasswordProvider) {
+	a.passwords = p
+}
+
+// keyCollection puts the identity and the recipient for the same public key together, since recipients
+// are usually kept in other files than the identities
+type keyCollection struct {
+	entries     []*keyEntry
+	byPublicKey map[string]*keyEntry
+}
+
+func (c *keyCollection) entryFor(publicKey []byte, label string) *keyEntry {
+	entry, found := c.byPublicKey[string(publicKey)]
+	if !found {
+		entry = &keyEntry{publicKey: publicKey}
+		c.byPublicKey[string(publicKey)] = entry
+		c.entries = append(c.entries, entry)
+	}
+	if entry.label == "" {
+		entry.label = label
+	}
+	return entry
+}
+
+func (c *keyCollection) add(f *keyFile, location string, protected bool) {
+	for _, i := range f.identities {
+		entry := c.entryFor(i.publicKey, i.label)
+		entry.privateLocations = appendIfMissing(entry.privateLocations, location)
+		entry.protected = entry.protected || protected
+	}
+	for _, r := range f.recipients {
+		entry := c.entryFor(r.publicKey, r.label)
+		entry.publicLocations = appendIfMissing(entry.publicLocations, location)
+	}
+}
+
+// decryptIdentityFile tries the passphrase that worked before, and then asks for the passphrase until
+// it is correct. Files the user didn't give a passphrase for are not asked about again
+func (a *access) decryptIdentityFile(ctx context.Context, fileName string, f *encryptedFile) ([]byte, bool) {
+	if p, ok := a.knownPasswords[fileName]; ok {
+		if content, e := f.decrypt(p); e == nil {
+			return content, true
+		}
+	}
+
+	incorrect := false
+	for a.passwords != nil && !a.declinedFiles[fileName] && ctx.Err() == nil {
+		p, ok := a.passwords.PasswordFor(fileName, incorrect)
+		if !ok {
+			a.declinedFiles[fileName] = true
+			break
+		}
+
+		content, e := f.decrypt(p)
+		if e == nil {
+			a.knownPasswords[fileName] = p
+			return content, true
+		}
+		if e != api.ErrIncorrectPassphrase {
+			a.log.WithError(e).WithField("file", fileName).Warn("couldn't decrypt the age identity file")
+			break
+		}
+		incorrect = true
+	}
+	return nil, false
+}
+
+func (a *access) warnAboutMismatchingPublicKeys(f *keyFile, fileName string) {
+	for _, i := range f.identities {
+		if !i.matchesAnnouncedPublicKey() {
+			a.log.WithField("file", fileName).Warn("the public key in the comment doesn't match the age identity")
+		}
+	}
+}
+
+func (a *access) addKeyFile(c *keyCollection, fileName string, content []byte, protected bool) {
+	f := parseKeyFile(string(content))
+	a.warnAboutMismatchingPublicKeys(f, fileName)
+	c.add(f, fileName, protected)
+}
+
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	c := &keyCollection{byPublicKey: map[string]*keyEntry{}}
+	locked := []api.KeyEntry{}
+
+	for _, fileName := range files.Matching(append(append([]string{}, a.configDirectories...), a.projectDirectories...), keyFilePatterns...) {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		content, e := files.ReadSmall(fileName, files.MaximumFileSize)
+		if e != nil {
+			a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the age key file")
+			continue
+		}
+		if !isEncryptedFile(content) {
+			a.addKeyFile(c, fileName, content, false)
+		}
+	}
+
+	for _, fileName := range files.Matching(a.configDirectories, encryptedFilePattern) {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		content, e := files.ReadSmall(fileName, files.MaximumFileSize)
+		if e != nil || !isEncryptedFile(content) {
+			continue
+		}
+		f, e := parseEncryptedFile(content)
+		if e != nil || !f.isPassphraseEncrypted() {
+			continue
+		}
+
+		if decrypted, ok := a.decryptIdentityFile(ctx, fileName, f); ok {
+			a.addKeyFile(c, fileName, decrypted, true)
+		} else {
+			locked = append(locked, &lockedIdentityFileEntry{location: fileName})
+		}
+	}
+
+	result := []api.KeyEntry{}
+	for _, entry := range c.entries {
+		result = append(result, entry)
+	}
+	return append(result, locked...), nil
+}
@@ -0,0 +1,124 @@
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *ageSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(configDirectories []string, projectDirectories ...string) *access {
+	logger := testutil.NullLogger()
+	return &access{
+		log:                logger,
+		configDirectories:  configDirectories,
+		projectDirectories: projectDirectories,
+		knownPasswords:     map[string][]byte{},
+		declinedFiles:      map[string]bool{},
+	}
+}
+
+func (s *ageSuite) writeFileForTest(dir, name string, content []byte) string {
+	fileName := filepath.Join(dir, name)
+	s.Require().NoError(os.WriteFile(fileName, content, 0600))
+	return fileName
+}
+
+func (s *ageSuite) Test_access_AllKeys_pairsIdentitiesWithRecipients() {
+	config, project := s.T().TempDir(), s.T().TempDir()
+	keysFile := s.writeFileForTest(config, "keys.txt", []byte(identityFileForTest))
+	recipientsFile := s.writeFileForTest(project, ".age-recipients", []byte("# Alice\n"+recipientForTest+"\nage1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\n"))
+	s.writeFileForTest(project, "notes.md", []byte(identityFileForTest))
+
+	keys := s.allKeysOf(accessForTest([]string{config}, project))
+	s.Require().Len(keys, 2)
+
+	k := keys[0].(api.AgeKeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{recipientsFile}, k.PublicKeyLocations())
+	s.Equal([]string{keysFile}, k.PrivateKeyLocations())
+	s.Equal([]string{recipientsFile, keysFile}, k.Locations())
+	s.Equal(api.Age, k.Algorithm())
+	s.Equal(256, k.Size())
+	s.Equal(recipientForTest, k.AgeRecipient())
+	s.Equal("Alice", k.UserID())
+	s.False(k.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.Equal("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", keys[1].(api.AgeKeyEntry).AgeRecipient())
+	s.Equal(api.PublicKeyType, keys[1].KeyType())
+
+	_, publicKey, _ := bech32Decode(recipientForTest)
+	s.Equal(publicKey, k.WithDigestContent(func(b []byte) []byte { return b }))
+	_, isSSH := k.(api.SSHPublicKeyEntry)
+	s.False(isSSH, "age keys can't be used with SSH")
+}
+
+func (s *ageSuite) Test_access_AllKeys_decryptsIdentityFilesWithThePassphraseFromTheProvider() {
+	config := s.T().TempDir()
+	fileName := s.writeFileForTest(config, "keys.txt.age", encryptedIdentityFileContentForTest())
+	a := accessForTest([]string{config})
+	p := &testutil.PasswordProvider{Passwords: []string{"not the secret", "secret"}}
+	a.SetPasswordProvider(p)
+
+	keys := s.allKeysOf(a)
+	s.Equal([]bool{false, true}, p.Asked)
+	s.Require().Len(keys, 1)
+	k := keys[0].(api.AgeKeyEntry)
+	s.Equal(recipientForTest, k.AgeRecipient())
+	s.Equal([]string{fileName}, k.PrivateKeyLocations())
+	s.True(k.(api.PrivateKeyEntry).IsPasswordProtected())
+
+	s.Len(s.allKeysOf(a), 1)
+	s.Len(p.Asked, 2, "the passphrase should be remembered")
+}
+
+func (s *ageSuite) Test_access_AllKeys_doesNotAskForMorePassphrasesWhenTheContextIsCancelled() {
+	config := s.T().TempDir()
+	s.writeFileForTest(config, "keys.age", []byte(armoredIdentityFileForTest))
+	s.writeFileForTest(config, "other.age", encryptedIdentityFileContentForTest())
+	a := accessForTest([]string{config})
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &testutil.PasswordProvider{Passwords: []string{"not the secret", "secret"}, OnAsk: cancel}
+	a.SetPasswordProvider(p)
+
+	keys, e := a.AllKeys(ctx)
+
+	s.Nil(keys)
+	s.Equal(context.Canceled, e)
+	s.Equal([]bool{false}, p.Asked)
+}
+
+func (s *ageSuite) Test_access_AllKeys_listsIdentityFilesThatCanNotBeDecryptedAsLocked() {
+	config, project := s.T().TempDir(), s.T().TempDir()
+	fileName := s.writeFileForTest(config, "keys.age", []byte(armoredIdentityFileForTest))
+	s.writeFileForTest(project, "secrets.age", encryptedIdentityFileContentForTest())
+	a := accessForTest([]string{config}, project)
+	p := &testutil.PasswordProvider{}
+	a.SetPasswordProvider(p)
+
+	for i := 0; i < 2; i++ {
+		keys := s.allKeysOf(a)
+		s.Require().Len(keys, 1)
+		s.Equal(api.PrivateKeyType, keys[0].KeyType())
+		s.Equal([]string{fileName}, keys[0].Locations())
+		s.Equal(api.Age, keys[0].Algorithm())
+		s.True(keys[0].(api.PrivateKeyEntry).IsPasswordProtected())
+	}
+	s.Equal([]bool{false}, p.Asked)
+}
+
+func (s *ageSuite) Test_Access_looksInTheConfigurationDirectoriesOfAgeAndSops() {
+	logger := testutil.NullLogger()
+	a := Access(logger, "/src/project").(*access)
+
+	home, _ := os.UserHomeDir()
+	s.Equal([]string{filepath.Join(home, ".config", "age"), filepath.Join(home, ".config", "sops", "age")}, a.configDirectories)
+	s.Equal([]string{"/src/project"}, a.projectDirectories)
+}
@@ -0,0 +1,131 @@
+package age
+
+import (
+	"errors"
+	"strings"
+)
+
+// This is an implementation of the Bech32 encoding, as specified in BIP 173
+// https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+// age doesn't use the 90 character limit from the specification, so we don't enforce it here
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+var errInvalidBech32 = errors.New("invalid bech32 string")
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range bech32Generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	result := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range []byte(hrp) {
+		result = append(result, c>>5)
+	}
+	result = append(result, 0)
+	for _, c := range []byte(hrp) {
+		result = append(result, c&31)
+	}
+	return result
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32ExpandHRP(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ 1
+	result := make([]byte, 6)
+	for i := range result {
+		result[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return result
+}
+
+func bech32VerifyChecksum(hrp string, data []byte) bool {
+	return bech32Polymod(append(bech32ExpandHRP(hrp), data...)) == 1
+}
+
+// convertBits regroups the bits of the input from groups of fromBits to groups of toBits
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, bool) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	result := []byte{}
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, false
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxValue))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxValue))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxValue != 0 {
+		return nil, false
+	}
+
+	return result, true
+}
+
+func bech32Encode(hrp string, data []byte) string {
+	values, _ := convertBits(data, 8, 5, true)
+
+	var result strings.Builder
+	result.WriteString(strings.ToLower(hrp))
+	result.WriteByte('1')
+	for _, v := range append(values, bech32Checksum(strings.ToLower(hrp), values)...) {
+		result.WriteByte(bech32Charset[v])
+	}
+	return result.String()
+}
+
+func bech32Decode(s string) (hrp string, data []byte, e error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errInvalidBech32
+	}
+	s = strings.ToLower(s)
+
+	separator := strings.LastIndexByte(s, '1')
+	if separator < 1 || separator+7 > len(s) {
+		return "", nil, errInvalidBech32
+	}
+
+	hrp = s[:separator]
+	values := []byte{}
+	for _, c := range []byte(s[separator+1:]) {
+		v := strings.IndexByte(bech32Charset, c)
+		if v == -1 {
+			return "", nil, errInvalidBech32
+		}
+		values = append(values, byte(v))
+	}
+
+	if !bech32VerifyChecksum(hrp, values) {
+		return "", nil, errInvalidBech32
+	}
+
+	data, ok := convertBits(values[:len(values)-6], 5, 8, false)
+	if !ok {
+		return "", nil, errInvalidBech32
+	}
+
+	return hrp, data, nil
+}
@@ -0,0 +1,50 @@
+package age
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ageSuite struct {
+	suite.Suite
+}
+
+func TestAgeSuite(t *testing.T) {
+	suite.Run(t, new(ageSuite))
+}
+
+func (s *ageSuite) Test_bech32Encode_generatesTheChecksumsFromTheSpecification() {
+	s.Equal("a12uel5l", bech32Encode("a", []byte{}))
+	s.Equal("abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", bech32Encode("abcdef", []byte{
+		0x00, 0x44, 0x32, 0x14, 0xc7, 0x42, 0x54, 0xb6, 0x35, 0xcf, 0x84, 0x65, 0x3a, 0x56, 0xd7, 0xc6, 0x75, 0xbe, 0x77, 0xdf,
+	}))
+}
+
+func (s *ageSuite) Test_bech32Decode_decodesValidStrings() {
+	hrp, data, e := bech32Decode("A12UEL5L")
+	s.NoError(e)
+	s.Equal("a", hrp)
+	s.Empty(data)
+
+	hrp, data, e = bech32Decode("abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw")
+	s.NoError(e)
+	s.Equal("abcdef", hrp)
+	s.Equal([]byte{
+		0x00, 0x44, 0x32, 0x14, 0xc7, 0x42, 0x54, 0xb6, 0x35, 0xcf, 0x84, 0x65, 0x3a, 0x56, 0xd7, 0xc6, 0x75, 0xbe, 0x77, 0xdf,
+	}, data)
+}
+
+func (s *ageSuite) Test_bech32Decode_rejectsInvalidStrings() {
+	for _, v := range []string{
+		"",
+		"a12uel5m",
+		"A12uel5l",
+		"1qzzfhee",
+		"a1b2uel5l",
+		"pzry9x0s0muk",
+	} {
+		_, _, e := bech32Decode(v)
+		s.Equal(errInvalidBech32, e, v)
+	}
+}
@@ -0,0 +1,245 @@
+package age
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+const versionLine = "age-encryption.org/v1"
+const armorType = "AGE ENCRYPTED FILE"
+const armorHeader = "-----BEGIN " + armorType + "-----"
+
+const stanzaPrefix = "-> "
+const macPrefix = "---"
+const stanzaBodyLineLength = 64
+
+const scryptStanzaType = "scrypt"
+const scryptLabel = "age-encryption.org/v1/scrypt"
+const scryptSaltSize = 16
+
+// maximumScryptWorkFactor is the same limit age uses, so files that would take too long are refused
+const maximumScryptWorkFactor = 22
+
+const fileKeySize = 16
+const payloadNonceSize = 16
+const payloadChunkSize = 64 * 1024
+
+var errInvalidEncryptedFile = errors.New("invalid age encrypted file")
+var errNotPassphraseEncrypted = errors.New("the file is not encrypted with a passphrase")
+
+type stanza struct {
+	kind string
+	args []string
+	body []byte
+}
+
+// encryptedFile is an age encrypted file. The MAC covers the header, up to and including the dashes before the MAC
+type encryptedFile struct {
+	stanzas    []*stanza
+	macMessage []byte
+	mac        []byte
+	payload    []byte
+}
+
+func isEncryptedFile(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(versionLine+"\n")) || bytes.HasPrefix(bytes.TrimSpace(content), []byte(armorHeader))
+}
+
+func readLine(data []byte) (string, []byte, bool) {
+	i := bytes.IndexByte(data, '\n')
+	if i == -1 {
+		return "", nil, false
+	}
+	return string(data[:i]), data[i+1:], true
+}
+
+func decodeBase64(s string) ([]byte, bool) {
+	data, e := base64.RawStdEncoding.Strict().DecodeString(s)
+	return data, e == nil
+}
+
+// readStanzaBody reads the lines of the body, which ends with the first line shorter than 64 characters
+func readStanzaBody(data []byte) ([]byte, []byte, bool) {
+	encoded := ""
+	for {
+		line, rest, ok := readLine(data)
+		if !ok || len(line) > stanzaBodyLineLength {
+			return nil, nil, false
+		}
+		encoded += line
+		data = rest
+		if len(line) < stanzaBodyLineLength {
+			break
+		}
+	}
+
+	body, ok := decodeBase64(encoded)
+	return body, data, ok
+}
+
+func dearmor(content []byte) ([]byte, bool) {
+	block, _ := pem.Decode(bytes.TrimSpace(content))
+	if block == nil || block.Type != armorType {
+		return nil, false
+	}
+	return block.Bytes, true
+}
+
+func parseEncryptedFile(content []byte) (*encryptedFile, error) {
+	if !bytes.HasPrefix(content, []byte(versionLine)) {
+		dearmored, ok := dearmor(content)
+		if !ok {
+			return nil, errInvalidEncryptedFile
+		}
+		content = dearmored
+	}
+
+	line, data, ok := readLine(content)
+	if !ok || line != versionLine {
+		return nil, errInvalidEncryptedFile
+	}
+
+	result := &encryptedFile{}
+	for {
+		start := len(content) - len(data)
+		line, rest, ok := readLine(data)
+		switch {
+		case !ok:
+			return nil, errInvalidEncryptedFile
+		case strings.HasPrefix(line, stanzaPrefix):
+			fields := strings.Split(strings.TrimPrefix(line, stanzaPrefix), " ")
+			body, afterBody, ok := readStanzaBody(rest)
+			if !ok || fields[0] == "" {
+				return nil, errInvalidEncryptedFile
+			}
+			result.stanzas = append(result.stanzas, &stanza{fields[0], fields[1:], body})
+			data = afterBody
+		case strings.HasPrefix(line, macPrefix+" "):
+			mac, ok := decodeBase64(strings.TrimPrefix(line, macPrefix+" "))
+			if !ok {
+				return nil, errInvalidEncryptedFile
+			}
+			result.macMessage = content[:start+len(macPrefix)]
+			result.mac = mac
+			result.payload = rest
+			return result, nil
+		default:
+			return nil, errInvalidEncryptedFile
+		}
+	}
+}
+
+// isPassphraseEncrypted checks the rule that a file encrypted with a passphrase can't have any other recipients
+func (f *encryptedFile) isPassphraseEncrypted() bool {
+	return len(f.stanzas) == 1 && f.stanzas[0].kind == scryptStanzaType
+}
+
+func hkdfKey(secret, salt []byte, info string) []byte {
+	key := make([]byte, chacha20poly1305.KeySize)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key)
+	return key
+}
+
+func (f *encryptedFile) unwrapFileKey(passphrase []byte) ([]byte, error) {
+	if !f.isPassphraseEncrypted() {
+		return nil, errNotPassphraseEncrypted
+	}
+
+	s := f.stanzas[0]
+	if len(s.args) != 2 {
+		return nil, errInvalidEncryptedFile
+	}
+	salt, ok := decodeBase64(s.args[0])
+	if !ok || len(salt) != scryptSaltSize {
+		return nil, errInvalidEncryptedFile
+	}
+	workFactor, e := strconv.Atoi(s.args[1])
+	if e != nil || workFactor <= 0 || workFactor > maximumScryptWorkFactor || s.args[1] != strconv.Itoa(workFactor) {
+		return nil, errInvalidEncryptedFile
+	}
+
+	key, e := scrypt.Key(passphrase, append([]byte(scryptLabel), salt...), 1<<workFactor, 8, 1, chacha20poly1305.KeySize)
+	if e != nil {
+		return nil, e
+	}
+	aead, e := chacha20poly1305.New(key)
+	if e != nil {
+		return nil, e
+	}
+
+	fileKey, e := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.body, nil)
+	if e != nil || len(fileKey) != fileKeySize {
+		return nil, api.ErrIncorrectPassphrase
+	}
+	return fileKey, nil
+}
+
+// chunkNonce is the counter of the chunk followed by a flag that is set for the last chunk
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func decryptPayload(fileKey, payload []byte) ([]byte, error) {
+	if len(payload) < payloadNonceSize {
+		return nil, errInvalidEncryptedFile
+	}
+	aead, e := chacha20poly1305.New(hkdfKey(fileKey, payload[:payloadNonceSize], "payload"))
+	if e != nil {
+		return nil, e
+	}
+
+	data := payload[payloadNonceSize:]
+	result := []byte{}
+	for counter := uint64(0); ; counter++ {
+		size := payloadChunkSize + aead.Overhead()
+		if len(data) < size {
+			size = len(data)
+		}
+		last := size == len(data)
+
+		chunk, e := aead.Open(nil, chunkNonce(counter, last), data[:size], nil)
+		if e != nil {
+			return nil, errInvalidEncryptedFile
+		}
+		result = append(result, chunk...)
+		data = data[size:]
+		if last {
+			return result, nil
+		}
+	}
+}
+
+// decrypt implements the scrypt recipient type of the age format, which is the only one that can be
+// decrypted without an identity
+func (f *encryptedFile) decrypt(passphrase []byte) ([]byte, error) {
+	fileKey, e := f.unwrapFileKey(passphrase)
+	if e != nil {
+		return nil, e
+	}
+
+	mac := hmac.New(sha256.New, hkdfKey(fileKey, nil, "header"))
+	mac.Write(f.macMessage)
+	if !hmac.Equal(mac.Sum(nil), f.mac) {
+		return nil, errInvalidEncryptedFile
+	}
+
+	return decryptPayload(fileKey, f.payload)
+}
@@ -0,0 +1,92 @@
+package age
+
+import "github.com/digitalautonomy/keymirror/api"
+
+func (s *ageSuite) Test_isEncryptedFile_recognizesBinaryAndArmoredFiles() {
+	s.True(isEncryptedFile(encryptedIdentityFileContentForTest()))
+	s.True(isEncryptedFile([]byte("\n" + armoredIdentityFileForTest)))
+	s.False(isEncryptedFile([]byte(identityFileForTest)))
+}
+
+func (s *ageSuite) Test_encryptedFile_decrypt_decryptsFilesEncryptedWithAPassphrase() {
+	for _, content := range [][]byte{encryptedIdentityFileContentForTest(), []byte(armoredIdentityFileForTest)} {
+		f, e := parseEncryptedFile(content)
+		s.Require().NoError(e)
+		s.True(f.isPassphraseEncrypted())
+
+		decrypted, e := f.decrypt([]byte("secret"))
+		s.Require().NoError(e)
+		s.Equal(identityFileForTest, string(decrypted))
+	}
+}
+
+func (s *ageSuite) Test_encryptedFile_decrypt_returnsAnErrorForTheWrongPassphrase() {
+	f, e := parseEncryptedFile(encryptedIdentityFileContentForTest())
+	s.Require().NoError(e)
+
+	_, e = f.decrypt([]byte("not the secret"))
+	s.Equal(api.ErrIncorrectPassphrase, e)
+}
+
+func (s *ageSuite) Test_encryptedFile_decrypt_checksTheMACOfTheHeader() {
+	f, e := parseEncryptedFile(encryptedIdentityFileContentForTest())
+	s.Require().NoError(e)
+	f.mac[0] ^= 1
+
+	_, e = f.decrypt([]byte("secret"))
+	s.Equal(errInvalidEncryptedFile, e)
+}
+
+func (s *ageSuite) Test_encryptedFile_decrypt_onlyDecryptsFilesEncryptedWithAPassphrase() {
+	content := encryptedIdentityFileContentForTest()
+	content[len(versionLine)+4] = 'S'
+	f, e := parseEncryptedFile(content)
+	s.Require().NoError(e)
+
+	_, e = f.decrypt([]byte("secret"))
+	s.Equal(errNotPassphraseEncrypted, e)
+}
+
+func (s *ageSuite) Test_encryptedFile_decrypt_checksThePayload() {
+	content := encryptedIdentityFileContentForTest()
+	content[len(content)-1] ^= 1
+	f, e := parseEncryptedFile(content)
+	s.Require().NoError(e)
+
+	_, e = f.decrypt([]byte("secret"))
+	s.Equal(errInvalidEncryptedFile, e)
+}
+
+func (s *ageSuite) Test_parseEncryptedFile_readsAllStanzas() {
+	f, e := parseEncryptedFile([]byte(versionLine + "\n" +
+		"-> X25519 TEiF0ypqr+bpvcqXNyCVJpL7OuwPdVwPL7KQEbFDOCc\n" +
+		"EmECAEcKN+n/Vs9SbWiV+Hu0r+E8R77DdWYyd83nw7U\n" +
+		"-> grease-a b\n" +
+		"\n" +
+		"--- Vn+54jqiiUCE+WZcEVY3f1sqHjlu/z1LCQ/T7Xm7qI0\n" +
+		"payload"))
+	s.Require().NoError(e)
+	s.Require().Len(f.stanzas, 2)
+	s.Equal("X25519", f.stanzas[0].kind)
+	s.Equal([]string{"TEiF0ypqr+bpvcqXNyCVJpL7OuwPdVwPL7KQEbFDOCc"}, f.stanzas[0].args)
+	s.Len(f.stanzas[0].body, 32)
+	s.Equal("grease-a", f.stanzas[1].kind)
+	s.Empty(f.stanzas[1].body)
+	s.Equal([]byte("payload"), f.payload)
+	s.False(f.isPassphraseEncrypted())
+}
+
+func (s *ageSuite) Test_parseEncryptedFile_refusesInvalidFiles() {
+	invalid := []string{
+		identityFileForTest,
+		versionLine + "\n",
+		versionLine + "\n-> scrypt\nnot base64!\n--- AAAA\n",
+		versionLine + "\nsomething else\n--- AAAA\n",
+		versionLine + "\n--- not base64!\n",
+		"-----BEGIN AGE ENCRYPTED FILE-----\n!!\n-----END AGE ENCRYPTED FILE-----\n",
+	}
+	for _, content := range invalid {
+		_, e := parseEncryptedFile([]byte(content))
+		s.Equal(errInvalidEncryptedFile, e, content)
+	}
+}
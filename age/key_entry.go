@@ -0,0 +1,128 @@
+package age
+
+import "github.com/digitalautonomy/keymirror/api"
+
+// keyEntry represents an age X25519 key, with the files containing its recipient and its identity
+type keyEntry struct {
+	publicKey        []byte
+	label            string
+	publicLocations  []string
+	privateLocations []string
+	protected        bool
+}
+
+func appendIfMissing(l []string, v string) []string {
+	for _, e := range l {
+		if e == v {
+			return l
+		}
+	}
+	return append(l, v)
+}
+
+// ID implements the api.KeyEntry interface. It's the same as the ID of a WireGuard key with the same X25519 public key
+func (k *keyEntry) ID() string {
+	return api.MaterialID("x25519", k.publicKey)
+}
+
+func (k *keyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+// Locations returns each file only once, even when it contains both the identity and the recipient
+func (k *keyEntry) Locations() []string {
+	result := append([]string{}, k.publicLocations...)
+	for _, l := range k.privateLocations {
+		result = appendIfMissing(result, l)
+	}
+	return result
+}
+
+func (k *keyEntry) PublicKeyLocations() []string {
+	return k.publicLocations
+}
+
+func (k *keyEntry) PrivateKeyLocations() []string {
+	return k.privateLocations
+}
+
+func (k *keyEntry) KeyType() api.KeyType {
+	switch {
+	case len(k.privateLocations) == 0:
+		return api.PublicKeyType
+	case len(k.publicLocations) == 0:
+		return api.PrivateKeyType
+	}
+	return api.PairKeyType
+}
+
+func (k *keyEntry) Size() int {
+	return x25519KeyLength * 8
+}
+
+func (k *keyEntry) Algorithm() api.Algorithm {
+	return api.Age
+}
+
+// WithDigestContent implements the api.PublicKeyEntry interface. The content is the raw X25519 public key
+func (k *keyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.publicKey)
+}
+
+// UserID returns the comment written right before the key, if there is one
+func (k *keyEntry) UserID() string {
+	return k.label
+}
+
+// IsPasswordProtected implements the api.PrivateKeyEntry interface. It is true when the
+// identity was read from a file encrypted with a passphrase
+func (k *keyEntry) IsPasswordProtected() bool {
+	return k.protected
+}
+
+// AgeRecipient implements the api.AgeKeyEntry interface
+func (k *keyEntry) AgeRecipient() string {
+	return bech32Encode(recipientPrefix, k.publicKey)
+}
+
+// lockedIdentityFileEntry represents an identity file encrypted with a passphrase that hasn't been given,
+// so it isn't known which identities it contains
+type lockedIdentityFileEntry struct {
+	location string
+}
+
+func (k *lockedIdentityFileEntry) ID() string {
+	return api.LocationID(k.location)
+}
+
+func (k *lockedIdentityFileEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *lockedIdentityFileEntry) Locations() []string {
+	return []string{k.location}
+}
+
+func (k *lockedIdentityFileEntry) PublicKeyLocations() []string {
+	return nil
+}
+
+func (k *lockedIdentityFileEntry) PrivateKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *lockedIdentityFileEntry) KeyType() api.KeyType {
+	return api.PrivateKeyType
+}
+
+func (k *lockedIdentityFileEntry) Size() int {
+	return x25519KeyLength * 8
+}
+
+func (k *lockedIdentityFileEntry) Algorithm() api.Algorithm {
+	return api.Age
+}
+
+func (k *lockedIdentityFileEntry) IsPasswordProtected() bool {
+	return true
+}
@@ -0,0 +1,101 @@
+package age
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"golang.org/x/crypto/curve25519"
+)
+
+const commentPrefix = "#"
+const publicKeyComment = "public key:"
+const createdComment = "created:"
+
+const x25519KeyLength = 32
+
+// the human readable parts of the bech32 encoding of recipients and identities name the kind of key
+func init() {
+	api.RegisterWireNames(api.Age, recipientPrefix, strings.ToLower(identityPrefix))
+}
+
+// identity is an age X25519 identity. The announced public key comes from the comment age-keygen
+// writes before the identity, and is nil when there is no such comment
+type identity struct {
+	secret             []byte
+	publicKey          []byte
+	announcedPublicKey []byte
+	label              string
+}
+
+// matchesAnnouncedPublicKey returns false when the comment before the identity names another public key,
+// which usually means that the file was edited by hand
+func (i *identity) matchesAnnouncedPublicKey() bool {
+	return i.announcedPublicKey == nil || bytes.Equal(i.publicKey, i.announcedPublicKey)
+}
+
+type recipient struct {
+	publicKey []byte
+	label     string
+}
+
+// keyFile holds the identities and recipients of a file. Identity files and recipient files use the
+// same format, with one key on each line, and comments starting with #
+type keyFile struct {
+	identities []*identity
+	recipients []*recipient
+}
+
+func decodeKey(s, hrp string) ([]byte, bool) {
+	h, data, e := bech32Decode(s)
+	if e != nil || h != hrp || len(data) != x25519KeyLength {
+		return nil, false
+	}
+	return data, true
+}
+
+func decodeRecipient(s string) ([]byte, bool) {
+	return decodeKey(s, recipientPrefix)
+}
+
+func decodeIdentity(s string) ([]byte, bool) {
+	return decodeKey(s, strings.ToLower(identityPrefix))
+}
+
+func x25519PublicKeyOf(secret []byte) []byte {
+	pub, _ := curve25519.X25519(secret, curve25519.Basepoint)
+	return pub
+}
+
+// parseKeyFile reads the native age keys of the file. Other lines, like SSH and plugin recipients,
+// are skipped. A comment right before a key is used as its label, except for the comments age-keygen writes
+func parseKeyFile(content string) *keyFile {
+	result := &keyFile{}
+	label := ""
+	var announced []byte
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, commentPrefix):
+			comment := strings.TrimSpace(strings.TrimPrefix(line, commentPrefix))
+			switch {
+			case strings.HasPrefix(comment, publicKeyComment):
+				announced, _ = decodeRecipient(strings.TrimSpace(strings.TrimPrefix(comment, publicKeyComment)))
+			case !strings.HasPrefix(comment, createdComment):
+				label = comment
+			}
+			continue
+		case strings.HasPrefix(line, identityPrefix):
+			if secret, ok := decodeIdentity(line); ok {
+				result.identities = append(result.identities, &identity{secret, x25519PublicKeyOf(secret), announced, label})
+			}
+		case strings.HasPrefix(line, recipientPrefix+"1"):
+			if pub, ok := decodeRecipient(line); ok {
+				result.recipients = append(result.recipients, &recipient{pub, label})
+			}
+		}
+		label, announced = "", nil
+	}
+	return result
+}
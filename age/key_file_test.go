@@ -0,0 +1,78 @@
+package age
+
+import (
+	"encoding/base64"
+)
+
+// identityFileForTest was created by age-keygen, with the creation time changed
+const identityFileForTest = `# created: 2026-10-19T01:00:00Z
+# public key: age1r9ldt4t9adx77qfnzf2gxljppf0ews9mr5nnpsvh3dw9h5hgkdjqdjf2ng
+AGE-SECRET-KEY-1HVP5G572D55AWYD02UWVY0YUX407NKQEX5WD9GUPHU2VTNZ4Y0GQNRCJND
+`
+
+const recipientForTest = "age1r9ldt4t9adx77qfnzf2gxljppf0ews9mr5nnpsvh3dw9h5hgkdjqdjf2ng"
+
+// encryptedIdentityFileForTest is identityFileForTest encrypted by age with the passphrase "secret",
+// using a work factor of 10 so the tests run fast
+const encryptedIdentityFileForTest = "" +
+	"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IHNjcnlwdCA4V3NsZksyaTVGelpuSlBWWGo0V09BIDEw" +
+	"Cmk4ZW1xSnZnT2JMcCtCZlVHZzNkdkx3Y3Q5N0JxVnlyQ1ZCdnl5NGpEUWsKLS0tIE93TUlhU1Nx" +
+	"anFiYjVaUXBsOVlRS3RxQkxadUhGd3FpTjh1enFzYU4xa1UKLBPhi9M0rzUT49Bg/SLPGgITg4Is" +
+	"mU/WShyRZ8N6nui/pb4WaFFgzAI3lQHwKxAJ3PFOEgqdMwO3T0AHGHriUfaXKosGxemLZnuEg4a1" +
+	"gG6bGbewcJI6hIZ2KFjpFxubNEebdkKiW3AH3X+S0cFQcxKNYW303hEqmnecV1ryUn3Qpwx6NwT8" +
+	"p3jO4SFF/FcL3k9Vb0npsR3EOLSdngmiFRBVdLsaz1uVJh/NJ4+zw+50IiUT0dyov8stUerjTLB4" +
+	"AVnxBSXJvuoZwYMTQ850VakkV9FPbb3K"
+
+// armoredIdentityFileForTest is identityFileForTest encrypted in the same way, with the armor option
+const armoredIdentityFileForTest = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IHNjcnlwdCBqZWhKT2hmaWViNGVLdk0z
+eGwyVHpBIDEwClBIY1hVUFN2WWJ1TWphb3ZzMnFEc3hSR2d2SDZYNkRvTXVrdjJB
+Z2xFR2MKLS0tIGVPVlhlaXAyQWhld2VweStYN1llMHQ5aCtmdDFmRnB2V3BNQ25X
+NG5kWVEKI5It17PMAj4eihR/uPy/26d7JPzZix1+LUyDk5e79RRSDHA/iIGX4rjQ
++IQ8fl0vExpgnOkjce2/sQpFhH1X0CpNhREh08fYMWjmzPGLyI1NhQ6sKTRoc7Vn
+dRO/St7nrpj0aAnURBAqMnPhYOtBJx35sWWO0ig8J0ILCerDWlmLjgeUywEUCfbl
+EF5I1unyNTkGy+zAvu0SfqwXHaMTgIJy574h6pPaMEtzhfYBVlGA+BJ+Jgi/tqWd
+/w3n5wdf7baKvBsgRd+QI4MENlnesIM3jFPO/t6l
+-----END AGE ENCRYPTED FILE-----
+`
+
+func encryptedIdentityFileContentForTest() []byte {
+	data, _ := base64.StdEncoding.DecodeString(encryptedIdentityFileForTest)
+	return data
+}
+
+func (s *ageSuite) Test_parseKeyFile_readsIdentitiesWithTheirPublicKeys() {
+	f := parseKeyFile(identityFileForTest)
+	s.Empty(f.recipients)
+	s.Require().Len(f.identities, 1)
+
+	i := f.identities[0]
+	s.Equal(recipientForTest, bech32Encode(recipientPrefix, i.publicKey))
+	s.Equal(i.publicKey, i.announcedPublicKey)
+	s.True(i.matchesAnnouncedPublicKey())
+	s.Empty(i.label)
+	s.Len(i.secret, 32)
+}
+
+func (s *ageSuite) Test_parseKeyFile_readsRecipientsWithTheCommentBeforeThemAsLabel() {
+	f := parseKeyFile("# Alice's laptop\n" +
+		recipientForTest + "\n" +
+		"\n" +
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHsKLqeplhpW+uObz5dvMgjz1OxfM/XXUB+VHtZ6isGN bob@example.org\n" +
+		"  age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p  \n" +
+		"age1notavalidrecipient\n")
+	s.Empty(f.identities)
+	s.Require().Len(f.recipients, 2)
+
+	s.Equal(recipientForTest, bech32Encode(recipientPrefix, f.recipients[0].publicKey))
+	s.Equal("Alice's laptop", f.recipients[0].label)
+	s.Equal("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", bech32Encode(recipientPrefix, f.recipients[1].publicKey))
+	s.Empty(f.recipients[1].label)
+}
+
+func (s *ageSuite) Test_parseKeyFile_noticesCommentsWithTheWrongPublicKey() {
+	f := parseKeyFile("# public key: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\n" +
+		"AGE-SECRET-KEY-1HVP5G572D55AWYD02UWVY0YUX407NKQEX5WD9GUPHU2VTNZ4Y0GQNRCJND\n")
+	s.Require().Len(f.identities, 1)
+	s.False(f.identities[0].matchesAnnouncedPublicKey())
+}
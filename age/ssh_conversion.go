@@ -0,0 +1,41 @@
+package age
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/edwards"
+)
+
+const recipientPrefix = "age"
+const identityPrefix = "AGE-SECRET-KEY-"
+
+// ErrUnsupportedKey is returned when trying to convert a key that is not an Ed25519 key
+var ErrUnsupportedKey = errors.New("only Ed25519 keys can be converted to age keys")
+
+// RecipientFrom returns the age X25519 recipient, starting with "age1", corresponding to the given public key
+func RecipientFrom(pub crypto.PublicKey) (string, error) {
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	u, e := edwards.PublicKeyToX25519(edPub)
+	if e != nil {
+		return "", e
+	}
+
+	return bech32Encode(recipientPrefix, u), nil
+}
+
+// IdentityFrom returns the age X25519 identity, starting with "AGE-SECRET-KEY-1", corresponding to the given private key
+func IdentityFrom(priv crypto.PrivateKey) (string, error) {
+	edPriv, ok := priv.(ed25519.PrivateKey)
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	return strings.ToUpper(bech32Encode(identityPrefix, edwards.PrivateKeyToX25519(edPriv))), nil
+}
@@ -0,0 +1,52 @@
+package age
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/digitalautonomy/keymirror/edwards"
+	"golang.org/x/crypto/curve25519"
+)
+
+func (s *ageSuite) Test_RecipientFrom_returnsAnAgeRecipient() {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	priv := ed25519.NewKeyFromSeed(seed)
+
+	r, e := RecipientFrom(priv.Public())
+	s.NoError(e)
+	s.Equal("age1mp0q0mpzkzkcs9fhct6y6e3drg2re7psc4av5sc9mpw84y8kkchq6r9kjp", r)
+
+	hrp, u, e := bech32Decode(r)
+	s.NoError(e)
+	s.Equal("age", hrp)
+	expected, _ := curve25519.X25519(edwards.PrivateKeyToX25519(priv), curve25519.Basepoint)
+	s.Equal(expected, u)
+}
+
+func (s *ageSuite) Test_RecipientFrom_failsForKeysThatAreNotEd25519() {
+	k, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, e := RecipientFrom(k.Public())
+	s.Equal(ErrUnsupportedKey, e)
+}
+
+func (s *ageSuite) Test_IdentityFrom_returnsAnUppercaseAgeIdentity() {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+
+	i, e := IdentityFrom(ed25519.NewKeyFromSeed(seed))
+	s.NoError(e)
+	s.Equal("AGE-SECRET-KEY-1XP7G8PJ09QEUKSN69MCUQZSP8N7L7FMGMXQVPGA9YRCQDYZDA98STDSL3D", i)
+
+	hrp, scalar, e := bech32Decode(i)
+	s.NoError(e)
+	s.Equal("age-secret-key-", hrp)
+	s.Equal("307c83864f2833cb427a2ef1c00a013cfdff2768d980c0a3a520f006904de94f", hex.EncodeToString(scalar))
+}
+
+func (s *ageSuite) Test_IdentityFrom_failsForKeysThatAreNotEd25519() {
+	k, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, e := IdentityFrom(k)
+	s.Equal(ErrUnsupportedKey, e)
+}
@@ -1,23 +1,370 @@
 package api
 
+import (
+	"fmt"
+	"sync"
+)
+
+// AlgorithmStatus tells whether keys of an algorithm should still be used
+type AlgorithmStatus string
+
+const RecommendedStatus = AlgorithmStatus("recommended")
+const LegacyStatus = AlgorithmStatus("legacy")
+const DeprecatedStatus = AlgorithmStatus("deprecated")
+const BrokenStatus = AlgorithmStatus("broken")
+
+// statusOrder goes from the best status to the worst one
+var statusOrder = []AlgorithmStatus{RecommendedStatus, LegacyStatus, DeprecatedStatus, BrokenStatus}
+
+func worstStatus(a, b AlgorithmStatus) AlgorithmStatus {
+	for _, s := range statusOrder {
+		if s == a {
+			return b
+		}
+		if s == b {
+			return a
+		}
+	}
+	return a
+}
+
+// statusForStrength returns the status of keys with the security strength, following the strengths
+// NIST SP 800-57 considers acceptable
+func statusForStrength(bits int) AlgorithmStatus {
+	switch {
+	case bits < 80:
+		return BrokenStatus
+	case bits < 112:
+		return DeprecatedStatus
+	case bits < 128:
+		return LegacyStatus
+	}
+	return RecommendedStatus
+}
+
 type Algorithm interface {
 	HasKeySize() bool
 	Name() string
+	// FixedKeySize is the size of all keys of algorithms without a key size, and zero for other algorithms
+	FixedKeySize() int
+	// WireNames are the names the algorithm has in the formats keys are stored in, like "ssh-rsa"
+	WireNames() []string
+	OIDs() []string
+	IsValidKeySize(size int) bool
+	// SecurityStrength is the estimated number of bits of security of keys of the size
+	SecurityStrength(size int) int
+	// Status tells whether keys of the size should still be used, taking both the algorithm and the
+	// security strength of the size into account
+	Status(size int) AlgorithmStatus
+}
+
+// AlgorithmSpec describes an algorithm to register
+type AlgorithmSpec struct {
+	Name      string
+	WireNames []string
+	OIDs      []string
+	// FixedKeySize is set for algorithms where all keys have the same size. Otherwise the size of
+	// keys is limited by KeySizes when there is only a few valid sizes, or by MinKeySize and MaxKeySize
+	FixedKeySize int
+	KeySizes     []int
+	MinKeySize   int
+	MaxKeySize   int
+	// Strength returns the security strength of keys of the size
+	Strength func(size int) int
+	Status   AlgorithmStatus
 }
 
 type algorithm struct {
-	hasKeySize bool
-	name       string
+	spec      AlgorithmSpec
+	wireNames []string
+	// registry is the registry the algorithm belongs to, that guards its wire names
+	registry *algorithmRegistry
 }
 
 func (a *algorithm) HasKeySize() bool {
-	return a.hasKeySize
+	return a.spec.FixedKeySize == 0
 }
 
 func (a *algorithm) Name() string {
-	return a.name
+	return a.spec.Name
+}
+
+func (a *algorithm) FixedKeySize() int {
+	return a.spec.FixedKeySize
+}
+
+func (a *algorithm) WireNames() []string {
+	a.registry.lock.RLock()
+	defer a.registry.lock.RUnlock()
+	return append([]string(nil), a.wireNames...)
+}
+
+func (a *algorithm) OIDs() []string {
+	a.registry.lock.RLock()
+	defer a.registry.lock.RUnlock()
+	return append([]string(nil), a.spec.OIDs...)
+}
+
+func (a *algorithm) IsValidKeySize(size int) bool {
+	if !a.HasKeySize() {
+		return size == 0 || size == a.spec.FixedKeySize
+	}
+	if len(a.spec.KeySizes) > 0 {
+		return contains(a.spec.KeySizes, size)
+	}
+	return size >= a.spec.MinKeySize && (a.spec.MaxKeySize == 0 || size <= a.spec.MaxKeySize)
+}
+
+func (a *algorithm) SecurityStrength(size int) int {
+	if !a.HasKeySize() {
+		size = a.spec.FixedKeySize
+	}
+	return a.spec.Strength(size)
+}
+
+func (a *algorithm) Status(size int) AlgorithmStatus {
+	return worstStatus(a.spec.Status, statusForStrength(a.SecurityStrength(size)))
+}
+
+func contains(sizes []int, size int) bool {
+	for _, s := range sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// finiteFieldStrength is the security strength of RSA, DSA and ElGamal keys of the size, from the
+// table in NIST SP 800-57, part 1
+func finiteFieldStrength(size int) int {
+	switch {
+	case size >= 15360:
+		return 256
+	case size >= 7680:
+		return 192
+	case size >= 3072:
+		return 128
+	case size >= 2048:
+		return 112
+	case size >= 1024:
+		return 80
+	}
+	return 0
+}
+
+// ellipticCurveStrength is the security strength of keys on a curve of the size
+func ellipticCurveStrength(size int) int {
+	if size > 512 {
+		return 256
+	}
+	return size / 2
+}
+
+type algorithmRegistry struct {
+	lock       sync.RWMutex
+	algorithms []*algorithm
+	byName     map[string]*algorithm
+	byWireName map[string]*algorithm
+	byOID      map[string]*algorithm
+}
+
+func newAlgorithmRegistry() *algorithmRegistry {
+	return &algorithmRegistry{
+		byName:     map[string]*algorithm{},
+		byWireName: map[string]*algorithm{},
+		byOID:      map[string]*algorithm{},
+	}
+}
+
+var registry = newAlgorithmRegistry()
+
+// RegisterAlgorithm adds the algorithm to the ones that can be looked up. It panics when an algorithm
+// with the same name, wire name or OID is already registered, since that is a developer error
+func RegisterAlgorithm(spec AlgorithmSpec) Algorithm {
+	return registry.register(spec)
+}
+
+// RegisterWireNames adds names the algorithm has in a format. Packages that read keys in a format register
+// the names used by it, so the algorithm of the keys can be looked up by the name stored with them
+func RegisterWireNames(a Algorithm, names ...string) {
+	alg := a.(*algorithm)
+	alg.registry.registerWireNames(alg, names)
 }
 
-var RSA Algorithm = &algorithm{hasKeySize: true, name: "RSA"}
-var Ed25519 Algorithm = &algorithm{hasKeySize: false, name: "Ed25519"}
-var DSA Algorithm = &algorithm{hasKeySize: true, name: "DSA"}
+// RegisterOIDs adds OIDs, in dotted form, that identify the algorithm in a format, the same way as RegisterWireNames
+func RegisterOIDs(a Algorithm, oids ...string) {
+	alg := a.(*algorithm)
+	alg.registry.registerOIDs(alg, oids)
+}
+
+// register checks all of the names and OIDs before adding any of them, so nothing is registered when
+// it panics
+func (r *algorithmRegistry) register(spec AlgorithmSpec) *algorithm {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.byName[spec.Name]; exists {
+		panic(fmt.Sprintf("the algorithm %s is already registered, which is a developer error", spec.Name))
+	}
+	a := &algorithm{spec: spec, registry: r}
+	r.checkUnused(r.byOID, a, spec.OIDs)
+	r.checkUnused(r.byWireName, a, spec.WireNames)
+
+	a.spec.OIDs = nil
+	r.addOIDs(a, spec.OIDs)
+	r.addWireNames(a, spec.WireNames)
+	r.byName[spec.Name] = a
+	r.algorithms = append(r.algorithms, a)
+	return a
+}
+
+func (r *algorithmRegistry) registerWireNames(a *algorithm, names []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checkUnused(r.byWireName, a, names)
+	r.addWireNames(a, names)
+}
+
+func (r *algorithmRegistry) registerOIDs(a *algorithm, oids []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checkUnused(r.byOID, a, oids)
+	r.addOIDs(a, oids)
+}
+
+func (r *algorithmRegistry) addWireNames(a *algorithm, names []string) {
+	for _, n := range names {
+		if _, exists := r.byWireName[n]; !exists {
+			r.byWireName[n] = a
+			a.wireNames = append(a.wireNames, n)
+		}
+	}
+}
+
+func (r *algorithmRegistry) addOIDs(a *algorithm, oids []string) {
+	for _, oid := range oids {
+		if _, exists := r.byOID[oid]; !exists {
+			r.byOID[oid] = a
+			a.spec.OIDs = append(a.spec.OIDs, oid)
+		}
+	}
+}
+
+// checkUnused panics when any of the names is already used by another algorithm. Formats can use
+// the same name for the same algorithm, like the key formats of libgcrypt
+func (r *algorithmRegistry) checkUnused(m map[string]*algorithm, a *algorithm, names []string) {
+	for _, n := range names {
+		if existing, exists := m[n]; exists && existing != a {
+			panic(fmt.Sprintf("%s is already used by the algorithm %s, which is a developer error", n, existing.Name()))
+		}
+	}
+}
+
+func (r *algorithmRegistry) lookUp(m map[string]*algorithm, name string) (Algorithm, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	a, ok := m[name]
+	if !ok {
+		return nil, false
+	}
+	return a, true
+}
+
+func (r *algorithmRegistry) all() []Algorithm {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	result := make([]Algorithm, 0, len(r.algorithms))
+	for _, a := range r.algorithms {
+		result = append(result, a)
+	}
+	return result
+}
+
+// AlgorithmNamed returns the registered algorithm with the name
+func AlgorithmNamed(name string) (Algorithm, bool) {
+	return registry.lookUp(registry.byName, name)
+}
+
+// AlgorithmWithWireName returns the registered algorithm with the name in one of the formats keys are stored in
+func AlgorithmWithWireName(name string) (Algorithm, bool) {
+	return registry.lookUp(registry.byWireName, name)
+}
+
+// AlgorithmWithOID returns the registered algorithm with the OID, in dotted form
+func AlgorithmWithOID(oid string) (Algorithm, bool) {
+	return registry.lookUp(registry.byOID, oid)
+}
+
+// Algorithms returns all registered algorithms, in the order they were registered
+func Algorithms() []Algorithm {
+	return registry.all()
+}
+
+func fixedStrength(bits int) func(int) int {
+	return func(int) int {
+		return bits
+	}
+}
+
+var RSA = RegisterAlgorithm(AlgorithmSpec{
+	Name:       "RSA",
+	MinKeySize: 1024,
+	MaxKeySize: 16384,
+	Strength:   finiteFieldStrength,
+	Status:     RecommendedStatus,
+})
+
+var Ed25519 = RegisterAlgorithm(AlgorithmSpec{
+	Name:         "Ed25519",
+	FixedKeySize: 256,
+	Strength:     fixedStrength(128),
+	Status:       RecommendedStatus,
+})
+
+// DSA is deprecated whatever the key size, since OpenSSH disabled it and FIPS 186-5 removed it
+var DSA = RegisterAlgorithm(AlgorithmSpec{
+	Name:       "DSA",
+	MinKeySize: 1024,
+	MaxKeySize: 3072,
+	Strength:   finiteFieldStrength,
+	Status:     DeprecatedStatus,
+})
+
+var ECDSA = RegisterAlgorithm(AlgorithmSpec{
+	Name:     "ECDSA",
+	KeySizes: []int{256, 384, 512, 521},
+	Strength: ellipticCurveStrength,
+	Status:   RecommendedStatus,
+})
+
+var X25519 = RegisterAlgorithm(AlgorithmSpec{
+	Name:         "X25519",
+	FixedKeySize: 256,
+	Strength:     fixedStrength(128),
+	Status:       RecommendedStatus,
+})
+
+// ElGamal is only still used for the encryption subkeys of old OpenPGP keys
+var ElGamal = RegisterAlgorithm(AlgorithmSpec{
+	Name:       "ElGamal",
+	MinKeySize: 1024,
+	MaxKeySize: 4096,
+	Strength:   finiteFieldStrength,
+	Status:     LegacyStatus,
+})
+
+var ECDH = RegisterAlgorithm(AlgorithmSpec{
+	Name:     "ECDH",
+	KeySizes: []int{256, 384, 512, 521},
+	Strength: ellipticCurveStrength,
+	Status:   RecommendedStatus,
+})
+
+// Age is used for the native X25519 keys of age, that are encoded differently from other X25519 keys
+var Age = RegisterAlgorithm(AlgorithmSpec{
+	Name:         "age",
+	FixedKeySize: 256,
+	Strength:     fixedStrength(128),
+	Status:       RecommendedStatus,
+})
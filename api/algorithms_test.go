@@ -0,0 +1,127 @@
+package api
+
+func (s *apiSuite) Test_Algorithm_IsValidKeySize_followsTheKeySizeRules() {
+	s.True(RSA.IsValidKeySize(3072))
+	s.False(RSA.IsValidKeySize(512), "RSA keys have a minimum size")
+	s.True(ECDSA.IsValidKeySize(521))
+	s.False(ECDSA.IsValidKeySize(300), "ECDSA keys are only valid with the sizes of the curves")
+	s.True(Ed25519.IsValidKeySize(0))
+	s.True(Ed25519.IsValidKeySize(256))
+	s.False(Ed25519.IsValidKeySize(512))
+}
+
+func (s *apiSuite) Test_Algorithm_SecurityStrength_dependsOnTheKeySize() {
+	s.Equal(80, RSA.SecurityStrength(1024))
+	s.Equal(112, RSA.SecurityStrength(2048))
+	s.Equal(128, RSA.SecurityStrength(4096))
+	s.Equal(192, ECDSA.SecurityStrength(384))
+	s.Equal(256, ECDSA.SecurityStrength(521))
+	s.Equal(128, Ed25519.SecurityStrength(0), "all keys of algorithms without a key size have the same strength")
+}
+
+func (s *apiSuite) Test_Algorithm_Status_takesTheWorstOfTheAlgorithmAndTheKeySize() {
+	s.Equal(RecommendedStatus, RSA.Status(3072))
+	s.Equal(LegacyStatus, RSA.Status(2048))
+	s.Equal(DeprecatedStatus, RSA.Status(1024))
+	s.Equal(BrokenStatus, RSA.Status(512))
+	s.Equal(DeprecatedStatus, DSA.Status(3072))
+	s.Equal(BrokenStatus, DSA.Status(512))
+	s.Equal(LegacyStatus, ElGamal.Status(4096))
+	s.Equal(RecommendedStatus, X25519.Status(0))
+}
+
+func (s *apiSuite) Test_AlgorithmNamed_findsTheRegisteredAlgorithms() {
+	a, ok := AlgorithmNamed("ECDSA")
+	s.True(ok)
+	s.Equal(ECDSA, a)
+
+	_, ok = AlgorithmNamed("Lamport")
+	s.False(ok)
+}
+
+func (s *apiSuite) Test_algorithmRegistry_register_makesTheAlgorithmAvailable() {
+	r := newAlgorithmRegistry()
+	a := r.register(AlgorithmSpec{
+		Name:       "Test",
+		WireNames:  []string{"test"},
+		OIDs:       []string{"1.3.6.1.4.1.99999.1"},
+		MinKeySize: 128,
+		Strength:   func(size int) int { return size },
+		Status:     LegacyStatus,
+	})
+
+	s.True(a.HasKeySize())
+	s.Equal([]string{"test"}, a.WireNames())
+	s.Equal([]string{"1.3.6.1.4.1.99999.1"}, a.OIDs())
+	s.Equal([]Algorithm{a}, r.all())
+	found, ok := r.lookUp(r.byWireName, "test")
+	s.True(ok)
+	s.Equal(a, found)
+	found, ok = r.lookUp(r.byOID, "1.3.6.1.4.1.99999.1")
+	s.True(ok)
+	s.Equal(a, found)
+
+	RegisterWireNames(a, "test-2")
+	s.Equal([]string{"test", "test-2"}, a.WireNames())
+	found, ok = r.lookUp(r.byWireName, "test-2")
+	s.True(ok)
+	s.Equal(a, found)
+	_, ok = AlgorithmWithWireName("test-2")
+	s.False(ok, "the wire name is only registered in the registry of the algorithm")
+
+	RegisterOIDs(a, "1.3.6.1.4.1.99999.2")
+	s.Equal([]string{"1.3.6.1.4.1.99999.1", "1.3.6.1.4.1.99999.2"}, a.OIDs())
+	found, ok = r.lookUp(r.byOID, "1.3.6.1.4.1.99999.2")
+	s.True(ok)
+	s.Equal(a, found)
+}
+
+func (s *apiSuite) Test_algorithmRegistry_registersTheSameNamesForTheSameAlgorithmOnlyOnce() {
+	r := newAlgorithmRegistry()
+	a := r.register(AlgorithmSpec{Name: "Test", WireNames: []string{"test", "test"}, OIDs: []string{"1.2.3"}})
+
+	RegisterWireNames(a, "test", "other", "other")
+	RegisterOIDs(a, "1.2.3")
+
+	s.Equal([]string{"test", "other"}, a.WireNames())
+	s.Equal([]string{"1.2.3"}, a.OIDs())
+}
+
+func (s *apiSuite) Test_algorithmRegistry_register_panicsWithoutRegisteringAnything_whenANameIsAlreadyUsed() {
+	r := newAlgorithmRegistry()
+	existing := r.register(AlgorithmSpec{Name: "Existing", WireNames: []string{"existing"}, OIDs: []string{"1.2.3"}})
+
+	s.Panics(func() {
+		r.register(AlgorithmSpec{Name: "Existing"})
+	}, "panics when the name is already used")
+
+	s.Panics(func() {
+		r.register(AlgorithmSpec{Name: "Other", WireNames: []string{"other"}, OIDs: []string{"1.2.4", "1.2.3"}})
+	}, "panics when the OID is already used")
+
+	s.Panics(func() {
+		r.register(AlgorithmSpec{Name: "Other", WireNames: []string{"other", "existing"}, OIDs: []string{"1.2.4"}})
+	}, "panics when the wire name is already used")
+
+	other := r.register(AlgorithmSpec{Name: "Other"})
+	s.Panics(func() {
+		RegisterWireNames(other, "other", "existing")
+	}, "panics when the wire name is used by another algorithm")
+
+	s.Panics(func() {
+		RegisterOIDs(other, "1.2.4", "1.2.3")
+	}, "panics when the OID is used by another algorithm")
+
+	s.Equal([]Algorithm{existing, other}, r.all())
+	s.Equal([]string{"existing"}, existing.WireNames())
+	s.Empty(other.WireNames())
+	s.Empty(other.OIDs())
+	_, ok := r.lookUp(r.byWireName, "other")
+	s.False(ok)
+	_, ok = r.lookUp(r.byOID, "1.2.4")
+	s.False(ok)
+}
+
+func (s *apiSuite) Test_Algorithms_returnsTheAlgorithmsInTheOrderTheyWereRegistered() {
+	s.Equal([]Algorithm{RSA, Ed25519, DSA, ECDSA, X25519, ElGamal, ECDH, Age}, Algorithms()[:8])
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type apiSuite struct {
+	suite.Suite
+}
+
+func TestAPISuite(t *testing.T) {
+	suite.Run(t, new(apiSuite))
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNotSupported is returned by combined key accesses when none of the sources supports the operation
+var ErrNotSupported = errors.New("none of the key accesses support this operation")
+
+// KeySource is a key access together with the name of where its keys come from, like "SSH" or "GnuPG"
+type KeySource struct {
+	Name   string
+	Access KeyAccess
+}
+
+// SourcedKeyAccess is implemented by key accesses that combine the keys of several sources. The failure of
+// a source is the reason its keys couldn't be listed the last time, or nil if they could
+type SourcedKeyAccess interface {
+	KeyAccess
+	Sources() []string
+	SourceOf(k KeyEntry) string
+	FailureOf(source string) error
+}
+
+// Combine returns a key access that lists the keys of all the sources, in order. The sources are listed
+// concurrently, and a source that fails doesn't stop the keys of the other sources from being listed. A key
+// is only listed once when more than one source reads the same files. Generating and importing keys is
+// done by the first source that supports it
+func Combine(sources ...KeySource) SourcedKeyAccess {
+	return &compositeKeyAccess{
+		sources:  sources,
+		sourceOf: map[string]string{},
+		failures: map[string]error{},
+	}
+}
+
+// compositeKeyAccess only lists the keys of its sources once at a time, since the sources are not
+// safe to use concurrently. The sources can ask for passwords while they are listed, and those
+// requests are answered from the goroutine that is listing the keys
+type compositeKeyAccess struct {
+	sources []KeySource
+
+	listing sync.Mutex
+
+	lock             sync.RWMutex
+	sourceOf         map[string]string
+	failures         map[string]error
+	passwords        PasswordProvider
+	passwordRequests chan passwordRequest
+}
+
+type passwordAnswer struct {
+	password []byte
+	ok       bool
+}
+
+type passwordRequest struct {
+	fileName  string
+	incorrect bool
+	answer    chan passwordAnswer
+}
+
+// listedKey is a key together with the index of the source that listed it
+type listedKey struct {
+	source int
+	key    KeyEntry
+}
+
+// streamKeysOf turns a source that panics into a failure of that source
+func streamKeysOf(ctx context.Context, a KeyAccess, found func(KeyEntry)) (failure error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure = fmt.Errorf("listing the keys failed: %v", r)
+		}
+	}()
+	return StreamKeys(ctx, a, found)
+}
+
+// streamAllSources lists all of the sources at the same time, sending their keys as they are found. It
+// returns the failures of the sources once all of them are done
+func (c *compositeKeyAccess) streamAllSources(ctx context.Context, keys chan<- listedKey) []error {
+	failures := make([]error, len(c.sources))
+	wg := sync.WaitGroup{}
+	for i, s := range c.sources {
+		wg.Add(1)
+		go func(i int, a KeyAccess) {
+			defer wg.Done()
+			failures[i] = streamKeysOf(ctx, a, func(k KeyEntry) {
+				keys <- listedKey{source: i, key: k}
+			})
+		}(i, s.Access)
+	}
+	wg.Wait()
+	return failures
+}
+
+func (c *compositeKeyAccess) setPasswordRequests(requests chan passwordRequest) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.passwordRequests = requests
+}
+
+func (c *compositeKeyAccess) forgetListedKeys() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.sourceOf = map[string]string{}
+	c.failures = map[string]error{}
+}
+
+// ListingOf identifies a key entry by its key and where it's stored, so the same key stored in other
+// places or formats by other sources is a different listing. The listing stays the same when the keys
+// are listed again, as long as the key is still stored in the same places
+func ListingOf(k KeyEntry) string {
+	return strings.Join(append([]string{k.ID()}, k.Locations()...), "\x00")
+}
+
+// rememberSourceOf returns false when the key was already listed, by a source that reads the same files
+func (c *compositeKeyAccess) rememberSourceOf(k listedKey) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	listing := ListingOf(k.key)
+	if _, listed := c.sourceOf[listing]; listed {
+		return false
+	}
+	c.sourceOf[listing] = c.sources[k.source].Name
+	return true
+}
+
+// rememberFailures keeps why sources failed. Sources that stopped because the listing was cancelled
+// didn't fail, so nothing is remembered in that case
+func (c *compositeKeyAccess) rememberFailures(ctx context.Context, failures []error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, e := range failures {
+		if e != nil {
+			c.failures[c.sources[i].Name] = e
+		}
+	}
+}
+
+// streamKeys gives found the keys of the sources as they are found, and answers the password requests
+// of the sources, all from the calling goroutine
+func (c *compositeKeyAccess) streamKeys(ctx context.Context, found func(listedKey)) error {
+	c.listing.Lock()
+	defer c.listing.Unlock()
+
+	requests := make(chan passwordRequest)
+	c.setPasswordRequests(requests)
+	defer c.setPasswordRequests(nil)
+	c.forgetListedKeys()
+
+	keys := make(chan listedKey)
+	done := make(chan []error)
+	go func() {
+		done <- c.streamAllSources(ctx, keys)
+	}()
+
+	for {
+		select {
+		case r := <-requests:
+			password, ok := c.askForPassword(r.fileName, r.incorrect)
+			r.answer <- passwordAnswer{password, ok}
+		case k := <-keys:
+			found(k)
+		case failures := <-done:
+			c.rememberFailures(ctx, failures)
+			return ctx.Err()
+		}
+	}
+}
+
+// StreamKeys gives found the keys of all of the sources in the order they are found
+func (c *compositeKeyAccess) StreamKeys(ctx context.Context, found func(KeyEntry)) error {
+	return c.streamKeys(ctx, func(k listedKey) {
+		if c.rememberSourceOf(k) {
+			found(k.key)
+		}
+	})
+}
+
+// AllKeys returns the keys of all of the sources, in the order of the sources. A source that fails
+// doesn't make listing fail, the failure is kept instead
+func (c *compositeKeyAccess) AllKeys(ctx context.Context) ([]KeyEntry, error) {
+	bySource := make([][]listedKey, len(c.sources))
+	if e := c.streamKeys(ctx, func(k listedKey) {
+		bySource[k.source] = append(bySource[k.source], k)
+	}); e != nil {
+		return nil, e
+	}
+
+	all := []KeyEntry{}
+	for _, keys := range bySource {
+		for _, k := range keys {
+			if c.rememberSourceOf(k) {
+				all = append(all, k.key)
+			}
+		}
+	}
+	return all, nil
+}
+
+func (c *compositeKeyAccess) Sources() []string {
+	result := []string{}
+	for _, s := range c.sources {
+		result = append(result, s.Name)
+	}
+	return result
+}
+
+// SourceOf returns the name of the source that listed the key the last time the keys were listed. The
+// key entry can be from an earlier listing, as long as the key is still stored in the same places
+func (c *compositeKeyAccess) SourceOf(k KeyEntry) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.sourceOf[ListingOf(k)]
+}
+
+func (c *compositeKeyAccess) FailureOf(source string) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.failures[source]
+}
+
+func (c *compositeKeyAccess) GenerateKey(o KeyGenerationOptions) (KeyEntry, error) {
+	for _, s := range c.sources {
+		if g, ok := s.Access.(KeyGenerator); ok {
+			return g.GenerateKey(o)
+		}
+	}
+	return nil, ErrNotSupported
+}
+
+func (c *compositeKeyAccess) ImportPublicKey(pub crypto.PublicKey, userID, fileName string) (KeyEntry, error) {
+	for _, s := range c.sources {
+		if i, ok := s.Access.(PublicKeyImporter); ok {
+			return i.ImportPublicKey(pub, userID, fileName)
+		}
+	}
+	return nil, ErrNotSupported
+}
+
+func (c *compositeKeyAccess) ImportPrivateKey(content []byte, fileName string) (KeyEntry, error) {
+	for _, s := range c.sources {
+		if i, ok := s.Access.(PrivateKeyImporter); ok {
+			return i.ImportPrivateKey(content, fileName)
+		}
+	}
+	return nil, ErrNotSupported
+}
+
+func (c *compositeKeyAccess) ImportPrivateKeyMaterial(priv crypto.PrivateKey, userID, fileName string, passphrase []byte) (KeyEntry, error) {
+	for _, s := range c.sources {
+		if i, ok := s.Access.(PrivateKeyMaterialImporter); ok {
+			return i.ImportPrivateKeyMaterial(priv, userID, fileName, passphrase)
+		}
+	}
+	return nil, ErrNotSupported
+}
+
+// SetPasswordProvider gives all of the sources that need passwords a provider that
+// forwards their requests to this one
+func (c *compositeKeyAccess) SetPasswordProvider(p PasswordProvider) {
+	c.lock.Lock()
+	c.passwords = p
+	c.lock.Unlock()
+
+	for _, s := range c.sources {
+		if u, ok := s.Access.(PasswordProviderUser); ok {
+			u.SetPasswordProvider(&forwardingPasswordProvider{c})
+		}
+	}
+}
+
+func (c *compositeKeyAccess) askForPassword(fileName string, incorrect bool) ([]byte, bool) {
+	c.lock.RLock()
+	p := c.passwords
+	c.lock.RUnlock()
+
+	if p == nil {
+		return nil, false
+	}
+	return p.PasswordFor(fileName, incorrect)
+}
+
+// forwardingPasswordProvider sends the requests of sources that are being listed to the goroutine
+// listing the keys, so the password provider is never used from more than one goroutine
+type forwardingPasswordProvider struct {
+	c *compositeKeyAccess
+}
+
+func (p *forwardingPasswordProvider) PasswordFor(fileName string, incorrect bool) ([]byte, bool) {
+	p.c.lock.RLock()
+	requests := p.c.passwordRequests
+	p.c.lock.RUnlock()
+
+	if requests == nil {
+		return p.c.askForPassword(fileName, incorrect)
+	}
+
+	answer := make(chan passwordAnswer)
+	requests <- passwordRequest{fileName: fileName, incorrect: incorrect, answer: answer}
+	a := <-answer
+	return a.password, a.ok
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"sync"
+)
+
+type fixedKeyAccess []KeyEntry
+
+func (ka fixedKeyAccess) AllKeys(context.Context) ([]KeyEntry, error) {
+	return ka, nil
+}
+
+type failingKeyAccess struct{}
+
+func (failingKeyAccess) AllKeys(context.Context) ([]KeyEntry, error) {
+	panic("the keyring is corrupted")
+}
+
+type erroringKeyAccess struct{}
+
+func (erroringKeyAccess) AllKeys(context.Context) ([]KeyEntry, error) {
+	return nil, errors.New("permission denied")
+}
+
+// streamingKeyAccess gives its keys one at a time, and waits for the test to let it continue
+// after each of them
+type streamingKeyAccess struct {
+	keys []KeyEntry
+	next chan bool
+}
+
+func (ka *streamingKeyAccess) AllKeys(context.Context) ([]KeyEntry, error) {
+	return ka.keys, nil
+}
+
+func (ka *streamingKeyAccess) StreamKeys(ctx context.Context, found func(KeyEntry)) error {
+	for _, k := range ka.keys {
+		found(k)
+		select {
+		case <-ka.next:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+type keyGeneratingAccess struct {
+	fixedKeyAccess
+	generated KeyEntry
+}
+
+func (ka *keyGeneratingAccess) GenerateKey(o KeyGenerationOptions) (KeyEntry, error) {
+	return ka.generated, nil
+}
+
+type importingAccess struct {
+	fixedKeyAccess
+	imported []string
+}
+
+func (ka *importingAccess) ImportPublicKey(pub crypto.PublicKey, userID, fileName string) (KeyEntry, error) {
+	ka.imported = append(ka.imported, fileName)
+	return nil, nil
+}
+
+func (ka *importingAccess) ImportPrivateKey(content []byte, fileName string) (KeyEntry, error) {
+	ka.imported = append(ka.imported, fileName)
+	return nil, nil
+}
+
+func (ka *importingAccess) ImportPrivateKeyMaterial(priv crypto.PrivateKey, userID, fileName string, passphrase []byte) (KeyEntry, error) {
+	ka.imported = append(ka.imported, fileName)
+	return nil, nil
+}
+
+// passwordAskingAccess asks for the password of its file every time the keys are listed
+type passwordAskingAccess struct {
+	fileName string
+	provider PasswordProvider
+	answers  []string
+}
+
+func (ka *passwordAskingAccess) SetPasswordProvider(p PasswordProvider) {
+	ka.provider = p
+}
+
+func (ka *passwordAskingAccess) AllKeys(context.Context) ([]KeyEntry, error) {
+	if password, ok := ka.provider.PasswordFor(ka.fileName, false); ok {
+		ka.answers = append(ka.answers, string(password))
+	}
+	return nil, nil
+}
+
+// passwordProviderForTest records the files it was asked about. It fails the test if it's used by
+// more than one goroutine at the same time
+type passwordProviderForTest struct {
+	s      *apiSuite
+	inUse  sync.Mutex
+	lock   sync.Mutex
+	files  []string
+	answer string
+}
+
+func (p *passwordProviderForTest) PasswordFor(fileName string, incorrect bool) ([]byte, bool) {
+	p.s.True(p.inUse.TryLock(), "the password provider should only be used by one goroutine at a time")
+	defer p.inUse.Unlock()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.files = append(p.files, fileName)
+	return []byte(p.answer), p.answer != ""
+}
+
+func (s *apiSuite) allKeysOf(ka KeyAccess) []KeyEntry {
+	keys, e := ka.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+type keyEntryForTest struct {
+	KeyEntry
+	name     string
+	location string
+}
+
+func (k *keyEntryForTest) ID() string {
+	return k.name
+}
+
+func (k *keyEntryForTest) Same(other KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *keyEntryForTest) Locations() []string {
+	return []string{k.location}
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_listsTheKeysOfAllSourcesInOrder() {
+	k1, k2, k3 := &keyEntryForTest{name: "1"}, &keyEntryForTest{name: "2"}, &keyEntryForTest{name: "3"}
+	ka := Combine(
+		KeySource{"first", fixedKeyAccess{k1, k2}},
+		KeySource{"empty", fixedKeyAccess{}},
+		KeySource{"last", fixedKeyAccess{k3}},
+	)
+
+	s.Equal([]KeyEntry{k1, k2, k3}, s.allKeysOf(ka))
+	s.Equal([]string{"first", "empty", "last"}, ka.Sources())
+	s.Equal("first", ka.SourceOf(k1))
+	s.Equal("first", ka.SourceOf(k2))
+	s.Equal("last", ka.SourceOf(k3))
+	s.Equal("", ka.SourceOf(&keyEntryForTest{name: "unknown"}))
+	s.Empty(s.allKeysOf(Combine()))
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_keepsListingTheOtherSourcesWhenOneFails() {
+	k1, k2 := &keyEntryForTest{name: "1"}, &keyEntryForTest{name: "2"}
+	ka := Combine(
+		KeySource{"first", fixedKeyAccess{k1}},
+		KeySource{"broken", failingKeyAccess{}},
+		KeySource{"last", fixedKeyAccess{k2}},
+	)
+
+	s.Equal([]KeyEntry{k1, k2}, s.allKeysOf(ka))
+	s.EqualError(ka.FailureOf("broken"), "listing the keys failed: the keyring is corrupted")
+	s.NoError(ka.FailureOf("first"))
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_keepsTheErrorsOfSourcesAsTheirFailures() {
+	k1 := &keyEntryForTest{name: "1"}
+	ka := Combine(KeySource{"first", fixedKeyAccess{k1}}, KeySource{"unreadable", erroringKeyAccess{}})
+
+	s.Equal([]KeyEntry{k1}, s.allKeysOf(ka))
+	s.EqualError(ka.FailureOf("unreadable"), "permission denied")
+}
+
+func (s *apiSuite) Test_Combine_StreamKeys_givesTheKeysAsSoonAsTheyAreFound() {
+	k1, k2, k3 := &keyEntryForTest{name: "1"}, &keyEntryForTest{name: "2"}, &keyEntryForTest{name: "3"}
+	streaming := &streamingKeyAccess{keys: []KeyEntry{k2, k3}, next: make(chan bool)}
+	ka := Combine(KeySource{"first", fixedKeyAccess{k1}}, KeySource{"streaming", streaming})
+
+	found := make(chan KeyEntry)
+	done := make(chan error)
+	go func() {
+		done <- ka.(KeyStreamer).StreamKeys(context.Background(), func(k KeyEntry) { found <- k })
+	}()
+
+	s.ElementsMatch([]KeyEntry{k1, k2}, []KeyEntry{<-found, <-found})
+	s.Equal("streaming", ka.SourceOf(k2))
+	streaming.next <- true
+	s.Equal(k3, <-found)
+	streaming.next <- true
+	s.NoError(<-done)
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_stopsWhenTheContextIsCancelled() {
+	streaming := &streamingKeyAccess{keys: []KeyEntry{&keyEntryForTest{name: "1"}}, next: make(chan bool)}
+	ka := Combine(KeySource{"streaming", streaming})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	keys, e := ka.AllKeys(ctx)
+
+	s.Nil(keys)
+	s.Equal(context.Canceled, e)
+	s.NoError(ka.FailureOf("streaming"), "a cancelled source didn't fail")
+}
+
+func (s *apiSuite) Test_StreamKeys_givesAllOfTheKeysOfKeyAccessesThatCanNotStream() {
+	k1, k2 := &keyEntryForTest{name: "1"}, &keyEntryForTest{name: "2"}
+	found := []KeyEntry{}
+
+	s.NoError(StreamKeys(context.Background(), fixedKeyAccess{k1, k2}, func(k KeyEntry) { found = append(found, k) }))
+	s.Equal([]KeyEntry{k1, k2}, found)
+
+	s.EqualError(StreamKeys(context.Background(), erroringKeyAccess{}, func(KeyEntry) {}), "permission denied")
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_listsKeysInTheSameFilesOnlyOnce() {
+	k1 := &keyEntryForTest{name: "1", location: "/home/amnesia/.ssh/id_ed25519"}
+	sameFile := &keyEntryForTest{name: "1", location: "/home/amnesia/.ssh/id_ed25519"}
+	otherFile := &keyEntryForTest{name: "1", location: "/home/amnesia/.minisign/minisign.pub"}
+	ka := Combine(KeySource{"first", fixedKeyAccess{k1}}, KeySource{"second", fixedKeyAccess{sameFile, otherFile}})
+
+	s.Equal([]KeyEntry{k1, otherFile}, s.allKeysOf(ka))
+	s.Equal("first", ka.SourceOf(sameFile))
+	s.Equal("second", ka.SourceOf(otherFile))
+}
+
+func (s *apiSuite) Test_Combine_SourceOf_knowsTheKeysOfEarlierListings() {
+	ka := Combine(KeySource{"first", fixedKeyAccess{&keyEntryForTest{name: "1"}}})
+	s.allKeysOf(ka)
+
+	s.Equal("first", ka.SourceOf(&keyEntryForTest{name: "1"}))
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_forgetsTheSourcesOfKeysThatAreNotListedAnymore() {
+	k1 := &keyEntryForTest{name: "1"}
+	first := &importingAccess{fixedKeyAccess: fixedKeyAccess{k1}}
+	ka := Combine(KeySource{"first", first})
+	s.allKeysOf(ka)
+
+	first.fixedKeyAccess = fixedKeyAccess{}
+	s.allKeysOf(ka)
+	s.Equal("", ka.SourceOf(k1))
+}
+
+func (s *apiSuite) Test_Combine_AllKeys_answersPasswordRequestsOneAtATime() {
+	p := &passwordProviderForTest{s: s, answer: "secret"}
+	accesses := []*passwordAskingAccess{}
+	sources := []KeySource{}
+	for _, f := range []string{"a.p12", "b.p12", "c.p12", "d.p12"} {
+		a := &passwordAskingAccess{fileName: f}
+		accesses = append(accesses, a)
+		sources = append(sources, KeySource{f, a})
+	}
+	ka := Combine(sources...)
+	ka.(PasswordProviderUser).SetPasswordProvider(p)
+
+	s.allKeysOf(ka)
+
+	s.ElementsMatch([]string{"a.p12", "b.p12", "c.p12", "d.p12"}, p.files)
+	for _, a := range accesses {
+		s.Equal([]string{"secret"}, a.answers)
+	}
+}
+
+func (s *apiSuite) Test_Combine_SetPasswordProvider_isUsedDirectlyOutsideOfListing() {
+	p := &passwordProviderForTest{s: s}
+	a := &passwordAskingAccess{fileName: "a.p12"}
+	ka := Combine(KeySource{"first", fixedKeyAccess{}}, KeySource{"asking", a})
+	ka.(PasswordProviderUser).SetPasswordProvider(p)
+
+	_, ok := a.provider.PasswordFor("a.p12", true)
+	s.False(ok)
+	s.Equal([]string{"a.p12"}, p.files)
+}
+
+func (s *apiSuite) Test_Combine_GenerateKey_usesTheFirstSourceThatSupportsIt() {
+	generated := &keyEntryForTest{name: "generated"}
+	ka := Combine(KeySource{"a", fixedKeyAccess{}}, KeySource{"b", &keyGeneratingAccess{generated: generated}}, KeySource{"c", &keyGeneratingAccess{}})
+
+	k, e := ka.(KeyGenerator).GenerateKey(KeyGenerationOptions{})
+	s.NoError(e)
+	s.Equal(generated, k)
+
+	_, e = Combine(KeySource{"a", fixedKeyAccess{}}).(KeyGenerator).GenerateKey(KeyGenerationOptions{})
+	s.Equal(ErrNotSupported, e)
+}
+
+func (s *apiSuite) Test_Combine_ImportPublicKey_usesTheFirstSourceThatSupportsIt() {
+	first, second := &importingAccess{}, &importingAccess{}
+	ka := Combine(KeySource{"a", fixedKeyAccess{}}, KeySource{"b", first}, KeySource{"c", second})
+
+	_, e := ka.(PublicKeyImporter).ImportPublicKey(nil, "", "/tmp/imported.pub")
+	s.NoError(e)
+	s.Equal([]string{"/tmp/imported.pub"}, first.imported)
+	s.Empty(second.imported)
+
+	_, e = Combine().(PublicKeyImporter).ImportPublicKey(nil, "", "/tmp/imported.pub")
+	s.Equal(ErrNotSupported, e)
+}
+
+func (s *apiSuite) Test_Combine_ImportPrivateKey_usesTheFirstSourceThatSupportsIt() {
+	first, second := &importingAccess{}, &importingAccess{}
+	ka := Combine(KeySource{"a", fixedKeyAccess{}}, KeySource{"b", first}, KeySource{"c", second})
+
+	_, e := ka.(PrivateKeyImporter).ImportPrivateKey(nil, "/tmp/id_restored")
+	s.NoError(e)
+	s.Equal([]string{"/tmp/id_restored"}, first.imported)
+	s.Empty(second.imported)
+
+	_, e = Combine().(PrivateKeyImporter).ImportPrivateKey(nil, "/tmp/id_restored")
+	s.Equal(ErrNotSupported, e)
+}
+
+func (s *apiSuite) Test_Combine_ImportPrivateKeyMaterial_usesTheFirstSourceThatSupportsIt() {
+	first, second := &importingAccess{}, &importingAccess{}
+	ka := Combine(KeySource{"a", fixedKeyAccess{}}, KeySource{"b", first}, KeySource{"c", second})
+
+	_, e := ka.(PrivateKeyMaterialImporter).ImportPrivateKeyMaterial(nil, "", "/tmp/id_restored", nil)
+	s.NoError(e)
+	s.Equal([]string{"/tmp/id_restored"}, first.imported)
+	s.Empty(second.imported)
+
+	_, e = Combine().(PrivateKeyMaterialImporter).ImportPrivateKeyMaterial(nil, "", "/tmp/id_restored", nil)
+	s.Equal(ErrNotSupported, e)
+}
@@ -1,5 +1,29 @@
 package api
 
+import "context"
+
+// KeyAccess lists the keys of a place keys are stored. Listing stops when the context is cancelled,
+// and an error is returned when the keys couldn't be listed
 type KeyAccess interface {
-	AllKeys() []KeyEntry
+	AllKeys(ctx context.Context) ([]KeyEntry, error)
+}
+
+// KeyStreamer is implemented by key accesses that can give each key as soon as it's found, instead of
+// only after all of them are listed
+type KeyStreamer interface {
+	StreamKeys(ctx context.Context, found func(KeyEntry)) error
+}
+
+// StreamKeys calls found with each key of the key access. The keys are given as soon as they are
+// found when the key access supports it, and all together once they are listed otherwise
+func StreamKeys(ctx context.Context, a KeyAccess, found func(KeyEntry)) error {
+	if ks, ok := a.(KeyStreamer); ok {
+		return ks.StreamKeys(ctx, found)
+	}
+
+	keys, e := a.AllKeys(ctx)
+	for _, k := range keys {
+		found(k)
+	}
+	return e
 }
@@ -1,6 +1,17 @@
 package api
 
+import (
+	"math/big"
+	"time"
+)
+
 type KeyEntry interface {
+	// ID identifies the key across reloads. It's derived from the public key material when it's known, so
+	// entries for the same key have the same ID. See PublicKeyID, MaterialID and LocationID
+	ID() string
+	// Same tells whether the other entry is for the same key. The same key can be listed more than once, for
+	// example when it's stored in more than one format, or when it's listed again after the keys are reloaded
+	Same(other KeyEntry) bool
 	Locations() []string
 	PublicKeyLocations() []string
 	PrivateKeyLocations() []string
@@ -19,3 +30,78 @@ type PrivateKeyEntry interface {
 	KeyEntry
 	IsPasswordProtected() bool
 }
+
+// RSAKeyEntry is implemented by key entries that can tell the public exponent of RSA keys.
+// For keys using other algorithms, the exponent is nil
+type RSAKeyEntry interface {
+	KeyEntry
+	RSAPublicExponent() *big.Int
+}
+
+// OpenPGPSubkey describes a subkey of an OpenPGP key. The expiry is the zero time for subkeys that never expire
+type OpenPGPSubkey struct {
+	Fingerprint []byte
+	Algorithm   Algorithm
+	Size        int
+	Created     time.Time
+	Expiry      time.Time
+}
+
+// OpenPGPKeyEntry is implemented by entries for OpenPGP keys. The fingerprint is a V4 or V5 fingerprint,
+// depending on the version of the key, and the expiry is the zero time for keys that never expire.
+// The primary user ID comes first
+type OpenPGPKeyEntry interface {
+	PublicKeyEntry
+	OpenPGPVersion() int
+	OpenPGPFingerprint() []byte
+	UserIDs() []string
+	Created() time.Time
+	Expiry() time.Time
+	Subkeys() []OpenPGPSubkey
+}
+
+// Certificate describes an X.509 certificate. The fingerprint is the SHA-256 digest of the whole certificate
+type Certificate struct {
+	Subject                 string
+	Issuer                  string
+	SubjectAlternativeNames []string
+	NotBefore               time.Time
+	NotAfter                time.Time
+	SHA256Fingerprint       []byte
+}
+
+// CertificateKeyEntry is implemented by entries for keys that can have X.509 certificates. There can be
+// more than one certificate for the same key, for example after the certificate has been renewed
+type CertificateKeyEntry interface {
+	PublicKeyEntry
+	Certificates() []Certificate
+}
+
+// PKCS12KeyEntry is implemented by entries for keys read from PKCS#12 files. The friendly name and
+// the local key ID are the attributes PKCS#12 uses to name keys and to match them with their certificates
+type PKCS12KeyEntry interface {
+	KeyEntry
+	FriendlyName() string
+	LocalKeyID() []byte
+}
+
+// OTRKeyEntry is implemented by entries for the long-lived DSA keys of OTR. The fingerprint is the SHA-1 digest
+// of the public key, that OTR clients show to verify who is on the other side of a conversation. For the keys of
+// contacts only the fingerprint is known, and the account is the name of the contact
+type OTRKeyEntry interface {
+	KeyEntry
+	OTRAccount() string
+	OTRProtocol() string
+	OTRFingerprint() []byte
+	// OTRTrust tells how the fingerprint of a contact was verified, like "verified" or "smp". It is empty for
+	// fingerprints that haven't been verified, and for the keys of our own accounts
+	OTRTrust() string
+}
+
+// MinisignKeyEntry is implemented by entries for the Ed25519 signing keys of minisign and of OpenBSD signify, that
+// use the same key format. The key ID is nil when it isn't known, since minisign encrypts it with the secret key
+type MinisignKeyEntry interface {
+	KeyEntry
+	MinisignKeyID() []byte
+	IsSignifyKey() bool
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"path/filepath"
+)
+
+// MaterialID returns the ID of keys with the given public key material. The kind tells apart material in
+// different formats, like "x25519" or "openpgp", so the same bytes in different formats don't have the same ID
+func MaterialID(kind string, material []byte) string {
+	digest := sha256.Sum256(material)
+	return kind + ":" + hex.EncodeToString(digest[:])
+}
+
+// PublicKeyID returns the ID of keys with the public key, given as one of the types from the standard crypto
+// packages. The same public key has the same ID whatever format it's stored in, so an SSH key and a certificate
+// for that key are the same key. It returns false for public keys that can't be encoded, like DSA keys
+func PublicKeyID(pub crypto.PublicKey) (string, bool) {
+	if pub == nil {
+		return "", false
+	}
+	der, e := x509.MarshalPKIXPublicKey(pub)
+	if e != nil {
+		return "", false
+	}
+	return MaterialID("pkix", der), true
+}
+
+// LocationID returns the ID of private keys whose public key isn't known, for example because the whole
+// key is encrypted. The key can only be recognized by the file it's stored in
+func LocationID(location string) string {
+	if abs, e := filepath.Abs(location); e == nil {
+		location = abs
+	}
+	return "file:" + location
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"crypto/dsa"
+	"crypto/ed25519"
+	"math/big"
+	"path/filepath"
+	"strings"
+)
+
+func (s *apiSuite) Test_MaterialID_dependsOnTheKindOfMaterial() {
+	material := []byte{0x01, 0x02, 0x03}
+
+	s.Equal("x25519:039058c6f2c0cb492c533b0a4d14ef77cc0f78abccced5287d84a1a2011cfb81", MaterialID("x25519", material))
+	s.NotEqual(MaterialID("x25519", material), MaterialID("openpgp", material))
+	s.NotEqual(MaterialID("x25519", material), MaterialID("x25519", []byte{0x01, 0x02}))
+}
+
+func (s *apiSuite) Test_PublicKeyID_isTheSameForTheSamePublicKey() {
+	pub := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public()
+
+	id, ok := PublicKeyID(pub)
+	s.True(ok)
+	s.True(strings.HasPrefix(id, "pkix:"))
+	copied, _ := PublicKeyID(ed25519.PublicKey(append([]byte{}, pub.(ed25519.PublicKey)...)))
+	s.Equal(id, copied)
+}
+
+func (s *apiSuite) Test_PublicKeyID_failsForKeysThatCanNotBeEncoded() {
+	_, ok := PublicKeyID(&dsa.PublicKey{Y: big.NewInt(42)})
+	s.False(ok)
+
+	_, ok = PublicKeyID(nil)
+	s.False(ok)
+}
+
+func (s *apiSuite) Test_LocationID_usesTheAbsolutePath() {
+	abs, _ := filepath.Abs("id_ed25519")
+
+	s.Equal("file:"+abs, LocationID("id_ed25519"))
+	s.Equal("file:/home/amnesia/.ssh/id_ed25519", LocationID("/home/amnesia/.ssh/id_ed25519"))
+}
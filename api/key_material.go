@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto"
+	"errors"
+)
+
+// ErrIncorrectPassphrase is returned when a private key can't be unlocked with the given passphrase
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+
+// PublicKeyMaterialEntry is implemented by public key entries that can give access
+// to the parsed public key, as one of the types from the standard crypto packages
+type PublicKeyMaterialEntry interface {
+	PublicKeyEntry
+	PublicKey() crypto.PublicKey
+}
+
+// UnlockablePrivateKeyEntry is implemented by private key entries that can be decrypted,
+// giving access to the secret key material. If the key is not password protected,
+// the passphrase will be ignored
+type UnlockablePrivateKeyEntry interface {
+	PrivateKeyEntry
+	Unlock(passphrase []byte) (UnlockedPrivateKey, error)
+}
+
+// UnlockedPrivateKey contains the secret material of a private key. The private key
+// returned will be one of the types from the standard crypto packages
+type UnlockedPrivateKey interface {
+	PrivateKey() crypto.PrivateKey
+	UserID() string
+}
+
+// DefaultKDFRounds is the number of key derivation rounds ssh-keygen uses by default
+const DefaultKDFRounds = 16
+
+// PrivateKeyProtection describes how a private key should be encrypted with a passphrase
+type PrivateKeyProtection struct {
+	Cipher string
+	Rounds int
+}
+
+// PassphraseChangeablePrivateKeyEntry is implemented by private key entries that can be re-encrypted
+// with a new passphrase. Using an empty new passphrase will remove the protection from the key
+type PassphraseChangeablePrivateKeyEntry interface {
+	UnlockablePrivateKeyEntry
+	// SupportedCiphers returns the names of the ciphers that can be used, starting with the recommended one
+	SupportedCiphers() []string
+	// ChangePassphrase replaces the private key, keeping a backup of the original. It returns the name of the backup,
+	// or an empty name when no backup was kept because it wouldn't have been protected by a passphrase
+	ChangePassphrase(oldPassphrase, newPassphrase []byte, protection PrivateKeyProtection) (string, error)
+}
+
+// UserIDEditableKeyEntry is implemented by key entries where the user ID can be changed.
+// The passphrase is only needed when the user ID is stored inside of a password protected private key
+type UserIDEditableKeyEntry interface {
+	KeyEntry
+	SetUserID(userID string, passphrase []byte) error
+}
+
+// KeyGenerationOptions describes a new key to generate. The size is the number of bits
+// for RSA keys and the curve size for ECDSA keys, and it is ignored for Ed25519 keys
+type KeyGenerationOptions struct {
+	Algorithm  Algorithm
+	Size       int
+	UserID     string
+	FileName   string
+	Passphrase []byte
+}
+
+// KeyGenerator is implemented by key access providers that can create new keys
+type KeyGenerator interface {
+	GenerateKey(options KeyGenerationOptions) (KeyEntry, error)
+}
+
+// PublicKeyImporter is implemented by key access providers that can store public keys
+// that come from somewhere else, with the given user ID
+type PublicKeyImporter interface {
+	ImportPublicKey(pub crypto.PublicKey, userID, fileName string) (KeyEntry, error)
+}
+
+// PrivateKeyImporter is implemented by key access providers that can store private key files
+// restored from somewhere else, like a paper backup. The content is kept as is, so a key
+// protected by a passphrase stays protected
+type PrivateKeyImporter interface {
+	ImportPrivateKey(content []byte, fileName string) (KeyEntry, error)
+}
+
+// PrivateKeyMaterialImporter is implemented by key access providers that can store private keys
+// restored from their key material, like keys combined from secret shares. The key is protected
+// with the passphrase, unless it is empty
+type PrivateKeyMaterialImporter interface {
+	ImportPrivateKeyMaterial(priv crypto.PrivateKey, userID, fileName string, passphrase []byte) (KeyEntry, error)
+}
+
+// SSHPublicKeyEntry is implemented by entries that know the SSH wire format of their public key,
+// the content OpenSSH calculates fingerprints from. It's nil for keys that can't be used with SSH
+type SSHPublicKeyEntry interface {
+	PublicKeyEntry
+	SSHPublicKey() []byte
+}
+
+// X25519PublicKeyEntry is implemented by entries for Curve25519 Diffie-Hellman keys, like
+// the ones used by WireGuard, that don't have a type in the standard crypto packages
+type X25519PublicKeyEntry interface {
+	PublicKeyEntry
+	X25519PublicKey() []byte
+}
+
+// AgeKeyEntry is implemented by entries for native age keys. The recipient is the
+// public key in the format age uses, starting with "age1"
+type AgeKeyEntry interface {
+	PublicKeyEntry
+	AgeRecipient() string
+}
+
+// PasswordProvider is used by key access providers to ask for the passwords of files that can't be read
+// without one. It is asked again with incorrect set to true when the previous password didn't work, and
+// returns false when the user doesn't want to give a password
+type PasswordProvider interface {
+	PasswordFor(fileName string, incorrect bool) ([]byte, bool)
+}
+
+// PasswordProviderUser is implemented by key access providers that need passwords to read some of
+// their files. Those files are skipped until a password provider is set
+type PasswordProviderUser interface {
+	SetPasswordProvider(p PasswordProvider)
+}
+
+// ExtractableKeyEntry is implemented by entries for keys stored in containers, like PKCS#12 files, so they
+// can be used by software that can't read the container. PEM returns the certificates followed by the private
+// key, if there is one. ExtractPrivateKey returns one of the types from the standard crypto packages
+type ExtractableKeyEntry interface {
+	KeyEntry
+	PEM() ([]byte, error)
+	ExtractPrivateKey() (crypto.PrivateKey, bool)
+}
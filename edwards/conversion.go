@@ -0,0 +1,69 @@
+// Package edwards converts Ed25519 keys to the other forms Curve25519 keys are used in, like the
+// X25519 keys of age and WireGuard and the expanded secret keys of Tor. The curve arithmetic is done
+// by filippo.io/edwards25519, which is constant time for everything that involves secret scalars
+package edwards
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// ScalarSize is the size of the little-endian secret scalars
+const ScalarSize = 32
+
+// ExpandedSecretKeySize is the size of the secret scalar followed by the prefix used for generating nonces
+const ExpandedSecretKeySize = 64
+
+// ErrInvalidPublicKey is returned for public keys that are not the encoding of a point of the curve
+var ErrInvalidPublicKey = errors.New("invalid Ed25519 public key")
+
+// Clamp clears the lowest three bits and the highest bit of the little-endian scalar, and sets its
+// second highest bit, as described in RFC 8032, section 5.1.5 and RFC 7748, section 5
+func Clamp(scalar []byte) {
+	scalar[0] &= 248
+	scalar[31] &= 127
+	scalar[31] |= 64
+}
+
+// ExpandSeed derives the clamped secret scalar and the nonce prefix from the seed of the private
+// key, in the same way as RFC 8032, section 5.1.5
+func ExpandSeed(priv ed25519.PrivateKey) []byte {
+	h := sha512.Sum512(priv.Seed())
+	Clamp(h[:ScalarSize])
+	return h[:]
+}
+
+// PublicKeyOfScalar returns the public key for the little-endian secret scalar, of ScalarSize bytes.
+// The scalar is used as is, without clamping it
+func PublicKeyOfScalar(scalar []byte) ed25519.PublicKey {
+	wide := make([]byte, 64)
+	copy(wide, scalar[:ScalarSize])
+	s, _ := edwards25519.NewScalar().SetUniformBytes(wide)
+	return new(edwards25519.Point).ScalarBaseMult(s).Bytes()
+}
+
+// PublicKeyToX25519 converts the Edwards point of an Ed25519 public key to the birationally
+// equivalent Montgomery u-coordinate, u = (1 + y) / (1 - y). Encodings that are not canonical
+// and the identity point, that has no u-coordinate, are refused
+func PublicKeyToX25519(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	p, e := new(edwards25519.Point).SetBytes(pub)
+	if e != nil || !bytes.Equal(p.Bytes(), pub) || p.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return p.BytesMontgomery(), nil
+}
+
+// PrivateKeyToX25519 returns the clamped scalar that Ed25519 derives from the seed of the private
+// key. This scalar corresponds to the result of PublicKeyToX25519
+func PrivateKeyToX25519(priv ed25519.PrivateKey) []byte {
+	return append([]byte{}, ExpandSeed(priv)[:ScalarSize]...)
+}
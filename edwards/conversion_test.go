@@ -0,0 +1,93 @@
+package edwards
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/curve25519"
+)
+
+type edwardsSuite struct {
+	suite.Suite
+}
+
+func TestEdwardsSuite(t *testing.T) {
+	suite.Run(t, new(edwardsSuite))
+}
+
+// rfc8032Key is the key from the first test vector in RFC 8032, section 7.1
+func rfc8032Key() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func (s *edwardsSuite) Test_Clamp_setsAndClearsTheBitsOfTheScalar() {
+	scalar := make([]byte, ScalarSize)
+	for i := range scalar {
+		scalar[i] = 0xff
+	}
+	Clamp(scalar)
+	s.Equal(byte(0xf8), scalar[0])
+	s.Equal(byte(0x7f), scalar[31])
+
+	scalar = make([]byte, ScalarSize)
+	Clamp(scalar)
+	s.Equal(byte(0x00), scalar[0])
+	s.Equal(byte(0x40), scalar[31])
+}
+
+func (s *edwardsSuite) Test_ExpandSeed_returnsTheClampedScalarAndTheNoncePrefix() {
+	expanded := ExpandSeed(rfc8032Key())
+	s.Len(expanded, ExpandedSecretKeySize)
+	s.Equal("307c83864f2833cb427a2ef1c00a013cfdff2768d980c0a3a520f006904de94f", hex.EncodeToString(expanded[:ScalarSize]))
+}
+
+func (s *edwardsSuite) Test_PublicKeyOfScalar_matchesTheKeyFromTheSeed() {
+	s.Equal(rfc8032Key().Public(), PublicKeyOfScalar(ExpandSeed(rfc8032Key())))
+
+	for i := 0; i < 20; i++ {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+		s.Equal(pub, PublicKeyOfScalar(ExpandSeed(priv)))
+	}
+}
+
+func (s *edwardsSuite) Test_PublicKeyToX25519_matchesTheConversionOfThePrivateKey() {
+	for i := 0; i < 20; i++ {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+		u, e := PublicKeyToX25519(pub)
+		s.Require().NoError(e)
+
+		expected, _ := curve25519.X25519(PrivateKeyToX25519(priv), curve25519.Basepoint)
+		s.Equal(expected, u)
+	}
+}
+
+func (s *edwardsSuite) Test_PublicKeyToX25519_rejectsInvalidPublicKeys() {
+	_, e := PublicKeyToX25519(ed25519.PublicKey{0x01, 0x02})
+	s.Equal(ErrInvalidPublicKey, e)
+
+	identity := make([]byte, 32)
+	identity[0] = 1
+	_, e = PublicKeyToX25519(identity)
+	s.Equal(ErrInvalidPublicKey, e)
+
+	notOnCurve, _ := hex.DecodeString("0200000000000000000000000000000000000000000000000000000000000000")
+	_, e = PublicKeyToX25519(notOnCurve)
+	s.Equal(ErrInvalidPublicKey, e)
+
+	// 2^255 - 18 is the same value of y as 1, and is not a canonical encoding
+	notCanonical, _ := hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	_, e = PublicKeyToX25519(notCanonical)
+	s.Equal(ErrInvalidPublicKey, e)
+}
+
+func (s *edwardsSuite) Test_PrivateKeyToX25519_returnsAClampedScalar() {
+	scalar := PrivateKeyToX25519(rfc8032Key())
+
+	s.Equal("307c83864f2833cb427a2ef1c00a013cfdff2768d980c0a3a520f006904de94f", hex.EncodeToString(scalar))
+	s.Len(scalar, ScalarSize)
+}
@@ -0,0 +1,81 @@
+// Package files contains the file handling shared by the places keys are read from and written to
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// MaximumFileSize is the size of the biggest file ReadSmall usually reads. Files with keys are small, so
+// bigger files are skipped without reading them
+const MaximumFileSize = 1 << 20
+
+// ErrFileTooBig is returned by ReadSmall for files that are bigger than the maximum size
+var ErrFileTooBig = errors.New("the file is too big to contain keys")
+
+// ReadSmall reads the file, unless it is bigger than the maximum size
+func ReadSmall(fileName string, maximumSize int64) ([]byte, error) {
+	info, e := os.Stat(fileName)
+	if e != nil {
+		return nil, e
+	}
+	if info.Size() > maximumSize {
+		return nil, ErrFileTooBig
+	}
+	return os.ReadFile(fileName)
+}
+
+// Matching returns the regular files in the directories that match any of the patterns, in the order
+// of the directories and then the patterns, without repeating files
+func Matching(dirs []string, patterns ...string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		for _, p := range patterns {
+			files, _ := filepath.Glob(filepath.Join(dir, p))
+			for _, f := range files {
+				if info, e := os.Stat(f); e == nil && info.Mode().IsRegular() && !seen[f] {
+					seen[f] = true
+					result = append(result, f)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// WriteAtomically writes the content to a temporary file in the same directory, and then renames it
+// to the given file name. The file is never partially written, and it gets the permissions even when
+// it already exists, so a secret can't be left readable by others by overwriting a public file
+func WriteAtomically(fileName string, content []byte, perm os.FileMode) error {
+	tmp, e := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".tmp*")
+	if e != nil {
+		return e
+	}
+	defer os.Remove(tmp.Name())
+
+	if e := WriteAndClose(tmp, content, perm); e != nil {
+		return e
+	}
+
+	return os.Rename(tmp.Name(), fileName)
+}
+
+// WriteAndClose sets the permissions of the file before writing the content to it
+func WriteAndClose(f *os.File, content []byte, perm os.FileMode) error {
+	e1 := f.Chmod(perm)
+	_, e2 := f.Write(content)
+	e3 := f.Sync()
+	e4 := f.Close()
+	return firstError(e1, e2, e3, e4)
+}
+
+func firstError(errs ...error) error {
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
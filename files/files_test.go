@@ -0,0 +1,78 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type filesSuite struct {
+	suite.Suite
+}
+
+func TestFilesSuite(t *testing.T) {
+	suite.Run(t, new(filesSuite))
+}
+
+func (s *filesSuite) Test_WriteAtomically_writesTheContentWithThePermissions() {
+	fileName := filepath.Join(s.T().TempDir(), "keys.txt")
+	s.Require().NoError(WriteAtomically(fileName, []byte("secret"), 0600))
+
+	content, e := os.ReadFile(fileName)
+	s.Require().NoError(e)
+	s.Equal("secret", string(content))
+	info, _ := os.Stat(fileName)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+}
+
+func (s *filesSuite) Test_WriteAtomically_restrictsThePermissionsOfAnExistingFile() {
+	fileName := filepath.Join(s.T().TempDir(), "keys.txt")
+	s.Require().NoError(os.WriteFile(fileName, []byte("public"), 0644))
+
+	s.Require().NoError(WriteAtomically(fileName, []byte("secret"), 0600))
+
+	info, _ := os.Stat(fileName)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+}
+
+func (s *filesSuite) Test_WriteAtomically_doesNotLeaveTemporaryFilesBehind() {
+	dir := s.T().TempDir()
+	s.Require().NoError(WriteAtomically(filepath.Join(dir, "keys.txt"), []byte("hello"), 0640))
+
+	entries, _ := os.ReadDir(dir)
+	s.Len(entries, 1)
+}
+
+func (s *filesSuite) Test_WriteAtomically_failsWhenTheDirectoryDoesNotExist() {
+	s.Error(WriteAtomically(filepath.Join(s.T().TempDir(), "missing", "keys.txt"), []byte("hello"), 0600))
+}
+
+func (s *filesSuite) Test_ReadSmall_readsFilesUpToTheMaximumSize() {
+	dir := s.T().TempDir()
+	small := filepath.Join(dir, "small")
+	big := filepath.Join(dir, "big")
+	s.Require().NoError(os.WriteFile(small, []byte("1234"), 0600))
+	s.Require().NoError(os.WriteFile(big, []byte("12345"), 0600))
+
+	content, e := ReadSmall(small, 4)
+	s.NoError(e)
+	s.Equal("1234", string(content))
+
+	_, e = ReadSmall(big, 4)
+	s.Equal(ErrFileTooBig, e)
+
+	_, e = ReadSmall(filepath.Join(dir, "missing"), 4)
+	s.True(os.IsNotExist(e))
+}
+
+func (s *filesSuite) Test_Matching_returnsTheRegularFilesMatchingThePatternsOnlyOnce() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "keys.txt"), nil, 0600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "recipients"), nil, 0600))
+	s.Require().NoError(os.Mkdir(filepath.Join(dir, "dir.txt"), 0700))
+
+	s.Equal([]string{filepath.Join(dir, "recipients"), filepath.Join(dir, "keys.txt")},
+		Matching([]string{dir, dir}, "recipients", "*.txt", "keys.*"))
+}
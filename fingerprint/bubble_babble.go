@@ -0,0 +1,49 @@
+package fingerprint
+
+const bubbleBabbleVowels = "aeiouy"
+const bubbleBabbleConsonants = "bcdfghklmnprstvzx"
+
+// BubbleBabble encodes the digest as pronounceable five letter words, as described by Antti Huima
+func BubbleBabble(digest []byte) string {
+	result := []byte{'x'}
+	seed := 1
+	rounds := len(digest)/2 + 1
+
+	for i := 0; i < rounds; i++ {
+		if i+1 == rounds && len(digest)%2 == 0 {
+			// The last tuple of an even length digest only contains the checksum
+			result = append(result,
+				bubbleBabbleVowels[seed%6],
+				bubbleBabbleConsonants[16],
+				bubbleBabbleVowels[seed/6])
+			break
+		}
+
+		b1 := int(digest[2*i])
+		result = append(result,
+			bubbleBabbleVowels[((b1>>6)&3+seed)%6],
+			bubbleBabbleConsonants[(b1>>2)&15],
+			bubbleBabbleVowels[((b1&3)+seed/6)%6])
+
+		if i+1 < rounds {
+			b2 := int(digest[2*i+1])
+			result = append(result,
+				bubbleBabbleConsonants[(b2>>4)&15],
+				'-',
+				bubbleBabbleConsonants[b2&15])
+			seed = (seed*5 + b1*7 + b2) % 36
+		}
+	}
+
+	return string(append(result, 'x'))
+}
+
+// BubbleBabbleOf encodes the SHA-1 digest of the key in the SSH wire format, which is what ssh-keygen -B
+// does. It returns false for keys that can't be used with SSH
+func BubbleBabbleOf(k digestable) (string, bool) {
+	content := sshContentOf(k)
+	if content == nil {
+		return "", false
+	}
+	return BubbleBabble(SHA1Hex.Digest(content)), true
+}
@@ -0,0 +1,18 @@
+package fingerprint
+
+func (s *fingerprintSuite) Test_BubbleBabble_encodesTheExamplesFromTheSpecification() {
+	s.Equal("xexax", BubbleBabble([]byte("")))
+	s.Equal("xesef-disof-gytuf-katof-movif-baxux", BubbleBabble([]byte("1234567890")))
+	s.Equal("xigak-nyryk-humil-bosek-sonax", BubbleBabble([]byte("Pineapple")))
+}
+
+func (s *fingerprintSuite) Test_BubbleBabbleOf_isTheSameAsSSHKeygen() {
+	bb, ok := BubbleBabbleOf(rfc8032KeyForQueries())
+	s.True(ok)
+	s.Equal("xunas-cidad-korip-hakiz-teges-bamik-pumor-pacan-mimoz-ronon-foxax", bb)
+}
+
+func (s *fingerprintSuite) Test_BubbleBabbleOf_isNotAvailableForKeysThatCanNotBeUsedWithSSH() {
+	_, ok := BubbleBabbleOf(&digestContentKey{[]byte("hello world")})
+	s.False(ok)
+}
@@ -0,0 +1,149 @@
+package fingerprint
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Format is one of the ways the fingerprint of a public key can be written
+type Format string
+
+const (
+	// OpenSSHSHA256 is what ssh-keygen -l, GitHub and most servers show, like SHA256:base64 without padding
+	OpenSSHSHA256 Format = "openssh-sha256"
+	// OpenSSHMD5 is what older versions of OpenSSH show, like MD5:aa:bb:cc
+	OpenSSHMD5 Format = "openssh-md5"
+	// SHA1Hex is the SHA-1 digest as upper case hexadecimal separated by colons
+	SHA1Hex Format = "sha1-hex"
+	// SHA256Hex is the SHA-256 digest as upper case hexadecimal separated by colons
+	SHA256Hex Format = "sha256-hex"
+)
+
+// Formats contains all the formats, in the order they should be shown
+var Formats = []Format{OpenSSHSHA256, OpenSSHMD5, SHA1Hex, SHA256Hex}
+
+// DefaultFormats are the ones shown until something else is chosen
+var DefaultFormats = []Format{OpenSSHSHA256, SHA1Hex, SHA256Hex}
+
+// IsValid returns true for the formats this package knows about
+func (f Format) IsValid() bool {
+	for _, ff := range Formats {
+		if f == ff {
+			return true
+		}
+	}
+	return false
+}
+
+// Digest calculates the digest the format is based on, from the public key in the SSH wire format
+func (f Format) Digest(content []byte) []byte {
+	switch f {
+	case OpenSSHMD5:
+		res := md5.Sum(content)
+		return res[:]
+	case SHA1Hex:
+		res := sha1.Sum(content)
+		return res[:]
+	default:
+		res := sha256.Sum256(content)
+		return res[:]
+	}
+}
+
+// Format writes a digest calculated by Digest
+func (f Format) Format(digest []byte) string {
+	switch f {
+	case OpenSSHSHA256:
+		return "SHA256:" + base64.RawStdEncoding.EncodeToString(digest)
+	case OpenSSHMD5:
+		return "MD5:" + strings.ToLower(Hex(digest))
+	default:
+		return Hex(digest)
+	}
+}
+
+// Hex returns the bytes as upper case hexadecimal separated by colons
+func Hex(digest []byte) string {
+	result := []string{}
+	for _, b := range digest {
+		result = append(result, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(result, ":")
+}
+
+// digestable is the part of api.PublicKeyEntry needed to calculate fingerprints
+type digestable interface {
+	WithDigestContent(func([]byte) []byte) []byte
+}
+
+// sshEncodable is the part of api.SSHPublicKeyEntry needed to calculate the OpenSSH fingerprints
+type sshEncodable interface {
+	SSHPublicKey() []byte
+}
+
+// IsOpenSSH returns true for the formats OpenSSH shows, that are calculated from the key in the SSH wire format
+func (f Format) IsOpenSSH() bool {
+	return f == OpenSSHSHA256 || f == OpenSSHMD5
+}
+
+// sshContentOf returns the key in the SSH wire format, or nil when it can't be used with SSH
+func sshContentOf(k digestable) []byte {
+	if s, ok := k.(sshEncodable); ok {
+		return s.SSHPublicKey()
+	}
+	return nil
+}
+
+// digestOf calculates the digest the format is based on. The OpenSSH formats use the key in the SSH wire
+// format, so they are only available for keys that can be used with SSH. The other formats use the
+// content of the key entry, which is the key as it is encoded in its own format
+func digestOf(k digestable, f Format) ([]byte, bool) {
+	if !f.IsOpenSSH() {
+		return k.WithDigestContent(f.Digest), true
+	}
+	content := sshContentOf(k)
+	if content == nil {
+		return nil, false
+	}
+	return f.Digest(content), true
+}
+
+// Of returns the fingerprint of the key in the given format. It returns false for the OpenSSH formats
+// when the key can't be used with SSH, since OpenSSH would never show a fingerprint for it
+func Of(k digestable, f Format) (string, bool) {
+	digest, ok := digestOf(k, f)
+	if !ok {
+		return "", false
+	}
+	return f.Format(digest), true
+}
+
+// OfContent returns the fingerprint of a public key in the SSH wire format
+func OfContent(content []byte, f Format) string {
+	return f.Format(f.Digest(content))
+}
+
+// SSHWireFormat returns the public key in the SSH wire format, or nil when it is not a kind of key SSH can use
+func SSHWireFormat(pub crypto.PublicKey) []byte {
+	k, e := ssh.NewPublicKey(pub)
+	if e != nil {
+		return nil
+	}
+	return k.Marshal()
+}
+
+// OfPublicKey returns the fingerprint of any public key that can be used with SSH
+func OfPublicKey(pub crypto.PublicKey, f Format) (string, error) {
+	k, e := ssh.NewPublicKey(pub)
+	if e != nil {
+		return "", e
+	}
+	return OfContent(k.Marshal(), f), nil
+}
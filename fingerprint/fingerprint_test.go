@@ -0,0 +1,132 @@
+package fingerprint
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fingerprintSuite struct {
+	suite.Suite
+}
+
+func TestFingerprintSuite(t *testing.T) {
+	suite.Run(t, new(fingerprintSuite))
+}
+
+func rfc8032PublicKey() ed25519.PublicKey {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	return ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+}
+
+type digestContentKey struct {
+	content []byte
+}
+
+func (k *digestContentKey) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.content)
+}
+
+// sshKeyForTest is an SSH key, where the content of the key is the SSH wire format
+type sshKeyForTest struct {
+	digestContentKey
+}
+
+func (k *sshKeyForTest) SSHPublicKey() []byte {
+	return k.content
+}
+
+// otherFormatKeyForTest is a key from another format that can also be used with SSH
+type otherFormatKeyForTest struct {
+	digestContentKey
+	ssh []byte
+}
+
+func (k *otherFormatKeyForTest) SSHPublicKey() []byte {
+	return k.ssh
+}
+
+func (s *fingerprintSuite) Test_OfPublicKey_isTheSameAsSSHKeygen() {
+	fp, e := OfPublicKey(rfc8032PublicKey(), OpenSSHSHA256)
+	s.NoError(e)
+	s.Equal("SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8", fp)
+
+	fp, e = OfPublicKey(rfc8032PublicKey(), OpenSSHMD5)
+	s.NoError(e)
+	s.Equal("MD5:cf:07:be:9d:68:ae:65:54:6d:a0:93:c3:6f:bd:0d:82", fp)
+}
+
+func (s *fingerprintSuite) Test_OfPublicKey_failsForKeysSSHDoesNotSupport() {
+	_, e := OfPublicKey(&ecdsa.PublicKey{}, OpenSSHSHA256)
+	s.Error(e)
+}
+
+func (s *fingerprintSuite) Test_OfContent_usesTheDigestOfTheFormat() {
+	s.Equal("SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek", OfContent([]byte("hello world"), OpenSSHSHA256))
+	s.Equal("MD5:5e:b6:3b:bb:e0:1e:ee:d0:93:cb:22:bb:8f:5a:cd:c3", OfContent([]byte("hello world"), OpenSSHMD5))
+	s.Equal("2A:AE:6C:35:C9:4F:CF:B4:15:DB:E9:5F:40:8B:9C:E9:1E:E8:46:ED", OfContent([]byte("hello world"), SHA1Hex))
+	s.Equal("B9:4D:27:B9:93:4D:3E:08:A5:2E:52:D7:DA:7D:AB:FA:C4:84:EF:E3:7A:53:80:EE:90:88:F7:AC:E2:EF:CD:E9", OfContent([]byte("hello world"), SHA256Hex))
+}
+
+func (s *fingerprintSuite) Test_Of_usesTheDigestContentOfTheKey() {
+	k := &sshKeyForTest{digestContentKey{[]byte("hello world")}}
+
+	fp, ok := Of(k, OpenSSHSHA256)
+	s.True(ok)
+	s.Equal("SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek", fp)
+
+	fp, ok = Of(k, SHA1Hex)
+	s.True(ok)
+	s.Equal("2A:AE:6C:35:C9:4F:CF:B4:15:DB:E9:5F:40:8B:9C:E9:1E:E8:46:ED", fp)
+}
+
+func (s *fingerprintSuite) Test_Of_usesTheSSHWireFormatForTheOpenSSHFormats() {
+	k := &otherFormatKeyForTest{digestContentKey{[]byte("hello world")}, SSHWireFormat(rfc8032PublicKey())}
+
+	fp, ok := Of(k, OpenSSHSHA256)
+	s.True(ok)
+	s.Equal("SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8", fp)
+
+	fp, ok = Of(k, OpenSSHMD5)
+	s.True(ok)
+	s.Equal("MD5:cf:07:be:9d:68:ae:65:54:6d:a0:93:c3:6f:bd:0d:82", fp)
+
+	fp, ok = Of(k, SHA256Hex)
+	s.True(ok)
+	s.Equal("B9:4D:27:B9:93:4D:3E:08:A5:2E:52:D7:DA:7D:AB:FA:C4:84:EF:E3:7A:53:80:EE:90:88:F7:AC:E2:EF:CD:E9", fp)
+}
+
+func (s *fingerprintSuite) Test_Of_hasNoOpenSSHFingerprintsForKeysThatCanNotBeUsedWithSSH() {
+	k := &digestContentKey{[]byte("hello world")}
+
+	_, ok := Of(k, OpenSSHSHA256)
+	s.False(ok)
+	_, ok = Of(k, OpenSSHMD5)
+	s.False(ok)
+
+	fp, ok := Of(k, SHA1Hex)
+	s.True(ok)
+	s.Equal("2A:AE:6C:35:C9:4F:CF:B4:15:DB:E9:5F:40:8B:9C:E9:1E:E8:46:ED", fp)
+}
+
+func (s *fingerprintSuite) Test_SSHWireFormat_isNilForKeysSSHDoesNotSupport() {
+	s.Equal(append([]byte{0, 0, 0, 11}, "ssh-ed25519"...), SSHWireFormat(rfc8032PublicKey())[:15])
+	s.Nil(SSHWireFormat([]byte{0x01}))
+}
+
+func (s *fingerprintSuite) Test_Hex_returnsAnUpperCaseHexadecimalStringWithColons() {
+	s.Equal("", Hex([]byte{}))
+	s.Equal("00", Hex([]byte{0}))
+	s.Equal("00:01:20:67:00:07:FC:00", Hex([]byte{0, 1, 32, 0x67, 0, 7, 0xfc, 0}))
+}
+
+func (s *fingerprintSuite) Test_IsValid_onlyAcceptsKnownFormats() {
+	for _, f := range Formats {
+		s.True(f.IsValid())
+	}
+	s.False(Format("sha512").IsValid())
+	s.False(Format("").IsValid())
+}
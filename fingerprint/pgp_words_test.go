@@ -0,0 +1,31 @@
+package fingerprint
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+func (s *fingerprintSuite) Test_PGPWords_encodesTheExampleFromTheWordList() {
+	digest, _ := hex.DecodeString("E58294F2E9A227486E8B061B31CC528FD7FA3F19")
+
+	s.Equal("topmost Istanbul Pluto vagabond treadmill Pacific brackish dictator goldfish Medusa "+
+		"afflict bravado chatter revolver Dupont midsummer stopwatch whimsical cowbell bottomless", PGPWords(digest))
+}
+
+func (s *fingerprintSuite) Test_PGPWords_usesDifferentWordsForEvenAndOddPositions() {
+	s.Equal("aardvark adroitness Zulu Yucatan", PGPWords([]byte{0x00, 0x00, 0xff, 0xff}))
+	s.Equal("", PGPWords([]byte{}))
+}
+
+func (s *fingerprintSuite) Test_PGPWordsOf_hasOneWordForEveryByteOfTheSHA256Digest() {
+	pw, ok := PGPWordsOf(rfc8032KeyForQueries())
+	s.True(ok)
+	words := strings.Split(pw, " ")
+	s.Len(words, 32)
+	s.Equal("goggles", words[0])
+}
+
+func (s *fingerprintSuite) Test_PGPWordsOf_isNotAvailableForKeysThatCanNotBeUsedWithSSH() {
+	_, ok := PGPWordsOf(&digestContentKey{[]byte("hello world")})
+	s.False(ok)
+}
@@ -0,0 +1,101 @@
+package fingerprint
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// MinimumQueryLength is the number of characters a partial fingerprint needs, so that
+// a few characters don't match most keys
+const MinimumQueryLength = 4
+
+var ErrInvalidFingerprint = errors.New("the text isn't a fingerprint in any of the known formats")
+var ErrFingerprintTooShort = errors.New("the fingerprint is too short to identify a key")
+
+const sha256Prefix = "SHA256:"
+const md5Prefix = "MD5:"
+
+// Query is a fingerprint, or the beginning of one, written in any of the formats people might
+// read out or paste: hexadecimal with or without separators, SHA256: base64 or MD5: hexadecimal
+type Query struct {
+	// hex is compared with the hexadecimal digests of hexFormats, in lower case and without separators
+	hex        string
+	hexFormats []Format
+	// base64 is compared with the OpenSSH SHA256 fingerprint, without padding
+	base64 string
+}
+
+func withoutSeparators(text string, separators string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(separators, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func normalizedHex(text string) (string, bool) {
+	h := strings.ToLower(withoutSeparators(text, ":-"))
+	return h, h != "" && strings.Trim(h, "0123456789abcdef") == ""
+}
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func normalizedBase64(text string) (string, bool) {
+	b := strings.TrimRight(text, "=")
+	return b, b != "" && strings.Trim(b, base64Alphabet) == ""
+}
+
+// ParseQuery understands the text as a fingerprint. Text that could be either hexadecimal or
+// base64 is compared in both ways
+func ParseQuery(text string) (*Query, error) {
+	t := withoutSeparators(strings.TrimSpace(text), " \t\r\n")
+	upper := strings.ToUpper(t)
+	q := &Query{}
+
+	switch {
+	case strings.HasPrefix(upper, sha256Prefix):
+		if b, ok := normalizedBase64(t[len(sha256Prefix):]); ok {
+			q.base64 = b
+		}
+	case strings.HasPrefix(upper, md5Prefix):
+		if h, ok := normalizedHex(t[len(md5Prefix):]); ok {
+			q.hex, q.hexFormats = h, []Format{OpenSSHMD5}
+		}
+	default:
+		if h, ok := normalizedHex(t); ok {
+			q.hex, q.hexFormats = h, []Format{OpenSSHMD5, SHA1Hex, SHA256Hex}
+		}
+		if b, ok := normalizedBase64(t); ok {
+			q.base64 = b
+		}
+	}
+
+	if q.hex == "" && q.base64 == "" {
+		return nil, ErrInvalidFingerprint
+	}
+	if len(q.hex) < MinimumQueryLength && len(q.base64) < MinimumQueryLength {
+		return nil, ErrFingerprintTooShort
+	}
+	return q, nil
+}
+
+// Matches returns true if the fingerprint of the key starts with the query
+func (q *Query) Matches(k digestable) bool {
+	if len(q.base64) >= MinimumQueryLength {
+		if digest, ok := digestOf(k, OpenSSHSHA256); ok && strings.HasPrefix(base64.RawStdEncoding.EncodeToString(digest), q.base64) {
+			return true
+		}
+	}
+
+	if len(q.hex) >= MinimumQueryLength {
+		for _, f := range q.hexFormats {
+			if digest, ok := digestOf(k, f); ok && strings.HasPrefix(hex.EncodeToString(digest), q.hex) {
+				return true
+			}
+		}
+	}
+	return false
+}
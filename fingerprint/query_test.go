@@ -0,0 +1,91 @@
+package fingerprint
+
+import (
+	"golang.org/x/crypto/ssh"
+)
+
+func rfc8032KeyForQueries() *sshKeyForTest {
+	k, _ := ssh.NewPublicKey(rfc8032PublicKey())
+	return &sshKeyForTest{digestContentKey{k.Marshal()}}
+}
+
+func (s *fingerprintSuite) Test_Query_matchesTheFingerprintInAllFormats() {
+	k := rfc8032KeyForQueries()
+
+	for _, text := range []string{
+		"SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8",
+		"SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8=",
+		"sha256:bbXpuKG6",
+		"bbXpuKG6zhzd",
+		"  SHA256: bbXp uKG6\n",
+		"MD5:cf:07:be:9d:68:ae:65:54:6d:a0:93:c3:6f:bd:0d:82",
+		"md5:CF:07:BE",
+		"cf07be9d68ae",
+		"CF-07-BE-9D",
+		"cf 07 be 9d",
+		OfContent(k.content, SHA1Hex),
+		OfContent(k.content, SHA1Hex)[:11],
+		OfContent(k.content, SHA256Hex),
+	} {
+		q, e := ParseQuery(text)
+		s.NoError(e, text)
+		s.True(q.Matches(k), text)
+	}
+}
+
+func (s *fingerprintSuite) Test_Query_doesNotMatchOtherFingerprints() {
+	k := rfc8032KeyForQueries()
+
+	for _, text := range []string{
+		"SHA256:cbXpuKG6",
+		"SHA256:BBXPUKG6",
+		"MD5:cf:07:be:9e",
+		"cf07be9e",
+		"00:11:22:33",
+	} {
+		q, e := ParseQuery(text)
+		s.NoError(e, text)
+		s.False(q.Matches(k), text)
+	}
+}
+
+func (s *fingerprintSuite) Test_ParseQuery_failsForTextThatIsNotAFingerprint() {
+	for _, text := range []string{"", "   ", "SHA256:", "MD5:bbXpuKG6", "not a fingerprint!", "cf:07:zz"} {
+		_, e := ParseQuery(text)
+		s.Equal(ErrInvalidFingerprint, e, text)
+	}
+}
+
+func (s *fingerprintSuite) Test_ParseQuery_failsForFingerprintsThatAreTooShort() {
+	for _, text := range []string{"cf0", "MD5:cf:0", "SHA256:bbX", "c"} {
+		_, e := ParseQuery(text)
+		s.Equal(ErrFingerprintTooShort, e, text)
+	}
+}
+
+func (s *fingerprintSuite) Test_ParseQuery_checksTheBase64OfSHA256Fingerprints() {
+	for _, text := range []string{"SHA256:!!!!", "SHA256:bbXpuKG6!", "SHA256:====", "SHA256:"} {
+		_, e := ParseQuery(text)
+		s.Equal(ErrInvalidFingerprint, e, text)
+	}
+
+	for _, text := range []string{"SHA256:b", "SHA256:bbX="} {
+		_, e := ParseQuery(text)
+		s.Equal(ErrFingerprintTooShort, e, text)
+	}
+}
+
+func (s *fingerprintSuite) Test_Query_onlyMatchesTheOpenSSHFormatsOfKeysThatCanBeUsedWithSSH() {
+	sshContent := rfc8032KeyForQueries().content
+	other := &digestContentKey{sshContent}
+
+	for _, text := range []string{"SHA256:bbXpuKG6", "MD5:cf:07:be:9d"} {
+		q, e := ParseQuery(text)
+		s.NoError(e, text)
+		s.False(q.Matches(other), text)
+		s.True(q.Matches(&otherFormatKeyForTest{digestContentKey{[]byte("hello world")}, sshContent}), text)
+	}
+
+	q, _ := ParseQuery(OfContent(sshContent, SHA256Hex))
+	s.True(q.Matches(other))
+}
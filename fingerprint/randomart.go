@@ -0,0 +1,100 @@
+package fingerprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The randomart is drawn by a "drunken bishop" walking around a field of
+// randomartWidth x randomartHeight squares, as described in "The drunken bishop: An
+// analysis of the OpenSSH fingerprint visualization algorithm". Every square counts
+// how many times the bishop visited it, and is shown with a symbol from randomartSymbols
+const randomartWidth = 17
+const randomartHeight = 9
+const randomartSymbols = " .o+=*BOX@%&#/^SE"
+
+const randomartStart = len(randomartSymbols) - 2
+const randomartEnd = len(randomartSymbols) - 1
+
+// Randomart draws the digest exactly like ssh-keygen -lv does, with the key type and size
+// in the upper border and the hash algorithm in the lower one. A size of zero is left out
+func Randomart(digest []byte, keyType string, size int, hash string) string {
+	field := [randomartWidth][randomartHeight]int{}
+	x, y := randomartWidth/2, randomartHeight/2
+
+	for _, input := range digest {
+		// Every byte is four moves, two bits each, starting with the least significant ones
+		for b := 0; b < 4; b++ {
+			x = clamp(x+direction(input&0x1), 0, randomartWidth-1)
+			y = clamp(y+direction(input&0x2), 0, randomartHeight-1)
+			if field[x][y] < randomartStart-1 {
+				field[x][y]++
+			}
+			input >>= 2
+		}
+	}
+
+	field[randomartWidth/2][randomartHeight/2] = randomartStart
+	field[x][y] = randomartEnd
+
+	lines := []string{randomartBorder(randomartTitle(keyType, size))}
+	for y := 0; y < randomartHeight; y++ {
+		line := "|"
+		for x := 0; x < randomartWidth; x++ {
+			line += string(randomartSymbols[field[x][y]])
+		}
+		lines = append(lines, line+"|")
+	}
+	lines = append(lines, randomartBorder(truncate(fmt.Sprintf("[%s]", hash))))
+
+	return strings.Join(lines, "\n")
+}
+
+// RandomartOf draws the SHA256 digest of the key, which is what ssh-keygen uses by default. It returns
+// false for keys that can't be used with SSH
+func RandomartOf(k digestable, keyType string, size int) (string, bool) {
+	digest, ok := digestOf(k, OpenSSHSHA256)
+	if !ok {
+		return "", false
+	}
+	return Randomart(digest, keyType, size, "SHA256"), true
+}
+
+func direction(bit byte) int {
+	if bit != 0 {
+		return 1
+	}
+	return -1
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// truncate keeps as much of the title as fits in the border
+func truncate(title string) string {
+	if len(title) >= randomartWidth {
+		return title[:randomartWidth-1]
+	}
+	return title
+}
+
+// randomartTitle leaves the size out when the title would otherwise not fit, the same way ssh-keygen does
+func randomartTitle(keyType string, size int) string {
+	title := fmt.Sprintf("[%s %d]", keyType, size)
+	if size <= 0 || len(title) > randomartWidth {
+		title = fmt.Sprintf("[%s]", keyType)
+	}
+	return truncate(title)
+}
+
+func randomartBorder(title string) string {
+	left := (randomartWidth - len(title)) / 2
+	return "+" + strings.Repeat("-", left) + title + strings.Repeat("-", randomartWidth-left-len(title)) + "+"
+}
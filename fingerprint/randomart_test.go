@@ -0,0 +1,55 @@
+package fingerprint
+
+import (
+	"golang.org/x/crypto/ssh"
+)
+
+func (s *fingerprintSuite) Test_RandomartOf_isTheSameAsSSHKeygen() {
+	k, _ := ssh.NewPublicKey(rfc8032PublicKey())
+
+	expected := "" +
+		"+--[ED25519 256]--+\n" +
+		"|            oo   |\n" +
+		"|           o. .  |\n" +
+		"|          o ..   |\n" +
+		"|         o o o.  |\n" +
+		"|        S + o  o |\n" +
+		"|       . + +  . E|\n" +
+		"|      . ..Ooo. B.|\n" +
+		"|     o o.BBB.o+ +|\n" +
+		"|     .*+OB=o=+.  |\n" +
+		"+----[SHA256]-----+"
+
+	art, ok := RandomartOf(&sshKeyForTest{digestContentKey{k.Marshal()}}, "ED25519", 256)
+	s.True(ok)
+	s.Equal(expected, art)
+}
+
+func (s *fingerprintSuite) Test_RandomartOf_isNotAvailableForKeysThatCanNotBeUsedWithSSH() {
+	_, ok := RandomartOf(&digestContentKey{[]byte("hello world")}, "ED25519", 256)
+	s.False(ok)
+}
+
+func (s *fingerprintSuite) Test_Randomart_marksTheStartAndEndOfTheWalk() {
+	art := Randomart([]byte{}, "X25519", 0, "SHA256")
+
+	s.Equal(""+
+		"+----[X25519]-----+\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"|        E        |\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"|                 |\n"+
+		"+----[SHA256]-----+", art)
+}
+
+func (s *fingerprintSuite) Test_Randomart_leavesTheSizeOut_whenItDoesNotFit() {
+	art := Randomart([]byte{0xff}, "ED25519-CERT", 256, "MD5")
+
+	s.Equal("+-[ED25519-CERT]--+", art[:19])
+	s.Equal("+------[MD5]------+", art[len(art)-19:])
+}
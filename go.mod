@@ -3,12 +3,16 @@ module github.com/digitalautonomy/keymirror
 go 1.18
 
 require (
+	filippo.io/edwards25519 v1.0.0
 	github.com/coyim/gotk3adapter v0.0.0-20220707154823-2087a87ccf9a
 	github.com/coyim/gotk3mocks v0.0.0-20220708161244-ff28ceea7348
 	github.com/prashantv/gostub v1.1.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.1
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
 	golang.org/x/exp v0.0.0-20220314205449-43aec2f8a4e7
+	golang.org/x/image v0.18.0
+	rsc.io/qr v0.2.0
 )
 
 require (
@@ -0,0 +1,144 @@
+package gpg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/openpgp"
+	"github.com/digitalautonomy/keymirror/sexp"
+)
+
+// gpg-agent stores each private key in its own file in private-keys-v1.d, named after the keygrip of the
+// key. Older versions of GnuPG write the S-expression of the key in the canonical format, and newer versions
+// use the extended format, a list of name and value pairs where the Key field contains the S-expression
+// https://github.com/gpg/gnupg/blob/master/agent/keyformat.txt
+
+const (
+	privateKeySexp          = "private-key"
+	protectedPrivateKeySexp = "protected-private-key"
+	shadowedPrivateKeySexp  = "shadowed-private-key"
+)
+
+const keyField = "Key"
+
+var errUnsupportedAgentKey = errors.New("unsupported gpg-agent key")
+
+// curveAliases are the other names gpg-agent uses for some of the OpenPGP curves
+var curveAliases = map[string]string{
+	"cv25519":  "Curve25519",
+	"nistp256": "NIST P-256",
+	"nistp384": "NIST P-384",
+	"nistp521": "NIST P-521",
+}
+
+func curveAlgorithm(name string, keyAgreement bool) (api.Algorithm, int, bool) {
+	if alias, ok := curveAliases[name]; ok {
+		name = alias
+	}
+	return openpgp.CurveAlgorithm(name, keyAgreement)
+}
+
+func bitLength(value []byte) int {
+	return new(big.Int).SetBytes(value).BitLen()
+}
+
+// agentKey is a private key stored by gpg-agent. Shadowed keys are stored on a smartcard, and
+// the file only contains the public key and a reference to the card, so they are not protected
+type agentKey struct {
+	location    string
+	protected   bool
+	algorithm   api.Algorithm
+	size        int
+	publicValue []byte
+}
+
+func parseAgentKey(content []byte) (*agentKey, error) {
+	key := content
+	if !bytes.HasPrefix(content, []byte("(")) {
+		key = []byte(extendedFormatFields(content)[keyField])
+	}
+
+	s, e := sexp.Parse(key)
+	if e != nil {
+		return nil, e
+	}
+
+	k := &agentKey{}
+	switch s.Name() {
+	case privateKeySexp, shadowedPrivateKeySexp:
+	case protectedPrivateKeySexp:
+		k.protected = true
+	default:
+		return nil, errUnsupportedAgentKey
+	}
+
+	if len(s.List) < 2 || !k.readParameters(s.List[1]) {
+		return nil, errUnsupportedAgentKey
+	}
+	return k, nil
+}
+
+// extendedFormatFields returns the fields of a key in the extended format. Values can continue
+// on the following lines, if those lines start with whitespace
+func extendedFormatFields(content []byte) map[string]string {
+	result := map[string]string{}
+	name := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if name != "" {
+				result[name] += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		n, value, found := strings.Cut(line, ":")
+		if !found || strings.HasPrefix(line, "#") {
+			name = ""
+			continue
+		}
+		name = n
+		result[name] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+// integerKeyParameters are the parameters of the keys libgcrypt names by their algorithm, that give
+// the size of the key and its public value
+var integerKeyParameters = map[string]struct{ size, publicValue string }{
+	"rsa": {"n", "n"},
+	"dsa": {"p", "y"},
+	"elg": {"p", "y"},
+}
+
+func init() {
+	api.RegisterWireNames(api.RSA, "rsa")
+	api.RegisterWireNames(api.DSA, "dsa")
+	api.RegisterWireNames(api.ElGamal, "elg")
+}
+
+func (k *agentKey) readParameters(params *sexp.Expression) bool {
+	if p, ok := integerKeyParameters[params.Name()]; ok {
+		k.algorithm, _ = api.AlgorithmWithWireName(params.Name())
+		k.size, k.publicValue = bitLength(params.ValueOf(p.size)), params.ValueOf(p.publicValue)
+		return k.publicValue != nil
+	}
+
+	switch params.Name() {
+	case "ecc", "ecdsa", "eddsa", "ecdh":
+		var ok bool
+		k.algorithm, k.size, ok = curveAlgorithm(string(params.ValueOf("curve")), params.Name() == "ecdh")
+		if !ok {
+			return false
+		}
+		k.publicValue = params.ValueOf("q")
+	default:
+		return false
+	}
+	return k.publicValue != nil
+}
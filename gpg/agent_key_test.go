@@ -0,0 +1,67 @@
+package gpg
+
+import (
+	"encoding/hex"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+func (s *gpgSuite) Test_parseAgentKey_readsProtectedKeysInTheExtendedFormat() {
+	k, e := parseAgentKey([]byte(aliceAgentKey))
+	s.Require().NoError(e)
+	s.True(k.protected)
+	s.Equal(api.Ed25519, k.algorithm)
+	s.Equal(alicePublicValue, hex.EncodeToString(k.publicValue))
+}
+
+func (s *gpgSuite) Test_parseAgentKey_readsUnprotectedKeysInTheCanonicalFormat() {
+	k, e := parseAgentKey(aliceSubkeyAgentKey())
+	s.Require().NoError(e)
+	s.False(k.protected)
+	s.Equal(api.X25519, k.algorithm)
+	s.Equal(aliceSubkeyPublicValue, hex.EncodeToString(k.publicValue))
+}
+
+func (s *gpgSuite) Test_parseAgentKey_readsRSAAndDSAKeys() {
+	k, e := parseAgentKey([]byte("(private-key (rsa (n #00FF01#) (e #010001#) (d #01#)))"))
+	s.Require().NoError(e)
+	s.Equal(api.RSA, k.algorithm)
+	s.Equal(16, k.size)
+	s.Equal([]byte{0x00, 0xFF, 0x01}, k.publicValue)
+
+	k, e = parseAgentKey([]byte("(shadowed-private-key (dsa (p #0FFF#) (q #01#) (g #02#) (y #0304#) (shadowed t1-v1 (card))))"))
+	s.Require().NoError(e)
+	s.False(k.protected)
+	s.Equal(api.DSA, k.algorithm)
+	s.Equal(12, k.size)
+	s.Equal([]byte{0x03, 0x04}, k.publicValue)
+}
+
+func (s *gpgSuite) Test_parseAgentKey_readsECDSAKeysOnNISTCurves() {
+	k, e := parseAgentKey([]byte("Key: (private-key (ecc (curve \"NIST P-521\") (q #0401#) (d #01#)))\n"))
+	s.Require().NoError(e)
+	s.Equal(api.ECDSA, k.algorithm)
+	s.Equal(521, k.size)
+}
+
+func (s *gpgSuite) Test_parseAgentKey_failsForUnsupportedKeys() {
+	for _, unsupported := range []string{
+		"(public-key (rsa (n #01#) (e #03#)))",
+		"(private-key (ecc (curve Ed448) (q #01#)))",
+		"(private-key (kyber (q #01#)))",
+		"(private-key (rsa (e #03#)))",
+		"(private-key)",
+		"Created: 20261019T005047\n",
+	} {
+		_, e := parseAgentKey([]byte(unsupported))
+		s.Error(e, unsupported)
+	}
+}
+
+func (s *gpgSuite) Test_extendedFormatFields_joinsContinuationLines() {
+	fields := extendedFormatFields([]byte("# a comment\nKey: (a\n  b)\nLabel: something: else\n\tcontinued\n"))
+	s.Equal(map[string]string{
+		"Key":   "(a\nb)",
+		"Label": "something: else\ncontinued",
+	}, fields)
+}
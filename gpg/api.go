@@ -0,0 +1,179 @@
+package gpg
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/openpgp"
+	"github.com/sirupsen/logrus"
+)
+
+const homeDirectoryVariable = "GNUPGHOME"
+const defaultHomeDirectory = ".gnupg"
+
+const (
+	keyboxFile            = "pubring.kbx"
+	publicKeyringFile     = "pubring.gpg"
+	secretKeyringFile     = "secring.gpg"
+	privateKeysDirectory  = "private-keys-v1.d"
+	privateKeyFilePattern = "*.key"
+)
+
+// Access returns a key access that lists the keys in the GnuPG home directory of the current user.
+// The keyrings and the private keys of gpg-agent are read directly, without running gpg
+func Access(l logrus.FieldLogger) api.KeyAccess {
+	return &access{
+		log:           l.WithField("component", "gpg"),
+		homeDirectory: homeDirectory(),
+	}
+}
+
+func homeDirectory() string {
+	if dir := os.Getenv(homeDirectoryVariable); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, defaultHomeDirectory)
+}
+
+type access struct {
+	log           logrus.Ext1FieldLogger
+	homeDirectory string
+}
+
+// keyCollection keeps the key entries in the order they were found, so keys that
+// are in more than one keyring are only listed once
+type keyCollection struct {
+	entries       []*keyEntry
+	byFingerprint map[string]*keyEntry
+}
+
+func (c *keyCollection) add(k *openpgp.TransferableKey, location string, private bool) {
+	f := hex.EncodeToString(k.Primary().Fingerprint())
+	entry, found := c.byFingerprint[f]
+	if !found {
+		entry = &keyEntry{key: k}
+		c.byFingerprint[f] = entry
+		c.entries = append(c.entries, entry)
+	}
+
+	if !private {
+		entry.publicLocations = append(entry.publicLocations, location)
+		return
+	}
+	entry.privateLocations = append(entry.privateLocations, location)
+	for _, p := range k.KeyPackets() {
+		entry.protected = entry.protected || p.IsProtected()
+	}
+}
+
+// addAgentKey adds the private key to the entry it belongs to, if there is one
+func (c *keyCollection) addAgentKey(ak *agentKey) bool {
+	for _, entry := range c.entries {
+		for _, p := range entry.key.KeyPackets() {
+			if openpgp.ComparablePublicValue(p.PublicValue()) == openpgp.ComparablePublicValue(ak.publicValue) {
+				entry.privateLocations = append(entry.privateLocations, ak.location)
+				entry.protected = entry.protected || ak.protected
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *access) readFile(name string) ([]byte, bool) {
+	content, e := os.ReadFile(filepath.Join(a.homeDirectory, name))
+	if e != nil {
+		if !os.IsNotExist(e) {
+			a.log.WithError(e).WithField("file", name).Warn("couldn't read GnuPG file")
+		}
+		return nil, false
+	}
+	return content, true
+}
+
+// keyrings are read in the order their keys are listed in
+var keyrings = []struct {
+	name    string
+	read    func([]byte) ([]*openpgp.TransferableKey, error)
+	private bool
+}{
+	{keyboxFile, readKeybox, false},
+	{publicKeyringFile, openpgp.ReadKeyring, false},
+	{secretKeyringFile, openpgp.ReadKeyring, true},
+}
+
+func (a *access) addKeys(c *keyCollection, name string, read func([]byte) ([]*openpgp.TransferableKey, error), private bool) {
+	content, ok := a.readFile(name)
+	if !ok {
+		return
+	}
+
+	keys, e := read(content)
+	if e != nil {
+		a.log.WithError(e).WithField("file", name).Warn("couldn't read all keys from the keyring")
+	}
+	location := filepath.Join(a.homeDirectory, name)
+	for _, k := range keys {
+		c.add(k, location, private)
+	}
+}
+
+func (a *access) agentKeys(ctx context.Context) ([]*agentKey, error) {
+	files, _ := filepath.Glob(filepath.Join(a.homeDirectory, privateKeysDirectory, privateKeyFilePattern))
+	result := []*agentKey{}
+	for _, f := range files {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+
+		content, e := os.ReadFile(f)
+		if e != nil {
+			a.log.WithError(e).WithField("file", f).Warn("couldn't read gpg-agent key")
+			continue
+		}
+
+		k, e := parseAgentKey(content)
+		if e != nil {
+			a.log.WithError(e).WithField("file", f).Debug("couldn't parse gpg-agent key")
+			continue
+		}
+		k.location = f
+		result = append(result, k)
+	}
+	return result, nil
+}
+
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	c := &keyCollection{byFingerprint: map[string]*keyEntry{}}
+	for _, k := range keyrings {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		a.addKeys(c, k.name, k.read, k.private)
+	}
+
+	agentKeys, e := a.agentKeys(ctx)
+	if e != nil {
+		return nil, e
+	}
+
+	result := []api.KeyEntry{}
+	unpaired := []api.KeyEntry{}
+	for _, ak := range agentKeys {
+		if !c.addAgentKey(ak) {
+			unpaired = append(unpaired, &agentKeyEntry{key: ak})
+		}
+	}
+	for _, entry := range c.entries {
+		result = append(result, entry)
+	}
+	return append(result, unpaired...), nil
+}
@@ -0,0 +1,118 @@
+package gpg
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *gpgSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(dir string) *access {
+	logger := testutil.NullLogger()
+	return &access{log: logger, homeDirectory: dir}
+}
+
+func (s *gpgSuite) writeFileForTest(dir, name string, content []byte) string {
+	fileName := filepath.Join(dir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(fileName), 0700))
+	s.Require().NoError(os.WriteFile(fileName, content, 0600))
+	return fileName
+}
+
+func (s *gpgSuite) Test_access_AllKeys_pairsKeysInTheKeyboxWithTheKeysOfTheAgent() {
+	dir := s.T().TempDir()
+	keybox := s.writeFileForTest(dir, keyboxFile, keyboxForTest(aliceKeyForTest()))
+	primary := s.writeFileForTest(dir, "private-keys-v1.d/01D4F422811437701E9F0FCD39C8BDAE06FB6579.key", []byte(aliceAgentKey))
+	sub := s.writeFileForTest(dir, "private-keys-v1.d/DF4AC041736BE8BBDF29F34F2991B210EA7D3C58.key", aliceSubkeyAgentKey())
+	other := s.writeFileForTest(dir, "private-keys-v1.d/FFFF.key", []byte("(private-key (rsa (n #00FF01#) (e #010001#)))"))
+	s.writeFileForTest(dir, "private-keys-v1.d/broken.key", []byte("(private-key"))
+
+	keys := s.allKeysOf(accessForTest(dir))
+	s.Require().Len(keys, 2)
+
+	k := keys[0].(api.OpenPGPKeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{keybox}, k.PublicKeyLocations())
+	s.Equal([]string{primary, sub}, k.PrivateKeyLocations())
+	s.Equal([]string{keybox, primary, sub}, k.Locations())
+	s.True(k.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.Equal(api.Ed25519, k.Algorithm())
+	s.Equal(4, k.OpenPGPVersion())
+	s.Equal(aliceFingerprint, hex.EncodeToString(k.OpenPGPFingerprint()))
+	s.Equal("Alice <alice@example.org>", k.UserID())
+	s.Equal(time.Unix(aliceCreated, 0), k.Created())
+	s.Equal(time.Unix(aliceExpiry, 0), k.Expiry())
+	s.Nil(k.(api.RSAKeyEntry).RSAPublicExponent())
+	s.Equal([]api.OpenPGPSubkey{{
+		Fingerprint: testutil.DecodeHex(aliceSubkeyFingerprint),
+		Algorithm:   api.X25519,
+		Created:     time.Unix(aliceCreated, 0),
+		Expiry:      time.Unix(aliceSubkeyExpiry, 0),
+	}}, k.Subkeys())
+	s.Equal(testutil.DecodeHex(aliceFingerprint), k.WithDigestContent(func(b []byte) []byte {
+		res := sha1.Sum(b)
+		return res[:]
+	}), "the SHA-1 digest is the V4 fingerprint")
+	s.Equal(fingerprint.SSHWireFormat(ed25519.PublicKey(testutil.DecodeHex(alicePublicValue)[1:])), k.(api.SSHPublicKeyEntry).SSHPublicKey())
+
+	agentOnly := keys[1].(api.PrivateKeyEntry)
+	s.Equal(api.PrivateKeyType, agentOnly.KeyType())
+	s.Equal([]string{other}, agentOnly.Locations())
+	s.Nil(agentOnly.PublicKeyLocations())
+	s.Equal(api.RSA, agentOnly.Algorithm())
+	s.Equal(16, agentOnly.Size())
+	s.False(agentOnly.IsPasswordProtected())
+}
+
+func (s *gpgSuite) Test_access_AllKeys_readsTheLegacyKeyrings_andListsKeysOnlyOnce() {
+	dir := s.T().TempDir()
+	keybox := s.writeFileForTest(dir, keyboxFile, keyboxForTest(aliceKeyForTest()))
+	pubring := s.writeFileForTest(dir, publicKeyringFile, aliceKeyForTest())
+
+	secring := s.writeFileForTest(dir, secretKeyringFile, aliceSecretKeyPacketForTest(254))
+
+	keys := s.allKeysOf(accessForTest(dir))
+	s.Require().Len(keys, 1)
+	s.Equal([]string{keybox, pubring}, keys[0].PublicKeyLocations())
+	s.Equal([]string{secring}, keys[0].PrivateKeyLocations())
+	s.True(keys[0].(api.PrivateKeyEntry).IsPasswordProtected())
+}
+
+func (s *gpgSuite) Test_access_AllKeys_listsKeysOnlyInTheSecretKeyringAsPrivateKeys() {
+	dir := s.T().TempDir()
+	secring := s.writeFileForTest(dir, secretKeyringFile, aliceSecretKeyPacketForTest(0))
+
+	keys := s.allKeysOf(accessForTest(dir))
+	s.Require().Len(keys, 1)
+	s.Equal(api.PrivateKeyType, keys[0].KeyType())
+	s.Equal([]string{secring}, keys[0].Locations())
+	s.False(keys[0].(api.PrivateKeyEntry).IsPasswordProtected())
+}
+
+func (s *gpgSuite) Test_access_AllKeys_returnsNothing_forAMissingHomeDirectory() {
+	s.Empty(s.allKeysOf(accessForTest(filepath.Join(s.T().TempDir(), "missing"))))
+}
+
+func (s *gpgSuite) Test_Access_usesTheGnuPGHomeDirectory() {
+	logger := testutil.NullLogger()
+
+	s.T().Setenv("GNUPGHOME", "/somewhere/else")
+	s.Equal("/somewhere/else", Access(logger).(*access).homeDirectory)
+
+	s.T().Setenv("GNUPGHOME", "")
+	home, _ := os.UserHomeDir()
+	s.Equal(filepath.Join(home, ".gnupg"), Access(logger).(*access).homeDirectory)
+}
@@ -0,0 +1,88 @@
+package gpg
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type gpgSuite struct {
+	suite.Suite
+}
+
+func TestGpgSuite(t *testing.T) {
+	suite.Run(t, new(gpgSuite))
+}
+
+// aliceKey was exported by GnuPG 2.2 from a key generated with:
+//
+//	gpg --quick-gen-key 'Alice <alice@example.org>' ed25519 cert,sign 2y
+//	gpg --quick-add-key <fingerprint> cv25519 encr 1y
+const aliceKey = "" +
+	"mDMEatVpZxYJKwYBBAHaRw8BAQdAUa0h63+ExUSq233/eBf2szljOvdlg4AA9GYwhGltpVa0GUFs" +
+	"aWNlIDxhbGljZUBleGFtcGxlLm9yZz6IlgQTFggAPhYhBEtK4FnH125gHVRVcjsyvkdrtz5JBQJq" +
+	"1WlnAhsDBQkDwmcABQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEDsyvkdrtz5JigQBAMPe9OIR" +
+	"A6TgcBkFl2vz7axVTKxfIlWyCTzI2VllzztXAP46g+w/kiADNKBCqi0skem208ZIvNDsRA4AoXDc" +
+	"qsoNDLg4BGrVaWcSCisGAQQBl1UBBQEBB0CnFc6kCZkMy1UURBc0FM9u37LsRz9PAv9C8AeHi+BK" +
+	"BgMBCAeIfgQYFggAJhYhBEtK4FnH125gHVRVcjsyvkdrtz5JBQJq1WlnAhsMBQkB4TOAAAoJEDsy" +
+	"vkdrtz5JELoBALhqyC2AxSaWcDb1Nz0OlCOTVkZJXs+7kAUWaMXMgnuzAQDAUmJWiJOB1Ey3Gja+" +
+	"NumrYeRf7MlbjtoYcIkxT11rCg=="
+
+const aliceFingerprint = "4b4ae059c7d76e601d5455723b32be476bb73e49"
+const aliceSubkeyFingerprint = "ca8e01c4d4230c3d415541d1990cf8952335d2d7"
+
+const aliceCreated = 1792371047
+const aliceExpiry = 1855443047
+const aliceSubkeyExpiry = 1823907047
+
+const alicePublicValue = "4051ad21eb7f84c544aadb7dff7817f6b339633af765838000f4663084696da556"
+const aliceSubkeyPublicValue = "40a715cea409990ccb551444173414cf6edfb2ec473f4f02ff42f007878be04a06"
+
+// aliceAgentKey is the private key of Alice's primary key as stored by gpg-agent, in the extended
+// format, with the protected secret replaced
+const aliceAgentKey = `Created: 20261019T005047
+Key: (protected-private-key (ecc (curve Ed25519)(flags eddsa)(q
+  #4051AD21EB7F84C544AADB7DFF7817F6B339633AF765838000F4663084696DA556#)
+ (protected openpgp-s2k3-ocb-aes ((sha1 #0102030405060708# "20971520")
+ #0102030405060708090A0B0C#)#DEADBEEF#)(protected-at "20261019T005047")))
+`
+
+// aliceSubkeyAgentKey returns the unprotected private key of Alice's subkey in the canonical
+// format used by older versions of gpg-agent, with a made up secret
+func aliceSubkeyAgentKey() []byte {
+	return []byte("(11:private-key(3:ecc(5:curve10:Curve25519)(5:flags9:djb-tweak)" +
+		"(1:q33:" + string(testutil.DecodeHex(aliceSubkeyPublicValue)) + ")" +
+		"(1:d32:" + string(make([]byte, 32)) + ")))")
+}
+
+func aliceKeyForTest() []byte {
+	data, _ := base64.StdEncoding.DecodeString(aliceKey)
+	return data
+}
+
+// aliceSecretKeyPacketForTest returns a secret key packet with Alice's primary key, followed by the
+// string-to-key usage byte. The secret itself is left out, since it is never read
+func aliceSecretKeyPacketForTest(s2kUsage byte) []byte {
+	// the primary key is the first packet of the export, with an old format header of two bytes
+	body := append(append([]byte{}, aliceKeyForTest()[2:2+0x33]...), s2kUsage)
+	return append([]byte{0xC5, byte(len(body))}, body...)
+}
+
+// keyboxBlobForTest creates a keybox blob of the given type. The key information, user IDs and
+// checksum of real blobs are not used when reading, so they are left out
+func keyboxBlobForTest(blobType byte, keyblock []byte) []byte {
+	header := append([]byte{blobType, 1, 0, 0}, testutil.Uint32(openPGPBlobHeaderLength)...)
+	header = append(header, testutil.Uint32(len(keyblock))...)
+	blob := append(header, keyblock...)
+	return append(testutil.Uint32(len(blob)+4), blob...)
+}
+
+func keyboxForTest(keyblocks ...[]byte) []byte {
+	result := keyboxBlobForTest(1, []byte("KBXf\x00\x00\x00\x00"))
+	for _, kb := range keyblocks {
+		result = append(result, keyboxBlobForTest(openPGPBlobType, kb)...)
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package gpg
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/openpgp"
+)
+
+// keyEntry represents an OpenPGP key from the keyrings. The private keys can be in the
+// legacy secret keyring or stored by gpg-agent, for the primary key or any of the subkeys
+type keyEntry struct {
+	key              *openpgp.TransferableKey
+	publicLocations  []string
+	privateLocations []string
+	protected        bool
+}
+
+// ID implements the api.KeyEntry interface. The fingerprint of the primary key identifies OpenPGP keys
+func (k *keyEntry) ID() string {
+	return api.MaterialID("openpgp", k.OpenPGPFingerprint())
+}
+
+func (k *keyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *keyEntry) Locations() []string {
+	return append(append([]string{}, k.publicLocations...), k.privateLocations...)
+}
+
+func (k *keyEntry) PublicKeyLocations() []string {
+	return k.publicLocations
+}
+
+func (k *keyEntry) PrivateKeyLocations() []string {
+	return k.privateLocations
+}
+
+func (k *keyEntry) KeyType() api.KeyType {
+	switch {
+	case len(k.privateLocations) == 0:
+		return api.PublicKeyType
+	case len(k.publicLocations) == 0:
+		return api.PrivateKeyType
+	}
+	return api.PairKeyType
+}
+
+func (k *keyEntry) Size() int {
+	return k.key.Primary().Size()
+}
+
+func (k *keyEntry) Algorithm() api.Algorithm {
+	return k.key.Primary().Algorithm()
+}
+
+// WithDigestContent implements the api.PublicKeyEntry interface. The content is the one used to calculate
+// the OpenPGP fingerprint, so the SHA-1 digest of a V4 key is the same as its fingerprint
+func (k *keyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.key.Primary().HashedKey())
+}
+
+// SSHPublicKey implements the api.SSHPublicKeyEntry interface. It's the primary key in the SSH wire
+// format, for the algorithms SSH supports
+func (k *keyEntry) SSHPublicKey() []byte {
+	pub, ok := k.key.Primary().PublicKey()
+	if !ok {
+		return nil
+	}
+	return fingerprint.SSHWireFormat(pub)
+}
+
+func (k *keyEntry) UserID() string {
+	ids := k.UserIDs()
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+func (k *keyEntry) IsPasswordProtected() bool {
+	return k.protected
+}
+
+// RSAPublicExponent implements the api.RSAKeyEntry interface
+func (k *keyEntry) RSAPublicExponent() *big.Int {
+	return k.key.Primary().RSAPublicExponent()
+}
+
+func (k *keyEntry) OpenPGPVersion() int {
+	return k.key.Primary().Version()
+}
+
+func (k *keyEntry) OpenPGPFingerprint() []byte {
+	return k.key.Primary().Fingerprint()
+}
+
+func (k *keyEntry) UserIDs() []string {
+	return k.key.UserIDs()
+}
+
+func (k *keyEntry) Created() time.Time {
+	return k.key.Primary().Created()
+}
+
+func (k *keyEntry) Expiry() time.Time {
+	return k.key.Expiry()
+}
+
+func (k *keyEntry) Subkeys() []api.OpenPGPSubkey {
+	result := []api.OpenPGPSubkey{}
+	for _, s := range k.key.Subkeys() {
+		result = append(result, api.OpenPGPSubkey{
+			Fingerprint: s.Key().Fingerprint(),
+			Algorithm:   s.Key().Algorithm(),
+			Size:        s.Key().Size(),
+			Created:     s.Key().Created(),
+			Expiry:      s.Expiry(),
+		})
+	}
+	return result
+}
+
+// agentKeyEntry represents a private key stored by gpg-agent that doesn't belong to any key in
+// the keyrings, like the keys added with ssh-add when gpg-agent is used as the SSH agent
+type agentKeyEntry struct {
+	key *agentKey
+}
+
+// ID implements the api.KeyEntry interface, using the public key stored with the private key
+func (k *agentKeyEntry) ID() string {
+	if len(k.key.publicValue) == 0 {
+		return api.LocationID(k.key.location)
+	}
+	return api.MaterialID("gpg-agent", k.key.publicValue)
+}
+
+func (k *agentKeyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *agentKeyEntry) Locations() []string {
+	return []string{k.key.location}
+}
+
+func (k *agentKeyEntry) PublicKeyLocations() []string {
+	return nil
+}
+
+func (k *agentKeyEntry) PrivateKeyLocations() []string {
+	return []string{k.key.location}
+}
+
+func (k *agentKeyEntry) KeyType() api.KeyType {
+	return api.PrivateKeyType
+}
+
+func (k *agentKeyEntry) Size() int {
+	return k.key.size
+}
+
+func (k *agentKeyEntry) Algorithm() api.Algorithm {
+	return k.key.algorithm
+}
+
+func (k *agentKeyEntry) IsPasswordProtected() bool {
+	return k.key.protected
+}
@@ -0,0 +1,79 @@
+package gpg
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/digitalautonomy/keymirror/openpgp"
+)
+
+// The keybox format used for pubring.kbx is described in kbx/keybox-blob.c in the GnuPG sources.
+// The file is a sequence of blobs, and the blobs containing OpenPGP keys have the transferable
+// key in a keyblock, at an offset given in the blob header
+// https://github.com/gpg/gnupg/blob/master/kbx/keybox-blob.c
+
+const openPGPBlobType = 2
+
+const (
+	blobTypeOffset          = 4
+	keyblockOffsetOffset    = 8
+	keyblockLengthOffset    = 12
+	openPGPBlobHeaderLength = 16
+	minimumBlobHeaderLength = 5
+)
+
+var errInvalidKeybox = errors.New("invalid keybox file")
+
+// keyboxKeyblocks returns the keyblocks of the OpenPGP blobs in the keybox. The keyblocks read
+// before an invalid blob are returned together with the error
+func keyboxKeyblocks(data []byte) ([][]byte, error) {
+	result := [][]byte{}
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return result, errInvalidKeybox
+		}
+		l := binary.BigEndian.Uint32(data)
+		if l < minimumBlobHeaderLength || uint64(l) > uint64(len(data)) {
+			return result, errInvalidKeybox
+		}
+
+		blob := data[:l]
+		data = data[l:]
+		if blob[blobTypeOffset] != openPGPBlobType {
+			continue
+		}
+
+		keyblock, ok := keyblockFrom(blob)
+		if !ok {
+			return result, errInvalidKeybox
+		}
+		result = append(result, keyblock)
+	}
+	return result, nil
+}
+
+func keyblockFrom(blob []byte) ([]byte, bool) {
+	if len(blob) < openPGPBlobHeaderLength {
+		return nil, false
+	}
+	offset := binary.BigEndian.Uint32(blob[keyblockOffsetOffset:])
+	length := binary.BigEndian.Uint32(blob[keyblockLengthOffset:])
+	if uint64(offset)+uint64(length) > uint64(len(blob)) {
+		return nil, false
+	}
+	return blob[offset : offset+length], true
+}
+
+// readKeybox returns the keys in all the keyblocks of the keybox
+func readKeybox(data []byte) ([]*openpgp.TransferableKey, error) {
+	keyblocks, e := keyboxKeyblocks(data)
+	result := []*openpgp.TransferableKey{}
+	for _, kb := range keyblocks {
+		keys, ke := openpgp.ReadKeyring(kb)
+		result = append(result, keys...)
+		if e == nil {
+			e = ke
+		}
+	}
+	return result, e
+}
@@ -0,0 +1,31 @@
+package gpg
+
+func (s *gpgSuite) Test_keyboxKeyblocks_returnsTheKeyblocksOfOpenPGPBlobs() {
+	data := keyboxForTest([]byte("first"), []byte("second"))
+	data = append(data, keyboxBlobForTest(3, []byte("X.509 certificate"))...)
+
+	keyblocks, e := keyboxKeyblocks(data)
+	s.NoError(e)
+	s.Equal([][]byte{[]byte("first"), []byte("second")}, keyblocks)
+}
+
+func (s *gpgSuite) Test_keyboxKeyblocks_failsForInvalidBlobs() {
+	data := keyboxForTest([]byte("first"))
+	keyblocks, e := keyboxKeyblocks(append(data, 0, 0, 0, 10, 2))
+	s.Equal(errInvalidKeybox, e)
+	s.Equal([][]byte{[]byte("first")}, keyblocks)
+
+	blob := keyboxBlobForTest(openPGPBlobType, []byte("first"))
+	blob[keyblockLengthOffset+3] = 100
+	_, e = keyboxKeyblocks(blob)
+	s.Equal(errInvalidKeybox, e)
+
+	_, e = keyboxKeyblocks([]byte{0, 0, 0, 6, openPGPBlobType, 1})
+	s.Equal(errInvalidKeybox, e)
+}
+
+func (s *gpgSuite) Test_readKeybox_readsTheKeysInAllKeyblocks() {
+	keys, e := readKeybox(keyboxForTest(aliceKeyForTest(), aliceKeyForTest()))
+	s.NoError(e)
+	s.Len(keys, 2)
+}
@@ -0,0 +1,71 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/files"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+// copyLabelToClipboard depends on the fact that focusing a selectable label will select all of its content
+func copyLabelToClipboard(l gtki.Label) {
+	l.GrabFocus()
+	_, _ = l.Emit("copy-clipboard")
+}
+
+// saveContentToFile asks the user where to save the content. The file gets the permissions even when it
+// replaces an existing file, so secrets don't stay readable by others
+func (u *ui) saveContentToFile(title, suggestedName string, content []byte, perm os.FileMode) {
+	d, _ := u.gtk.FileChooserDialogNewWith2Buttons(title, u.mainWindow, gtki.FILE_CHOOSER_ACTION_SAVE,
+		i18n.Local("_Cancel"), gtki.RESPONSE_CANCEL,
+		i18n.Local("_Save"), gtki.RESPONSE_ACCEPT)
+	defer d.Destroy()
+
+	d.SetDoOverwriteConfirmation(true)
+	d.SetCurrentName(suggestedName)
+
+	if d.Run() != int(gtki.RESPONSE_ACCEPT) {
+		return
+	}
+
+	fileName := d.GetFilename()
+	if e := files.WriteAtomically(fileName, content, perm); e != nil {
+		u.log.WithError(e).WithField("file", fileName).Error("couldn't save the file")
+		u.showMessage(fmt.Sprintf(i18n.Local("The file couldn't be saved: %s"), e))
+	}
+}
+
+func (kd *keyDetails) onClicked(id string, f func()) {
+	kd.builder.get(id).(gtki.Button).Connect("clicked", f)
+}
+
+func (kd *keyDetails) enable(ids ...string) {
+	for _, id := range ids {
+		kd.builder.get(id).(gtki.Widget).SetSensitive(true)
+	}
+}
+
+// showMessage shows the message in a dialog, and waits until the user closes it
+func (u *ui) showMessage(message string) {
+	d, b := buildObjectFrom[gtki.Dialog](u, "MessageDialog")
+	defer d.Destroy()
+
+	b.get("message").(gtki.Label).SetLabel(message)
+	d.SetTransientFor(u.mainWindow)
+	d.Run()
+}
+
+// chooseFolder asks the user for a folder. The action decides whether new folders can be created
+func (u *ui) chooseFolder(title string, action gtki.FileChooserAction) (string, bool) {
+	d, _ := u.gtk.FileChooserDialogNewWith2Buttons(title, u.mainWindow, action,
+		i18n.Local("_Cancel"), gtki.RESPONSE_CANCEL,
+		i18n.Local("_Select"), gtki.RESPONSE_ACCEPT)
+	defer d.Destroy()
+
+	if d.Run() != int(gtki.RESPONSE_ACCEPT) {
+		return "", false
+	}
+	return d.GetFilename(), true
+}
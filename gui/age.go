@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/age"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const ageRecipientLabel = "ageRecipientLabel"
+const ageRecipientBox = "ageRecipientBox"
+const ageRecipient = "ageRecipient"
+const copyAgeRecipientButton = "copyAgeRecipientButton"
+const saveAgeRecipientButton = "saveAgeRecipientButton"
+
+const ageIdentityLabel = "ageIdentityLabel"
+const ageIdentityBox = "ageIdentityBox"
+const ageIdentity = "ageIdentity"
+const revealAgeIdentityButton = "revealAgeIdentityButton"
+const copyAgeIdentityButton = "copyAgeIdentityButton"
+const saveAgeIdentityButton = "saveAgeIdentityButton"
+
+func ageRecipientFor(k api.KeyEntry) (string, bool) {
+	if ak, ok := k.(api.AgeKeyEntry); ok {
+		return ak.AgeRecipient(), true
+	}
+
+	pk, ok := k.(api.PublicKeyMaterialEntry)
+	if !ok {
+		return "", false
+	}
+
+	recipient, e := age.RecipientFrom(pk.PublicKey())
+	return recipient, e == nil
+}
+
+func (kd *keyDetails) displayAgeRecipient() {
+	recipient, ok := ageRecipientFor(kd.key)
+	if !ok {
+		kd.hideAll(ageRecipientLabel, ageRecipientBox)
+		return
+	}
+
+	label := kd.builder.get(ageRecipient).(gtki.Label)
+	label.SetLabel(recipient)
+	label.SetTooltipText(recipient)
+
+	kd.onClicked(copyAgeRecipientButton, func() {
+		copyLabelToClipboard(label)
+	})
+	kd.onClicked(saveAgeRecipientButton, func() {
+		kd.ui.saveContentToFile(i18n.Local("Save age recipient"), "recipient.txt", []byte(recipient+"\n"), 0644)
+	})
+}
+
+func (kd *keyDetails) displayAgeIdentity() {
+	k, ok := kd.key.(api.UnlockablePrivateKeyEntry)
+	if !ok || kd.key.Algorithm() != api.Ed25519 {
+		kd.hideAll(ageIdentityLabel, ageIdentityBox)
+		return
+	}
+
+	kd.onClicked(revealAgeIdentityButton, func() {
+		kd.revealAgeIdentity(k)
+	})
+}
+
+// ageIdentityFileContent follows the same format as the files generated by age-keygen
+func ageIdentityFileContent(identity, recipient string) []byte {
+	return []byte(fmt.Sprintf("# public key: %s\n%s\n", recipient, identity))
+}
+
+func (kd *keyDetails) revealAgeIdentity(k api.UnlockablePrivateKeyEntry) {
+	unlocked, ok := kd.ui.unlockPrivateKey(k)
+	if !ok {
+		return
+	}
+
+	identity, e := age.IdentityFrom(unlocked.PrivateKey())
+	if e != nil {
+		kd.ui.log.WithError(e).Error("couldn't convert the private key to an age identity")
+		return
+	}
+	recipient, _ := age.RecipientFrom(unlocked.PrivateKey().(crypto.Signer).Public())
+
+	label := kd.builder.get(ageIdentity).(gtki.Label)
+	label.SetLabel(identity)
+
+	kd.hide(revealAgeIdentityButton)
+	kd.enable(copyAgeIdentityButton, saveAgeIdentityButton)
+	kd.onClicked(copyAgeIdentityButton, func() {
+		copyLabelToClipboard(label)
+	})
+	kd.onClicked(saveAgeIdentityButton, func() {
+		kd.ui.saveContentToFile(i18n.Local("Save age identity"), "keys.txt", ageIdentityFileContent(identity, recipient), 0600)
+	})
+}
@@ -0,0 +1,179 @@
+package gui
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/stretchr/testify/mock"
+)
+
+type publicKeyMaterialEntryMock struct {
+	publicKeyEntryMock
+}
+
+func (pk *publicKeyMaterialEntryMock) PublicKey() crypto.PublicKey {
+	returns := pk.Called()
+	return ret[crypto.PublicKey](returns, 0)
+}
+
+func ed25519KeyForTest() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+const ed25519KeyForTestRecipient = "age1mp0q0mpzkzkcs9fhct6y6e3drg2re7psc4av5sc9mpw84y8kkchq6r9kjp"
+const ed25519KeyForTestIdentity = "AGE-SECRET-KEY-1XP7G8PJ09QEUKSN69MCUQZSP8N7L7FMGMXQVPGA9YRCQDYZDA98STDSL3D"
+
+func (s *guiSuite) addButtonToGet(b *gtk.MockBuilder, name string) *gtk.MockButton {
+	button := &gtk.MockButton{}
+	b.On("GetObject", name).Return(button, nil).Once()
+	s.addObjectToAssert(button)
+	return button
+}
+
+func (s *guiSuite) expectClickHandler(button *gtk.MockButton) *func() {
+	var handler func()
+	button.On("Connect", "clicked", mock.Anything).Return(nil).Once().Run(func(a mock.Arguments) {
+		handler = a.Get(1).(func())
+	})
+	return &handler
+}
+
+func (s *guiSuite) Test_keyDetails_displayAgeRecipient_showsTheRecipientOfAnEd25519Key() {
+	builderMock := &gtk.MockBuilder{}
+	key := &publicKeyMaterialEntryMock{}
+	key.On("PublicKey").Return(ed25519KeyForTest().Public()).Once()
+
+	kd := &keyDetails{
+		ui:      &ui{gtk: s.gtkMock},
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	label := s.addLabelToGet(builderMock, "ageRecipient")
+	label.On("SetLabel", ed25519KeyForTestRecipient).Return().Once()
+	label.On("SetTooltipText", ed25519KeyForTestRecipient).Return().Once()
+
+	copyHandler := s.expectClickHandler(s.addButtonToGet(builderMock, "copyAgeRecipientButton"))
+	s.expectClickHandler(s.addButtonToGet(builderMock, "saveAgeRecipientButton"))
+
+	kd.displayAgeRecipient()
+
+	label.On("GrabFocus").Return().Once()
+	label.On("Emit", "copy-clipboard", mock.Anything).Return(nil, nil).Once()
+	(*copyHandler)()
+
+	key.AssertExpectations(s.T())
+	builderMock.AssertExpectations(s.T())
+}
+
+type ageKeyEntryMock struct {
+	keyEntryMock
+	recipient string
+}
+
+func (k *ageKeyEntryMock) AgeRecipient() string {
+	return k.recipient
+}
+
+func (k *ageKeyEntryMock) WithDigestContent(func([]byte) []byte) []byte {
+	return nil
+}
+
+func (k *ageKeyEntryMock) UserID() string {
+	return ""
+}
+
+func (s *guiSuite) Test_keyDetails_displayAgeRecipient_showsTheRecipientOfAnAgeKey() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		ui:      &ui{gtk: s.gtkMock},
+		builder: &builder{builderMock},
+		key:     &ageKeyEntryMock{recipient: ed25519KeyForTestRecipient},
+	}
+
+	label := s.addLabelToGet(builderMock, "ageRecipient")
+	label.On("SetLabel", ed25519KeyForTestRecipient).Return().Once()
+	label.On("SetTooltipText", ed25519KeyForTestRecipient).Return().Once()
+
+	s.expectClickHandler(s.addButtonToGet(builderMock, "copyAgeRecipientButton"))
+	s.expectClickHandler(s.addButtonToGet(builderMock, "saveAgeRecipientButton"))
+
+	kd.displayAgeRecipient()
+
+	builderMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayAgeRecipient_hidesTheRowForKeysThatAreNotEd25519() {
+	builderMock := &gtk.MockBuilder{}
+	key := &publicKeyMaterialEntryMock{}
+	key.On("PublicKey").Return(&rsa.PublicKey{N: big.NewInt(3233), E: 17}).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "ageRecipientLabel", "ageRecipientBox")
+
+	kd.displayAgeRecipient()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayAgeIdentity_hidesTheRowForKeysThatCanNotBeUnlocked() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &keyEntryMock{},
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "ageIdentityLabel", "ageIdentityBox")
+
+	kd.displayAgeIdentity()
+}
+
+func (s *guiSuite) Test_keyDetails_displayAgeIdentity_revealsTheIdentityWhenAsked() {
+	builderMock := &gtk.MockBuilder{}
+	unlocked := &unlockedPrivateKeyMock{}
+	unlocked.On("PrivateKey").Return(ed25519KeyForTest())
+	key := &unlockablePrivateKeyEntryMock{}
+	key.On("Algorithm").Return(api.Ed25519).Once()
+	key.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+	key.On("IsPasswordProtected").Return(false)
+	key.On("Unlock", []byte(nil)).Return(unlocked, nil).Once()
+
+	kd := &keyDetails{
+		ui:      &ui{gtk: s.gtkMock},
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	revealHandler := s.expectClickHandler(s.addButtonToGet(builderMock, "revealAgeIdentityButton"))
+
+	kd.displayAgeIdentity()
+
+	label := s.addLabelToGet(builderMock, "ageIdentity")
+	label.On("SetLabel", ed25519KeyForTestIdentity).Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "revealAgeIdentityButton")
+	copyButton := s.addButtonToGet(builderMock, "copyAgeIdentityButton")
+	copyButton.On("SetSensitive", true).Return().Once()
+	s.expectClickHandler(s.addButtonToGet(builderMock, "copyAgeIdentityButton"))
+	saveButton := s.addButtonToGet(builderMock, "saveAgeIdentityButton")
+	saveButton.On("SetSensitive", true).Return().Once()
+	s.expectClickHandler(s.addButtonToGet(builderMock, "saveAgeIdentityButton"))
+
+	(*revealHandler)()
+
+	key.AssertExpectations(s.T())
+	builderMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_ageIdentityFileContent_followsTheAgeKeygenFormat() {
+	s.Equal("# public key: age1abc\nAGE-SECRET-KEY-1XYZ\n", string(ageIdentityFileContent("AGE-SECRET-KEY-1XYZ", "age1abc")))
+}
@@ -0,0 +1,178 @@
+package gui
+
+import (
+	"crypto"
+	"crypto/x509/pkix"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/x509"
+)
+
+const createCertificateLabel = "createCertificateLabel"
+const createCertificateButton = "createCertificateButton"
+
+const certificateRequestKind = "request"
+
+var certificateKeyAlgorithms = []api.Algorithm{api.RSA, api.ECDSA, api.Ed25519}
+
+// keyUsageChecks are in the same order as in the dialog
+var keyUsageChecks = []struct {
+	id    string
+	usage x509.KeyUsage
+}{
+	{"digitalSignatureCheck", x509.DigitalSignature},
+	{"keyEnciphermentCheck", x509.KeyEncipherment},
+	{"certificateSigningCheck", x509.CertificateSigning},
+	{"serverAuthenticationCheck", x509.ServerAuthentication},
+	{"clientAuthenticationCheck", x509.ClientAuthentication},
+}
+
+func certificateKeyFor(k api.KeyEntry) (api.UnlockablePrivateKeyEntry, bool) {
+	pk, ok := k.(api.UnlockablePrivateKeyEntry)
+	if !ok {
+		return nil, false
+	}
+
+	for _, a := range certificateKeyAlgorithms {
+		if k.Algorithm() == a {
+			return pk, true
+		}
+	}
+	return nil, false
+}
+
+// certificateFileName suggests a name based on the private key file, using the
+// usual extensions for certificates and certificate requests
+func certificateFileName(privateKeyLocation string, request bool) string {
+	extension := ".crt"
+	if request {
+		extension = ".csr"
+	}
+
+	base := filepath.Base(privateKeyLocation)
+	if base == "." || base == string(filepath.Separator) {
+		base = "certificate"
+	}
+	return base + extension
+}
+
+type certificateDialog struct {
+	dialog  gtki.Dialog
+	builder *builder
+}
+
+func (u *ui) newCertificateDialog() *certificateDialog {
+	d, b := buildObjectFrom[gtki.Dialog](u, "CertificateDialog")
+	d.SetTransientFor(u.mainWindow)
+
+	cd := &certificateDialog{d, b}
+	cd.builder.get("certificateKindSelection").(gtki.ComboBoxText).Connect("changed", cd.kindChanged)
+
+	return cd
+}
+
+func (cd *certificateDialog) isRequest() bool {
+	return cd.builder.get("certificateKindSelection").(gtki.ComboBoxText).GetActiveID() == certificateRequestKind
+}
+
+// kindChanged disables the validity, since that is decided by the certificate authority for requests
+func (cd *certificateDialog) kindChanged() {
+	request := cd.isRequest()
+	for _, id := range []string{"validityLabel", "validityDays"} {
+		cd.builder.get(id).(gtki.Widget).SetSensitive(!request)
+	}
+}
+
+func (cd *certificateDialog) textOf(id string) string {
+	text, _ := cd.builder.get(id).(gtki.Entry).GetText()
+	return strings.TrimSpace(text)
+}
+
+func (cd *certificateDialog) keyUsage() x509.KeyUsage {
+	result := x509.KeyUsage(0)
+	for _, c := range keyUsageChecks {
+		if cd.builder.get(c.id).(gtki.ToggleButton).GetActive() {
+			result |= c.usage
+		}
+	}
+	return result
+}
+
+func (cd *certificateDialog) options(now time.Time) x509.CertificateOptions {
+	subject := pkix.Name{CommonName: cd.textOf("commonNameEntry")}
+	if organization := cd.textOf("organizationEntry"); organization != "" {
+		subject.Organization = []string{organization}
+	}
+
+	days := cd.builder.get("validityDays").(gtki.SpinButton).GetValueAsInt()
+
+	return x509.CertificateOptions{
+		Subject:   subject,
+		NotBefore: now,
+		Validity:  time.Duration(days) * 24 * time.Hour,
+		KeyUsage:  cd.keyUsage(),
+	}.WithSubjectAlternativeNames(cd.textOf("alternativeNamesEntry"))
+}
+
+func (cd *certificateDialog) create(priv crypto.PrivateKey, now time.Time) ([]byte, error) {
+	if cd.isRequest() {
+		return x509.CertificateSigningRequest(priv, cd.options(now))
+	}
+	return x509.SelfSignedCertificate(priv, cd.options(now))
+}
+
+func (cd *certificateDialog) showError(e error) {
+	message := fmt.Sprintf(i18n.Local("The certificate couldn't be created: %s"), e)
+	if e == x509.ErrNoSubject {
+		message = i18n.Local("Enter a common name or at least one alternative name.")
+	}
+	cd.builder.get("certificateError").(gtki.Label).SetLabel(message)
+}
+
+// createCertificate runs the dialog until a certificate or certificate request has been created, or the
+// user cancels. It returns the PEM encoded result and whether it is a certificate request
+func (u *ui) createCertificate(priv crypto.PrivateKey) ([]byte, bool, bool) {
+	cd := u.newCertificateDialog()
+	defer cd.dialog.Destroy()
+
+	for cd.dialog.Run() == int(gtki.RESPONSE_OK) {
+		content, e := cd.create(priv, time.Now())
+		if e == nil {
+			return content, cd.isRequest(), true
+		}
+		cd.showError(e)
+	}
+	return nil, false, false
+}
+
+func (kd *keyDetails) displayCreateCertificate() {
+	k, ok := certificateKeyFor(kd.key)
+	if !ok {
+		kd.hideAll(createCertificateLabel, createCertificateButton)
+		return
+	}
+
+	kd.onClicked(createCertificateButton, func() {
+		unlocked, ok := kd.ui.unlockPrivateKey(k)
+		if !ok {
+			return
+		}
+
+		content, request, ok := kd.ui.createCertificate(unlocked.PrivateKey())
+		if !ok {
+			return
+		}
+
+		title := i18n.Local("Save certificate")
+		if request {
+			title = i18n.Local("Save certificate signing request")
+		}
+		kd.ui.saveContentToFile(title, certificateFileName(firstOrEmpty(kd.key.PrivateKeyLocations()), request), content, 0644)
+	})
+}
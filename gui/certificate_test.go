@@ -0,0 +1,129 @@
+package gui
+
+import (
+	"crypto/ed25519"
+	cx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"time"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/x509"
+)
+
+func (s *guiSuite) Test_certificateKeyFor_onlyAcceptsUnlockableKeysOfSupportedAlgorithms() {
+	_, ok := certificateKeyFor(fixedKeyEntry("/home/amnesia/.ssh/id_rsa", api.RSA))
+	s.False(ok)
+
+	dsaKey := &unlockablePrivateKeyEntryMock{}
+	dsaKey.On("Algorithm").Return(api.DSA)
+	_, ok = certificateKeyFor(dsaKey)
+	s.False(ok)
+
+	for _, a := range []api.Algorithm{api.RSA, api.ECDSA, api.Ed25519} {
+		k := &unlockablePrivateKeyEntryMock{}
+		k.On("Algorithm").Return(a)
+		res, ok := certificateKeyFor(k)
+		s.True(ok)
+		s.Equal(k, res)
+	}
+}
+
+func (s *guiSuite) Test_certificateFileName_usesTheNameOfThePrivateKey() {
+	s.Equal("id_ed25519.crt", certificateFileName("/home/amnesia/.ssh/id_ed25519", false))
+	s.Equal("id_ed25519.csr", certificateFileName("/home/amnesia/.ssh/id_ed25519", true))
+	s.Equal("certificate.crt", certificateFileName("", false))
+}
+
+func (s *guiSuite) Test_keyDetails_displayCreateCertificate_hidesTheButtonForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_rsa.pub", api.RSA),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, createCertificateLabel, createCertificateButton)
+
+	kd.displayCreateCertificate()
+}
+
+func (s *guiSuite) certificateDialogForTest(kind string, texts map[string]string, checked ...string) *certificateDialog {
+	builderMock := &gtk.MockBuilder{}
+	s.addObjectToAssert(builderMock)
+
+	kindSelection := &gtk.MockComboBoxText{}
+	kindSelection.On("GetActiveID").Return(kind)
+	builderMock.On("GetObject", "certificateKindSelection").Return(kindSelection, nil).Maybe()
+
+	for id, text := range texts {
+		entry := &gtk.MockEntry{}
+		entry.On("GetText").Return(text, nil)
+		builderMock.On("GetObject", id).Return(entry, nil)
+	}
+
+	validity := &gtk.MockSpinButton{}
+	validity.On("GetValueAsInt").Return(30).Maybe()
+	builderMock.On("GetObject", "validityDays").Return(validity, nil).Maybe()
+
+	for _, c := range keyUsageChecks {
+		check := &gtk.MockCheckButton{}
+		active := false
+		for _, id := range checked {
+			active = active || id == c.id
+		}
+		check.On("GetActive").Return(active)
+		builderMock.On("GetObject", c.id).Return(check, nil)
+	}
+
+	return &certificateDialog{builder: &builder{builderMock}}
+}
+
+func (s *guiSuite) Test_certificateDialog_options_collectsTheValuesFromTheDialog() {
+	cd := s.certificateDialogForTest("certificate", map[string]string{
+		"commonNameEntry":       " batcave.example ",
+		"organizationEntry":     "Wayne Enterprises",
+		"alternativeNamesEntry": "batcave.example, 192.0.2.1",
+	}, "digitalSignatureCheck", "serverAuthenticationCheck")
+
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	s.Equal(x509.CertificateOptions{
+		Subject:     pkix.Name{CommonName: "batcave.example", Organization: []string{"Wayne Enterprises"}},
+		DNSNames:    []string{"batcave.example"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+		NotBefore:   now,
+		Validity:    30 * 24 * time.Hour,
+		KeyUsage:    x509.DigitalSignature | x509.ServerAuthentication,
+	}, cd.options(now))
+}
+
+func (s *guiSuite) Test_certificateDialog_create_createsACertificateRequestWhenSelected() {
+	cd := s.certificateDialogForTest("request", map[string]string{
+		"commonNameEntry":       "alfred",
+		"organizationEntry":     "",
+		"alternativeNamesEntry": "",
+	}, "clientAuthenticationCheck")
+
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	content, e := cd.create(priv, time.Now())
+	s.Require().NoError(e)
+
+	block, _ := pem.Decode(content)
+	s.Require().NotNil(block)
+	s.Equal("CERTIFICATE REQUEST", block.Type)
+	csr, e := cx509.ParseCertificateRequest(block.Bytes)
+	s.Require().NoError(e)
+	s.Equal("alfred", csr.Subject.CommonName)
+}
+
+func (s *guiSuite) Test_certificateDialog_showError_explainsAMissingSubject() {
+	builderMock := &gtk.MockBuilder{}
+	cd := &certificateDialog{builder: &builder{builderMock}}
+
+	s.addLabelToGet(builderMock, "certificateError").On("SetLabel", "Enter a common name or at least one alternative name.").Return().Once()
+	cd.showError(x509.ErrNoSubject)
+
+	s.addLabelToGet(builderMock, "certificateError").On("SetLabel", "The certificate couldn't be created: only RSA, ECDSA and Ed25519 keys can be used for certificates").Return().Once()
+	cd.showError(x509.ErrUnsupportedKey)
+}
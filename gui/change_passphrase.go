@@ -0,0 +1,133 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const changePassphraseLabel = "changePassphraseLabel"
+const changePassphraseButton = "changePassphraseButton"
+
+type changePassphraseDialog struct {
+	dialog  gtki.Dialog
+	builder *builder
+	key     api.PassphraseChangeablePrivateKeyEntry
+}
+
+func (u *ui) newChangePassphraseDialog(k api.PassphraseChangeablePrivateKeyEntry) *changePassphraseDialog {
+	d, b := buildObjectFrom[gtki.Dialog](u, "ChangePassphraseDialog")
+	d.SetTransientFor(u.mainWindow)
+
+	cpd := &changePassphraseDialog{d, b, k}
+	cpd.populateCiphers()
+	if !k.IsPasswordProtected() {
+		cpd.hideAll("currentPassphraseLabel", "currentPassphraseEntry")
+	}
+
+	return cpd
+}
+
+func (cpd *changePassphraseDialog) hideAll(ids ...string) {
+	for _, id := range ids {
+		cpd.builder.get(id).(hideable).Hide()
+	}
+}
+
+func (cpd *changePassphraseDialog) populateCiphers() {
+	ciphers := cpd.builder.get("cipherSelection").(gtki.ComboBoxText)
+	for _, c := range cpd.key.SupportedCiphers() {
+		ciphers.AppendText(c)
+	}
+	ciphers.SetActive(0)
+}
+
+func (cpd *changePassphraseDialog) textOf(id string) []byte {
+	text, _ := cpd.builder.get(id).(gtki.Entry).GetText()
+	return []byte(text)
+}
+
+func (cpd *changePassphraseDialog) protection() api.PrivateKeyProtection {
+	return api.PrivateKeyProtection{
+		Cipher: cpd.builder.get("cipherSelection").(gtki.ComboBoxText).GetActiveText(),
+		Rounds: cpd.builder.get("kdfRounds").(gtki.SpinButton).GetValueAsInt(),
+	}
+}
+
+func (cpd *changePassphraseDialog) showError(message string) {
+	cpd.builder.get("changePassphraseError").(gtki.Label).SetLabel(message)
+}
+
+// tryToChange returns true when the passphrase was changed, or when the problem is
+// not something the user can fix by trying again
+func (cpd *changePassphraseDialog) tryToChange(u *ui) (string, bool) {
+	newPassphrase := cpd.textOf("newPassphraseEntry")
+	if string(newPassphrase) != string(cpd.textOf("confirmPassphraseEntry")) {
+		cpd.showError(i18n.Local("The new passphrases do not match."))
+		return "", false
+	}
+
+	var oldPassphrase []byte
+	if cpd.key.IsPasswordProtected() {
+		oldPassphrase = cpd.textOf("currentPassphraseEntry")
+	}
+
+	backup, e := cpd.key.ChangePassphrase(oldPassphrase, newPassphrase, cpd.protection())
+	if e == api.ErrIncorrectPassphrase {
+		cpd.showError(i18n.Local("The current passphrase is incorrect."))
+		return "", false
+	}
+
+	if e != nil {
+		u.log.WithError(e).Error("couldn't change the passphrase of the private key")
+		cpd.showError(fmt.Sprintf(i18n.Local("The passphrase couldn't be changed: %s"), e))
+		return "", false
+	}
+
+	return backup, true
+}
+
+// changePassphrase runs the dialog until the passphrase has been changed or the user cancels.
+// It returns the name of the backup of the previous private key
+func (u *ui) changePassphrase(k api.PassphraseChangeablePrivateKeyEntry) (string, bool) {
+	cpd := u.newChangePassphraseDialog(k)
+	defer cpd.dialog.Destroy()
+
+	for cpd.dialog.Run() == int(gtki.RESPONSE_OK) {
+		if backup, ok := cpd.tryToChange(u); ok {
+			return backup, true
+		}
+	}
+	return "", false
+}
+
+func (kd *keyDetails) displayChangePassphrase() {
+	k, ok := kd.key.(api.PassphraseChangeablePrivateKeyEntry)
+	if !ok {
+		kd.hideAll(changePassphraseLabel, changePassphraseButton)
+		return
+	}
+
+	kd.onClicked(changePassphraseButton, func() {
+		wasProtected := k.IsPasswordProtected()
+		if backup, ok := kd.ui.changePassphrase(k); ok {
+			kd.updateIsPasswordProtected()
+			kd.displayNotification(passphraseChangedMessage(backup, wasProtected))
+			kd.show(notificationIdentifier)
+		}
+	})
+}
+
+// passphraseChangedMessage warns about backups of keys that weren't protected, since anyone who can
+// read the backup can use the key
+func passphraseChangedMessage(backup string, wasProtected bool) string {
+	switch {
+	case backup == "":
+		return i18n.Local("The passphrase was changed.")
+	case !wasProtected:
+		return fmt.Sprintf(i18n.Local("The passphrase was changed. The previous private key was saved to %s without a passphrase, so you should remove it."), backup)
+	}
+	return fmt.Sprintf(i18n.Local("The passphrase was changed. The previous private key was saved to %s"), backup)
+}
@@ -0,0 +1,176 @@
+package gui
+
+import (
+	"errors"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/prashantv/gostub"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+type passphraseChangeablePrivateKeyEntryMock struct {
+	unlockablePrivateKeyEntryMock
+}
+
+func (pk *passphraseChangeablePrivateKeyEntryMock) SupportedCiphers() []string {
+	returns := pk.Called()
+	return ret[[]string](returns, 0)
+}
+
+func (pk *passphraseChangeablePrivateKeyEntryMock) ChangePassphrase(oldPassphrase, newPassphrase []byte, protection api.PrivateKeyProtection) (string, error) {
+	returns := pk.Called(oldPassphrase, newPassphrase, protection)
+	return returns.String(0), returns.Error(1)
+}
+
+type changePassphraseDialogMocks struct {
+	dialog       *gtk.MockDialog
+	current      *gtk.MockEntry
+	newPass      *gtk.MockEntry
+	confirmation *gtk.MockEntry
+	errorLabel   *gtk.MockLabel
+}
+
+func (s *guiSuite) setupChangePassphraseDialog(protected bool, responses ...int) *changePassphraseDialogMocks {
+	m := &changePassphraseDialogMocks{
+		dialog:       &gtk.MockDialog{},
+		current:      &gtk.MockEntry{},
+		newPass:      &gtk.MockEntry{},
+		confirmation: &gtk.MockEntry{},
+		errorLabel:   &gtk.MockLabel{},
+	}
+	b := s.setupBuildingOfObject(m.dialog, "ChangePassphraseDialog")
+
+	m.dialog.On("SetTransientFor", nil).Return().Once()
+	for _, r := range responses {
+		m.dialog.On("Run").Return(r).Once()
+	}
+	m.dialog.On("Destroy").Return().Once()
+
+	ciphers := &gtk.MockComboBoxText{}
+	ciphers.On("AppendText", "aes256-ctr").Return().Once()
+	ciphers.On("AppendText", "aes128-ctr").Return().Once()
+	ciphers.On("SetActive", 0).Return().Once()
+	ciphers.On("GetActiveText").Return("aes256-ctr").Maybe()
+	rounds := &gtk.MockSpinButton{}
+	rounds.On("GetValueAsInt").Return(16).Maybe()
+
+	b.On("GetObject", "cipherSelection").Return(ciphers, nil)
+	b.On("GetObject", "kdfRounds").Return(rounds, nil).Maybe()
+	b.On("GetObject", "newPassphraseEntry").Return(m.newPass, nil)
+	b.On("GetObject", "confirmPassphraseEntry").Return(m.confirmation, nil)
+	b.On("GetObject", "changePassphraseError").Return(m.errorLabel, nil)
+	if protected {
+		b.On("GetObject", "currentPassphraseEntry").Return(m.current, nil)
+	} else {
+		s.addLabelsThatShouldHide(b, "currentPassphraseLabel", "currentPassphraseEntry")
+	}
+
+	s.addObjectToAssert(m.dialog)
+	s.addObjectToAssert(ciphers)
+	s.addObjectToAssert(m.errorLabel)
+	return m
+}
+
+func passphraseChangeableKeyForTest(protected bool) *passphraseChangeablePrivateKeyEntryMock {
+	k := &passphraseChangeablePrivateKeyEntryMock{}
+	k.On("IsPasswordProtected").Return(protected)
+	k.On("SupportedCiphers").Return([]string{"aes256-ctr", "aes128-ctr"}).Once()
+	return k
+}
+
+var protectionForTest = api.PrivateKeyProtection{Cipher: "aes256-ctr", Rounds: 16}
+
+func (s *guiSuite) Test_changePassphrase_complainsWhenTheNewPassphrasesDoNotMatch() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	m := s.setupChangePassphraseDialog(false, -5, -6)
+	m.newPass.On("GetText").Return("one", nil).Once()
+	m.confirmation.On("GetText").Return("two", nil).Once()
+	m.errorLabel.On("SetLabel", "The new passphrases do not match.").Return().Once()
+
+	k := passphraseChangeableKeyForTest(false)
+	u := &ui{gtk: s.gtkMock}
+	_, ok := u.changePassphrase(k)
+
+	s.False(ok)
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_changePassphrase_asksAgainWhenTheCurrentPassphraseIsIncorrect() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	m := s.setupChangePassphraseDialog(true, -5, -5)
+	m.newPass.On("GetText").Return("new", nil).Twice()
+	m.confirmation.On("GetText").Return("new", nil).Twice()
+	m.current.On("GetText").Return("wrong", nil).Once()
+	m.current.On("GetText").Return("right", nil).Once()
+	m.errorLabel.On("SetLabel", "The current passphrase is incorrect.").Return().Once()
+
+	k := passphraseChangeableKeyForTest(true)
+	k.On("ChangePassphrase", []byte("wrong"), []byte("new"), protectionForTest).Return("", api.ErrIncorrectPassphrase).Once()
+	k.On("ChangePassphrase", []byte("right"), []byte("new"), protectionForTest).Return("/home/amnesia/.ssh/id_ed25519.bak", nil).Once()
+
+	u := &ui{gtk: s.gtkMock}
+	backup, ok := u.changePassphrase(k)
+
+	s.True(ok)
+	s.Equal("/home/amnesia/.ssh/id_ed25519.bak", backup)
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_changePassphrase_canRemoveThePassphraseAndShowsOtherErrors() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	m := s.setupChangePassphraseDialog(true, -5, -4)
+	m.newPass.On("GetText").Return("", nil).Once()
+	m.confirmation.On("GetText").Return("", nil).Once()
+	m.current.On("GetText").Return("right", nil).Once()
+	m.errorLabel.On("SetLabel", "The passphrase couldn't be changed: permission denied").Return().Once()
+
+	k := passphraseChangeableKeyForTest(true)
+	k.On("ChangePassphrase", []byte("right"), []byte{}, protectionForTest).Return("", errors.New("permission denied")).Once()
+
+	log, hook := test.NewNullLogger()
+	u := &ui{gtk: s.gtkMock, log: log}
+	_, ok := u.changePassphrase(k)
+
+	s.False(ok)
+	s.Len(hook.AllEntries(), 1)
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayChangePassphrase_hidesTheRowForKeysThatCanNotBeChanged() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &publicKeyEntryMock{},
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "changePassphraseLabel", "changePassphraseButton")
+
+	kd.displayChangePassphrase()
+}
+
+func (s *guiSuite) Test_keyDetails_updateIsPasswordProtected_showsTheLabelForProtectedKeys() {
+	builderMock := &gtk.MockBuilder{}
+	box := &gtk.MockBox{}
+	sc := &gtk.MockStyleContext{}
+	box.On("GetStyleContext").Return(sc, nil).Once()
+	sc.On("AddClass", "passwordProtectedPrivateKey").Return().Once()
+	k := &unlockablePrivateKeyEntryMock{}
+	k.On("IsPasswordProtected").Return(true).Once()
+
+	kd := &keyDetails{builder: &builder{builderMock}, key: k, box: box}
+	l := s.addLabelToGet(builderMock, "passwordProtectedLabel")
+	l.On("Show").Return().Once()
+
+	kd.updateIsPasswordProtected()
+
+	sc.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_passphraseChangedMessage_warnsAboutUnprotectedBackups() {
+	s.Equal("The passphrase was changed.", passphraseChangedMessage("", false))
+	s.Equal("The passphrase was changed. The previous private key was saved to /home/amnesia/.ssh/id_rsa.bak",
+		passphraseChangedMessage("/home/amnesia/.ssh/id_rsa.bak", true))
+	s.Contains(passphraseChangedMessage("/home/amnesia/.ssh/id_rsa.bak", false), "without a passphrase, so you should remove it")
+}
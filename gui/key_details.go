@@ -1,11 +1,10 @@
 package gui
 
 import (
-	"crypto/sha1"
-	"crypto/sha256"
 	"fmt"
 	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
 	"github.com/digitalautonomy/keymirror/i18n"
 	"strings"
 )
@@ -22,13 +21,14 @@ func clearAllChildrenOf[T any](b clearable[T]) {
 }
 
 type keyDetails struct {
+	ui      *ui
 	builder *builder
 	key     api.KeyEntry
 	box     gtki.Box
 }
 
-func newKeyDetails(builder *builder, key api.KeyEntry, box gtki.Box) *keyDetails {
-	return &keyDetails{builder, key, box}
+func newKeyDetails(u *ui, builder *builder, key api.KeyEntry, box gtki.Box) *keyDetails {
+	return &keyDetails{u, builder, key, box}
 }
 
 const publicKeyPath = "publicKeyPath"
@@ -60,7 +60,7 @@ func (kd *keyDetails) setClassForKeyDetails() {
 	className := keyTypeClassNames[kd.key.KeyType()]
 	addClass(kd.box, className)
 	addClass(kd.box, fmt.Sprintf("algorithm-%s", strings.ToLower(kd.key.Algorithm().Name())))
-	if kd.key.Algorithm().HasKeySize() {
+	if kd.key.Algorithm().HasKeySize() && kd.key.Size() > 0 {
 		addClass(kd.box, fmt.Sprintf("key-size-%d", kd.key.Size()))
 	}
 }
@@ -69,6 +69,10 @@ type hideable interface {
 	Hide()
 }
 
+type showable interface {
+	Show()
+}
+
 func (kd *keyDetails) hideAll(ids ...string) {
 	for _, id := range ids {
 		kd.hide(id)
@@ -79,6 +83,16 @@ func (kd *keyDetails) hide(id string) {
 	l.Hide()
 }
 
+func (kd *keyDetails) showAll(ids ...string) {
+	for _, id := range ids {
+		kd.show(id)
+	}
+}
+
+func (kd *keyDetails) show(id string) {
+	kd.builder.get(id).(showable).Show()
+}
+
 func (kd *keyDetails) displayLocations(keyLocations []string, path, pathLabel string) {
 	if keyLocations != nil {
 		label := kd.builder.get(path).(gtki.Label)
@@ -89,47 +103,49 @@ func (kd *keyDetails) displayLocations(keyLocations []string, path, pathLabel st
 	}
 }
 
-func returningSlice20(f func([]byte) [20]byte) func([]byte) []byte {
-	return func(v []byte) []byte {
-		res := f(v)
-		return res[:]
-	}
-}
+const openSSHSHA256FingerprintLabel = "openSSHSHA256FingerprintLabel"
+const openSSHSHA256Fingerprint = "openSSHSHA256Fingerprint"
+const md5FingerprintLabel = "md5FingerprintLabel"
+const md5Fingerprint = "md5Fingerprint"
+const sha1FingerprintLabel = "sha1FingerprintLabel"
+const sha1Fingerprint = "sha1Fingerprint"
+const sha256FingerprintLabel = "sha256FingerprintLabel"
+const sha256Fingerprint = "sha256Fingerprint"
 
-func returningSlice32(f func([]byte) [32]byte) func([]byte) []byte {
-	return func(v []byte) []byte {
-		res := f(v)
-		return res[:]
-	}
+type fingerprintRow struct {
+	format fingerprint.Format
+	label  string
+	value  string
 }
 
-func formatByteForFingerprint(b byte) string {
-	return fmt.Sprintf("%02X", b)
+var fingerprintRows = []fingerprintRow{
+	{fingerprint.OpenSSHSHA256, openSSHSHA256FingerprintLabel, openSSHSHA256Fingerprint},
+	{fingerprint.OpenSSHMD5, md5FingerprintLabel, md5Fingerprint},
+	{fingerprint.SHA1Hex, sha1FingerprintLabel, sha1Fingerprint},
+	{fingerprint.SHA256Hex, sha256FingerprintLabel, sha256Fingerprint},
 }
 
-func formatFingerprint(f []byte) string {
-	result := []string{}
+func (kd *keyDetails) displayFingerprint(fingerprintLabel, fingerprintValue string, f fingerprint.Format) {
+	pk, ok := kd.key.(api.PublicKeyEntry)
+	if !ok || !kd.ui.currentPreferences().showsFingerprint(f) {
+		kd.hideAll(fingerprintLabel, fingerprintValue)
+		return
+	}
 
-	for _, v := range f {
-		result = append(result, formatByteForFingerprint(v))
+	fp, ok := fingerprint.Of(pk, f)
+	if !ok {
+		kd.hideAll(fingerprintLabel, fingerprintValue)
+		return
 	}
 
-	return strings.Join(result, ":")
+	label := kd.builder.get(fingerprintValue).(gtki.Label)
+	label.SetLabel(fp)
+	label.SetTooltipText(fp)
 }
 
-const sha1FingerprintLabel = "sha1FingerprintLabel"
-const sha1Fingerprint = "sha1Fingerprint"
-const sha256FingerprintLabel = "sha256FingerprintLabel"
-const sha256Fingerprint = "sha256Fingerprint"
-
-func (kd *keyDetails) displayFingerprint(fingerprintLabel, fingerprint string, f func([]byte) []byte) {
-	if pk, ok := kd.key.(api.PublicKeyEntry); ok {
-		fp := formatFingerprint(pk.WithDigestContent(f))
-		label := kd.builder.get(fingerprint).(gtki.Label)
-		label.SetLabel(fp)
-		label.SetTooltipText(fp)
-	} else {
-		kd.hideAll(fingerprintLabel, fingerprint)
+func (kd *keyDetails) displayFingerprints() {
+	for _, r := range fingerprintRows {
+		kd.displayFingerprint(r.label, r.value, r.format)
 	}
 }
 
@@ -148,11 +164,21 @@ func (kd *keyDetails) displayIsPasswordProtected() {
 	}
 }
 
+func (kd *keyDetails) updateIsPasswordProtected() {
+	if kd.privateKeyIsPasswordProtected() {
+		kd.show(passwordProtectedLabel)
+		addClass(kd.box, "passwordProtectedPrivateKey")
+	} else {
+		kd.hide(passwordProtectedLabel)
+		removeClass(kd.box, "passwordProtectedPrivateKey")
+	}
+}
+
 const algorithmIdentifier = "algorithm"
 
 func formatKeyAlgorithm(k api.KeyEntry) string {
 	algo := k.Algorithm()
-	if algo.HasKeySize() {
+	if algo.HasKeySize() && k.Size() > 0 {
 		// TODO: this formatting probably needs to be i18n in the future
 		return fmt.Sprintf("%s (%d bits)", algo.Name(), k.Size())
 	}
@@ -164,6 +190,46 @@ func (kd *keyDetails) displayAlgorithm() {
 	label.SetLabel(formatKeyAlgorithm(kd.key))
 }
 
+const securityLabelIdentifier = "securityLabel"
+const securityIdentifier = "security"
+
+func formatAlgorithmStatus(status api.AlgorithmStatus) string {
+	switch status {
+	case api.RecommendedStatus:
+		return i18n.Local("Recommended")
+	case api.LegacyStatus:
+		return i18n.Local("Legacy")
+	case api.DeprecatedStatus:
+		return i18n.Local("Deprecated")
+	case api.BrokenStatus:
+		return i18n.Local("Broken")
+	}
+	return string(status)
+}
+
+// formatKeySecurity describes the status and security strength of the key, or returns false when
+// they can't be known because the size of the key is unknown
+func formatKeySecurity(k api.KeyEntry) (string, bool) {
+	algo := k.Algorithm()
+	size := 0
+	if algo.HasKeySize() {
+		size = k.Size()
+		if size <= 0 {
+			return "", false
+		}
+	}
+	return fmt.Sprintf(i18n.Local("%s (%d bits of security)"), formatAlgorithmStatus(algo.Status(size)), algo.SecurityStrength(size)), true
+}
+
+func (kd *keyDetails) displaySecurity() {
+	security, ok := formatKeySecurity(kd.key)
+	if !ok {
+		kd.hideAll(securityLabelIdentifier, securityIdentifier)
+		return
+	}
+	kd.builder.get(securityIdentifier).(gtki.Label).SetLabel(security)
+}
+
 const userIDLabelIdentifier = "userIDLabel"
 const userIDIdentifier = "userID"
 
@@ -171,8 +237,9 @@ func (kd *keyDetails) displayUserID() {
 	if pk, ok := kd.key.(api.PublicKeyEntry); ok {
 		label := kd.builder.get(userIDIdentifier).(gtki.Label)
 		label.SetLabel(pk.UserID())
+		kd.displayUserIDEditing(label)
 	} else {
-		kd.hideAll(userIDLabelIdentifier, userIDIdentifier)
+		kd.hideAll(userIDLabelIdentifier, userIDIdentifier, editUserIDButton)
 	}
 }
 
@@ -206,9 +273,27 @@ func (kd *keyDetails) display() {
 	kd.displayLocations(kd.key.PrivateKeyLocations(), privateKeyPath, privateKeyPathLabel)
 	kd.displayIsPasswordProtected()
 	kd.displayAlgorithm()
+	kd.displaySecurity()
+	kd.displayRSAParameters()
 	kd.displayUserID()
-	kd.displayFingerprint(sha1FingerprintLabel, sha1Fingerprint, returningSlice20(sha1.Sum))
-	kd.displayFingerprint(sha256FingerprintLabel, sha256Fingerprint, returningSlice32(sha256.Sum256))
+	kd.displayPKCS12Attributes()
+	kd.displayOTRAttributes()
+	kd.displaySigningKeyID()
+	kd.displayFingerprints()
+	kd.displayBubbleBabble()
+	kd.displayPGPWords()
+	kd.displayRandomart()
+	kd.displayAgeRecipient()
+	kd.displayAgeIdentity()
+	kd.displayChangePassphrase()
+	kd.displayCreateCertificate()
+	kd.displayOnionAddress()
+	kd.displayOnionServiceExport()
+	kd.displayWireGuardPublicKey()
+	kd.displayWireGuardPrivateKey()
+	kd.displayPaperBackup()
+	kd.displayKeyShares()
+	kd.displayExtraction()
 	kd.setClassForKeyDetails()
 }
 
@@ -216,7 +301,7 @@ func (u *ui) populateKeyDetails(key api.KeyEntry, into gtki.Box) {
 	clearAllChildrenOf[gtki.Widget](into)
 	b, builder := buildObjectFrom[gtki.Box](u, "KeyDetails")
 
-	kd := newKeyDetails(builder, key, b)
+	kd := newKeyDetails(u, builder, key, b)
 	kd.display()
 
 	into.Add(b)
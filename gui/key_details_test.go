@@ -1,10 +1,10 @@
 package gui
 
 import (
-	"crypto/sha1"
 	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/coyim/gotk3mocks/gtk"
 	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,6 +19,9 @@ func expectClassToBeAdded(m mockable, class string) *gtk.MockStyleContext {
 	return scMock
 }
 
+// helloWorldPGPWords are the PGP words of the SHA-256 digest of "hello world"
+const helloWorldPGPWords = "sentence disruptive brackish proximate playhouse disruptive concert antenna reindeer coherence Dupont stethoscope surmount insincere rhythm whimsical snowslide Jupiter uncut torpedo keyboard enterprise merit universe peachy maritime virus penetrate tiger unravel spindle ultimate"
+
 func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThePublicKeyPath() {
 	keyDetailsBoxMock := &gtk.MockBox{}
 	builderKeyDetailsBoxMock := s.setupBuildingOfObject(keyDetailsBoxMock, "KeyDetails")
@@ -34,6 +37,46 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThe
 		"privateKeyPathLabel",
 		"privateKeyPath",
 		"passwordProtectedLabel",
+		"ageRecipientLabel",
+		"ageRecipientBox",
+		"ageIdentityLabel",
+		"ageIdentityBox",
+		"changePassphraseLabel",
+		"changePassphraseButton",
+		"editUserIDButton",
+		"createCertificateLabel",
+		"createCertificateButton",
+		"onionAddressLabel",
+		"onionAddressBox",
+		"exportOnionServiceLabel",
+		"exportOnionServiceButton",
+		"wireGuardPublicKeyLabel",
+		"wireGuardPublicKeyBox",
+		"wireGuardPrivateKeyLabel",
+		"wireGuardPrivateKeyBox",
+		"paperBackupLabel",
+		"paperBackupBox",
+		"keySharesLabel",
+		"keySharesBox",
+		"md5FingerprintLabel",
+		"md5Fingerprint",
+		"rsaExponentLabel",
+		"rsaExponent",
+		"rsaWarning",
+		"friendlyNameLabel",
+		"friendlyName",
+		"localKeyIDLabel",
+		"localKeyID",
+		"extractLabel",
+		"extractBox",
+		"otrAccountLabel",
+		"otrAccount",
+		"otrFingerprintLabel",
+		"otrFingerprint",
+		"otrTrustLabel",
+		"otrTrust",
+		"signingKeyIDLabel",
+		"signingKeyID",
 	)
 
 	notificationMessage := &gtk.MockLabel{}
@@ -43,27 +86,45 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThe
 	textProperties := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "algorithm").Return(textProperties, nil).Once()
 	textProperties.On("SetLabel", "Ed25519").Return().Once()
+	security := s.addLabelToGet(builderKeyDetailsBoxMock, "security")
+	security.On("SetLabel", "Recommended (128 bits of security)").Return().Once()
 
+	fingerprintOpenSSH := &gtk.MockLabel{}
+	builderKeyDetailsBoxMock.On("GetObject", "openSSHSHA256Fingerprint").Return(fingerprintOpenSSH, nil).Once()
 	fingerprintSha1 := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "sha1Fingerprint").Return(fingerprintSha1, nil).Once()
 	fingerprintSha256 := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "sha256Fingerprint").Return(fingerprintSha256, nil).Once()
 
+	bubbleBabbleValue := s.addLabelToGet(builderKeyDetailsBoxMock, "bubbleBabble")
+	bubbleBabbleValue.On("SetLabel", "xepip-varaf-hodig-zefor-gyhyt-rupih-zubym-rulyv-nolov-micyv-taxyx").Return().Once()
+	bubbleBabbleValue.On("SetTooltipText", "xepip-varaf-hodig-zefor-gyhyt-rupih-zubym-rulyv-nolov-micyv-taxyx").Return().Once()
+	pgpWordsValue := s.addLabelToGet(builderKeyDetailsBoxMock, "pgpWords")
+	pgpWordsValue.On("SetLabel", helloWorldPGPWords).Return().Once()
+	pgpWordsValue.On("SetTooltipText", helloWorldPGPWords).Return().Once()
+
+	randomartValue := s.addLabelToGet(builderKeyDetailsBoxMock, "randomart")
+	randomartValue.On("SetLabel", mock.AnythingOfType("string")).Return().Once()
+
 	UserIDValue := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "userID").Return(UserIDValue, nil).Once()
 	UserIDValue.On("SetLabel", "").Return().Once()
 
-	keMock := &publicKeyEntryMock{}
+	keMock := &sshPublicKeyEntryMock{}
+	keMock.On("SSHPublicKey").Return([]byte("hello world")).Times(4)
 	keMock.On("WithDigestContent", mock.Anything).Return([]byte{0xAB, 0xCD, 0x10}).Once()
 	keMock.On("WithDigestContent", mock.Anything).Return([]byte{0xCC, 0x07, 0x00, 0xFF}).Once()
 	keMock.On("PublicKeyLocations").Return([]string{"/a/path/to/a/public/key"}).Once()
 	keMock.On("PrivateKeyLocations").Return(nil).Once()
 	keMock.On("KeyType").Return(api.PublicKeyType).Maybe()
-	keMock.On("Algorithm").Return(api.Ed25519).Times(3)
+	keMock.On("Algorithm").Return(api.Ed25519).Times(7)
 	keMock.On("UserID").Return("").Once()
 	pathPublicKeyPath.On("SetLabel", "/a/path/to/a/public/key").Return().Once()
 	pathPublicKeyPath.On("SetTooltipText", "/a/path/to/a/public/key").Return().Once()
 
+	fingerprintOpenSSH.On("SetLabel", "SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek").Return().Once()
+	fingerprintOpenSSH.On("SetTooltipText", "SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek").Return().Once()
+
 	fingerprintSha1.On("SetLabel", "AB:CD:10").Return().Once()
 	fingerprintSha1.On("SetTooltipText", "AB:CD:10").Return().Once()
 
@@ -79,6 +140,7 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThe
 	keyDetailsHolder.AssertExpectations(s.T())
 	keMock.AssertExpectations(s.T())
 	notificationMessage.AssertExpectations(s.T())
+	fingerprintOpenSSH.AssertExpectations(s.T())
 	fingerprintSha1.AssertExpectations(s.T())
 	fingerprintSha256.AssertExpectations(s.T())
 	UserIDValue.AssertExpectations(s.T())
@@ -107,6 +169,54 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThe
 		"sha1Fingerprint",
 		"sha256FingerprintLabel",
 		"sha256Fingerprint",
+		"ageRecipientLabel",
+		"ageRecipientBox",
+		"ageIdentityLabel",
+		"ageIdentityBox",
+		"changePassphraseLabel",
+		"changePassphraseButton",
+		"editUserIDButton",
+		"createCertificateLabel",
+		"createCertificateButton",
+		"onionAddressLabel",
+		"onionAddressBox",
+		"exportOnionServiceLabel",
+		"exportOnionServiceButton",
+		"wireGuardPublicKeyLabel",
+		"wireGuardPublicKeyBox",
+		"wireGuardPrivateKeyLabel",
+		"wireGuardPrivateKeyBox",
+		"paperBackupLabel",
+		"paperBackupBox",
+		"keySharesLabel",
+		"keySharesBox",
+		"openSSHSHA256FingerprintLabel",
+		"openSSHSHA256Fingerprint",
+		"md5FingerprintLabel",
+		"md5Fingerprint",
+		"randomartLabel",
+		"randomart",
+		"bubbleBabbleLabel",
+		"bubbleBabble",
+		"pgpWordsLabel",
+		"pgpWords",
+		"rsaExponentLabel",
+		"rsaExponent",
+		"rsaWarning",
+		"friendlyNameLabel",
+		"friendlyName",
+		"localKeyIDLabel",
+		"localKeyID",
+		"extractLabel",
+		"extractBox",
+		"otrAccountLabel",
+		"otrAccount",
+		"otrFingerprintLabel",
+		"otrFingerprint",
+		"otrTrustLabel",
+		"otrTrust",
+		"signingKeyIDLabel",
+		"signingKeyID",
 	)
 
 	notificationMessage := &gtk.MockLabel{}
@@ -116,12 +226,14 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysThe
 	textProperties := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "algorithm").Return(textProperties, nil).Once()
 	textProperties.On("SetLabel", "Ed25519").Return().Once()
+	security := s.addLabelToGet(builderKeyDetailsBoxMock, "security")
+	security.On("SetLabel", "Recommended (128 bits of security)").Return().Once()
 
 	keMock := &keyEntryMock{}
 	keMock.On("PublicKeyLocations").Return(nil).Once()
 	keMock.On("PrivateKeyLocations").Return([]string{"/a/path/to/a/private/key"}).Once()
 	keMock.On("KeyType").Return(api.PrivateKeyType).Maybe()
-	keMock.On("Algorithm").Return(api.Ed25519).Times(3)
+	keMock.On("Algorithm").Return(api.Ed25519).Times(4)
 	pathPrivateKey.On("SetLabel", "/a/path/to/a/private/key").Return().Once()
 	pathPrivateKey.On("SetTooltipText", "/a/path/to/a/private/key").Return().Once()
 
@@ -163,17 +275,67 @@ func (s *guiSuite) Test_populateKeyDetails_createsTheKeyDetailsBoxAndDisplaysBot
 		"sha256FingerprintLabel",
 		"sha256Fingerprint",
 		"notification",
+		"ageRecipientLabel",
+		"ageRecipientBox",
+		"ageIdentityLabel",
+		"ageIdentityBox",
+		"changePassphraseLabel",
+		"changePassphraseButton",
+		"editUserIDButton",
+		"createCertificateLabel",
+		"createCertificateButton",
+		"onionAddressLabel",
+		"onionAddressBox",
+		"exportOnionServiceLabel",
+		"exportOnionServiceButton",
+		"wireGuardPublicKeyLabel",
+		"wireGuardPublicKeyBox",
+		"wireGuardPrivateKeyLabel",
+		"wireGuardPrivateKeyBox",
+		"paperBackupLabel",
+		"paperBackupBox",
+		"keySharesLabel",
+		"keySharesBox",
+		"openSSHSHA256FingerprintLabel",
+		"openSSHSHA256Fingerprint",
+		"md5FingerprintLabel",
+		"md5Fingerprint",
+		"randomartLabel",
+		"randomart",
+		"bubbleBabbleLabel",
+		"bubbleBabble",
+		"pgpWordsLabel",
+		"pgpWords",
+		"rsaExponentLabel",
+		"rsaExponent",
+		"rsaWarning",
+		"friendlyNameLabel",
+		"friendlyName",
+		"localKeyIDLabel",
+		"localKeyID",
+		"extractLabel",
+		"extractBox",
+		"otrAccountLabel",
+		"otrAccount",
+		"otrFingerprintLabel",
+		"otrFingerprint",
+		"otrTrustLabel",
+		"otrTrust",
+		"signingKeyIDLabel",
+		"signingKeyID",
 	)
 
 	identifierAlgorithm := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "algorithm").Return(identifierAlgorithm, nil).Once()
 	identifierAlgorithm.On("SetLabel", "Ed25519").Return().Once()
+	security := s.addLabelToGet(builderKeyDetailsBoxMock, "security")
+	security.On("SetLabel", "Recommended (128 bits of security)").Return().Once()
 
 	keMock := &keyEntryMock{}
 	keMock.On("PublicKeyLocations").Return([]string{"/a/path/to/a/public/key"}).Once()
 	keMock.On("PrivateKeyLocations").Return([]string{"/a/path/to/a/private/key"}).Once()
 	keMock.On("KeyType").Return(api.PairKeyType).Maybe()
-	keMock.On("Algorithm").Return(api.Ed25519).Times(3)
+	keMock.On("Algorithm").Return(api.Ed25519).Times(4)
 	pathPublicKey.On("SetLabel", "/a/path/to/a/public/key").Return().Once()
 	pathPublicKey.On("SetTooltipText", "/a/path/to/a/public/key").Return().Once()
 	pathPrivateKey.On("SetLabel", "/a/path/to/a/private/key").Return().Once()
@@ -208,28 +370,6 @@ func (s *guiSuite) Test_clearAllChildrenOf_removeEachOneOfTheChildrenOfTheBox()
 	boxMock.AssertExpectations(s.T())
 }
 
-func (s *guiSuite) Test_formatFingerprint_returnsAnUpperCaseHexadecimalStringWithColons() {
-	f := []byte{}
-	expected := ""
-	s.Equal(expected, formatFingerprint(f))
-
-	f = []byte{0}
-	expected = "00"
-	s.Equal(expected, formatFingerprint(f))
-
-	f = []byte{8}
-	expected = "08"
-	s.Equal(expected, formatFingerprint(f))
-
-	f = []byte{0xfe}
-	expected = "FE"
-	s.Equal(expected, formatFingerprint(f))
-
-	f = []byte{0, 1, 32, 0x67, 0, 7, 0xfc, 0}
-	expected = "00:01:20:67:00:07:FC:00"
-	s.Equal(expected, formatFingerprint(f))
-}
-
 func (s *guiSuite) Test_keyDetails_displayFingerprint_calculateTheFingerprintAndDisplaysIt() {
 	keyMock := &publicKeyEntryMock{}
 	var calledWithFunc *func([]byte) []byte
@@ -242,6 +382,7 @@ func (s *guiSuite) Test_keyDetails_displayFingerprint_calculateTheFingerprintAnd
 	builderMock := &gtk.MockBuilder{}
 
 	kd := &keyDetails{
+		ui:      &ui{},
 		builder: &builder{builderMock},
 		key:     keyMock,
 	}
@@ -251,7 +392,7 @@ func (s *guiSuite) Test_keyDetails_displayFingerprint_calculateTheFingerprintAnd
 	labelMock.On("SetLabel", "73:6F:6D:65:74:68:69:6E:67").Return().Once()
 	labelMock.On("SetTooltipText", "73:6F:6D:65:74:68:69:6E:67").Return().Once()
 
-	kd.displayFingerprint("a row", "sha1Fingerprint", returningSlice20(sha1.Sum))
+	kd.displayFingerprint("a row", "sha1Fingerprint", fingerprint.SHA1Hex)
 
 	labelMock.AssertExpectations(s.T())
 	builderMock.AssertExpectations(s.T())
@@ -270,6 +411,7 @@ func (s *guiSuite) Test_keyDetails_displayFingerprint_hideTheFingerprintRow_ifDi
 	fingerprintSha1 := &gtk.MockLabel{}
 
 	kd := &keyDetails{
+		ui:      &ui{},
 		builder: &builder{builderMock},
 		key:     keyMock,
 	}
@@ -279,9 +421,114 @@ func (s *guiSuite) Test_keyDetails_displayFingerprint_hideTheFingerprintRow_ifDi
 	fingerprintSha1.On("Hide").Return().Once()
 	builderMock.On("GetObject", "fingerprintSha1").Return(fingerprintSha1, nil).Maybe()
 
-	kd.displayFingerprint("labelFingerprintSha1", "fingerprintSha1", func([]byte) []byte {
-		return []byte{}
-	})
+	kd.displayFingerprint("labelFingerprintSha1", "fingerprintSha1", fingerprint.SHA1Hex)
 
 	labelFingerprintSha1.AssertExpectations(s.T())
 }
+
+func (s *guiSuite) Test_keyDetails_displayFingerprint_hideTheFingerprintRow_ifTheFormatIsNotChosen() {
+	keyMock := &publicKeyEntryMock{}
+	builderMock := &gtk.MockBuilder{}
+
+	kd := &keyDetails{
+		ui:      &ui{preferences: &preferences{FingerprintFormats: []fingerprint.Format{fingerprint.OpenSSHSHA256}}},
+		builder: &builder{builderMock},
+		key:     keyMock,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "md5FingerprintLabel", "md5Fingerprint")
+
+	kd.displayFingerprint("md5FingerprintLabel", "md5Fingerprint", fingerprint.OpenSSHMD5)
+
+	keyMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayFingerprint_showsTheOpenSSHFormats() {
+	keyMock := &sshPublicKeyEntryMock{}
+	keyMock.On("SSHPublicKey").Return([]byte("hello world")).Twice()
+	builderMock := &gtk.MockBuilder{}
+
+	kd := &keyDetails{
+		ui:      &ui{preferences: &preferences{FingerprintFormats: fingerprint.Formats}},
+		builder: &builder{builderMock},
+		key:     keyMock,
+	}
+
+	openSSH := s.addLabelToGet(builderMock, "openSSHSHA256Fingerprint")
+	openSSH.On("SetLabel", "SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek").Return().Once()
+	openSSH.On("SetTooltipText", "SHA256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek").Return().Once()
+	md5 := s.addLabelToGet(builderMock, "md5Fingerprint")
+	md5.On("SetLabel", "MD5:5e:b6:3b:bb:e0:1e:ee:d0:93:cb:22:bb:8f:5a:cd:c3").Return().Once()
+	md5.On("SetTooltipText", "MD5:5e:b6:3b:bb:e0:1e:ee:d0:93:cb:22:bb:8f:5a:cd:c3").Return().Once()
+
+	kd.displayFingerprint("openSSHSHA256FingerprintLabel", "openSSHSHA256Fingerprint", fingerprint.OpenSSHSHA256)
+	kd.displayFingerprint("md5FingerprintLabel", "md5Fingerprint", fingerprint.OpenSSHMD5)
+
+	keyMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayFingerprint_hidesTheOpenSSHFormats_forKeysThatCanNotBeUsedWithSSH() {
+	keyMock := &sshPublicKeyEntryMock{}
+	keyMock.On("SSHPublicKey").Return(nil).Twice()
+	keyMock.On("WithDigestContent", mock.Anything).Return([]byte{0xAB, 0xCD, 0x10}).Once()
+	builderMock := &gtk.MockBuilder{}
+
+	kd := &keyDetails{
+		ui:      &ui{preferences: &preferences{FingerprintFormats: fingerprint.Formats}},
+		builder: &builder{builderMock},
+		key:     keyMock,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "openSSHSHA256FingerprintLabel", "openSSHSHA256Fingerprint", "md5FingerprintLabel", "md5Fingerprint")
+	sha1 := s.addLabelToGet(builderMock, "sha1Fingerprint")
+	sha1.On("SetLabel", "AB:CD:10").Return().Once()
+	sha1.On("SetTooltipText", "AB:CD:10").Return().Once()
+
+	kd.displayFingerprint("openSSHSHA256FingerprintLabel", "openSSHSHA256Fingerprint", fingerprint.OpenSSHSHA256)
+	kd.displayFingerprint("md5FingerprintLabel", "md5Fingerprint", fingerprint.OpenSSHMD5)
+	kd.displayFingerprint("sha1FingerprintLabel", "sha1Fingerprint", fingerprint.SHA1Hex)
+
+	keyMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_formatKeySecurity_describesTheStatusAndStrengthOfTheKey() {
+	res, ok := formatKeySecurity(fixedKeyEntry("/a/key", api.Ed25519))
+	s.True(ok)
+	s.Equal("Recommended (128 bits of security)", res)
+
+	k := &keyEntryMock{}
+	k.On("Algorithm").Return(api.RSA)
+	k.On("Size").Return(2048)
+	res, ok = formatKeySecurity(k)
+	s.True(ok)
+	s.Equal("Legacy (112 bits of security)", res)
+
+	k = &keyEntryMock{}
+	k.On("Algorithm").Return(api.DSA)
+	k.On("Size").Return(3072)
+	res, ok = formatKeySecurity(k)
+	s.True(ok)
+	s.Equal("Deprecated (128 bits of security)", res)
+}
+
+func (s *guiSuite) Test_formatKeySecurity_failsForUnknownKeySizes() {
+	k := &keyEntryMock{}
+	k.On("Algorithm").Return(api.RSA)
+	k.On("Size").Return(0)
+	_, ok := formatKeySecurity(k)
+	s.False(ok)
+}
+
+func (s *guiSuite) Test_keyDetails_displaySecurity_hidesTheRowForUnknownKeySizes() {
+	k := &keyEntryMock{}
+	k.On("Algorithm").Return(api.RSA)
+	k.On("Size").Return(0)
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "securityLabel", "security")
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     k,
+	}
+	kd.displaySecurity()
+}
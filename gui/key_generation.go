@@ -0,0 +1,204 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+// generatableAlgorithms are listed in the order they are offered to the user
+var generatableAlgorithms = []api.Algorithm{api.Ed25519, api.RSA, api.ECDSA}
+
+// keySizeCandidates are the sizes usual key generation tools offer. Only the ones the algorithm
+// accepts, and that are recommended or legacy for it, are offered for an algorithm
+var keySizeCandidates = []int{256, 384, 521, 1024, 2048, 3072, 4096, 7680, 8192}
+
+// offeredStatuses are the statuses of the sizes offered, in the order they are offered in
+var offeredStatuses = []api.AlgorithmStatus{api.RecommendedStatus, api.LegacyStatus}
+
+// keySizesFor returns the sizes offered for the algorithm, with the recommended ones first
+func keySizesFor(a api.Algorithm) []int {
+	if !a.HasKeySize() {
+		return nil
+	}
+	result := []int{}
+	for _, status := range offeredStatuses {
+		for _, size := range keySizeCandidates {
+			if a.IsValidKeySize(size) && a.Status(size) == status {
+				result = append(result, size)
+			}
+		}
+	}
+	return result
+}
+
+func defaultKeyFileName(home string, a api.Algorithm) string {
+	return filepath.Join(home, ".ssh", "id_"+strings.ToLower(a.Name()))
+}
+
+func anyFileExists(fileNames ...string) bool {
+	for _, f := range fileNames {
+		if _, e := os.Stat(f); e == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// keyGenerationProblem returns a description of what stops the key from being generated,
+// or an empty string if there is nothing wrong
+func keyGenerationProblem(fileName, passphrase, confirmation string) string {
+	if fileName == "" {
+		return i18n.Local("A file name is required.")
+	}
+	if anyFileExists(fileName, fileName+".pub") {
+		return i18n.Local("A key with that file name already exists.")
+	}
+	if passphrase != confirmation {
+		return i18n.Local("The passphrases do not match.")
+	}
+	return ""
+}
+
+type keyGenerationAssistant struct {
+	assistant   gtki.Assistant
+	builder     *builder
+	generator   api.KeyGenerator
+	onGenerated func(api.KeyEntry)
+}
+
+func (u *ui) newKeyGenerationAssistant(g api.KeyGenerator, onGenerated func(api.KeyEntry)) *keyGenerationAssistant {
+	a, b := buildObjectFrom[gtki.Assistant](u, "KeyGenerationAssistant")
+	a.SetTransientFor(u.mainWindow)
+
+	kga := &keyGenerationAssistant{a, b, g, onGenerated}
+
+	algorithms := kga.comboBox("algorithmSelection")
+	for _, alg := range generatableAlgorithms {
+		algorithms.AppendText(alg.Name())
+	}
+	algorithms.SetActive(0)
+	kga.algorithmChanged()
+	algorithms.Connect("changed", kga.algorithmChanged)
+
+	for _, id := range []string{"fileNameEntry", "passphraseEntry", "confirmPassphraseEntry"} {
+		kga.entry(id).Connect("changed", kga.validate)
+	}
+	kga.validate()
+
+	a.Connect("prepare", kga.updateSummary)
+	a.Connect("apply", func() { kga.generate(u) })
+	a.Connect("cancel", a.Destroy)
+	a.Connect("close", a.Destroy)
+
+	return kga
+}
+
+func (kga *keyGenerationAssistant) comboBox(id string) gtki.ComboBoxText {
+	return kga.builder.get(id).(gtki.ComboBoxText)
+}
+
+func (kga *keyGenerationAssistant) entry(id string) gtki.Entry {
+	return kga.builder.get(id).(gtki.Entry)
+}
+
+func (kga *keyGenerationAssistant) textOf(id string) string {
+	text, _ := kga.entry(id).GetText()
+	return text
+}
+
+func (kga *keyGenerationAssistant) setLabel(id, text string) {
+	kga.builder.get(id).(gtki.Label).SetLabel(text)
+}
+
+func (kga *keyGenerationAssistant) algorithm() api.Algorithm {
+	return generatableAlgorithms[kga.comboBox("algorithmSelection").GetActive()]
+}
+
+func (kga *keyGenerationAssistant) size() int {
+	size, _ := strconv.Atoi(kga.comboBox("keySizeSelection").GetActiveText())
+	return size
+}
+
+func (kga *keyGenerationAssistant) algorithmChanged() {
+	alg := kga.algorithm()
+
+	sizes := kga.comboBox("keySizeSelection")
+	sizes.RemoveAll()
+	for _, size := range keySizesFor(alg) {
+		sizes.AppendText(strconv.Itoa(size))
+	}
+	sizes.SetActive(0)
+
+	for _, id := range []string{"keySizeLabel", "keySizeSelection"} {
+		w := kga.builder.get(id).(gtki.Widget)
+		w.SetVisible(alg.HasKeySize())
+	}
+
+	home, _ := os.UserHomeDir()
+	kga.entry("fileNameEntry").SetText(defaultKeyFileName(home, alg))
+}
+
+func (kga *keyGenerationAssistant) validate() {
+	problem := keyGenerationProblem(kga.textOf("fileNameEntry"), kga.textOf("passphraseEntry"), kga.textOf("confirmPassphraseEntry"))
+	kga.setLabel("keyGenerationProblem", problem)
+	kga.assistant.SetPageComplete(kga.builder.get("detailsPage").(gtki.Widget), problem == "")
+}
+
+func (kga *keyGenerationAssistant) options() api.KeyGenerationOptions {
+	return api.KeyGenerationOptions{
+		Algorithm:  kga.algorithm(),
+		Size:       kga.size(),
+		UserID:     kga.textOf("userIDEntry"),
+		FileName:   kga.textOf("fileNameEntry"),
+		Passphrase: []byte(kga.textOf("passphraseEntry")),
+	}
+}
+
+func describeKeyGeneration(o api.KeyGenerationOptions) string {
+	description := o.Algorithm.Name()
+	if o.Algorithm.HasKeySize() {
+		description = fmt.Sprintf(i18n.Local("%s, %d bits"), description, o.Size)
+	}
+
+	protection := i18n.Local("The private key will not be protected by a passphrase.")
+	if len(o.Passphrase) > 0 {
+		protection = i18n.Local("The private key will be protected by a passphrase.")
+	}
+
+	return fmt.Sprintf(i18n.Local("A new %s key will be written to %s and %s.\n%s"), description, o.FileName, o.FileName+".pub", protection)
+}
+
+func (kga *keyGenerationAssistant) updateSummary() {
+	kga.setLabel("keyGenerationSummary", describeKeyGeneration(kga.options()))
+}
+
+func (kga *keyGenerationAssistant) generate(u *ui) {
+	o := kga.options()
+	k, e := kga.generator.GenerateKey(o)
+	if e != nil {
+		u.log.WithError(e).Error("couldn't generate a new key")
+		kga.setLabel("keyGenerationResult", fmt.Sprintf(i18n.Local("The key couldn't be generated: %s"), e))
+		return
+	}
+
+	kga.setLabel("keyGenerationResult", fmt.Sprintf(i18n.Local("The new key was written to %s."), o.FileName))
+	kga.onGenerated(k)
+}
+
+// generateKey shows the key generation assistant. The function given is called after a key has been generated
+func (u *ui) generateKey(ka api.KeyAccess, onGenerated func(api.KeyEntry)) {
+	g, ok := ka.(api.KeyGenerator)
+	if !ok {
+		u.log.Warn("the key access doesn't support generating keys")
+		return
+	}
+
+	u.newKeyGenerationAssistant(g, onGenerated).assistant.Show()
+}
@@ -0,0 +1,129 @@
+package gui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+type keyGeneratorMock struct {
+	keyAccessMock
+}
+
+func (ka *keyGeneratorMock) GenerateKey(o api.KeyGenerationOptions) (api.KeyEntry, error) {
+	returns := ka.Called(o)
+	return ret[api.KeyEntry](returns, 0), returns.Error(1)
+}
+
+func (s *guiSuite) Test_keySizesFor_returnsTheRecommendedSizeFirst() {
+	s.Equal([]int{3072, 4096, 7680, 8192, 2048}, keySizesFor(api.RSA))
+	s.Equal([]int{256, 384, 521}, keySizesFor(api.ECDSA))
+	s.Empty(keySizesFor(api.Ed25519))
+}
+
+func (s *guiSuite) Test_keySizesFor_doesNotOfferDeprecatedSizes() {
+	s.NotContains(keySizesFor(api.RSA), 1024)
+	s.Empty(keySizesFor(api.DSA))
+}
+
+func (s *guiSuite) Test_defaultKeyFileName_followsTheNamingOfSSHKeygen() {
+	s.Equal("/home/amnesia/.ssh/id_ed25519", defaultKeyFileName("/home/amnesia", api.Ed25519))
+	s.Equal("/home/amnesia/.ssh/id_rsa", defaultKeyFileName("/home/amnesia", api.RSA))
+	s.Equal("/home/amnesia/.ssh/id_ecdsa", defaultKeyFileName("/home/amnesia", api.ECDSA))
+}
+
+func (s *guiSuite) Test_keyGenerationProblem_describesWhatIsWrong() {
+	dir := s.T().TempDir()
+	fileName := filepath.Join(dir, "id_ed25519")
+
+	s.Equal("", keyGenerationProblem(fileName, "", ""))
+	s.Equal("", keyGenerationProblem(fileName, "secret", "secret"))
+	s.Equal("A file name is required.", keyGenerationProblem("", "", ""))
+	s.Equal("The passphrases do not match.", keyGenerationProblem(fileName, "secret", "secrets"))
+
+	s.Require().NoError(os.WriteFile(fileName+".pub", []byte{}, 0644))
+	s.Equal("A key with that file name already exists.", keyGenerationProblem(fileName, "", ""))
+}
+
+func (s *guiSuite) Test_describeKeyGeneration_mentionsTheSizeAndProtection() {
+	s.Equal("A new RSA, 3072 bits key will be written to /tmp/id_rsa and /tmp/id_rsa.pub.\n"+
+		"The private key will be protected by a passphrase.",
+		describeKeyGeneration(api.KeyGenerationOptions{Algorithm: api.RSA, Size: 3072, FileName: "/tmp/id_rsa", Passphrase: []byte("secret")}))
+
+	s.Equal("A new Ed25519 key will be written to /tmp/id_ed25519 and /tmp/id_ed25519.pub.\n"+
+		"The private key will not be protected by a passphrase.",
+		describeKeyGeneration(api.KeyGenerationOptions{Algorithm: api.Ed25519, FileName: "/tmp/id_ed25519"}))
+}
+
+func (s *guiSuite) keyGenerationAssistantForTest(g api.KeyGenerator, onGenerated func(api.KeyEntry)) (*keyGenerationAssistant, *gtk.MockBuilder) {
+	builderMock := &gtk.MockBuilder{}
+	s.addObjectToAssert(builderMock)
+
+	algorithms := &gtk.MockComboBoxText{}
+	algorithms.On("GetActive").Return(2).Once()
+	sizes := &gtk.MockComboBoxText{}
+	sizes.On("GetActiveText").Return("384").Once()
+	builderMock.On("GetObject", "algorithmSelection").Return(algorithms, nil).Once()
+	builderMock.On("GetObject", "keySizeSelection").Return(sizes, nil).Once()
+
+	for id, text := range map[string]string{
+		"userIDEntry":     "alfred@batcave",
+		"fileNameEntry":   "/home/amnesia/.ssh/id_ecdsa",
+		"passphraseEntry": "correct horse",
+	} {
+		entry := &gtk.MockEntry{}
+		entry.On("GetText").Return(text, nil).Once()
+		builderMock.On("GetObject", id).Return(entry, nil).Once()
+	}
+
+	return &keyGenerationAssistant{
+		builder:     &builder{builderMock},
+		generator:   g,
+		onGenerated: onGenerated,
+	}, builderMock
+}
+
+func (s *guiSuite) Test_keyGenerationAssistant_generate_generatesTheKeyWithTheSelectedOptions() {
+	g := &keyGeneratorMock{}
+	generated := &keyEntryMock{}
+	g.On("GenerateKey", api.KeyGenerationOptions{
+		Algorithm:  api.ECDSA,
+		Size:       384,
+		UserID:     "alfred@batcave",
+		FileName:   "/home/amnesia/.ssh/id_ecdsa",
+		Passphrase: []byte("correct horse"),
+	}).Return(generated, nil).Once()
+	s.addObjectToAssert(g)
+
+	var result api.KeyEntry
+	kga, builderMock := s.keyGenerationAssistantForTest(g, func(k api.KeyEntry) { result = k })
+	s.addLabelToGet(builderMock, "keyGenerationResult").On("SetLabel", "The new key was written to /home/amnesia/.ssh/id_ecdsa.").Return().Once()
+
+	kga.generate(&ui{})
+
+	s.Equal(generated, result)
+}
+
+func (s *guiSuite) Test_keyGenerationAssistant_generate_showsAndLogsFailures() {
+	g := &keyGeneratorMock{}
+	g.On("GenerateKey", api.KeyGenerationOptions{
+		Algorithm:  api.ECDSA,
+		Size:       384,
+		UserID:     "alfred@batcave",
+		FileName:   "/home/amnesia/.ssh/id_ecdsa",
+		Passphrase: []byte("correct horse"),
+	}).Return(nil, errors.New("permission denied")).Once()
+	s.addObjectToAssert(g)
+
+	kga, builderMock := s.keyGenerationAssistantForTest(g, func(api.KeyEntry) { s.Fail("should not be called") })
+	s.addLabelToGet(builderMock, "keyGenerationResult").On("SetLabel", "The key couldn't be generated: permission denied").Return().Once()
+
+	log, hook := test.NewNullLogger()
+	kga.generate(&ui{log: log})
+
+	s.Len(hook.AllEntries(), 1)
+}
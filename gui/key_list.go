@@ -17,7 +17,7 @@ func (u *ui) createKeyEntryBoxFrom(entry api.KeyEntry, detailsBox gtki.Box, deta
 			removeClass(*u.currentlyVisibleKeyEntryButton, "current")
 		}
 
-		if u.currentlyVisibleKeyEntry == nil || *u.currentlyVisibleKeyEntry != entry {
+		if u.currentlyVisibleKeyEntryButton == nil || *u.currentlyVisibleKeyEntryButton != b {
 			detailsRev.Show()
 			detailsRev.SetRevealChild(true)
 			addClass(b, "current")
@@ -31,15 +31,33 @@ func (u *ui) createKeyEntryBoxFrom(entry api.KeyEntry, detailsBox gtki.Box, deta
 		}
 		u.onWindowSizeChange()
 	})
+	u.keyListEntries = append(u.keyListEntries, keyListEntry{entry, b})
 	return b
 }
 
-func (u *ui) populateListWithKeyEntries(access api.KeyAccess, box gtki.Box, detailsBox gtki.Box, detailsRev gtki.Revealer, onNoKeys func(box gtki.Box)) {
-	for _, e := range access.AllKeys() {
-		onNoKeys = func(box gtki.Box) {}
-		box.Add(u.createKeyEntryBoxFrom(e, detailsBox, detailsRev))
+// addKeyEntryTo adds a key that was found to the list of keys
+func (u *ui) addKeyEntryTo(box gtki.Box, entry api.KeyEntry, detailsBox gtki.Box, detailsRev gtki.Revealer) keyListEntry {
+	box.Add(u.createKeyEntryBoxFrom(entry, detailsBox, detailsRev))
+	return u.keyListEntries[len(u.keyListEntries)-1]
+}
+
+// reselectKeyEntry shows the details of the key that was shown before the keys were loaded again, once
+// it's found again in the same places. The same key stored elsewhere is another entry in the list
+func (u *ui) reselectKeyEntry(le keyListEntry) {
+	if u.keyToReselect == "" || api.ListingOf(le.entry) != u.keyToReselect {
+		return
+	}
+	u.keyToReselect = ""
+	_, _ = le.button.Emit("clicked")
+}
+
+// listedKeys returns the keys that are in the list of keys
+func (u *ui) listedKeys() []api.KeyEntry {
+	result := []api.KeyEntry{}
+	for _, le := range u.keyListEntries {
+		result = append(result, le.entry)
 	}
-	onNoKeys(box)
+	return result
 }
 
 func (u *ui) showNoAvailableKeysMessage(box gtki.Box) {
@@ -1,7 +1,8 @@
 package gui
 
 import (
-	"github.com/coyim/gotk3adapter/gtki"
+	"context"
+	"fmt"
 	"github.com/coyim/gotk3mocks/gtk"
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/digitalautonomy/keymirror/i18n"
@@ -23,6 +24,19 @@ func ret[T any](args getter, index int) T {
 
 type keyEntryMock struct {
 	mock.Mock
+	id string
+}
+
+// ID is the address of the mock unless the test sets one, so mocks are different keys unless the test says otherwise
+func (ke *keyEntryMock) ID() string {
+	if ke.id == "" {
+		return fmt.Sprintf("%p", ke)
+	}
+	return ke.id
+}
+
+func (ke *keyEntryMock) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == ke.ID()
 }
 
 func (ke *keyEntryMock) Locations() []string {
@@ -69,6 +83,15 @@ func (pk *publicKeyEntryMock) UserID() string {
 	return returns.String(0)
 }
 
+type sshPublicKeyEntryMock struct {
+	publicKeyEntryMock
+}
+
+func (pk *sshPublicKeyEntryMock) SSHPublicKey() []byte {
+	returns := pk.Called()
+	return ret[[]byte](returns, 0)
+}
+
 func (s *guiSuite) Test_createKeyEntryBoxFrom_CreatesAGTKIBoxWithTheGivenASSHKeyEntry() {
 	box := s.setupBuildingOfKeyEntry("/home/amnesia/id_ed25519.pub", "Ed25519")
 
@@ -80,7 +103,7 @@ func (s *guiSuite) Test_createKeyEntryBoxFrom_CreatesAGTKIBoxWithTheGivenASSHKey
 	keyEntry := &keyEntryMock{}
 	keyEntry.On("Locations").Return([]string{"/home/amnesia/id_ed25519.pub"}).Once()
 	keyEntry.On("Size").Return(0).Maybe()
-	keyEntry.On("Algorithm").Return(api.Ed25519).Times(4)
+	keyEntry.On("Algorithm").Return(api.Ed25519).Times(5)
 
 	var clickedHandler func() = nil
 	box.On("Connect", "clicked", mock.Anything).Return(nil).Once().Run(func(a mock.Arguments) {
@@ -110,6 +133,54 @@ func (s *guiSuite) Test_createKeyEntryBoxFrom_CreatesAGTKIBoxWithTheGivenASSHKey
 		"sha256Fingerprint",
 		"userIDLabel",
 		"userID",
+		"ageRecipientLabel",
+		"ageRecipientBox",
+		"ageIdentityLabel",
+		"ageIdentityBox",
+		"changePassphraseLabel",
+		"changePassphraseButton",
+		"editUserIDButton",
+		"createCertificateLabel",
+		"createCertificateButton",
+		"onionAddressLabel",
+		"onionAddressBox",
+		"exportOnionServiceLabel",
+		"exportOnionServiceButton",
+		"wireGuardPublicKeyLabel",
+		"wireGuardPublicKeyBox",
+		"wireGuardPrivateKeyLabel",
+		"wireGuardPrivateKeyBox",
+		"paperBackupLabel",
+		"paperBackupBox",
+		"keySharesLabel",
+		"keySharesBox",
+		"openSSHSHA256FingerprintLabel",
+		"openSSHSHA256Fingerprint",
+		"md5FingerprintLabel",
+		"md5Fingerprint",
+		"randomartLabel",
+		"randomart",
+		"bubbleBabbleLabel",
+		"bubbleBabble",
+		"pgpWordsLabel",
+		"pgpWords",
+		"rsaExponentLabel",
+		"rsaExponent",
+		"rsaWarning",
+		"friendlyNameLabel",
+		"friendlyName",
+		"localKeyIDLabel",
+		"localKeyID",
+		"extractLabel",
+		"extractBox",
+		"otrAccountLabel",
+		"otrAccount",
+		"otrFingerprintLabel",
+		"otrFingerprint",
+		"otrTrustLabel",
+		"otrTrust",
+		"signingKeyIDLabel",
+		"signingKeyID",
 	)
 
 	keyEntry.On("PublicKeyLocations").Return([]string{"/a/path/to/a/public/key"}).Once()
@@ -129,6 +200,8 @@ func (s *guiSuite) Test_createKeyEntryBoxFrom_CreatesAGTKIBoxWithTheGivenASSHKey
 	properties := &gtk.MockLabel{}
 	builderKeyDetailsBoxMock.On("GetObject", "algorithm").Return(properties, nil).Once()
 	properties.On("SetLabel", "Ed25519").Return().Once()
+	security := s.addLabelToGet(builderKeyDetailsBoxMock, "security")
+	security.On("SetLabel", "Recommended (128 bits of security)").Return().Once()
 
 	scMock1 := expectClassToBeAdded(box, "current")
 	scMock2 := expectClassToBeAdded(keyDetailsBoxMock, "publicKey")
@@ -146,19 +219,21 @@ func (s *guiSuite) Test_createKeyEntryBoxFrom_CreatesAGTKIBoxWithTheGivenASSHKey
 	scMock2.AssertExpectations(s.T())
 	scMock3.AssertExpectations(s.T())
 	properties.AssertExpectations(s.T())
+	security.AssertExpectations(s.T())
 }
 
 type keyAccessMock struct {
 	mock.Mock
 }
 
-func (ka *keyAccessMock) AllKeys() []api.KeyEntry {
-	return ka.Called().Get(0).([]api.KeyEntry)
+func (ka *keyAccessMock) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	returns := ka.Called()
+	return ret[[]api.KeyEntry](returns, 0), returns.Error(1)
 }
 
 func fixedKeyAccess(keys ...api.KeyEntry) api.KeyAccess {
 	ka := &keyAccessMock{}
-	ka.On("AllKeys").Return(keys).Maybe()
+	ka.On("AllKeys").Return(keys, nil).Maybe()
 	return ka
 }
 
@@ -191,53 +266,6 @@ func (s *guiSuite) setupBuildingOfKeyEntry(path, algo string) *gtk.MockButton {
 	return box
 }
 
-func (s *guiSuite) Test_populateListWithKeyEntries_IfThereAreKeyEntriesAddsThemIntoAGTKBoxWithoutCallingOnNoKeysFunctionPassedInParameter() {
-	ka := fixedKeyAccess(
-		fixedKeyEntry("/home/amnesia/.ssh/id_rsa", api.RSA),
-		fixedKeyEntry("/home/amnesia/.ssh/id_ed25519", api.Ed25519),
-		fixedKeyEntry("/home/amnesia/.ssh/id_dsa", api.DSA),
-	)
-
-	box1 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_rsa", "RSA")
-	box2 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ed25519", "Ed25519")
-	box3 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_dsa", "DSA")
-
-	box1.On("Connect", "clicked", mock.Anything).Return(nil).Once()
-	box2.On("Connect", "clicked", mock.Anything).Return(nil).Once()
-	box3.On("Connect", "clicked", mock.Anything).Return(nil).Once()
-
-	box := &gtk.MockBox{}
-	box.On("Add", box1).Return().Once()
-	box.On("Add", box2).Return().Once()
-	box.On("Add", box3).Return().Once()
-
-	u := &ui{gtk: s.gtkMock}
-
-	called := false
-	onNoKeys := func(box gtki.Box) { called = true }
-
-	u.populateListWithKeyEntries(ka, box, nil, nil, onNoKeys)
-
-	box.AssertExpectations(s.T())
-	s.False(called)
-}
-
-func (s *guiSuite) Test_populateListWithKeyEntries_IfThereAreNoKeyEntriesExecuteOnNoKeysFunctionPassedInParameter() {
-	ka := fixedKeyAccess()
-
-	box := &gtk.MockBox{}
-
-	u := &ui{gtk: s.gtkMock}
-
-	called := false
-	onNoKeys := func(box gtki.Box) { called = true }
-
-	u.populateListWithKeyEntries(ka, box, nil, nil, onNoKeys)
-
-	box.AssertExpectations(s.T())
-	s.True(called)
-}
-
 func (s *guiSuite) Test_showNoAvailableKeysMessage_AddsAMessageIntoAGTKBoxWhenThereAreNoAvailableKeys() {
 
 	sc := &gtk.MockStyleContext{}
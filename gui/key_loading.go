@@ -0,0 +1,94 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const keyLoadingBox = "keyLoadingBox"
+const keyLoadingSpinner = "keyLoadingSpinner"
+const keyLoadingLabel = "keyLoadingLabel"
+
+// runInBackground runs the function on another goroutine. It is a variable, so tests can run it right away
+var runInBackground = func(f func()) {
+	go f()
+}
+
+// onMainLoop runs the function on the GTK main loop, since widgets can't be used from other goroutines
+func (u *ui) onMainLoop(f func()) {
+	u.glib.IdleAdd(f)
+}
+
+// keyLoadingIndicator shows a spinner and how many keys have been found while the keys are loaded
+type keyLoadingIndicator struct {
+	box     gtki.Box
+	spinner gtki.Spinner
+	label   gtki.Label
+}
+
+func keyLoadingIndicatorFrom(b *builder) *keyLoadingIndicator {
+	return &keyLoadingIndicator{
+		box:     b.get(keyLoadingBox).(gtki.Box),
+		spinner: b.get(keyLoadingSpinner).(gtki.Spinner),
+		label:   b.get(keyLoadingLabel).(gtki.Label),
+	}
+}
+
+func (k *keyLoadingIndicator) start() {
+	k.label.SetLabel(i18n.Local("Looking for keys…"))
+	k.spinner.Start()
+	k.box.Show()
+}
+
+func (k *keyLoadingIndicator) found(keys int) {
+	k.label.SetLabel(fmt.Sprintf(i18n.Local("Looking for keys (%d found)…"), keys))
+}
+
+func (k *keyLoadingIndicator) stop() {
+	k.spinner.Stop()
+	k.box.Hide()
+}
+
+// startLoadingKeys cancels the keys that are still being loaded, so the keys found by them aren't
+// added to the list after it's emptied
+func (u *ui) startLoadingKeys() context.Context {
+	if u.cancelKeyLoading != nil {
+		u.cancelKeyLoading()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancelKeyLoading = cancel
+	u.keyListEntries = nil
+	u.keyLoading.start()
+	return ctx
+}
+
+// loadKeys lists the keys of the key access in the background, so the window keeps responding while
+// the key files are read. found is called with each key as soon as it's found, and done once all of
+// them are, both on the main loop. Neither is called anymore once the keys are loaded again
+func (u *ui) loadKeys(access api.KeyAccess, found func(api.KeyEntry), done func(error)) {
+	ctx := u.startLoadingKeys()
+	cancel := u.cancelKeyLoading
+
+	runInBackground(func() {
+		e := api.StreamKeys(ctx, access, func(k api.KeyEntry) {
+			u.onMainLoop(func() {
+				if ctx.Err() == nil {
+					found(k)
+					u.keyLoading.found(len(u.keyListEntries))
+				}
+			})
+		})
+
+		u.onMainLoop(func() {
+			if ctx.Err() == nil {
+				cancel()
+				u.keyLoading.stop()
+				done(e)
+			}
+		})
+	})
+}
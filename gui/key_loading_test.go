@@ -0,0 +1,153 @@
+package gui
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coyim/gotk3adapter/glibi"
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/coyim/gotk3mocks/glib"
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/mock"
+)
+
+// mainLoopForTest keeps the functions added to the main loop until the test runs them
+type mainLoopForTest struct {
+	glib.Mock
+	pending chan func()
+}
+
+func newMainLoopForTest() *mainLoopForTest {
+	m := &mainLoopForTest{pending: make(chan func(), 100)}
+	m.On("IdleAdd", mock.Anything).Return(glibi.SourceHandle(0)).Run(func(args mock.Arguments) {
+		m.pending <- args.Get(0).(func())
+	})
+	return m
+}
+
+// run runs the functions that were added to the main loop, until there are none left
+func (m *mainLoopForTest) run() {
+	for {
+		select {
+		case f := <-m.pending:
+			f()
+		default:
+			return
+		}
+	}
+}
+
+func runningInTheForeground() func() {
+	return gostub.Stub(&runInBackground, func(f func()) { f() }).Reset
+}
+
+func (s *guiSuite) keyLoadingIndicatorForTest() (*keyLoadingIndicator, *gtk.MockBox, *gtk.MockSpinner, *gtk.MockLabel) {
+	box, spinner, label := &gtk.MockBox{}, &gtk.MockSpinner{}, &gtk.MockLabel{}
+	s.addObjectToAssert(box)
+	s.addObjectToAssert(spinner)
+	s.addObjectToAssert(label)
+	return &keyLoadingIndicator{box, spinner, label}, box, spinner, label
+}
+
+func (s *guiSuite) Test_keyLoadingIndicatorFrom_getsTheWidgetsFromTheBuilder() {
+	b := &gtk.MockBuilder{}
+	box, spinner, label := &gtk.MockBox{}, &gtk.MockSpinner{}, &gtk.MockLabel{}
+	b.On("GetObject", "keyLoadingBox").Return(box, nil).Once()
+	b.On("GetObject", "keyLoadingSpinner").Return(spinner, nil).Once()
+	b.On("GetObject", "keyLoadingLabel").Return(label, nil).Once()
+
+	s.Equal(&keyLoadingIndicator{box, spinner, label}, keyLoadingIndicatorFrom(&builder{b}))
+	b.AssertExpectations(s.T())
+}
+
+// streamingKeyAccessForTest gives its keys one at a time
+type streamingKeyAccessForTest struct {
+	keyAccessMock
+	keys   []api.KeyEntry
+	failed error
+}
+
+func (ka *streamingKeyAccessForTest) StreamKeys(ctx context.Context, found func(api.KeyEntry)) error {
+	for _, k := range ka.keys {
+		found(k)
+	}
+	return ka.failed
+}
+
+func (s *guiSuite) Test_loadKeys_givesTheKeysOnTheMainLoopAndShowsTheProgress() {
+	defer runningInTheForeground()()
+	loop := newMainLoopForTest()
+	indicator, box, spinner, label := s.keyLoadingIndicatorForTest()
+	u := &ui{glib: loop, keyLoading: indicator}
+	k1, k2 := &keyEntryMock{}, &keyEntryMock{}
+	ka := &streamingKeyAccessForTest{keys: []api.KeyEntry{k1, k2}, failed: errors.New("permission denied")}
+
+	label.On("SetLabel", "Looking for keys…").Return().Once()
+	spinner.On("Start").Return().Once()
+	box.On("Show").Return().Once()
+	found := []api.KeyEntry{}
+	var loadingError error
+	u.loadKeys(ka, func(k api.KeyEntry) {
+		found = append(found, k)
+		u.keyListEntries = append(u.keyListEntries, keyListEntry{entry: k})
+	}, func(e error) {
+		loadingError = e
+	})
+
+	s.Empty(found, "the keys are only given on the main loop")
+	label.On("SetLabel", "Looking for keys (1 found)…").Return().Once()
+	label.On("SetLabel", "Looking for keys (2 found)…").Return().Once()
+	spinner.On("Stop").Return().Once()
+	box.On("Hide").Return().Once()
+	loop.run()
+
+	s.Equal([]api.KeyEntry{k1, k2}, found)
+	s.EqualError(loadingError, "permission denied")
+}
+
+func (s *guiSuite) Test_loadKeys_ignoresTheKeysOfAnEarlierLoading() {
+	defer runningInTheForeground()()
+	loop := newMainLoopForTest()
+	indicator, box, spinner, label := s.keyLoadingIndicatorForTest()
+	u := &ui{glib: loop, keyLoading: indicator}
+	old, k := &keyEntryMock{}, &keyEntryMock{}
+
+	label.On("SetLabel", mock.Anything).Return()
+	spinner.On("Start").Return().Twice()
+	box.On("Show").Return().Twice()
+	found := []api.KeyEntry{}
+	doneCalled := 0
+	u.loadKeys(fixedKeyAccess(old), func(k api.KeyEntry) { found = append(found, k) }, func(error) { doneCalled++ })
+	u.loadKeys(fixedKeyAccess(k), func(k api.KeyEntry) { found = append(found, k) }, func(error) { doneCalled++ })
+
+	spinner.On("Stop").Return().Once()
+	box.On("Hide").Return().Once()
+	loop.run()
+
+	s.Equal([]api.KeyEntry{k}, found)
+	s.Equal(1, doneCalled)
+}
+
+func (s *guiSuite) Test_PasswordFor_asksForThePasswordOnTheMainLoop() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	s.setupPassphraseDialog("Enter the password for /home/amnesia/cert.p12:", gtki.ResponseType(-5), "open sesame")
+	loop := newMainLoopForTest()
+	u := &ui{gtk: s.gtkMock, glib: loop}
+
+	type answer struct {
+		password []byte
+		ok       bool
+	}
+	answered := make(chan answer)
+	go func() {
+		p, ok := u.PasswordFor("/home/amnesia/cert.p12", false)
+		answered <- answer{p, ok}
+	}()
+
+	(<-loop.pending)()
+	a := <-answered
+	s.True(a.ok)
+	s.Equal([]byte("open sesame"), a.password)
+}
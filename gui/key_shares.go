@@ -0,0 +1,309 @@
+package gui
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/shamir"
+)
+
+const keySharesLabel = "keySharesLabel"
+const keySharesBox = "keySharesBox"
+const splitKeyButton = "splitKeyButton"
+const verifyKeySharesButton = "verifyKeySharesButton"
+
+var keySharesAlgorithms = []api.Algorithm{api.RSA, api.ECDSA, api.Ed25519}
+
+// openSSHFingerprint is the same fingerprint ssh-keygen -l shows by default
+func openSSHFingerprint(pub crypto.PublicKey) (string, error) {
+	return fingerprint.OfPublicKey(pub, fingerprint.OpenSSHSHA256)
+}
+
+func keySharesKeyFor(k api.KeyEntry) (api.UnlockablePrivateKeyEntry, bool) {
+	pk, ok := k.(api.UnlockablePrivateKeyEntry)
+	if !ok {
+		return nil, false
+	}
+
+	for _, a := range keySharesAlgorithms {
+		if k.Algorithm() == a {
+			return pk, true
+		}
+	}
+	return nil, false
+}
+
+func keyShareFileName(dir, keyName string, s *shamir.KeyShare) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-share-%d-of-%d.txt", keyName, s.Index, s.Total))
+}
+
+// writeKeyShares refuses to overwrite anything, since the files might be shares of another key
+func writeKeyShares(dir, keyName string, shares []*shamir.KeyShare) error {
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return e
+	}
+
+	for _, s := range shares {
+		f, e := os.OpenFile(keyShareFileName(dir, keyName, s), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if e != nil {
+			return e
+		}
+		_, e = f.WriteString(s.Armor())
+		if e2 := f.Close(); e == nil {
+			e = e2
+		}
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// splitKey creates the shares of the unlocked key, with the same fingerprint and user ID the key has
+func splitKey(unlocked api.UnlockedPrivateKey, total, threshold int) ([]*shamir.KeyShare, error) {
+	signer, ok := unlocked.PrivateKey().(crypto.Signer)
+	if !ok {
+		return nil, shamir.ErrUnsupportedKey
+	}
+
+	fingerprint, e := openSSHFingerprint(signer.Public())
+	if e != nil {
+		return nil, e
+	}
+
+	return shamir.SplitKey(signer, fingerprint, unlocked.UserID(), total, threshold)
+}
+
+func keySharesErrorMessage(e error) string {
+	if e == shamir.ErrInvalidThreshold {
+		return i18n.Local("The number of shares needed to restore the key can't be more than the number of shares.")
+	}
+	return fmt.Sprintf(i18n.Local("The key couldn't be split: %s"), e)
+}
+
+// runSplitKeyDialog runs the dialog until the shares have been created, or the user cancels
+func (u *ui) runSplitKeyDialog(unlocked api.UnlockedPrivateKey) ([]*shamir.KeyShare, bool) {
+	d, b := buildObjectFrom[gtki.Dialog](u, "SplitKeyDialog")
+	defer d.Destroy()
+	d.SetTransientFor(u.mainWindow)
+
+	for d.Run() == int(gtki.RESPONSE_OK) {
+		total := b.get("totalSharesSpin").(gtki.SpinButton).GetValueAsInt()
+		threshold := b.get("thresholdSpin").(gtki.SpinButton).GetValueAsInt()
+
+		shares, e := splitKey(unlocked, total, threshold)
+		if e == nil {
+			return shares, true
+		}
+		b.get("keySharesError").(gtki.Label).SetLabel(keySharesErrorMessage(e))
+	}
+	return nil, false
+}
+
+func (u *ui) splitKey(k api.UnlockablePrivateKeyEntry) {
+	unlocked, ok := u.unlockPrivateKey(k)
+	if !ok {
+		return
+	}
+
+	shares, ok := u.runSplitKeyDialog(unlocked)
+	if !ok {
+		return
+	}
+
+	dir, ok := u.chooseFolder(i18n.Local("Choose where to save the key shares"), gtki.FILE_CHOOSER_ACTION_CREATE_FOLDER)
+	if !ok {
+		return
+	}
+
+	keyName := filepath.Base(firstOrEmpty(k.PrivateKeyLocations()))
+	if e := writeKeyShares(dir, keyName, shares); e != nil {
+		u.log.WithError(e).WithField("directory", dir).Error("couldn't save the key shares")
+		u.showMessage(fmt.Sprintf(i18n.Local("The key shares couldn't be saved: %s"), e))
+		return
+	}
+
+	u.showMessage(fmt.Sprintf(i18n.Local("The %d shares were saved to %s. Give each of them to a different custodian, and remove them from this computer. Any %d of them can restore the key."),
+		len(shares), dir, shares[0].Threshold))
+}
+
+// combineKeyShares restores the key, and checks that it is the one the shares say they belong to
+func combineKeyShares(text string) (crypto.Signer, *shamir.KeyShare, error) {
+	shares, e := shamir.ParseKeyShares(text)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	priv, e := shamir.CombineKey(shares)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, shamir.ErrUnsupportedKey
+	}
+
+	if fingerprint, _ := openSSHFingerprint(signer.Public()); fingerprint != shares[0].Fingerprint {
+		return nil, nil, shamir.ErrChecksumMismatch
+	}
+	return signer, shares[0], nil
+}
+
+func combineKeySharesErrorMessage(e error) string {
+	if e == shamir.ErrNotEnoughShares {
+		return i18n.Local("There are not enough shares to restore the key.")
+	}
+	return fmt.Sprintf(i18n.Local("The key couldn't be restored: %s"), e)
+}
+
+type combineKeySharesDialog struct {
+	dialog  gtki.Dialog
+	builder *builder
+}
+
+func (u *ui) newCombineKeySharesDialog() *combineKeySharesDialog {
+	d, b := buildObjectFrom[gtki.Dialog](u, "CombineKeySharesDialog")
+	d.SetTransientFor(u.mainWindow)
+	return &combineKeySharesDialog{d, b}
+}
+
+func (cd *combineKeySharesDialog) text() string {
+	buffer := cd.builder.get("keySharesBuffer").(gtki.TextBuffer)
+	start, end := buffer.GetBounds()
+	return buffer.GetText(start, end, false)
+}
+
+func (cd *combineKeySharesDialog) textOf(id string) string {
+	text, _ := cd.builder.get(id).(gtki.Entry).GetText()
+	return strings.TrimSpace(text)
+}
+
+func (cd *combineKeySharesDialog) showError(message string) {
+	cd.builder.get("keySharesError").(gtki.Label).SetLabel(message)
+}
+
+// keySharesVerification describes whether the shares restore the key with the given public key
+func keySharesVerification(text string, pub crypto.PublicKey) (string, bool) {
+	signer, _, e := combineKeyShares(text)
+	if e != nil {
+		return combineKeySharesErrorMessage(e), false
+	}
+
+	expected, _ := openSSHFingerprint(pub)
+	if restored, _ := openSSHFingerprint(signer.Public()); restored != expected {
+		return i18n.Local("The shares restore a different key."), false
+	}
+	return i18n.Local("The shares restore this key."), true
+}
+
+// publicKeyOf only needs to unlock private keys that don't have a public key file next to them
+func (u *ui) publicKeyOf(k api.UnlockablePrivateKeyEntry) (crypto.PublicKey, bool) {
+	if pk, ok := k.(api.PublicKeyMaterialEntry); ok {
+		return pk.PublicKey(), true
+	}
+
+	unlocked, ok := u.unlockPrivateKey(k)
+	if !ok {
+		return nil, false
+	}
+	signer, ok := unlocked.PrivateKey().(crypto.Signer)
+	if !ok {
+		return nil, false
+	}
+	return signer.Public(), true
+}
+
+// verifyKeyShares lets custodians check that their shares still restore the key, without writing it anywhere
+func (u *ui) verifyKeyShares(k api.UnlockablePrivateKeyEntry) {
+	pub, ok := u.publicKeyOf(k)
+	if !ok {
+		return
+	}
+
+	cd := u.newCombineKeySharesDialog()
+	defer cd.dialog.Destroy()
+	cd.dialog.SetTitle(i18n.Local("Verify key shares"))
+	cd.builder.get("okButton").(gtki.Button).SetLabel(i18n.Local("_Verify"))
+	cd.builder.get("restoredKeyGrid").(gtki.Widget).Hide()
+
+	for cd.dialog.Run() == int(gtki.RESPONSE_OK) {
+		message, ok := keySharesVerification(cd.text(), pub)
+		if ok {
+			cd.dialog.Hide()
+			u.showMessage(message)
+			return
+		}
+		cd.showError(message)
+	}
+}
+
+func restoredKeyFileNameIn(home string) string {
+	return filepath.Join(home, ".ssh", restoredKeyFileName)
+}
+
+// restore combines the shares and imports the key, or returns a description of the problem
+func (cd *combineKeySharesDialog) restore(importer api.PrivateKeyMaterialImporter) (string, string) {
+	fileName := cd.textOf("restoredKeyFileNameEntry")
+	passphrase := cd.textOf("restoredKeyPassphraseEntry")
+	if problem := keyGenerationProblem(fileName, passphrase, cd.textOf("restoredKeyConfirmPassphraseEntry")); problem != "" {
+		return "", problem
+	}
+
+	signer, share, e := combineKeyShares(cd.text())
+	if e != nil {
+		return "", combineKeySharesErrorMessage(e)
+	}
+
+	if _, e := importer.ImportPrivateKeyMaterial(signer, share.UserID, fileName, []byte(passphrase)); e != nil {
+		return "", fmt.Sprintf(i18n.Local("The key couldn't be saved: %s"), e)
+	}
+	return fileName, ""
+}
+
+func (u *ui) restoreFromKeyShares(ka api.KeyAccess, onRestored func()) {
+	importer, ok := ka.(api.PrivateKeyMaterialImporter)
+	if !ok {
+		u.log.Warn("the key access doesn't support importing private keys")
+		return
+	}
+
+	cd := u.newCombineKeySharesDialog()
+	defer cd.dialog.Destroy()
+
+	home, _ := os.UserHomeDir()
+	cd.builder.get("restoredKeyFileNameEntry").(gtki.Entry).SetText(restoredKeyFileNameIn(home))
+
+	for cd.dialog.Run() == int(gtki.RESPONSE_OK) {
+		fileName, problem := cd.restore(importer)
+		if problem == "" {
+			cd.dialog.Hide()
+			u.showMessage(fmt.Sprintf(i18n.Local("The key was restored to %s."), fileName))
+			onRestored()
+			return
+		}
+		cd.showError(problem)
+	}
+}
+
+func (kd *keyDetails) displayKeyShares() {
+	k, ok := keySharesKeyFor(kd.key)
+	if !ok {
+		kd.hideAll(keySharesLabel, keySharesBox)
+		return
+	}
+
+	kd.onClicked(splitKeyButton, func() {
+		kd.ui.splitKey(k)
+	})
+	kd.onClicked(verifyKeySharesButton, func() {
+		kd.ui.verifyKeyShares(k)
+	})
+}
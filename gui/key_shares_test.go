@@ -0,0 +1,148 @@
+package gui
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/shamir"
+)
+
+const ed25519KeyForTestFingerprint = "SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8"
+
+func (s *guiSuite) keySharesForTest(total, threshold int) []*shamir.KeyShare {
+	unlocked := &unlockedPrivateKeyMock{}
+	unlocked.On("PrivateKey").Return(ed25519KeyForTest())
+	unlocked.On("UserID").Return("batman@gotham")
+
+	shares, e := splitKey(unlocked, total, threshold)
+	s.Require().NoError(e)
+	return shares
+}
+
+func armorAll(shares ...*shamir.KeyShare) string {
+	result := []string{}
+	for _, sh := range shares {
+		result = append(result, sh.Armor())
+	}
+	return strings.Join(result, "\n")
+}
+
+func (s *guiSuite) Test_openSSHFingerprint_isTheSameAsTheOneFromSSHKeygen() {
+	fingerprint, e := openSSHFingerprint(ed25519KeyForTest().Public())
+	s.NoError(e)
+	s.Equal(ed25519KeyForTestFingerprint, fingerprint)
+}
+
+func (s *guiSuite) Test_keySharesKeyFor_onlyAcceptsUnlockableKeysOfSupportedAlgorithms() {
+	_, ok := keySharesKeyFor(fixedKeyEntry("/home/amnesia/.ssh/id_rsa.pub", api.RSA))
+	s.False(ok)
+
+	dsaKey := &unlockablePrivateKeyEntryMock{}
+	dsaKey.On("Algorithm").Return(api.DSA)
+	_, ok = keySharesKeyFor(dsaKey)
+	s.False(ok)
+
+	k := &unlockablePrivateKeyEntryMock{}
+	k.On("Algorithm").Return(api.Ed25519)
+	res, ok := keySharesKeyFor(k)
+	s.True(ok)
+	s.Equal(k, res)
+}
+
+func (s *guiSuite) Test_keyDetails_displayKeyShares_hidesTheRowForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_rsa.pub", api.RSA),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, keySharesLabel, keySharesBox)
+
+	kd.displayKeyShares()
+}
+
+func (s *guiSuite) Test_splitKey_writesTheFingerprintAndUserIDOnTheShares() {
+	shares := s.keySharesForTest(5, 3)
+
+	s.Len(shares, 5)
+	s.Equal(ed25519KeyForTestFingerprint, shares[0].Fingerprint)
+	s.Equal("batman@gotham", shares[0].UserID)
+	s.Equal(3, shares[4].Threshold)
+}
+
+func (s *guiSuite) Test_splitKey_reportsAnInvalidThreshold() {
+	unlocked := &unlockedPrivateKeyMock{}
+	unlocked.On("PrivateKey").Return(ed25519KeyForTest())
+	unlocked.On("UserID").Return("")
+
+	_, e := splitKey(unlocked, 3, 4)
+	s.Equal(shamir.ErrInvalidThreshold, e)
+	s.Equal("The number of shares needed to restore the key can't be more than the number of shares.", keySharesErrorMessage(e))
+}
+
+func (s *guiSuite) Test_writeKeyShares_writesOneProtectedFilePerShare() {
+	dir := filepath.Join(s.T().TempDir(), "shares")
+	shares := s.keySharesForTest(3, 2)
+
+	s.Require().NoError(writeKeyShares(dir, "id_ed25519", shares))
+
+	for _, sh := range shares {
+		fileName := keyShareFileName(dir, "id_ed25519", sh)
+		content, e := os.ReadFile(fileName)
+		s.Require().NoError(e)
+		s.Equal(sh.Armor(), string(content))
+		info, _ := os.Stat(fileName)
+		s.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+	s.Equal(filepath.Join(dir, "id_ed25519-share-2-of-3.txt"), keyShareFileName(dir, "id_ed25519", shares[1]))
+
+	s.True(os.IsExist(writeKeyShares(dir, "id_ed25519", shares)))
+}
+
+func (s *guiSuite) Test_combineKeyShares_restoresTheKeyFromEnoughShares() {
+	shares := s.keySharesForTest(5, 3)
+
+	signer, share, e := combineKeyShares(armorAll(shares[4], shares[0], shares[2]))
+	s.Require().NoError(e)
+	s.Equal(ed25519KeyForTest(), signer)
+	s.Equal("batman@gotham", share.UserID)
+
+	_, _, e = combineKeyShares(armorAll(shares[4], shares[0]))
+	s.Equal(shamir.ErrNotEnoughShares, e)
+	s.Equal("There are not enough shares to restore the key.", combineKeySharesErrorMessage(e))
+}
+
+func (s *guiSuite) Test_combineKeyShares_checksTheFingerprintOnTheShares() {
+	shares := s.keySharesForTest(3, 2)
+	for _, sh := range shares {
+		sh.Fingerprint = "SHA256:somethingElse"
+	}
+
+	_, _, e := combineKeyShares(armorAll(shares...))
+	s.Equal(shamir.ErrChecksumMismatch, e)
+}
+
+func (s *guiSuite) Test_keySharesVerification_comparesTheRestoredKeyWithTheGivenOne() {
+	text := armorAll(s.keySharesForTest(3, 2)...)
+
+	message, ok := keySharesVerification(text, ed25519KeyForTest().Public())
+	s.True(ok)
+	s.Equal("The shares restore this key.", message)
+
+	other := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	message, ok = keySharesVerification(text, other.Public())
+	s.False(ok)
+	s.Equal("The shares restore a different key.", message)
+
+	message, ok = keySharesVerification("", other.Public())
+	s.False(ok)
+	s.Equal("The key couldn't be restored: the text doesn't contain any key shares", message)
+}
+
+func (s *guiSuite) Test_restoredKeyFileNameIn_usesTheSSHDirectory() {
+	s.Equal("/home/amnesia/.ssh/id_restored", restoredKeyFileNameIn("/home/amnesia"))
+}
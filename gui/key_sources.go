@@ -0,0 +1,116 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const keySourceFilter = "keySourceFilter"
+const keyListProblemsLabel = "keyListProblemsLabel"
+
+// allKeySources is used as the visible source when the keys of all sources are shown
+const allKeySources = ""
+
+// sourceAt returns the source chosen in the filter, where the first choice shows all sources
+func sourceAt(sources []string, active int) string {
+	if active < 1 || active > len(sources) {
+		return allKeySources
+	}
+	return sources[active-1]
+}
+
+// setupKeySourceFilter lets the user only show the keys of one source, when the keys come from more than one
+func (a *application) setupKeySourceFilter(b *builder) {
+	sa, ok := a.keys.(api.SourcedKeyAccess)
+	if !ok || len(sa.Sources()) < 2 {
+		return
+	}
+
+	sources := sa.Sources()
+	filter := b.get(keySourceFilter).(gtki.ComboBoxText)
+	filter.AppendText(i18n.Local("All sources"))
+	for _, s := range sources {
+		filter.AppendText(s)
+	}
+	filter.SetActive(0)
+	filter.Connect("changed", func() {
+		a.ui.visibleKeySource = sourceAt(sources, filter.GetActive())
+		a.showKeysOfVisibleSource()
+	})
+	filter.Show()
+}
+
+func (a *application) isOfVisibleSource(k api.KeyEntry) bool {
+	if a.ui.visibleKeySource == allKeySources {
+		return true
+	}
+	sa, ok := a.keys.(api.SourcedKeyAccess)
+	return ok && sa.SourceOf(k) == a.ui.visibleKeySource
+}
+
+// showKeyListEntry shows a key in the list, unless it doesn't come from the source chosen in the filter
+func (a *application) showKeyListEntry(le keyListEntry) {
+	if a.isOfVisibleSource(le.entry) {
+		le.button.Show()
+	} else {
+		le.button.Hide()
+	}
+}
+
+// showKeysOfVisibleSource hides the keys in the list that don't come from the source chosen in the filter
+func (a *application) showKeysOfVisibleSource() {
+	for _, le := range a.ui.keyListEntries {
+		a.showKeyListEntry(le)
+	}
+}
+
+// listingProblems describes why the keys couldn't be listed and which sources failed the last time
+// they were listed. The message is empty when there were no problems, and the details give the error
+// of each source that failed
+func (a *application) listingProblems(e error) (message string, details string) {
+	messages, failures := []string{}, []string{}
+	if e != nil {
+		messages = append(messages, fmt.Sprintf(i18n.Local("The keys couldn't be listed: %s"), e))
+	}
+
+	if sa, ok := a.keys.(api.SourcedKeyAccess); ok {
+		failed := []string{}
+		for _, s := range sa.Sources() {
+			if e := sa.FailureOf(s); e != nil {
+				failed = append(failed, s)
+				failures = append(failures, fmt.Sprintf("%s: %s", s, e))
+			}
+		}
+		if len(failed) > 0 {
+			messages = append(messages, fmt.Sprintf(i18n.Local("The keys from %s couldn't be listed"), strings.Join(failed, ", ")))
+		}
+	}
+	return strings.Join(messages, "\n"), strings.Join(failures, "\n")
+}
+
+// showListingProblems tells in the key list why keys are missing from it, and logs the errors
+func (a *application) showListingProblems(e error) {
+	if e != nil {
+		a.ui.log.WithError(e).Warn("couldn't list the keys")
+	}
+	if sa, ok := a.keys.(api.SourcedKeyAccess); ok {
+		for _, s := range sa.Sources() {
+			if e := sa.FailureOf(s); e != nil {
+				a.ui.log.WithError(e).WithField("source", s).Warn("couldn't list the keys of the source")
+			}
+		}
+	}
+
+	message, details := a.listingProblems(e)
+	if message == "" {
+		a.ui.keyListProblems.Hide()
+		return
+	}
+	a.ui.keyListProblems.SetLabel(message)
+	a.ui.keyListProblems.SetTooltipText(details)
+	a.ui.keyListProblems.Show()
+}
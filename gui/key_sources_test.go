@@ -0,0 +1,145 @@
+package gui
+
+import (
+	"errors"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
+)
+
+type sourcedKeyAccessForTest struct {
+	keyAccessMock
+	sources  []string
+	sourceOf map[api.KeyEntry]string
+	failures map[string]error
+}
+
+func (ka *sourcedKeyAccessForTest) Sources() []string {
+	return ka.sources
+}
+
+func (ka *sourcedKeyAccessForTest) SourceOf(k api.KeyEntry) string {
+	return ka.sourceOf[k]
+}
+
+func (ka *sourcedKeyAccessForTest) FailureOf(source string) error {
+	return ka.failures[source]
+}
+
+func (s *guiSuite) Test_sourceAt_returnsTheSourceChosenInTheFilter() {
+	sources := []string{"SSH", "GnuPG"}
+	s.Equal(allKeySources, sourceAt(sources, 0))
+	s.Equal("SSH", sourceAt(sources, 1))
+	s.Equal("GnuPG", sourceAt(sources, 2))
+	s.Equal(allKeySources, sourceAt(sources, 3))
+	s.Equal(allKeySources, sourceAt(sources, -1))
+}
+
+func (s *guiSuite) Test_application_setupKeySourceFilter_onlyShowsTheKeysOfTheChosenSource() {
+	sshKey, gpgKey := &keyEntryMock{}, &keyEntryMock{}
+	sshButton, gpgButton := &gtk.MockButton{}, &gtk.MockButton{}
+	ka := &sourcedKeyAccessForTest{
+		sources:  []string{"SSH", "GnuPG"},
+		sourceOf: map[api.KeyEntry]string{sshKey: "SSH", gpgKey: "GnuPG"},
+	}
+	a := &application{
+		ui:   &ui{keyListEntries: []keyListEntry{{sshKey, sshButton}, {gpgKey, gpgButton}}},
+		keys: ka,
+	}
+
+	builderMock := &gtk.MockBuilder{}
+	filter := &gtk.MockComboBoxText{}
+	builderMock.On("GetObject", "keySourceFilter").Return(filter, nil).Once()
+	filter.On("AppendText", "All sources").Return().Once()
+	filter.On("AppendText", "SSH").Return().Once()
+	filter.On("AppendText", "GnuPG").Return().Once()
+	filter.On("SetActive", 0).Return().Once()
+	filter.On("Show").Return().Once()
+	var changed func()
+	filter.On("Connect", "changed", mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+		changed = args.Get(1).(func())
+	})
+
+	a.setupKeySourceFilter(&builder{builderMock})
+
+	filter.On("GetActive").Return(2).Once()
+	sshButton.On("Hide").Return().Once()
+	gpgButton.On("Show").Return().Once()
+	changed()
+	s.Equal("GnuPG", a.ui.visibleKeySource)
+
+	filter.On("GetActive").Return(0).Once()
+	sshButton.On("Show").Return().Once()
+	gpgButton.On("Show").Return().Once()
+	changed()
+	s.Equal(allKeySources, a.ui.visibleKeySource)
+
+	builderMock.AssertExpectations(s.T())
+	filter.AssertExpectations(s.T())
+	sshButton.AssertExpectations(s.T())
+	gpgButton.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_application_setupKeySourceFilter_staysHiddenWithOnlyOneSource() {
+	builderMock := &gtk.MockBuilder{}
+	a := &application{ui: &ui{}, keys: &sourcedKeyAccessForTest{sources: []string{"SSH"}}}
+	a.setupKeySourceFilter(&builder{builderMock})
+
+	a = &application{ui: &ui{}, keys: fixedKeyAccess()}
+	a.setupKeySourceFilter(&builder{builderMock})
+
+	builderMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_application_listingProblems_describesTheErrorAndTheSourcesThatFailed() {
+	a := &application{
+		keys: &sourcedKeyAccessForTest{
+			sources: []string{"SSH", "GnuPG", "age"},
+			failures: map[string]error{
+				"GnuPG": errors.New("the keyring is corrupted"),
+				"age":   errors.New("permission denied"),
+			},
+		},
+	}
+
+	message, details := a.listingProblems(nil)
+	s.Equal("The keys from GnuPG, age couldn't be listed", message)
+	s.Equal("GnuPG: the keyring is corrupted\nage: permission denied", details)
+
+	message, _ = a.listingProblems(errors.New("out of memory"))
+	s.Equal("The keys couldn't be listed: out of memory\nThe keys from GnuPG, age couldn't be listed", message)
+
+	a.keys = fixedKeyAccess()
+	message, details = a.listingProblems(nil)
+	s.Empty(message)
+	s.Empty(details)
+}
+
+func (s *guiSuite) Test_application_showListingProblems_showsAndLogsTheSourcesThatCouldNotBeListed() {
+	log, hook := test.NewNullLogger()
+	problems := &gtk.MockLabel{}
+	a := &application{
+		ui: &ui{log: log, keyListProblems: problems},
+		keys: &sourcedKeyAccessForTest{
+			sources:  []string{"SSH", "GnuPG"},
+			failures: map[string]error{"GnuPG": errors.New("the keyring is corrupted")},
+		},
+	}
+
+	problems.On("SetLabel", "The keys from GnuPG couldn't be listed").Return().Once()
+	problems.On("SetTooltipText", "GnuPG: the keyring is corrupted").Return().Once()
+	problems.On("Show").Return().Once()
+	a.showListingProblems(nil)
+
+	s.Require().Len(hook.AllEntries(), 1)
+	s.Equal("couldn't list the keys of the source", hook.LastEntry().Message)
+	s.Equal("GnuPG", hook.LastEntry().Data["source"])
+
+	a.keys = &sourcedKeyAccessForTest{sources: []string{"SSH", "GnuPG"}}
+	problems.On("Hide").Return().Once()
+	a.showListingProblems(nil)
+
+	problems.AssertExpectations(s.T())
+}
@@ -17,20 +17,78 @@ func (a *application) createMainWindow(app gtki.Application) gtki.Window {
 	box := b.get("keyListBox").(gtki.Box)
 	box2 := b.get("keyDetailsBox").(gtki.Box)
 	keyDetailsRevealer := b.get("keyDetailsRevealer").(gtki.Revealer)
-	a.addMenuHandlers(b, app)
+	a.addMenuHandlers(b, app, func() { a.refreshMainWindow(box, box2, keyDetailsRevealer) })
+	// the main window is set first, since listing the keys can ask for passwords
+	a.ui.mainWindow = w
+	a.ui.keyLoading = keyLoadingIndicatorFrom(b)
+	a.ui.keyListProblems = b.get(keyListProblemsLabel).(gtki.Label)
+	a.setupKeySourceFilter(b)
 	a.populateMainWindow(box, box2, keyDetailsRevealer)
 	w.SetApplication(app)
 	return w
 }
 
-func (a *application) addMenuHandlers(b gtki.Builder, app gtki.Application) {
+func (a *application) addMenuHandlers(b gtki.Builder, app gtki.Application, refresh func()) {
 	b.ConnectSignals(map[string]interface{}{
 		"on_quit_window": app.Quit,
+		"on_new_key": func() {
+			a.ui.generateKey(a.keys, func(api.KeyEntry) { refresh() })
+		},
+		"on_import_onion_service": func() {
+			a.ui.importOnionService(a.keys, refresh)
+		},
+		"on_import_paper_backup": func() {
+			a.ui.importPaperBackup(a.keys, refresh)
+		},
+		"on_restore_from_key_shares": func() {
+			a.ui.restoreFromKeyShares(a.keys, refresh)
+		},
+		"on_verify_fingerprint": func() {
+			a.ui.verifyFingerprint()
+		},
+		"on_preferences": func() {
+			a.ui.editPreferences(refresh)
+		},
 	})
 }
 
+// populateMainWindow adds each key to the list as soon as it's found, while the keys are loaded in the background
 func (a *application) populateMainWindow(listBox, detailsBox gtki.Box, detailsRev gtki.Revealer) {
-	a.ui.populateListWithKeyEntries(a.keys, listBox, detailsBox, detailsRev, a.ui.showNoAvailableKeysMessage)
+	a.ui.loadKeys(a.keys, func(e api.KeyEntry) {
+		le := a.ui.addKeyEntryTo(listBox, e, detailsBox, detailsRev)
+		a.showKeyListEntry(le)
+		if a.isOfVisibleSource(le.entry) {
+			a.ui.reselectKeyEntry(le)
+		}
+	}, func(e error) {
+		a.keysLoaded(listBox, e)
+	})
+}
+
+func (a *application) keysLoaded(listBox gtki.Box, e error) {
+	a.ui.keyToReselect = ""
+	if len(a.ui.keyListEntries) == 0 {
+		a.ui.showNoAvailableKeysMessage(listBox)
+		listBox.ShowAll()
+	}
+	a.showListingProblems(e)
+	a.ui.onWindowSizeChange()
+}
+
+// refreshMainWindow reads all keys again. The details of the key that was shown are closed, and shown
+// again when the key is found again
+func (a *application) refreshMainWindow(listBox, detailsBox gtki.Box, detailsRev gtki.Revealer) {
+	a.ui.keyToReselect = ""
+	if a.ui.currentlyVisibleKeyEntry != nil {
+		a.ui.keyToReselect = api.ListingOf(*a.ui.currentlyVisibleKeyEntry)
+	}
+	clearAllChildrenOf[gtki.Widget](listBox)
+	detailsRev.SetRevealChild(false)
+	detailsRev.Hide()
+	a.ui.currentlyVisibleKeyEntry = nil
+	a.ui.currentlyVisibleKeyEntryButton = nil
+	a.populateMainWindow(listBox, detailsBox, detailsRev)
+	a.ui.onWindowSizeChange()
 }
 
 func (a *application) activate(app gtki.Application) {
@@ -50,17 +108,23 @@ func (a *application) start() {
 	app.Run([]string{})
 }
 
-func Start(gtk gtki.Gtk, gdk gdki.Gdk, gio gioi.Gio, log logrus.Ext1FieldLogger, ka api.KeyAccess) {
+func Start(gtk gtki.Gtk, gdk gdki.Gdk, gio gioi.Gio, glib glibi.Glib, log logrus.Ext1FieldLogger, ka api.KeyAccess) {
 	app := &application{
 		ui: &ui{
-			gtk: gtk,
-			gdk: gdk,
-			gio: gio,
-			log: log.WithField("component", "gui"),
+			gtk:  gtk,
+			gdk:  gdk,
+			gio:  gio,
+			glib: glib,
+			log:  log.WithField("component", "gui"),
 		},
 
 		keys: ka,
 	}
+	app.ui.loadPreferences()
+
+	if pu, ok := ka.(api.PasswordProviderUser); ok {
+		pu.SetPasswordProvider(app.ui)
+	}
 
 	app.start()
 }
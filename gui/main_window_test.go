@@ -1,11 +1,14 @@
 package gui
 
 import (
+	"errors"
 	"github.com/coyim/gotk3adapter/glibi"
+	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/coyim/gotk3mocks/gdk"
 	"github.com/coyim/gotk3mocks/gio"
 	"github.com/coyim/gotk3mocks/gtk"
 	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
 	"github.com/prashantv/gostub"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/mock"
@@ -77,7 +80,7 @@ func (s *guiSuite) Test_Start_StartsGTKApplication() {
 
 	gdkMock := &gdk.Mock{}
 	log, _ := test.NewNullLogger()
-	Start(gtkMock, gdkMock, nil, log, nil)
+	Start(gtkMock, gdkMock, nil, nil, log, nil)
 
 	appMock.AssertExpectations(s.T())
 	gtkMock.AssertExpectations(s.T())
@@ -141,8 +144,9 @@ func (s *guiSuite) Test_Start_ConnectsAnEventHandlerForActivateSignalThatShowsTh
 
 	gioMock := &gio.Mock{}
 
+	loop := newMainLoopForTest()
 	log, _ := test.NewNullLogger()
-	Start(s.gtkMock, gdkMock, gioMock, log, ka)
+	Start(s.gtkMock, gdkMock, gioMock, loop, log, ka)
 
 	winMock := &gtk.MockApplicationWindow{}
 	winMock.On("SetApplication", appMock).Return().Once()
@@ -162,10 +166,25 @@ func (s *guiSuite) Test_Start_ConnectsAnEventHandlerForActivateSignalThatShowsTh
 	builderMock.On("GetObject", "keyDetailsRevealer").Return(detailsRevealer, nil).Once()
 	builderMock.On("ConnectSignals", mock.Anything).Return().Once()
 
+	indicator, loadingBox, spinner, loadingLabel := s.keyLoadingIndicatorForTest()
+	builderMock.On("GetObject", "keyLoadingBox").Return(indicator.box, nil).Once()
+	builderMock.On("GetObject", "keyLoadingSpinner").Return(indicator.spinner, nil).Once()
+	builderMock.On("GetObject", "keyLoadingLabel").Return(indicator.label, nil).Once()
+	problems := &gtk.MockLabel{}
+	problems.On("Hide").Return().Once()
+	builderMock.On("GetObject", "keyListProblemsLabel").Return(problems, nil).Once()
+	loadingLabel.On("SetLabel", mock.Anything).Return()
+	spinner.On("Start").Return().Once()
+	spinner.On("Stop").Return().Once()
+	loadingBox.On("Show").Return().Once()
+	loadingBox.On("Hide").Return().Once()
+
 	box1 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ed25519", "Ed25519")
 	box1.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	box1.On("Show").Return().Once()
 	box2 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_rsa", "RSA")
 	box2.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	box2.On("Show").Return().Once()
 
 	listBox.On("Add", box1).Return().Once()
 	listBox.On("Add", box2).Return().Once()
@@ -179,8 +198,10 @@ func (s *guiSuite) Test_Start_ConnectsAnEventHandlerForActivateSignalThatShowsTh
 
 	stubStyleProviders(s.gtkMock, gdkMock)
 	defer setupStubbedDefinitions()()
+	defer runningInTheForeground()()
 
 	activateEventHandler()
+	loop.run()
 
 	winMock.AssertExpectations(s.T())
 	builderMock.AssertExpectations(s.T())
@@ -199,12 +220,12 @@ func (s *guiSuite) Test_addMenuHandlers_ConnectAnEvenHandlerForActivateSignalTha
 	})
 
 	a := application{}
-	a.addMenuHandlers(builderMock, applicationMock)
+	a.addMenuHandlers(builderMock, applicationMock, func() {})
 
 	builderMock.AssertExpectations(s.T())
 
 	s.NotNil(connectedArgument, "connect signals should be called with an argument")
-	s.Len(*connectedArgument, 1)
+	s.Len(*connectedArgument, 7)
 	fcalled := (*connectedArgument)["on_quit_window"].(func())
 
 	applicationMock.On("Quit").Return().Once()
@@ -213,3 +234,216 @@ func (s *guiSuite) Test_addMenuHandlers_ConnectAnEvenHandlerForActivateSignalTha
 
 	applicationMock.AssertExpectations(s.T())
 }
+
+func (s *guiSuite) Test_addMenuHandlers_ConnectsTheNewKeyMenuItem_whichLogsWhenKeysCanNotBeGenerated() {
+	builderMock := &gtk.MockBuilder{}
+	var handlers map[string]interface{}
+	builderMock.On("ConnectSignals", mock.Anything).Return().Once().Run(func(args mock.Arguments) {
+		handlers = args.Get(0).(map[string]interface{})
+	})
+
+	log, hook := test.NewNullLogger()
+	a := application{ui: &ui{log: log}, keys: fixedKeyAccess()}
+	a.addMenuHandlers(builderMock, &gtk.MockApplication{}, func() {})
+
+	handlers["on_new_key"].(func())()
+
+	s.Len(hook.AllEntries(), 1)
+	s.Equal("the key access doesn't support generating keys", hook.LastEntry().Message)
+}
+
+func (s *guiSuite) applicationForLoadingTest(ka api.KeyAccess) (*application, *mainLoopForTest) {
+	loop := newMainLoopForTest()
+	indicator, box, spinner, label := s.keyLoadingIndicatorForTest()
+	label.On("SetLabel", mock.Anything).Return()
+	spinner.On("Start").Return()
+	spinner.On("Stop").Return()
+	box.On("Show").Return()
+	box.On("Hide").Return()
+
+	problems := &gtk.MockLabel{}
+	problems.On("Hide").Return().Maybe()
+
+	log, _ := test.NewNullLogger()
+	return &application{
+		ui: &ui{
+			gtk:                s.gtkMock,
+			glib:               loop,
+			log:                log,
+			keyLoading:         indicator,
+			keyListProblems:    problems,
+			onWindowSizeChange: func() {},
+		},
+		keys: ka,
+	}, loop
+}
+
+func (s *guiSuite) Test_populateMainWindow_addsAndShowsTheKeysAsTheyAreFound() {
+	defer runningInTheForeground()()
+	a, loop := s.applicationForLoadingTest(fixedKeyAccess(
+		fixedKeyEntry("/home/amnesia/.ssh/id_rsa", api.RSA),
+		fixedKeyEntry("/home/amnesia/.ssh/id_ed25519", api.Ed25519),
+	))
+
+	box1 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_rsa", "RSA")
+	box1.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	box1.On("Show").Return().Once()
+	box2 := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ed25519", "Ed25519")
+	box2.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	box2.On("Show").Return().Once()
+
+	listBox := &gtk.MockBox{}
+	listBox.On("Add", box1).Return().Once()
+	listBox.On("Add", box2).Return().Once()
+	s.addObjectToAssert(listBox)
+
+	a.populateMainWindow(listBox, nil, nil)
+	loop.run()
+
+	s.Len(a.ui.keyListEntries, 2)
+}
+
+func (s *guiSuite) listBoxShowingNoKeysMessage() *gtk.MockBox {
+	sc := &gtk.MockStyleContext{}
+	sc.On("AddClass", "infoMessage").Return().Once()
+	label := &gtk.MockLabel{}
+	label.On("GetStyleContext").Return(sc, nil).Once()
+	s.gtkMock.On("LabelNew", i18n.Local("\u26A0 No keys available \u26A0")).Return(label, nil).Once()
+
+	listBox := &gtk.MockBox{}
+	listBox.On("Add", label).Return().Once()
+	listBox.On("ShowAll").Return().Once()
+	s.addObjectToAssert(listBox)
+	return listBox
+}
+
+func (s *guiSuite) Test_populateMainWindow_showsAMessageWhenThereAreNoKeys() {
+	defer runningInTheForeground()()
+	a, loop := s.applicationForLoadingTest(fixedKeyAccess())
+	listBox := s.listBoxShowingNoKeysMessage()
+
+	a.populateMainWindow(listBox, nil, nil)
+	loop.run()
+
+	s.Empty(a.ui.keyListEntries)
+}
+
+func (s *guiSuite) Test_populateMainWindow_warnsWhenTheKeysCouldNotBeListed() {
+	defer runningInTheForeground()()
+	ka := &keyAccessMock{}
+	ka.On("AllKeys").Return(nil, errors.New("permission denied")).Once()
+	a, loop := s.applicationForLoadingTest(ka)
+	log, hook := test.NewNullLogger()
+	a.ui.log = log
+	problems := &gtk.MockLabel{}
+	problems.On("SetLabel", "The keys couldn't be listed: permission denied").Return().Once()
+	problems.On("SetTooltipText", "").Return().Once()
+	problems.On("Show").Return().Once()
+	s.addObjectToAssert(problems)
+	a.ui.keyListProblems = problems
+
+	listBox := s.listBoxShowingNoKeysMessage()
+
+	a.populateMainWindow(listBox, nil, nil)
+	loop.run()
+
+	s.Require().Len(hook.AllEntries(), 1)
+	s.Equal("couldn't list the keys", hook.LastEntry().Message)
+}
+
+func (s *guiSuite) Test_refreshMainWindow_replacesTheKeysInTheListAndClosesTheDetails() {
+	listBox := &gtk.MockBox{}
+	oldEntry := &gtk.MockButton{}
+	listBox.On("GetChildren").Return([]gtki.Widget{oldEntry}).Once()
+	listBox.On("Remove", oldEntry).Return().Once()
+	newEntry := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ecdsa", "ECDSA")
+	newEntry.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	newEntry.On("Show").Return().Once()
+	listBox.On("Add", newEntry).Return().Once()
+	s.addObjectToAssert(listBox)
+
+	detailsRevealer := &gtk.MockRevealer{}
+	detailsRevealer.On("SetRevealChild", false).Return().Once()
+	detailsRevealer.On("Hide").Return().Once()
+	s.addObjectToAssert(detailsRevealer)
+
+	defer runningInTheForeground()()
+	a, loop := s.applicationForLoadingTest(fixedKeyAccess(fixedKeyEntry("/home/amnesia/.ssh/id_ecdsa", api.ECDSA)))
+	var visibleKey api.KeyEntry = fixedKeyEntry("/home/amnesia/.ssh/id_rsa", api.RSA)
+	a.ui.currentlyVisibleKeyEntry = &visibleKey
+	sizeChanged := 0
+	a.ui.onWindowSizeChange = func() { sizeChanged++ }
+
+	a.refreshMainWindow(listBox, &gtk.MockBox{}, detailsRevealer)
+	loop.run()
+
+	s.Nil(a.ui.currentlyVisibleKeyEntry)
+	s.Len(a.ui.keyListEntries, 1)
+	s.Equal(2, sizeChanged, "the size changes when the details are closed, and when all keys are found")
+}
+
+func (s *guiSuite) Test_refreshMainWindow_showsTheDetailsOfTheKeyThatWasShownWhenItIsFoundAgain() {
+	listBox := &gtk.MockBox{}
+	listBox.On("GetChildren").Return([]gtki.Widget{}).Once()
+	elsewhere := s.setupBuildingOfKeyEntry("/home/amnesia/certs/ecdsa.pem", "ECDSA")
+	elsewhere.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	elsewhere.On("Show").Return().Once()
+	shownBefore := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ecdsa", "ECDSA")
+	shownBefore.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	shownBefore.On("Show").Return().Once()
+	shownBefore.On("Emit", "clicked", mock.Anything).Return(nil, nil).Once()
+	listBox.On("Add", mock.Anything).Return().Twice()
+	s.addObjectToAssert(listBox)
+
+	detailsRevealer := &gtk.MockRevealer{}
+	detailsRevealer.On("SetRevealChild", false).Return().Once()
+	detailsRevealer.On("Hide").Return().Once()
+
+	sameKeyElsewhere := fixedKeyEntry("/home/amnesia/certs/ecdsa.pem", api.ECDSA).(*keyEntryMock)
+	sameKeyElsewhere.id = "pkix:ecdsa"
+	reloaded := fixedKeyEntry("/home/amnesia/.ssh/id_ecdsa", api.ECDSA).(*keyEntryMock)
+	reloaded.id = "pkix:ecdsa"
+	defer runningInTheForeground()()
+	a, loop := s.applicationForLoadingTest(fixedKeyAccess(sameKeyElsewhere, reloaded))
+	visible := fixedKeyEntry("/home/amnesia/.ssh/id_ecdsa", api.ECDSA).(*keyEntryMock)
+	visible.id = "pkix:ecdsa"
+	var visibleKey api.KeyEntry = visible
+	a.ui.currentlyVisibleKeyEntry = &visibleKey
+
+	a.refreshMainWindow(listBox, &gtk.MockBox{}, detailsRevealer)
+	loop.run()
+
+	s.Empty(a.ui.keyToReselect)
+}
+
+func (s *guiSuite) Test_refreshMainWindow_doesNotShowTheDetailsOfAKeyHiddenByTheSourceFilter() {
+	listBox := &gtk.MockBox{}
+	listBox.On("GetChildren").Return([]gtki.Widget{}).Once()
+	hidden := s.setupBuildingOfKeyEntry("/home/amnesia/.ssh/id_ecdsa", "ECDSA")
+	hidden.On("Connect", "clicked", mock.Anything).Return(nil).Once()
+	hidden.On("Hide").Return().Once()
+	listBox.On("Add", hidden).Return().Once()
+	s.addObjectToAssert(listBox)
+
+	detailsRevealer := &gtk.MockRevealer{}
+	detailsRevealer.On("SetRevealChild", false).Return().Once()
+	detailsRevealer.On("Hide").Return().Once()
+
+	reloaded := fixedKeyEntry("/home/amnesia/.ssh/id_ecdsa", api.ECDSA)
+	ka := &sourcedKeyAccessForTest{
+		sources:  []string{"SSH", "GnuPG"},
+		sourceOf: map[api.KeyEntry]string{reloaded: "SSH"},
+	}
+	ka.On("AllKeys").Return([]api.KeyEntry{reloaded}, nil).Once()
+	defer runningInTheForeground()()
+	a, loop := s.applicationForLoadingTest(ka)
+	a.ui.visibleKeySource = "GnuPG"
+	var visibleKey api.KeyEntry = fixedKeyEntry("/home/amnesia/.ssh/id_ecdsa", api.ECDSA)
+	a.ui.currentlyVisibleKeyEntry = &visibleKey
+
+	a.refreshMainWindow(listBox, &gtk.MockBox{}, detailsRevealer)
+	loop.run()
+
+	s.Nil(a.ui.currentlyVisibleKeyEntry)
+	s.Empty(a.ui.keyToReselect)
+}
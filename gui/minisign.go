@@ -0,0 +1,35 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/minisign"
+)
+
+const signingKeyIDLabel = "signingKeyIDLabel"
+const signingKeyID = "signingKeyID"
+
+// formatSigningKeyID returns the key ID together with the tool the key is used with, since signify
+// doesn't show key IDs and people will compare them with the ones minisign shows
+func formatSigningKeyID(k api.MinisignKeyEntry) string {
+	id := minisign.FormatKeyID(k.MinisignKeyID())
+	if id == "" {
+		return ""
+	}
+
+	tool := "minisign"
+	if k.IsSignifyKey() {
+		tool = "signify"
+	}
+	return fmt.Sprintf("%s (%s)", id, tool)
+}
+
+func (kd *keyDetails) displaySigningKeyID() {
+	k, ok := kd.key.(api.MinisignKeyEntry)
+	if !ok {
+		kd.hideAll(signingKeyIDLabel, signingKeyID)
+		return
+	}
+	kd.setLabelOrHide(formatSigningKeyID(k), signingKeyIDLabel, signingKeyID)
+}
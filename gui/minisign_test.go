@@ -0,0 +1,68 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+type minisignKeyEntryMock struct {
+	keyEntryMock
+	keyID   []byte
+	signify bool
+}
+
+func (k *minisignKeyEntryMock) MinisignKeyID() []byte {
+	return k.keyID
+}
+
+func (k *minisignKeyEntryMock) IsSignifyKey() bool {
+	return k.signify
+}
+
+var minisignKeyIDForTest = []byte{0x04, 0x7a, 0x6f, 0x17, 0xfc, 0xca, 0x6d, 0xe0}
+
+func (s *guiSuite) Test_formatSigningKeyID_includesTheTool() {
+	s.Equal("E06DCAFC176F7A04 (minisign)", formatSigningKeyID(&minisignKeyEntryMock{keyID: minisignKeyIDForTest}))
+	s.Equal("E06DCAFC176F7A04 (signify)", formatSigningKeyID(&minisignKeyEntryMock{keyID: minisignKeyIDForTest, signify: true}))
+	s.Equal("", formatSigningKeyID(&minisignKeyEntryMock{}))
+}
+
+func (s *guiSuite) Test_keyDetails_displaySigningKeyID_showsTheKeyID() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &minisignKeyEntryMock{keyID: minisignKeyIDForTest},
+	}
+
+	label := s.addLabelToGet(builderMock, "signingKeyID")
+	label.On("SetLabel", "E06DCAFC176F7A04 (minisign)").Return().Once()
+	label.On("SetTooltipText", "E06DCAFC176F7A04 (minisign)").Return().Once()
+
+	kd.displaySigningKeyID()
+
+	builderMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displaySigningKeyID_hidesTheRowWhenTheKeyIDIsNotKnown() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &minisignKeyEntryMock{},
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "signingKeyIDLabel", "signingKeyID")
+
+	kd.displaySigningKeyID()
+}
+
+func (s *guiSuite) Test_keyDetails_displaySigningKeyID_hidesTheRowForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_ed25519", api.Ed25519),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "signingKeyIDLabel", "signingKeyID")
+
+	kd.displaySigningKeyID()
+}
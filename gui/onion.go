@@ -0,0 +1,131 @@
+package gui
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/tor"
+)
+
+const onionAddressLabel = "onionAddressLabel"
+const onionAddressBox = "onionAddressBox"
+const onionAddress = "onionAddress"
+const copyOnionAddressButton = "copyOnionAddressButton"
+
+const exportOnionServiceLabel = "exportOnionServiceLabel"
+const exportOnionServiceButton = "exportOnionServiceButton"
+
+func onionAddressFor(k api.KeyEntry) (string, bool) {
+	pk, ok := k.(api.PublicKeyMaterialEntry)
+	if !ok {
+		return "", false
+	}
+
+	address, e := tor.OnionAddress(pk.PublicKey())
+	return address, e == nil
+}
+
+func (kd *keyDetails) displayOnionAddress() {
+	address, ok := onionAddressFor(kd.key)
+	if !ok {
+		kd.hideAll(onionAddressLabel, onionAddressBox)
+		return
+	}
+
+	label := kd.builder.get(onionAddress).(gtki.Label)
+	label.SetLabel(address)
+	label.SetTooltipText(address)
+
+	kd.onClicked(copyOnionAddressButton, func() {
+		copyLabelToClipboard(label)
+	})
+}
+
+func (kd *keyDetails) displayOnionServiceExport() {
+	k, ok := kd.key.(api.UnlockablePrivateKeyEntry)
+	if !ok || kd.key.Algorithm() != api.Ed25519 {
+		kd.hideAll(exportOnionServiceLabel, exportOnionServiceButton)
+		return
+	}
+
+	kd.onClicked(exportOnionServiceButton, func() {
+		kd.ui.exportOnionService(k)
+	})
+}
+
+func (u *ui) exportOnionService(k api.UnlockablePrivateKeyEntry) {
+	unlocked, ok := u.unlockPrivateKey(k)
+	if !ok {
+		return
+	}
+
+	priv, ok := unlocked.PrivateKey().(ed25519.PrivateKey)
+	if !ok {
+		return
+	}
+
+	dir, ok := u.chooseFolder(i18n.Local("Choose the onion service directory"), gtki.FILE_CHOOSER_ACTION_CREATE_FOLDER)
+	if !ok {
+		return
+	}
+
+	if e := tor.WriteServiceDirectory(dir, priv); e != nil {
+		u.log.WithError(e).WithField("directory", dir).Error("couldn't export the onion service keys")
+		u.showMessage(fmt.Sprintf(i18n.Local("The onion service keys couldn't be exported: %s"), e))
+		return
+	}
+
+	u.showMessage(fmt.Sprintf(i18n.Local("The onion service keys were written to %s. Remember that Tor requires the directory to only be accessible by the user running it."), dir))
+}
+
+// importedOnionServiceFileName places the imported public key with the rest of the SSH keys,
+// named after the directory of the onion service
+func importedOnionServiceFileName(home, serviceDir string) string {
+	return filepath.Join(home, ".ssh", fmt.Sprintf("onion_%s.pub", filepath.Base(serviceDir)))
+}
+
+// importOnionService imports the public key of an onion service. Tor only keeps the expanded secret
+// key, which can't be converted back into the seed OpenSSH private keys need
+func (u *ui) importOnionService(ka api.KeyAccess, onImported func()) {
+	importer, ok := ka.(api.PublicKeyImporter)
+	if !ok {
+		u.log.Warn("the key access doesn't support importing keys")
+		return
+	}
+
+	dir, ok := u.chooseFolder(i18n.Local("Choose the onion service directory"), gtki.FILE_CHOOSER_ACTION_SELECT_FOLDER)
+	if !ok {
+		return
+	}
+
+	message, imported := u.importOnionServiceFrom(importer, dir)
+	u.showMessage(message)
+	if imported {
+		onImported()
+	}
+}
+
+func (u *ui) importOnionServiceFrom(importer api.PublicKeyImporter, dir string) (string, bool) {
+	service, e := tor.ReadServiceDirectory(dir)
+	if e != nil {
+		u.log.WithError(e).WithField("directory", dir).Error("couldn't read the onion service")
+		return fmt.Sprintf(i18n.Local("The onion service couldn't be read: %s"), e), false
+	}
+
+	home, _ := os.UserHomeDir()
+	fileName := importedOnionServiceFileName(home, dir)
+	if _, e := importer.ImportPublicKey(service.PublicKey, service.Address, fileName); e != nil {
+		return fmt.Sprintf(i18n.Local("The public key of the onion service couldn't be imported: %s"), e), false
+	}
+
+	message := fmt.Sprintf(i18n.Local("The public key of %s was imported to %s."), service.Address, fileName)
+	if service.SecretKey != nil {
+		message += "\n" + i18n.Local("Tor stores the secret key in an expanded format that can't be converted to an OpenSSH private key, so it was left in the onion service directory.")
+	}
+	return message, true
+}
@@ -0,0 +1,123 @@
+package gui
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"path/filepath"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/tor"
+	"github.com/prashantv/gostub"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
+)
+
+const ed25519KeyForTestOnionAddress = "25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion"
+
+type publicKeyImporterMock struct {
+	keyAccessMock
+}
+
+func (ka *publicKeyImporterMock) ImportPublicKey(pub crypto.PublicKey, userID, fileName string) (api.KeyEntry, error) {
+	returns := ka.Called(pub, userID, fileName)
+	return ret[api.KeyEntry](returns, 0), returns.Error(1)
+}
+
+func (s *guiSuite) Test_keyDetails_displayOnionAddress_showsTheAddressOfAnEd25519Key() {
+	builderMock := &gtk.MockBuilder{}
+	key := &publicKeyMaterialEntryMock{}
+	key.On("PublicKey").Return(ed25519KeyForTest().Public()).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	label := s.addLabelToGet(builderMock, "onionAddress")
+	label.On("SetLabel", ed25519KeyForTestOnionAddress).Return().Once()
+	label.On("SetTooltipText", ed25519KeyForTestOnionAddress).Return().Once()
+	copyHandler := s.expectClickHandler(s.addButtonToGet(builderMock, "copyOnionAddressButton"))
+
+	kd.displayOnionAddress()
+
+	label.On("GrabFocus").Return().Once()
+	label.On("Emit", "copy-clipboard", mock.Anything).Return(nil, nil).Once()
+	(*copyHandler)()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayOnionAddress_hidesTheRowForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	key := &publicKeyMaterialEntryMock{}
+	key.On("PublicKey").Return(&rsa.PublicKey{N: big.NewInt(3233), E: 17}).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "onionAddressLabel", "onionAddressBox")
+
+	kd.displayOnionAddress()
+}
+
+func (s *guiSuite) Test_keyDetails_displayOnionServiceExport_hidesTheRowForKeysThatAreNotEd25519() {
+	builderMock := &gtk.MockBuilder{}
+	key := &unlockablePrivateKeyEntryMock{}
+	key.On("Algorithm").Return(api.RSA).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "exportOnionServiceLabel", "exportOnionServiceButton")
+
+	kd.displayOnionServiceExport()
+}
+
+func (s *guiSuite) Test_importedOnionServiceFileName_isNamedAfterTheServiceDirectory() {
+	s.Equal("/home/amnesia/.ssh/onion_web.pub", importedOnionServiceFileName("/home/amnesia", "/var/lib/tor/web/"))
+}
+
+func (s *guiSuite) Test_importOnionServiceFrom_importsThePublicKey() {
+	home := s.T().TempDir()
+	defer gostub.New().SetEnv("HOME", home).Reset()
+
+	dir := filepath.Join(s.T().TempDir(), "web")
+	s.Require().NoError(tor.WriteServiceDirectory(dir, ed25519KeyForTest()))
+
+	importer := &publicKeyImporterMock{}
+	fileName := filepath.Join(home, ".ssh", "onion_web.pub")
+	importer.On("ImportPublicKey", ed25519KeyForTest().Public(), ed25519KeyForTestOnionAddress, fileName).Return(&keyEntryMock{}, nil).Once()
+	s.addObjectToAssert(importer)
+
+	u := &ui{}
+	message, ok := u.importOnionServiceFrom(importer, dir)
+	s.True(ok)
+	s.Equal("The public key of "+ed25519KeyForTestOnionAddress+" was imported to "+fileName+".\n"+
+		"Tor stores the secret key in an expanded format that can't be converted to an OpenSSH private key, so it was left in the onion service directory.", message)
+}
+
+func (s *guiSuite) Test_importOnionServiceFrom_reportsProblems() {
+	log, hook := test.NewNullLogger()
+	u := &ui{log: log}
+
+	message, ok := u.importOnionServiceFrom(&publicKeyImporterMock{}, s.T().TempDir())
+	s.False(ok)
+	s.Equal("The onion service couldn't be read: the directory doesn't contain onion service keys", message)
+	s.Len(hook.AllEntries(), 1)
+
+	dir := s.T().TempDir()
+	s.Require().NoError(tor.WriteServiceDirectory(dir, ed25519KeyForTest()))
+	importer := &publicKeyImporterMock{}
+	importer.On("ImportPublicKey", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("file exists")).Once()
+
+	message, ok = u.importOnionServiceFrom(importer, dir)
+	s.False(ok)
+	s.Equal("The public key of the onion service couldn't be imported: file exists", message)
+}
@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/otr"
+)
+
+const otrAccountLabel = "otrAccountLabel"
+const otrAccount = "otrAccount"
+const otrFingerprintLabel = "otrFingerprintLabel"
+const otrFingerprint = "otrFingerprint"
+const otrTrustLabel = "otrTrustLabel"
+const otrTrust = "otrTrust"
+
+func formatOTRAccount(k api.OTRKeyEntry) string {
+	if k.OTRProtocol() == "" {
+		return k.OTRAccount()
+	}
+	return fmt.Sprintf("%s (%s)", k.OTRAccount(), k.OTRProtocol())
+}
+
+// formatOTRTrust describes the trust levels libotr clients write. Other trust levels are
+// shown as they are
+func formatOTRTrust(trust string) string {
+	switch trust {
+	case "":
+		return i18n.Local("Not verified")
+	case "verified":
+		return i18n.Local("Verified manually")
+	case "smp":
+		return i18n.Local("Verified with a shared secret")
+	}
+	return trust
+}
+
+func (kd *keyDetails) displayOTRAttributes() {
+	k, ok := kd.key.(api.OTRKeyEntry)
+	if !ok {
+		kd.hideAll(otrAccountLabel, otrAccount, otrFingerprintLabel, otrFingerprint, otrTrustLabel, otrTrust)
+		return
+	}
+
+	kd.setLabelOrHide(formatOTRAccount(k), otrAccountLabel, otrAccount)
+	kd.setLabelOrHide(otr.FormatFingerprint(k.OTRFingerprint()), otrFingerprintLabel, otrFingerprint)
+
+	// The trust is only known for the fingerprints of contacts, since our own keys don't need to be verified
+	if k.KeyType() != api.PublicKeyType {
+		kd.hideAll(otrTrustLabel, otrTrust)
+		return
+	}
+	kd.setLabelOrHide(formatOTRTrust(k.OTRTrust()), otrTrustLabel, otrTrust)
+}
@@ -0,0 +1,117 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+type otrKeyEntryMock struct {
+	keyEntryMock
+	account     string
+	protocol    string
+	fingerprint []byte
+	trust       string
+}
+
+func (k *otrKeyEntryMock) OTRAccount() string {
+	return k.account
+}
+
+func (k *otrKeyEntryMock) OTRProtocol() string {
+	return k.protocol
+}
+
+func (k *otrKeyEntryMock) OTRFingerprint() []byte {
+	return k.fingerprint
+}
+
+func (k *otrKeyEntryMock) OTRTrust() string {
+	return k.trust
+}
+
+var otrFingerprintForTest = []byte{0x44, 0x96, 0x7b, 0x72, 0x19, 0xaa, 0x31, 0x11, 0x64, 0xe7, 0xd3, 0xf0, 0x64, 0x81, 0xfc, 0xe0, 0xd2, 0x3d, 0x41, 0x1c}
+
+const otrFormattedFingerprintForTest = "44967B72 19AA3111 64E7D3F0 6481FCE0 D23D411C"
+
+func (s *guiSuite) Test_formatOTRAccount_includesTheProtocolWhenItIsKnown() {
+	s.Equal("alice@example.org (prpl-jabber)", formatOTRAccount(&otrKeyEntryMock{account: "alice@example.org", protocol: "prpl-jabber"}))
+	s.Equal("alice@example.org", formatOTRAccount(&otrKeyEntryMock{account: "alice@example.org"}))
+}
+
+func (s *guiSuite) Test_formatOTRTrust_describesTheTrustLevelsOfLibotr() {
+	s.Equal("Not verified", formatOTRTrust(""))
+	s.Equal("Verified manually", formatOTRTrust("verified"))
+	s.Equal("Verified with a shared secret", formatOTRTrust("smp"))
+	s.Equal("something else", formatOTRTrust("something else"))
+}
+
+func (s *guiSuite) Test_keyDetails_displayOTRAttributes_showsTheFingerprintAndTrustOfContacts() {
+	builderMock := &gtk.MockBuilder{}
+	key := &otrKeyEntryMock{account: "bob@example.org", protocol: "prpl-jabber", fingerprint: otrFingerprintForTest, trust: "smp"}
+	key.On("KeyType").Return(api.PublicKeyType).Once()
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	account := s.addLabelToGet(builderMock, "otrAccount")
+	account.On("SetLabel", "bob@example.org (prpl-jabber)").Return().Once()
+	account.On("SetTooltipText", "bob@example.org (prpl-jabber)").Return().Once()
+	fp := s.addLabelToGet(builderMock, "otrFingerprint")
+	fp.On("SetLabel", otrFormattedFingerprintForTest).Return().Once()
+	fp.On("SetTooltipText", otrFormattedFingerprintForTest).Return().Once()
+	trust := s.addLabelToGet(builderMock, "otrTrust")
+	trust.On("SetLabel", "Verified with a shared secret").Return().Once()
+	trust.On("SetTooltipText", "Verified with a shared secret").Return().Once()
+
+	kd.displayOTRAttributes()
+
+	key.AssertExpectations(s.T())
+	builderMock.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayOTRAttributes_hidesTheTrustOfOwnKeys() {
+	builderMock := &gtk.MockBuilder{}
+	key := &otrKeyEntryMock{account: "alice@example.org", fingerprint: otrFingerprintForTest}
+	key.On("KeyType").Return(api.PairKeyType).Once()
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	account := s.addLabelToGet(builderMock, "otrAccount")
+	account.On("SetLabel", "alice@example.org").Return().Once()
+	account.On("SetTooltipText", "alice@example.org").Return().Once()
+	fp := s.addLabelToGet(builderMock, "otrFingerprint")
+	fp.On("SetLabel", otrFormattedFingerprintForTest).Return().Once()
+	fp.On("SetTooltipText", otrFormattedFingerprintForTest).Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "otrTrustLabel", "otrTrust")
+
+	kd.displayOTRAttributes()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayOTRAttributes_hidesTheRowsForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_ed25519", api.Ed25519),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "otrAccountLabel", "otrAccount", "otrFingerprintLabel", "otrFingerprint", "otrTrustLabel", "otrTrust")
+
+	kd.displayOTRAttributes()
+}
+
+func (s *guiSuite) Test_formatKeyAlgorithm_leavesOutUnknownKeySizes() {
+	k := &keyEntryMock{}
+	k.On("Algorithm").Return(api.DSA)
+	k.On("Size").Return(0).Once()
+	s.Equal("DSA", formatKeyAlgorithm(k))
+
+	k = &keyEntryMock{}
+	k.On("Algorithm").Return(api.DSA)
+	k.On("Size").Return(1024)
+	s.Equal("DSA (1024 bits)", formatKeyAlgorithm(k))
+}
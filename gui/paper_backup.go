@@ -0,0 +1,132 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/paper"
+)
+
+const paperBackupLabel = "paperBackupLabel"
+const paperBackupBox = "paperBackupBox"
+const exportPaperBackupPDFButton = "exportPaperBackupPDFButton"
+const exportPaperBackupPNGButton = "exportPaperBackupPNGButton"
+
+const restoredKeyFileName = "id_restored"
+
+// paperBackupFor uses the private key file as it is, so keys protected by a passphrase stay protected on paper
+func paperBackupFor(privateKeyLocation string) (*paper.Backup, error) {
+	content, e := os.ReadFile(privateKeyLocation)
+	if e != nil {
+		return nil, e
+	}
+	return &paper.Backup{Name: filepath.Base(privateKeyLocation), Content: content}, nil
+}
+
+func paperBackupFileName(b *paper.Backup, extension string) string {
+	return fmt.Sprintf("%s-paper-backup.%s", b.Name, extension)
+}
+
+func (kd *keyDetails) displayPaperBackup() {
+	k, ok := kd.key.(api.UnlockablePrivateKeyEntry)
+	location := ""
+	if ok {
+		location = firstOrEmpty(k.PrivateKeyLocations())
+	}
+	if location == "" {
+		kd.hideAll(paperBackupLabel, paperBackupBox)
+		return
+	}
+
+	kd.onClicked(exportPaperBackupPDFButton, func() {
+		kd.ui.exportPaperBackup(k, location, "pdf", (*paper.Backup).PDF)
+	})
+	kd.onClicked(exportPaperBackupPNGButton, func() {
+		kd.ui.exportPaperBackup(k, location, "png", (*paper.Backup).PNG)
+	})
+}
+
+func (u *ui) exportPaperBackup(k api.PrivateKeyEntry, location, extension string, render func(*paper.Backup) ([]byte, error)) {
+	b, e := paperBackupFor(location)
+	var content []byte
+	if e == nil {
+		content, e = render(b)
+	}
+	if e != nil {
+		u.log.WithError(e).WithField("file", location).Error("couldn't create the paper backup")
+		u.showMessage(fmt.Sprintf(i18n.Local("The paper backup couldn't be created: %s"), e))
+		return
+	}
+
+	if !k.IsPasswordProtected() {
+		u.showMessage(i18n.Local("This private key isn't protected by a passphrase, so anyone who gets hold of the paper backup can use the key. Consider adding a passphrase before printing it."))
+	}
+
+	u.saveContentToFile(i18n.Local("Save paper backup"), paperBackupFileName(b, extension), content, 0600)
+}
+
+// importedPaperBackupFileName places the restored key with the rest of the SSH keys. The name comes
+// from the backup, so only its last element is used
+func importedPaperBackupFileName(home string, b *paper.Backup) string {
+	name := filepath.Base(b.Name)
+	if b.Name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		name = restoredKeyFileName
+	}
+	return filepath.Join(home, ".ssh", name)
+}
+
+func paperBackupErrorMessage(e error) string {
+	if le, ok := e.(*paper.LineChecksumError); ok {
+		return fmt.Sprintf(i18n.Local("Line %d doesn't match its checksum. Check it for typing or scanning mistakes."), le.Line)
+	}
+	return fmt.Sprintf(i18n.Local("The paper backup couldn't be imported: %s"), e)
+}
+
+func (u *ui) importPaperBackupFrom(importer api.PrivateKeyImporter, text string) (string, error) {
+	b, e := paper.Decode(text)
+	if e != nil {
+		return "", e
+	}
+
+	home, _ := os.UserHomeDir()
+	fileName := importedPaperBackupFileName(home, b)
+	if _, e := importer.ImportPrivateKey(b.Content, fileName); e != nil {
+		return "", e
+	}
+	return fmt.Sprintf(i18n.Local("The key was restored to %s."), fileName), nil
+}
+
+// runPaperBackupDialog runs the dialog until a backup has been imported, or the user cancels
+func (u *ui) runPaperBackupDialog(importer api.PrivateKeyImporter) (string, bool) {
+	d, b := buildObjectFrom[gtki.Dialog](u, "PaperBackupDialog")
+	defer d.Destroy()
+	d.SetTransientFor(u.mainWindow)
+
+	buffer := b.get("paperBackupBuffer").(gtki.TextBuffer)
+	for d.Run() == int(gtki.RESPONSE_OK) {
+		start, end := buffer.GetBounds()
+		message, e := u.importPaperBackupFrom(importer, buffer.GetText(start, end, false))
+		if e == nil {
+			return message, true
+		}
+		b.get("paperBackupError").(gtki.Label).SetLabel(paperBackupErrorMessage(e))
+	}
+	return "", false
+}
+
+func (u *ui) importPaperBackup(ka api.KeyAccess, onImported func()) {
+	importer, ok := ka.(api.PrivateKeyImporter)
+	if !ok {
+		u.log.Warn("the key access doesn't support importing private keys")
+		return
+	}
+
+	if message, ok := u.runPaperBackupDialog(importer); ok {
+		u.showMessage(message)
+		onImported()
+	}
+}
@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/paper"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/mock"
+)
+
+type privateKeyImporterMock struct {
+	keyAccessMock
+}
+
+func (ka *privateKeyImporterMock) ImportPrivateKey(content []byte, fileName string) (api.KeyEntry, error) {
+	returns := ka.Called(content, fileName)
+	return ret[api.KeyEntry](returns, 0), returns.Error(1)
+}
+
+func (s *guiSuite) Test_paperBackupFor_usesThePrivateKeyFileAsItIs() {
+	fileName := filepath.Join(s.T().TempDir(), "id_ed25519")
+	s.Require().NoError(os.WriteFile(fileName, []byte("the private key"), 0600))
+
+	b, e := paperBackupFor(fileName)
+	s.Require().NoError(e)
+	s.Equal(&paper.Backup{Name: "id_ed25519", Content: []byte("the private key")}, b)
+	s.Equal("id_ed25519-paper-backup.pdf", paperBackupFileName(b, "pdf"))
+
+	_, e = paperBackupFor(filepath.Join(s.T().TempDir(), "id_rsa"))
+	s.True(os.IsNotExist(e))
+}
+
+func (s *guiSuite) Test_keyDetails_displayPaperBackup_hidesTheRowForKeysWithoutAPrivateKey() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_rsa.pub", api.RSA),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, paperBackupLabel, paperBackupBox)
+
+	kd.displayPaperBackup()
+}
+
+func (s *guiSuite) Test_keyDetails_displayPaperBackup_connectsTheExportButtons() {
+	builderMock := &gtk.MockBuilder{}
+	key := &unlockablePrivateKeyEntryMock{}
+	key.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.expectClickHandler(s.addButtonToGet(builderMock, exportPaperBackupPDFButton))
+	s.expectClickHandler(s.addButtonToGet(builderMock, exportPaperBackupPNGButton))
+
+	kd.displayPaperBackup()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_importedPaperBackupFileName_onlyUsesTheLastElementOfTheName() {
+	s.Equal("/home/amnesia/.ssh/id_ed25519", importedPaperBackupFileName("/home/amnesia", &paper.Backup{Name: "id_ed25519"}))
+	s.Equal("/home/amnesia/.ssh/passwd", importedPaperBackupFileName("/home/amnesia", &paper.Backup{Name: "../../etc/passwd"}))
+	s.Equal("/home/amnesia/.ssh/id_restored", importedPaperBackupFileName("/home/amnesia", &paper.Backup{}))
+	s.Equal("/home/amnesia/.ssh/id_restored", importedPaperBackupFileName("/home/amnesia", &paper.Backup{Name: ".."}))
+}
+
+func (s *guiSuite) Test_importPaperBackupFrom_importsTheDecodedKey() {
+	home := s.T().TempDir()
+	defer gostub.New().SetEnv("HOME", home).Reset()
+
+	b := &paper.Backup{Name: "id_ed25519", Content: []byte("the private key")}
+	importer := &privateKeyImporterMock{}
+	fileName := filepath.Join(home, ".ssh", "id_ed25519")
+	importer.On("ImportPrivateKey", b.Content, fileName).Return(&keyEntryMock{}, nil).Once()
+	s.addObjectToAssert(importer)
+
+	u := &ui{}
+	message, e := u.importPaperBackupFrom(importer, b.TextBlock())
+	s.NoError(e)
+	s.Equal("The key was restored to "+fileName+".", message)
+}
+
+func (s *guiSuite) Test_importPaperBackupFrom_reportsProblems() {
+	u := &ui{}
+
+	_, e := u.importPaperBackupFrom(&privateKeyImporterMock{}, "nothing to see here")
+	s.Equal(paper.ErrNoBackup, e)
+
+	importer := &privateKeyImporterMock{}
+	importer.On("ImportPrivateKey", []byte("the private key"), mock.AnythingOfType("string")).Return(nil, errors.New("file exists")).Once()
+	_, e = u.importPaperBackupFrom(importer, (&paper.Backup{Content: []byte("the private key")}).TextBlock())
+	s.EqualError(e, "file exists")
+}
+
+func (s *guiSuite) Test_paperBackupErrorMessage_pointsToTheWrongLine() {
+	s.Equal("Line 3 doesn't match its checksum. Check it for typing or scanning mistakes.", paperBackupErrorMessage(&paper.LineChecksumError{Line: 3}))
+	s.Equal("The paper backup couldn't be imported: the text doesn't contain a paper backup", paperBackupErrorMessage(paper.ErrNoBackup))
+}
@@ -0,0 +1,80 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+func (u *ui) askForPassphrase(message string) ([]byte, bool) {
+	d, b := buildObjectFrom[gtki.Dialog](u, "PassphraseDialog")
+	defer d.Destroy()
+
+	b.get("passphraseMessage").(gtki.Label).SetLabel(message)
+	d.SetTransientFor(u.mainWindow)
+
+	if d.Run() != int(gtki.RESPONSE_OK) {
+		return nil, false
+	}
+
+	passphrase, _ := b.get("passphraseEntry").(gtki.Entry).GetText()
+	return []byte(passphrase), true
+}
+
+func firstOrEmpty(l []string) string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+var errCancelled = errors.New("cancelled by the user")
+
+func isPasswordProtected(k api.KeyEntry) bool {
+	pk, ok := k.(api.PrivateKeyEntry)
+	return ok && pk.IsPasswordProtected()
+}
+
+// withPassphrase will ask the user for the passphrase of the key and call the function with it, until
+// the function doesn't fail because of an incorrect passphrase, or the user gives up. If the key is
+// not password protected the function will be called directly with an empty passphrase
+func (u *ui) withPassphrase(k api.KeyEntry, f func(passphrase []byte) error) error {
+	var passphrase []byte
+	message := fmt.Sprintf(i18n.Local("Enter the passphrase for the private key %s:"), firstOrEmpty(k.PrivateKeyLocations()))
+
+	for {
+		if isPasswordProtected(k) {
+			p, ok := u.askForPassphrase(message)
+			if !ok {
+				return errCancelled
+			}
+			passphrase = p
+		}
+
+		e := f(passphrase)
+		if e != api.ErrIncorrectPassphrase || !isPasswordProtected(k) {
+			return e
+		}
+
+		message = i18n.Local("The passphrase was incorrect. Please try again:")
+	}
+}
+
+// unlockPrivateKey will ask the user for the passphrase of the key, until the key is unlocked
+// or the user gives up. If the key is not password protected it will be unlocked directly
+func (u *ui) unlockPrivateKey(k api.UnlockablePrivateKeyEntry) (api.UnlockedPrivateKey, bool) {
+	var unlocked api.UnlockedPrivateKey
+	e := u.withPassphrase(k, func(passphrase []byte) (e error) {
+		unlocked, e = k.Unlock(passphrase)
+		return e
+	})
+
+	if e != nil && e != errCancelled {
+		u.log.WithError(e).Error("couldn't unlock the private key")
+	}
+
+	return unlocked, e == nil
+}
@@ -0,0 +1,137 @@
+package gui
+
+import (
+	"crypto"
+	"errors"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/prashantv/gostub"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
+)
+
+type unlockablePrivateKeyEntryMock struct {
+	keyEntryMock
+}
+
+func (pk *unlockablePrivateKeyEntryMock) IsPasswordProtected() bool {
+	returns := pk.Called()
+	return returns.Bool(0)
+}
+
+func (pk *unlockablePrivateKeyEntryMock) Unlock(passphrase []byte) (api.UnlockedPrivateKey, error) {
+	returns := pk.Called(passphrase)
+	return ret[api.UnlockedPrivateKey](returns, 0), returns.Error(1)
+}
+
+type unlockedPrivateKeyMock struct {
+	mock.Mock
+}
+
+func (k *unlockedPrivateKeyMock) PrivateKey() crypto.PrivateKey {
+	returns := k.Called()
+	return ret[crypto.PrivateKey](returns, 0)
+}
+
+func (k *unlockedPrivateKeyMock) UserID() string {
+	returns := k.Called()
+	return returns.String(0)
+}
+
+func (s *guiSuite) setupPassphraseDialog(message string, response gtki.ResponseType, passphrase string) *gtk.MockDialog {
+	dialog := &gtk.MockDialog{}
+	b := s.setupBuildingOfObject(dialog, "PassphraseDialog")
+	messageLabel := s.addLabelToGet(b, "passphraseMessage")
+	messageLabel.On("SetLabel", message).Return().Once()
+	dialog.On("SetTransientFor", nil).Return().Once()
+	dialog.On("Run").Return(int(response)).Once()
+	dialog.On("Destroy").Return().Once()
+
+	if response == gtki.RESPONSE_OK {
+		entry := &gtk.MockEntry{}
+		b.On("GetObject", "passphraseEntry").Return(entry, nil).Once()
+		entry.On("GetText").Return(passphrase, nil).Once()
+		s.addObjectToAssert(entry)
+	}
+
+	s.addObjectToAssert(dialog)
+	return dialog
+}
+
+func (s *guiSuite) Test_askForPassphrase_returnsThePassphraseEnteredByTheUser() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	s.setupPassphraseDialog("Tell me the secret", gtki.ResponseType(-5), "open sesame")
+
+	u := &ui{gtk: s.gtkMock}
+	p, ok := u.askForPassphrase("Tell me the secret")
+
+	s.True(ok)
+	s.Equal([]byte("open sesame"), p)
+}
+
+func (s *guiSuite) Test_askForPassphrase_returnsFalseIfTheUserCancels() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	s.setupPassphraseDialog("Tell me the secret", gtki.ResponseType(-6), "")
+
+	u := &ui{gtk: s.gtkMock}
+	p, ok := u.askForPassphrase("Tell me the secret")
+
+	s.False(ok)
+	s.Nil(p)
+}
+
+func (s *guiSuite) Test_unlockPrivateKey_unlocksAKeyThatIsNotPasswordProtectedWithoutAsking() {
+	unlocked := &unlockedPrivateKeyMock{}
+	k := &unlockablePrivateKeyEntryMock{}
+	k.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+	k.On("IsPasswordProtected").Return(false)
+	k.On("Unlock", []byte(nil)).Return(unlocked, nil).Once()
+
+	u := &ui{gtk: s.gtkMock}
+	res, ok := u.unlockPrivateKey(k)
+
+	s.True(ok)
+	s.Equal(unlocked, res)
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_unlockPrivateKey_asksAgainWhenThePassphraseIsIncorrect() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	s.setupPassphraseDialog("Enter the passphrase for the private key /home/amnesia/.ssh/id_ed25519:", gtki.ResponseType(-5), "wrong")
+	s.setupPassphraseDialog("The passphrase was incorrect. Please try again:", gtki.ResponseType(-5), "right")
+
+	unlocked := &unlockedPrivateKeyMock{}
+	k := &unlockablePrivateKeyEntryMock{}
+	k.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+	k.On("IsPasswordProtected").Return(true)
+	k.On("Unlock", []byte("wrong")).Return(nil, api.ErrIncorrectPassphrase).Once()
+	k.On("Unlock", []byte("right")).Return(unlocked, nil).Once()
+
+	u := &ui{gtk: s.gtkMock}
+	res, ok := u.unlockPrivateKey(k)
+
+	s.True(ok)
+	s.Equal(unlocked, res)
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_unlockPrivateKey_givesUpOnOtherErrors() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+	s.setupPassphraseDialog("Enter the passphrase for the private key /home/amnesia/.ssh/id_ed25519:", gtki.ResponseType(-5), "something")
+
+	k := &unlockablePrivateKeyEntryMock{}
+	k.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+	k.On("IsPasswordProtected").Return(true)
+	k.On("Unlock", []byte("something")).Return(nil, errors.New("the file is gone")).Once()
+
+	log, hook := test.NewNullLogger()
+	u := &ui{gtk: s.gtkMock, log: log}
+	res, ok := u.unlockPrivateKey(k)
+
+	s.False(ok)
+	s.Nil(res)
+	s.Len(hook.AllEntries(), 1)
+	k.AssertExpectations(s.T())
+}
@@ -0,0 +1,163 @@
+package gui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+	"github.com/digitalautonomy/keymirror/ssh"
+)
+
+const friendlyNameLabel = "friendlyNameLabel"
+const friendlyName = "friendlyName"
+const localKeyIDLabel = "localKeyIDLabel"
+const localKeyID = "localKeyID"
+
+const extractLabel = "extractLabel"
+const extractBox = "extractBox"
+const extractPEMButton = "extractPEMButton"
+const extractOpenSSHButton = "extractOpenSSHButton"
+
+// the algorithms of the private keys that can be extracted in the OpenSSH format
+var openSSHExtractionAlgorithms = []api.Algorithm{api.RSA, api.ECDSA, api.Ed25519}
+
+const defaultExtractedKeyName = "extracted"
+
+// PasswordFor implements the api.PasswordProvider interface, so key accesses can ask for
+// the passwords of files, like PKCS#12 files, while the keys are listed. The keys are listed
+// in the background, so the password is asked for on the main loop
+func (u *ui) PasswordFor(fileName string, incorrect bool) ([]byte, bool) {
+	message := fmt.Sprintf(i18n.Local("Enter the password for %s:"), fileName)
+	if incorrect {
+		message = fmt.Sprintf(i18n.Local("The password for %s was incorrect. Please try again:"), fileName)
+	}
+
+	var password []byte
+	var ok bool
+	answered := make(chan bool)
+	u.onMainLoop(func() {
+		password, ok = u.askForPassphrase(message)
+		close(answered)
+	})
+	<-answered
+	return password, ok
+}
+
+// formatLocalKeyID uses the same format as openssl pkcs12 -info
+func formatLocalKeyID(id []byte) string {
+	parts := []string{}
+	for _, b := range id {
+		parts = append(parts, strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (kd *keyDetails) setLabelOrHide(value, label, valueLabel string) {
+	if value == "" {
+		kd.hideAll(label, valueLabel)
+		return
+	}
+	l := kd.builder.get(valueLabel).(gtki.Label)
+	l.SetLabel(value)
+	l.SetTooltipText(value)
+}
+
+func (kd *keyDetails) displayPKCS12Attributes() {
+	k, ok := kd.key.(api.PKCS12KeyEntry)
+	if !ok {
+		kd.hideAll(friendlyNameLabel, friendlyName, localKeyIDLabel, localKeyID)
+		return
+	}
+
+	kd.setLabelOrHide(k.FriendlyName(), friendlyNameLabel, friendlyName)
+	kd.setLabelOrHide(formatLocalKeyID(k.LocalKeyID()), localKeyIDLabel, localKeyID)
+}
+
+// extractedKeyName suggests a name for the extracted files, based on the friendly name
+// of the key, or on the file it was extracted from
+func extractedKeyName(k api.KeyEntry) string {
+	name := ""
+	if pk, ok := k.(api.PKCS12KeyEntry); ok {
+		name = filepath.Base(pk.FriendlyName())
+	}
+	if name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		location := filepath.Base(firstOrEmpty(k.Locations()))
+		name = strings.TrimSuffix(location, filepath.Ext(location))
+	}
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = defaultExtractedKeyName
+	}
+	return name
+}
+
+func canBeExtractedToOpenSSH(k api.ExtractableKeyEntry) bool {
+	if _, ok := k.ExtractPrivateKey(); !ok {
+		return false
+	}
+	for _, a := range openSSHExtractionAlgorithms {
+		if k.Algorithm() == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (kd *keyDetails) displayExtraction() {
+	k, ok := kd.key.(api.ExtractableKeyEntry)
+	if !ok {
+		kd.hideAll(extractLabel, extractBox)
+		return
+	}
+
+	kd.onClicked(extractPEMButton, func() {
+		kd.ui.extractToPEM(k)
+	})
+
+	if !canBeExtractedToOpenSSH(k) {
+		kd.hideAll(extractOpenSSHButton)
+		return
+	}
+	kd.onClicked(extractOpenSSHButton, func() {
+		kd.ui.extractToOpenSSH(k)
+	})
+}
+
+func (u *ui) extractToPEM(k api.ExtractableKeyEntry) {
+	content, e := k.PEM()
+	if e != nil {
+		u.log.WithError(e).Error("couldn't extract the key")
+		u.showMessage(fmt.Sprintf(i18n.Local("The key couldn't be extracted: %s"), e))
+		return
+	}
+
+	perm := os.FileMode(0644)
+	if _, ok := k.ExtractPrivateKey(); ok {
+		perm = 0600
+	}
+	u.saveContentToFile(i18n.Local("Save as PEM"), extractedKeyName(k)+".pem", content, perm)
+}
+
+func userIDOf(k api.KeyEntry) string {
+	if pk, ok := k.(api.PublicKeyEntry); ok {
+		return pk.UserID()
+	}
+	return ""
+}
+
+func (u *ui) extractToOpenSSH(k api.ExtractableKeyEntry) {
+	priv, _ := k.ExtractPrivateKey()
+	content, e := ssh.PrivateKeyFileContent(priv, userIDOf(k), nil)
+	if e != nil {
+		u.log.WithError(e).Error("couldn't extract the key")
+		u.showMessage(fmt.Sprintf(i18n.Local("The key couldn't be extracted: %s"), e))
+		return
+	}
+
+	u.showMessage(i18n.Local("The extracted private key isn't protected by a passphrase. Consider adding one before using it."))
+	u.saveContentToFile(i18n.Local("Save as OpenSSH"), extractedKeyName(k), content, 0600)
+}
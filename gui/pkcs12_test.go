@@ -0,0 +1,119 @@
+package gui
+
+import (
+	"crypto"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+type pkcs12KeyEntryMock struct {
+	keyEntryMock
+	friendlyName string
+	localKeyID   []byte
+	private      crypto.PrivateKey
+}
+
+func (k *pkcs12KeyEntryMock) FriendlyName() string {
+	return k.friendlyName
+}
+
+func (k *pkcs12KeyEntryMock) LocalKeyID() []byte {
+	return k.localKeyID
+}
+
+func (k *pkcs12KeyEntryMock) PEM() ([]byte, error) {
+	return nil, nil
+}
+
+func (k *pkcs12KeyEntryMock) ExtractPrivateKey() (crypto.PrivateKey, bool) {
+	return k.private, k.private != nil
+}
+
+func (s *guiSuite) Test_formatLocalKeyID_usesTheSameFormatAsOpenSSL() {
+	s.Equal("EC 35 4D 01", formatLocalKeyID([]byte{0xec, 0x35, 0x4d, 0x01}))
+	s.Equal("", formatLocalKeyID(nil))
+}
+
+func (s *guiSuite) Test_extractedKeyName_usesTheFriendlyNameOrTheFileName() {
+	s.Equal("Alice's key", extractedKeyName(&pkcs12KeyEntryMock{friendlyName: "Alice's key"}))
+	s.Equal("passwd", extractedKeyName(&pkcs12KeyEntryMock{friendlyName: "../../etc/passwd"}))
+
+	k := &pkcs12KeyEntryMock{}
+	k.On("Locations").Return([]string{"/home/amnesia/certs/client.p12"}).Once()
+	s.Equal("client", extractedKeyName(k))
+
+	k = &pkcs12KeyEntryMock{friendlyName: ".."}
+	k.On("Locations").Return([]string{}).Once()
+	s.Equal("extracted", extractedKeyName(k))
+}
+
+func (s *guiSuite) Test_canBeExtractedToOpenSSH_needsAPrivateKeyOfAnOpenSSHAlgorithm() {
+	k := &pkcs12KeyEntryMock{private: ed25519KeyForTest()}
+	k.On("Algorithm").Return(api.Ed25519)
+	s.True(canBeExtractedToOpenSSH(k))
+
+	k = &pkcs12KeyEntryMock{private: "a DSA key"}
+	k.On("Algorithm").Return(api.DSA)
+	s.False(canBeExtractedToOpenSSH(k))
+
+	s.False(canBeExtractedToOpenSSH(&pkcs12KeyEntryMock{}))
+}
+
+func (s *guiSuite) Test_keyDetails_displayPKCS12Attributes_showsTheFriendlyNameAndLocalKeyID() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &pkcs12KeyEntryMock{friendlyName: "Alice's key", localKeyID: []byte{0xec, 0x35}},
+	}
+
+	name := s.addLabelToGet(builderMock, "friendlyName")
+	name.On("SetLabel", "Alice's key").Return().Once()
+	name.On("SetTooltipText", "Alice's key").Return().Once()
+	id := s.addLabelToGet(builderMock, "localKeyID")
+	id.On("SetLabel", "EC 35").Return().Once()
+	id.On("SetTooltipText", "EC 35").Return().Once()
+
+	kd.displayPKCS12Attributes()
+}
+
+func (s *guiSuite) Test_keyDetails_displayPKCS12Attributes_hidesMissingAttributes() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &pkcs12KeyEntryMock{localKeyID: []byte{1}},
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "friendlyNameLabel", "friendlyName")
+	id := s.addLabelToGet(builderMock, "localKeyID")
+	id.On("SetLabel", "01").Return().Once()
+	id.On("SetTooltipText", "01").Return().Once()
+
+	kd.displayPKCS12Attributes()
+}
+
+func (s *guiSuite) Test_keyDetails_displayExtraction_onlyOffersOpenSSHForPrivateKeys() {
+	builderMock := &gtk.MockBuilder{}
+	key := &pkcs12KeyEntryMock{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.expectClickHandler(s.addButtonToGet(builderMock, "extractPEMButton"))
+	s.addLabelsThatShouldHide(builderMock, "extractOpenSSHButton")
+
+	kd.displayExtraction()
+}
+
+func (s *guiSuite) Test_keyDetails_displayExtraction_hidesTheRowForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_ed25519", api.Ed25519),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "extractLabel", "extractBox")
+
+	kd.displayExtraction()
+}
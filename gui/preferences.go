@@ -0,0 +1,134 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+// preferences are the choices of the user that are kept between runs of the application
+type preferences struct {
+	FingerprintFormats []fingerprint.Format `json:"fingerprintFormats"`
+}
+
+// defaultPreferences copies the defaults, since decoding the preferences file reuses the slices it decodes into
+func defaultPreferences() *preferences {
+	return &preferences{FingerprintFormats: append([]fingerprint.Format{}, fingerprint.DefaultFormats...)}
+}
+
+func preferencesFileIn(configDir string) string {
+	return filepath.Join(configDir, "keymirror", "preferences.json")
+}
+
+func preferencesFile() string {
+	dir, e := os.UserConfigDir()
+	if e != nil {
+		return ""
+	}
+	return preferencesFileIn(dir)
+}
+
+// loadPreferencesFrom always returns usable preferences, using the defaults for anything
+// that can't be read, so a broken file never stops the application from starting
+func loadPreferencesFrom(fileName string) (*preferences, error) {
+	content, e := os.ReadFile(fileName)
+	if os.IsNotExist(e) {
+		return defaultPreferences(), nil
+	}
+	if e != nil {
+		return defaultPreferences(), e
+	}
+
+	p := defaultPreferences()
+	if e := json.Unmarshal(content, p); e != nil {
+		return defaultPreferences(), e
+	}
+
+	formats := []fingerprint.Format{}
+	for _, f := range p.FingerprintFormats {
+		if f.IsValid() {
+			formats = append(formats, f)
+		}
+	}
+	p.FingerprintFormats = formats
+	return p, nil
+}
+
+func (p *preferences) saveTo(fileName string) error {
+	if e := os.MkdirAll(filepath.Dir(fileName), 0700); e != nil {
+		return e
+	}
+	content, e := json.MarshalIndent(p, "", "  ")
+	if e != nil {
+		return e
+	}
+	return os.WriteFile(fileName, append(content, '\n'), 0600)
+}
+
+func (p *preferences) showsFingerprint(f fingerprint.Format) bool {
+	for _, ff := range p.FingerprintFormats {
+		if f == ff {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *ui) loadPreferences() {
+	u.preferencesFile = preferencesFile()
+	p, e := loadPreferencesFrom(u.preferencesFile)
+	if e != nil {
+		u.log.WithError(e).WithField("file", u.preferencesFile).Warn("couldn't read the preferences, using the defaults")
+	}
+	u.preferences = p
+}
+
+func (u *ui) currentPreferences() *preferences {
+	if u.preferences == nil {
+		return defaultPreferences()
+	}
+	return u.preferences
+}
+
+var fingerprintFormatCheckButtons = map[fingerprint.Format]string{
+	fingerprint.OpenSSHSHA256: "openSSHSHA256FingerprintCheck",
+	fingerprint.OpenSSHMD5:    "md5FingerprintCheck",
+	fingerprint.SHA1Hex:       "sha1FingerprintCheck",
+	fingerprint.SHA256Hex:     "sha256FingerprintCheck",
+}
+
+// editPreferences lets the user change the preferences, saving them if they were accepted
+func (u *ui) editPreferences(onChanged func()) {
+	d, b := buildObjectFrom[gtki.Dialog](u, "PreferencesDialog")
+	defer d.Destroy()
+	d.SetTransientFor(u.mainWindow)
+
+	current := u.currentPreferences()
+	for _, f := range fingerprint.Formats {
+		b.get(fingerprintFormatCheckButtons[f]).(gtki.ToggleButton).SetActive(current.showsFingerprint(f))
+	}
+
+	if d.Run() != int(gtki.RESPONSE_OK) {
+		return
+	}
+
+	p := &preferences{FingerprintFormats: []fingerprint.Format{}}
+	for _, f := range fingerprint.Formats {
+		if b.get(fingerprintFormatCheckButtons[f]).(gtki.ToggleButton).GetActive() {
+			p.FingerprintFormats = append(p.FingerprintFormats, f)
+		}
+	}
+	u.preferences = p
+	d.Hide()
+
+	if e := p.saveTo(u.preferencesFile); e != nil {
+		u.log.WithError(e).WithField("file", u.preferencesFile).Error("couldn't save the preferences")
+		u.showMessage(fmt.Sprintf(i18n.Local("The preferences couldn't be saved: %s"), e))
+	}
+	onChanged()
+}
@@ -0,0 +1,102 @@
+package gui
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/prashantv/gostub"
+)
+
+func (s *guiSuite) Test_preferencesFileIn_isInsideTheKeyMirrorFolder() {
+	s.Equal(filepath.Join("/home/x/.config", "keymirror", "preferences.json"), preferencesFileIn("/home/x/.config"))
+}
+
+func (s *guiSuite) Test_loadPreferencesFrom_returnsTheDefaults_whenThereIsNoFile() {
+	p, e := loadPreferencesFrom(filepath.Join(s.T().TempDir(), "preferences.json"))
+	s.NoError(e)
+	s.Equal(defaultPreferences(), p)
+}
+
+func (s *guiSuite) Test_loadPreferencesFrom_returnsTheDefaults_whenTheFileIsBroken() {
+	fileName := filepath.Join(s.T().TempDir(), "preferences.json")
+	s.NoError(os.WriteFile(fileName, []byte("{not json"), 0600))
+
+	p, e := loadPreferencesFrom(fileName)
+	s.Error(e)
+	s.Equal(defaultPreferences(), p)
+}
+
+func (s *guiSuite) Test_loadPreferencesFrom_ignoresUnknownFingerprintFormats() {
+	fileName := filepath.Join(s.T().TempDir(), "preferences.json")
+	s.NoError(os.WriteFile(fileName, []byte(`{"fingerprintFormats": ["openssh-md5", "sha3"]}`), 0600))
+
+	p, e := loadPreferencesFrom(fileName)
+	s.NoError(e)
+	s.Equal([]fingerprint.Format{fingerprint.OpenSSHMD5}, p.FingerprintFormats)
+}
+
+func (s *guiSuite) Test_preferences_saveTo_writesPreferencesThatCanBeLoaded() {
+	fileName := preferencesFileIn(s.T().TempDir())
+	p := &preferences{FingerprintFormats: []fingerprint.Format{}}
+	s.NoError(p.saveTo(fileName))
+
+	info, e := os.Stat(fileName)
+	s.NoError(e)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	loaded, e := loadPreferencesFrom(fileName)
+	s.NoError(e)
+	s.Equal(p, loaded)
+	s.False(loaded.showsFingerprint(fingerprint.OpenSSHSHA256))
+}
+
+func (s *guiSuite) Test_ui_currentPreferences_usesTheDefaultsUntilPreferencesAreLoaded() {
+	u := &ui{}
+	s.True(u.currentPreferences().showsFingerprint(fingerprint.OpenSSHSHA256))
+	s.False(u.currentPreferences().showsFingerprint(fingerprint.OpenSSHMD5))
+
+	u.preferences = &preferences{FingerprintFormats: []fingerprint.Format{fingerprint.OpenSSHMD5}}
+	s.False(u.currentPreferences().showsFingerprint(fingerprint.OpenSSHSHA256))
+	s.True(u.currentPreferences().showsFingerprint(fingerprint.OpenSSHMD5))
+}
+
+func (s *guiSuite) Test_ui_editPreferences_savesTheChosenFingerprintFormats() {
+	defer gostub.Stub(&gtki.RESPONSE_OK, gtki.ResponseType(-5)).Reset()
+
+	dialog := &gtk.MockDialog{}
+	b := s.setupBuildingOfObject(dialog, "PreferencesDialog")
+	dialog.On("SetTransientFor", nil).Return().Once()
+	dialog.On("Run").Return(-5).Once()
+	dialog.On("Hide").Return().Once()
+	dialog.On("Destroy").Return().Once()
+	s.addObjectToAssert(dialog)
+
+	chosen := map[string]bool{
+		"openSSHSHA256FingerprintCheck": true,
+		"md5FingerprintCheck":           true,
+		"sha1FingerprintCheck":          false,
+		"sha256FingerprintCheck":        false,
+	}
+	for id, active := range chosen {
+		check := &gtk.MockToggleButton{}
+		b.On("GetObject", id).Return(check, nil).Twice()
+		check.On("SetActive", id != "md5FingerprintCheck").Return().Once()
+		check.On("GetActive").Return(active).Once()
+		s.addObjectToAssert(check)
+	}
+
+	fileName := preferencesFileIn(s.T().TempDir())
+	u := &ui{gtk: s.gtkMock, preferencesFile: fileName}
+	changed := false
+	u.editPreferences(func() { changed = true })
+
+	s.True(changed)
+	expected := []fingerprint.Format{fingerprint.OpenSSHSHA256, fingerprint.OpenSSHMD5}
+	s.Equal(expected, u.preferences.FingerprintFormats)
+	loaded, e := loadPreferencesFrom(fileName)
+	s.NoError(e)
+	s.Equal(expected, loaded.FingerprintFormats)
+}
@@ -0,0 +1,36 @@
+package gui
+
+import (
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+)
+
+const randomartLabel = "randomartLabel"
+const randomart = "randomart"
+
+// randomartKeySize shows the size of all keys for algorithms that always have the same key size,
+// the same way ssh-keygen does
+func randomartKeySize(k api.KeyEntry) int {
+	if k.Algorithm().HasKeySize() {
+		return k.Size()
+	}
+	return k.Algorithm().FixedKeySize()
+}
+
+func (kd *keyDetails) displayRandomart() {
+	pk, ok := kd.key.(api.PublicKeyEntry)
+	if !ok {
+		kd.hideAll(randomartLabel, randomart)
+		return
+	}
+
+	art, ok := fingerprint.RandomartOf(pk, strings.ToUpper(pk.Algorithm().Name()), randomartKeySize(pk))
+	if !ok {
+		kd.hideAll(randomartLabel, randomart)
+		return
+	}
+	kd.builder.get(randomart).(gtki.Label).SetLabel(art)
+}
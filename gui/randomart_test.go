@@ -0,0 +1,65 @@
+package gui
+
+import (
+	"strings"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *guiSuite) Test_randomartKeySize_usesTheFixedSizeOfAlgorithmsWithoutKeySizes() {
+	rk := &keyEntryMock{}
+	rk.On("Algorithm").Return(api.RSA).Once()
+	rk.On("Size").Return(3072).Once()
+	s.Equal(3072, randomartKeySize(rk))
+
+	s.Equal(256, randomartKeySize(fixedKeyEntry("/a/key", api.Ed25519)))
+	s.Equal(256, randomartKeySize(fixedKeyEntry("/a/key", api.X25519)))
+}
+
+func (s *guiSuite) Test_keyDetails_displayRandomart_drawsTheRandomartOfThePublicKey() {
+	key := &sshPublicKeyEntryMock{}
+	key.On("Algorithm").Return(api.Ed25519).Maybe()
+	key.On("SSHPublicKey").Return([]byte{}).Once()
+	builderMock := &gtk.MockBuilder{}
+
+	art := s.addLabelToGet(builderMock, "randomart")
+	art.On("SetLabel", mock.MatchedBy(func(l string) bool {
+		return strings.HasPrefix(l, "+--[ED25519 256]--+\n") && strings.HasSuffix(l, "\n+----[SHA256]-----+")
+	})).Return().Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayRandomart()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayRandomart_hidesTheRow_forKeysThatCanNotBeUsedWithSSH() {
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "randomartLabel", "randomart")
+
+	key := &publicKeyEntryMock{}
+	key.On("Algorithm").Return(api.Ed25519).Maybe()
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayRandomart()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayRandomart_hidesTheRow_forPrivateKeys() {
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "randomartLabel", "randomart")
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/a/private/key", api.Ed25519),
+	}
+	kd.displayRandomart()
+}
@@ -0,0 +1,74 @@
+package gui
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const rsaExponentLabel = "rsaExponentLabel"
+const rsaExponent = "rsaExponent"
+const rsaWarning = "rsaWarning"
+
+// usualRSAKeySizeMultiple is the granularity RSA key sizes are almost always chosen in
+const usualRSAKeySizeMultiple = 512
+
+var standardRSAExponent = big.NewInt(65537)
+
+func isUsualRSAKeySize(size int) bool {
+	return size%usualRSAKeySizeMultiple == 0 && api.RSA.IsValidKeySize(size)
+}
+
+// rsaKeyWarnings describes everything that is unusual about the size or the exponent of the key
+func rsaKeyWarnings(size int, exponent *big.Int) []string {
+	warnings := []string{}
+
+	switch api.RSA.Status(size) {
+	case api.BrokenStatus:
+		warnings = append(warnings, fmt.Sprintf(i18n.Local("RSA keys of %d bits are broken and should not be used."), size))
+	case api.DeprecatedStatus:
+		warnings = append(warnings, fmt.Sprintf(i18n.Local("RSA keys of %d bits only give %d bits of security and are not considered secure anymore."), size, api.RSA.SecurityStrength(size)))
+	default:
+		if !isUsualRSAKeySize(size) {
+			warnings = append(warnings, fmt.Sprintf(i18n.Local("%d bits is an unusual size for an RSA key. The key might have been created by unusual software."), size))
+		}
+	}
+
+	switch {
+	case exponent.Cmp(standardRSAExponent) == 0:
+	case exponent.Cmp(big.NewInt(3)) < 0 || exponent.Bit(0) == 0:
+		warnings = append(warnings, i18n.Local("The public exponent is not valid for RSA keys."))
+	case exponent.Cmp(standardRSAExponent) < 0:
+		warnings = append(warnings, i18n.Local("The public exponent is small. Old software used small exponents, but 65537 is the standard value."))
+	default:
+		warnings = append(warnings, i18n.Local("The public exponent is unusual. Almost all RSA keys use 65537."))
+	}
+
+	return warnings
+}
+
+func (kd *keyDetails) displayRSAParameters() {
+	var exponent *big.Int
+	if k, ok := kd.key.(api.RSAKeyEntry); ok {
+		exponent = k.RSAPublicExponent()
+	}
+	if exponent == nil {
+		kd.hideAll(rsaExponentLabel, rsaExponent, rsaWarning)
+		return
+	}
+
+	label := kd.builder.get(rsaExponent).(gtki.Label)
+	label.SetLabel(exponent.String())
+	label.SetTooltipText(exponent.String())
+
+	warnings := rsaKeyWarnings(kd.key.Size(), exponent)
+	if len(warnings) == 0 {
+		kd.hide(rsaWarning)
+		return
+	}
+	kd.builder.get(rsaWarning).(gtki.Label).SetLabel(strings.Join(warnings, "\n"))
+}
@@ -0,0 +1,103 @@
+package gui
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/stretchr/testify/mock"
+)
+
+type rsaKeyEntryMock struct {
+	keyEntryMock
+	exponent *big.Int
+}
+
+func (k *rsaKeyEntryMock) RSAPublicExponent() *big.Int {
+	return k.exponent
+}
+
+func (s *guiSuite) Test_rsaKeyWarnings_acceptsStandardKeys() {
+	s.Empty(rsaKeyWarnings(2048, big.NewInt(65537)))
+	s.Empty(rsaKeyWarnings(3072, big.NewInt(65537)))
+	s.Empty(rsaKeyWarnings(8192, big.NewInt(65537)))
+}
+
+func (s *guiSuite) Test_rsaKeyWarnings_flagsSmallAndNonStandardSizes() {
+	s.Len(rsaKeyWarnings(1024, big.NewInt(65537)), 1)
+	s.Equal([]string{"RSA keys of 1536 bits only give 80 bits of security and are not considered secure anymore."}, rsaKeyWarnings(1536, big.NewInt(65537)))
+	s.Equal([]string{"RSA keys of 768 bits are broken and should not be used."}, rsaKeyWarnings(768, big.NewInt(65537)))
+	s.Contains(rsaKeyWarnings(3071, big.NewInt(65537))[0], "3071 bits is an unusual size")
+	s.Contains(rsaKeyWarnings(32768, big.NewInt(65537))[0], "32768 bits is an unusual size")
+	s.Empty(rsaKeyWarnings(7680, big.NewInt(65537)))
+}
+
+func (s *guiSuite) Test_rsaKeyWarnings_flagsUnusualExponents() {
+	s.Equal([]string{"The public exponent is small. Old software used small exponents, but 65537 is the standard value."}, rsaKeyWarnings(2048, big.NewInt(3)))
+	s.Equal([]string{"The public exponent is unusual. Almost all RSA keys use 65537."}, rsaKeyWarnings(2048, big.NewInt(65539)))
+	s.Equal([]string{"The public exponent is not valid for RSA keys."}, rsaKeyWarnings(2048, big.NewInt(1)))
+	s.Equal([]string{"The public exponent is not valid for RSA keys."}, rsaKeyWarnings(2048, big.NewInt(65536)))
+	s.Len(rsaKeyWarnings(1024, big.NewInt(3)), 2)
+}
+
+func (s *guiSuite) Test_keyDetails_displayRSAParameters_showsTheExponentAndWarnings() {
+	key := &rsaKeyEntryMock{exponent: big.NewInt(3)}
+	key.On("Size").Return(1536).Once()
+	builderMock := &gtk.MockBuilder{}
+
+	exponent := s.addLabelToGet(builderMock, "rsaExponent")
+	exponent.On("SetLabel", "3").Return().Once()
+	exponent.On("SetTooltipText", "3").Return().Once()
+	warning := s.addLabelToGet(builderMock, "rsaWarning")
+	warning.On("SetLabel", mock.MatchedBy(func(l string) bool {
+		return len(strings.Split(l, "\n")) == 2
+	})).Return().Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayRSAParameters()
+
+	key.AssertExpectations(s.T())
+	exponent.AssertExpectations(s.T())
+	warning.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayRSAParameters_hidesTheWarning_forStandardKeys() {
+	key := &rsaKeyEntryMock{exponent: big.NewInt(65537)}
+	key.On("Size").Return(4096).Once()
+	builderMock := &gtk.MockBuilder{}
+
+	exponent := s.addLabelToGet(builderMock, "rsaExponent")
+	exponent.On("SetLabel", "65537").Return().Once()
+	exponent.On("SetTooltipText", "65537").Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "rsaWarning")
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayRSAParameters()
+
+	exponent.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayRSAParameters_hidesTheRows_forKeysWithoutExponent() {
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "rsaExponentLabel", "rsaExponent", "rsaWarning")
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/a/key", api.Ed25519),
+	}
+	kd.displayRSAParameters()
+
+	builderMock = &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "rsaExponentLabel", "rsaExponent", "rsaWarning")
+	kd = &keyDetails{
+		builder: &builder{builderMock},
+		key:     &rsaKeyEntryMock{},
+	}
+	kd.displayRSAParameters()
+}
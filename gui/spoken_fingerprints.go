@@ -0,0 +1,42 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+)
+
+const bubbleBabbleLabel = "bubbleBabbleLabel"
+const bubbleBabble = "bubbleBabble"
+const pgpWordsLabel = "pgpWordsLabel"
+const pgpWords = "pgpWords"
+
+// displaySpokenFingerprint shows a representation of the fingerprint that is meant to be read out loud
+func (kd *keyDetails) displaySpokenFingerprint(rowLabel, row string, f func(pk api.PublicKeyEntry) (string, bool)) {
+	pk, ok := kd.key.(api.PublicKeyEntry)
+	if !ok {
+		kd.hideAll(rowLabel, row)
+		return
+	}
+
+	text, ok := f(pk)
+	if !ok {
+		kd.hideAll(rowLabel, row)
+		return
+	}
+	label := kd.builder.get(row).(gtki.Label)
+	label.SetLabel(text)
+	label.SetTooltipText(text)
+}
+
+func (kd *keyDetails) displayBubbleBabble() {
+	kd.displaySpokenFingerprint(bubbleBabbleLabel, bubbleBabble, func(pk api.PublicKeyEntry) (string, bool) {
+		return fingerprint.BubbleBabbleOf(pk)
+	})
+}
+
+func (kd *keyDetails) displayPGPWords() {
+	kd.displaySpokenFingerprint(pgpWordsLabel, pgpWords, func(pk api.PublicKeyEntry) (string, bool) {
+		return fingerprint.PGPWordsOf(pk)
+	})
+}
@@ -0,0 +1,64 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+func (s *guiSuite) Test_keyDetails_displayBubbleBabble_isTheSameAsSSHKeygen() {
+	builderMock := &gtk.MockBuilder{}
+	label := s.addLabelToGet(builderMock, "bubbleBabble")
+	expected := "xunas-cidad-korip-hakiz-teges-bamik-pumor-pacan-mimoz-ronon-foxax"
+	label.On("SetLabel", expected).Return().Once()
+	label.On("SetTooltipText", expected).Return().Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     ed25519KeyEntryForTest("/a/key.pub"),
+	}
+	kd.displayBubbleBabble()
+}
+
+func (s *guiSuite) Test_keyDetails_displayPGPWords_showsAWordForEveryByteOfTheDigest() {
+	key := &sshPublicKeyEntryMock{}
+	key.On("SSHPublicKey").Return([]byte("hello world")).Once()
+	builderMock := &gtk.MockBuilder{}
+	label := s.addLabelToGet(builderMock, "pgpWords")
+	label.On("SetLabel", helloWorldPGPWords).Return().Once()
+	label.On("SetTooltipText", helloWorldPGPWords).Return().Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayPGPWords()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displaySpokenFingerprints_hideTheRows_forKeysThatCanNotBeUsedWithSSH() {
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "bubbleBabbleLabel", "bubbleBabble", "pgpWordsLabel", "pgpWords")
+
+	key := &publicKeyEntryMock{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+	kd.displayBubbleBabble()
+	kd.displayPGPWords()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displaySpokenFingerprints_hideTheRows_forPrivateKeys() {
+	builderMock := &gtk.MockBuilder{}
+	s.addLabelsThatShouldHide(builderMock, "bubbleBabbleLabel", "bubbleBabble", "pgpWordsLabel", "pgpWords")
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/a/private/key", api.Ed25519),
+	}
+	kd.displayBubbleBabble()
+	kd.displayPGPWords()
+}
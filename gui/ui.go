@@ -1,8 +1,11 @@
 package gui
 
 import (
+	"context"
+
 	"github.com/coyim/gotk3adapter/gdki"
 	"github.com/coyim/gotk3adapter/gioi"
+	"github.com/coyim/gotk3adapter/glibi"
 	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/sirupsen/logrus"
@@ -13,14 +16,26 @@ import (
 // logging and error handling. most other things does NOT belong here.
 
 type ui struct {
-	gtk gtki.Gtk
-	gdk gdki.Gdk
-	gio gioi.Gio
+	gtk  gtki.Gtk
+	gdk  gdki.Gdk
+	gio  gioi.Gio
+	glib glibi.Glib
 
 	// error handler
 	log logrus.Ext1FieldLogger
 
+	mainWindow gtki.Window
+
 	currentlyVisibleKeyEntry       *api.KeyEntry
 	currentlyVisibleKeyEntryButton *gtki.Button
+	keyListEntries                 []keyListEntry
+	keyToReselect                  string
+	visibleKeySource               string
+	keyLoading                     *keyLoadingIndicator
+	keyListProblems                gtki.Label
+	cancelKeyLoading               context.CancelFunc
 	onWindowSizeChange             func()
+
+	preferences     *preferences
+	preferencesFile string
 }
@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+const userIDEntry = "userIDEntry"
+const editUserIDButton = "editUserIDButton"
+const cancelUserIDEditButton = "cancelUserIDEditButton"
+
+// setUserID changes the user ID of the key, asking for the passphrase of the private key if necessary
+func (u *ui) setUserID(k api.UserIDEditableKeyEntry, userID string) bool {
+	e := u.withPassphrase(k, func(passphrase []byte) error {
+		return k.SetUserID(userID, passphrase)
+	})
+
+	if e != nil && e != errCancelled {
+		u.log.WithError(e).Error("couldn't change the user ID of the key")
+	}
+
+	return e == nil
+}
+
+func (kd *keyDetails) displayUserIDEditing(label gtki.Label) {
+	k, ok := kd.key.(api.UserIDEditableKeyEntry)
+	if !ok {
+		kd.hide(editUserIDButton)
+		return
+	}
+
+	entry := kd.builder.get(userIDEntry).(gtki.Entry)
+
+	kd.onClicked(editUserIDButton, func() {
+		entry.SetText(label.GetLabel())
+		kd.hideAll(userIDIdentifier, editUserIDButton)
+		kd.showAll(userIDEntry, cancelUserIDEditButton)
+		entry.GrabFocus()
+	})
+
+	kd.onClicked(cancelUserIDEditButton, kd.stopEditingUserID)
+
+	entry.Connect("activate", func() {
+		userID, _ := entry.GetText()
+		if kd.ui.setUserID(k, userID) {
+			label.SetLabel(userID)
+			kd.stopEditingUserID()
+		}
+	})
+}
+
+func (kd *keyDetails) stopEditingUserID() {
+	kd.hideAll(userIDEntry, cancelUserIDEditButton)
+	kd.showAll(userIDIdentifier, editUserIDButton)
+}
@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"errors"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
+)
+
+type userIDEditablePublicKeyEntryMock struct {
+	publicKeyEntryMock
+}
+
+func (pk *userIDEditablePublicKeyEntryMock) SetUserID(userID string, passphrase []byte) error {
+	returns := pk.Called(userID, passphrase)
+	return returns.Error(0)
+}
+
+func (s *guiSuite) Test_setUserID_changesTheUserIDOfAKeyWithoutPassphrase() {
+	k := &userIDEditablePublicKeyEntryMock{}
+	k.On("PrivateKeyLocations").Return(nil).Once()
+	k.On("SetUserID", "robin@batcave", []byte(nil)).Return(nil).Once()
+
+	u := &ui{gtk: s.gtkMock}
+	s.True(u.setUserID(k, "robin@batcave"))
+	k.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_setUserID_logsFailures() {
+	k := &userIDEditablePublicKeyEntryMock{}
+	k.On("PrivateKeyLocations").Return(nil).Once()
+	k.On("SetUserID", "robin@batcave", []byte(nil)).Return(errors.New("read only file system")).Once()
+
+	log, hook := test.NewNullLogger()
+	u := &ui{gtk: s.gtkMock, log: log}
+	s.False(u.setUserID(k, "robin@batcave"))
+	s.Len(hook.AllEntries(), 1)
+}
+
+func (s *guiSuite) Test_keyDetails_displayUserIDEditing_hidesTheEditButtonForKeysThatCanNotBeEdited() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     &publicKeyEntryMock{},
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "editUserIDButton")
+
+	kd.displayUserIDEditing(&gtk.MockLabel{})
+}
+
+func (s *guiSuite) Test_keyDetails_displayUserIDEditing_editsTheUserIDInline() {
+	builderMock := &gtk.MockBuilder{}
+	k := &userIDEditablePublicKeyEntryMock{}
+	kd := &keyDetails{
+		ui:      &ui{gtk: s.gtkMock},
+		builder: &builder{builderMock},
+		key:     k,
+	}
+
+	label := &gtk.MockLabel{}
+	entry := &gtk.MockEntry{}
+	builderMock.On("GetObject", "userIDEntry").Return(entry, nil).Once()
+	editHandler := s.expectClickHandler(s.addButtonToGet(builderMock, "editUserIDButton"))
+	s.expectClickHandler(s.addButtonToGet(builderMock, "cancelUserIDEditButton"))
+	var activateHandler func()
+	entry.On("Connect", "activate", mock.Anything).Return(nil).Once().Run(func(a mock.Arguments) {
+		activateHandler = a.Get(1).(func())
+	})
+
+	kd.displayUserIDEditing(label)
+
+	label.On("GetLabel").Return("root@localhost").Once()
+	entry.On("SetText", "root@localhost").Return().Once()
+	entry.On("GrabFocus").Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "userID", "editUserIDButton")
+	builderMock.On("GetObject", "userIDEntry").Return(entry, nil).Once()
+	entry.On("Show").Return().Once()
+	s.addLabelToGet(builderMock, "cancelUserIDEditButton").On("Show").Return().Once()
+
+	(*editHandler)()
+
+	entry.On("GetText").Return("robin@batcave", nil).Once()
+	k.On("PrivateKeyLocations").Return(nil).Once()
+	k.On("SetUserID", "robin@batcave", []byte(nil)).Return(nil).Once()
+	label.On("SetLabel", "robin@batcave").Return().Once()
+	builderMock.On("GetObject", "userIDEntry").Return(entry, nil).Once()
+	entry.On("Hide").Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "cancelUserIDEditButton")
+	s.addLabelToGet(builderMock, "userID").On("Show").Return().Once()
+	s.addLabelToGet(builderMock, "editUserIDButton").On("Show").Return().Once()
+
+	activateHandler()
+
+	label.AssertExpectations(s.T())
+	entry.AssertExpectations(s.T())
+	builderMock.AssertExpectations(s.T())
+	k.AssertExpectations(s.T())
+}
@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/i18n"
+)
+
+const fingerprintMatchClass = "fingerprintMatch"
+
+// keyListEntry remembers which button in the key list shows which key
+type keyListEntry struct {
+	entry  api.KeyEntry
+	button gtki.Button
+}
+
+// keysMatchingFingerprint only looks at keys with a public key, since that is what fingerprints are calculated from
+func keysMatchingFingerprint(keys []api.KeyEntry, q *fingerprint.Query) []api.KeyEntry {
+	result := []api.KeyEntry{}
+	for _, k := range keys {
+		if pk, ok := k.(api.PublicKeyEntry); ok && q.Matches(pk) {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+func fingerprintQueryErrorMessage(e error) string {
+	if e == fingerprint.ErrFingerprintTooShort {
+		return fmt.Sprintf(i18n.Local("Enter at least %d characters of the fingerprint."), fingerprint.MinimumQueryLength)
+	}
+	return i18n.Local("This doesn't look like a fingerprint. It can be written in hexadecimal, with or without colons, or start with SHA256: or MD5:.")
+}
+
+// fingerprintVerification returns the keys with the fingerprint, or a description of why there are none
+func fingerprintVerification(keys []api.KeyEntry, text string) ([]api.KeyEntry, string) {
+	q, e := fingerprint.ParseQuery(text)
+	if e != nil {
+		return nil, fingerprintQueryErrorMessage(e)
+	}
+
+	matches := keysMatchingFingerprint(keys, q)
+	if len(matches) == 0 {
+		return nil, i18n.Local("None of the keys has this fingerprint.")
+	}
+	return matches, ""
+}
+
+func describeFingerprintMatches(matches []api.KeyEntry) string {
+	if len(matches) == 1 {
+		return fmt.Sprintf(i18n.Local("The fingerprint belongs to %s."), matches[0].Locations()[0])
+	}
+
+	locations := []string{}
+	for _, m := range matches {
+		locations = append(locations, m.Locations()[0])
+	}
+	return fmt.Sprintf(i18n.Local("The fingerprint matches %d keys. Enter more of it to tell them apart:\n%s"),
+		len(matches), strings.Join(locations, "\n"))
+}
+
+func isOneOf(k api.KeyEntry, keys []api.KeyEntry) bool {
+	for _, kk := range keys {
+		if k.Same(kk) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlightKeys marks the keys in the list, and shows the details of the first one
+func (u *ui) highlightKeys(keys []api.KeyEntry) {
+	shown := false
+	for _, le := range u.keyListEntries {
+		removeClass(le.button, fingerprintMatchClass)
+		if !isOneOf(le.entry, keys) {
+			continue
+		}
+
+		addClass(le.button, fingerprintMatchClass)
+		if !shown && (u.currentlyVisibleKeyEntryButton == nil || *u.currentlyVisibleKeyEntryButton != le.button) {
+			_, _ = le.button.Emit("clicked")
+		}
+		shown = true
+	}
+}
+
+// verifyFingerprint finds the key in the list a fingerprint belongs to, for example when it's read out over the phone
+func (u *ui) verifyFingerprint() {
+	d, b := buildObjectFrom[gtki.Dialog](u, "VerifyFingerprintDialog")
+	defer d.Destroy()
+	d.SetTransientFor(u.mainWindow)
+
+	for d.Run() == int(gtki.RESPONSE_OK) {
+		text, _ := b.get("fingerprintEntry").(gtki.Entry).GetText()
+		matches, problem := fingerprintVerification(u.listedKeys(), text)
+		if problem == "" {
+			d.Hide()
+			u.highlightKeys(matches)
+			u.showMessage(describeFingerprintMatches(matches))
+			return
+		}
+		b.get("fingerprintError").(gtki.Label).SetLabel(problem)
+	}
+}
@@ -0,0 +1,87 @@
+package gui
+
+import (
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/stretchr/testify/mock"
+	xssh "golang.org/x/crypto/ssh"
+)
+
+type digestingKeyEntryMock struct {
+	publicKeyEntryMock
+	content []byte
+}
+
+func (k *digestingKeyEntryMock) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.content)
+}
+
+func (k *digestingKeyEntryMock) SSHPublicKey() []byte {
+	return k.content
+}
+
+func ed25519KeyEntryForTest(location string) *digestingKeyEntryMock {
+	pub, _ := xssh.NewPublicKey(ed25519KeyForTest().Public())
+	k := &digestingKeyEntryMock{content: pub.Marshal()}
+	k.On("Locations").Return([]string{location}).Maybe()
+	return k
+}
+
+func (s *guiSuite) Test_fingerprintVerification_findsTheKeysWithTheFingerprint() {
+	k := ed25519KeyEntryForTest("/home/x/.ssh/id_ed25519.pub")
+	other := &digestingKeyEntryMock{content: []byte("another key")}
+	keys := []api.KeyEntry{fixedKeyEntry("/home/x/.ssh/id_rsa", api.RSA), other, k}
+
+	matches, problem := fingerprintVerification(keys, "SHA256:bbXpuKG6zhzdmnxq256TlqzFBzRl2f6OOg722cYNbU8")
+	s.Empty(problem)
+	s.Equal([]api.KeyEntry{k}, matches)
+
+	matches, problem = fingerprintVerification(keys, "MD5:cf:07:be:9d")
+	s.Empty(problem)
+	s.Equal([]api.KeyEntry{k}, matches)
+	s.Equal("The fingerprint belongs to /home/x/.ssh/id_ed25519.pub.", describeFingerprintMatches(matches))
+}
+
+func (s *guiSuite) Test_fingerprintVerification_reportsWhyNoKeyWasFound() {
+	keys := []api.KeyEntry{ed25519KeyEntryForTest("/home/x/.ssh/id_ed25519.pub")}
+
+	_, problem := fingerprintVerification(keys, "SHA256:AAAAAAAA")
+	s.Equal("None of the keys has this fingerprint.", problem)
+
+	_, problem = fingerprintVerification(keys, "cf0")
+	s.Equal("Enter at least 4 characters of the fingerprint.", problem)
+
+	_, problem = fingerprintVerification(keys, "not a fingerprint!")
+	s.Contains(problem, "doesn't look like a fingerprint")
+}
+
+func (s *guiSuite) Test_describeFingerprintMatches_listsAllTheKeysThatMatch() {
+	matches := []api.KeyEntry{ed25519KeyEntryForTest("/a/key.pub"), ed25519KeyEntryForTest("/another/key.pub")}
+	s.Equal("The fingerprint matches 2 keys. Enter more of it to tell them apart:\n/a/key.pub\n/another/key.pub", describeFingerprintMatches(matches))
+}
+
+func (s *guiSuite) Test_ui_highlightKeys_marksTheMatchesAndShowsTheFirstOne() {
+	match := ed25519KeyEntryForTest("/a/key.pub")
+	matchButton := &gtk.MockButton{}
+	matchStyle := &gtk.MockStyleContext{}
+	matchButton.On("GetStyleContext").Return(matchStyle, nil).Twice()
+	matchStyle.On("RemoveClass", "fingerprintMatch").Return().Once()
+	matchStyle.On("AddClass", "fingerprintMatch").Return().Once()
+	matchButton.On("Emit", "clicked", mock.Anything).Return(nil, nil).Once()
+
+	otherButton := &gtk.MockButton{}
+	otherStyle := &gtk.MockStyleContext{}
+	otherButton.On("GetStyleContext").Return(otherStyle, nil).Once()
+	otherStyle.On("RemoveClass", "fingerprintMatch").Return().Once()
+
+	s.addObjectToAssert(matchButton)
+	s.addObjectToAssert(matchStyle)
+	s.addObjectToAssert(otherButton)
+	s.addObjectToAssert(otherStyle)
+
+	u := &ui{keyListEntries: []keyListEntry{
+		{fixedKeyEntry("/another/key", api.RSA), otherButton},
+		{match, matchButton},
+	}}
+	u.highlightKeys([]api.KeyEntry{match})
+}
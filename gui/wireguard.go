@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"encoding/base64"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/wireguard"
+)
+
+const wireGuardPublicKeyLabel = "wireGuardPublicKeyLabel"
+const wireGuardPublicKeyBox = "wireGuardPublicKeyBox"
+const wireGuardPublicKey = "wireGuardPublicKey"
+const copyWireGuardPublicKeyButton = "copyWireGuardPublicKeyButton"
+
+const wireGuardPrivateKeyLabel = "wireGuardPrivateKeyLabel"
+const wireGuardPrivateKeyBox = "wireGuardPrivateKeyBox"
+const wireGuardPrivateKey = "wireGuardPrivateKey"
+const revealWireGuardPrivateKeyButton = "revealWireGuardPrivateKeyButton"
+const copyWireGuardPrivateKeyButton = "copyWireGuardPrivateKeyButton"
+
+// wireGuardPublicKeyFor works both for keys that already are X25519 keys, and for Ed25519 keys
+func wireGuardPublicKeyFor(k api.KeyEntry) (string, bool) {
+	if xk, ok := k.(api.X25519PublicKeyEntry); ok {
+		return base64.StdEncoding.EncodeToString(xk.X25519PublicKey()), true
+	}
+
+	pk, ok := k.(api.PublicKeyMaterialEntry)
+	if !ok {
+		return "", false
+	}
+
+	pub, e := wireguard.PublicKeyFrom(pk.PublicKey())
+	return pub, e == nil
+}
+
+func (kd *keyDetails) displayWireGuardPublicKey() {
+	pub, ok := wireGuardPublicKeyFor(kd.key)
+	if !ok {
+		kd.hideAll(wireGuardPublicKeyLabel, wireGuardPublicKeyBox)
+		return
+	}
+
+	label := kd.builder.get(wireGuardPublicKey).(gtki.Label)
+	label.SetLabel(pub)
+	label.SetTooltipText(pub)
+
+	kd.onClicked(copyWireGuardPublicKeyButton, func() {
+		copyLabelToClipboard(label)
+	})
+}
+
+func (kd *keyDetails) displayWireGuardPrivateKey() {
+	k, ok := kd.key.(api.UnlockablePrivateKeyEntry)
+	if !ok || kd.key.Algorithm() != api.Ed25519 {
+		kd.hideAll(wireGuardPrivateKeyLabel, wireGuardPrivateKeyBox)
+		return
+	}
+
+	kd.onClicked(revealWireGuardPrivateKeyButton, func() {
+		kd.revealWireGuardPrivateKey(k)
+	})
+}
+
+func (kd *keyDetails) revealWireGuardPrivateKey(k api.UnlockablePrivateKeyEntry) {
+	unlocked, ok := kd.ui.unlockPrivateKey(k)
+	if !ok {
+		return
+	}
+
+	priv, e := wireguard.PrivateKeyFrom(unlocked.PrivateKey())
+	if e != nil {
+		kd.ui.log.WithError(e).Error("couldn't convert the private key to a WireGuard private key")
+		return
+	}
+
+	label := kd.builder.get(wireGuardPrivateKey).(gtki.Label)
+	label.SetLabel(priv)
+
+	kd.hide(revealWireGuardPrivateKeyButton)
+	kd.enable(copyWireGuardPrivateKeyButton)
+	kd.onClicked(copyWireGuardPrivateKeyButton, func() {
+		copyLabelToClipboard(label)
+	})
+}
@@ -0,0 +1,123 @@
+package gui
+
+import (
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/coyim/gotk3mocks/gtk"
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+const ed25519KeyForTestWireGuardPublicKey = "2F4H7CKwrYgVN8L0TWYtGhQ8+DDFespDBdhcepD2ti4="
+const ed25519KeyForTestWireGuardPrivateKey = "MHyDhk8oM8tCei7xwAoBPP3/J2jZgMCjpSDwBpBN6U8="
+
+type x25519PublicKeyEntryMock struct {
+	publicKeyEntryMock
+}
+
+func (k *x25519PublicKeyEntryMock) X25519PublicKey() []byte {
+	returns := k.Called()
+	return ret[[]byte](returns, 0)
+}
+
+func (s *guiSuite) Test_wireGuardPublicKeyFor_supportsX25519AndEd25519Keys() {
+	xk := &x25519PublicKeyEntryMock{}
+	xk.On("X25519PublicKey").Return(make([]byte, 32)).Once()
+	pub, ok := wireGuardPublicKeyFor(xk)
+	s.True(ok)
+	s.Equal("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", pub)
+
+	ek := &publicKeyMaterialEntryMock{}
+	ek.On("PublicKey").Return(ed25519KeyForTest().Public()).Once()
+	pub, ok = wireGuardPublicKeyFor(ek)
+	s.True(ok)
+	s.Equal(ed25519KeyForTestWireGuardPublicKey, pub)
+
+	rk := &publicKeyMaterialEntryMock{}
+	rk.On("PublicKey").Return(&rsa.PublicKey{N: big.NewInt(3233), E: 17}).Once()
+	_, ok = wireGuardPublicKeyFor(rk)
+	s.False(ok)
+
+	_, ok = wireGuardPublicKeyFor(fixedKeyEntry("/home/amnesia/.ssh/id_rsa", api.RSA))
+	s.False(ok)
+}
+
+func (s *guiSuite) Test_keyDetails_displayWireGuardPublicKey_showsThePublicKey() {
+	builderMock := &gtk.MockBuilder{}
+	key := &publicKeyMaterialEntryMock{}
+	key.On("PublicKey").Return(ed25519KeyForTest().Public()).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	label := s.addLabelToGet(builderMock, "wireGuardPublicKey")
+	label.On("SetLabel", ed25519KeyForTestWireGuardPublicKey).Return().Once()
+	label.On("SetTooltipText", ed25519KeyForTestWireGuardPublicKey).Return().Once()
+	s.expectClickHandler(s.addButtonToGet(builderMock, "copyWireGuardPublicKeyButton"))
+
+	kd.displayWireGuardPublicKey()
+
+	key.AssertExpectations(s.T())
+}
+
+func (s *guiSuite) Test_keyDetails_displayWireGuardPublicKey_hidesTheRowForOtherKeys() {
+	builderMock := &gtk.MockBuilder{}
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     fixedKeyEntry("/home/amnesia/.ssh/id_rsa.pub", api.RSA),
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "wireGuardPublicKeyLabel", "wireGuardPublicKeyBox")
+
+	kd.displayWireGuardPublicKey()
+}
+
+func (s *guiSuite) Test_keyDetails_displayWireGuardPrivateKey_hidesTheRowForKeysThatAreNotEd25519() {
+	builderMock := &gtk.MockBuilder{}
+	key := &unlockablePrivateKeyEntryMock{}
+	key.On("Algorithm").Return(api.RSA).Once()
+
+	kd := &keyDetails{
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	s.addLabelsThatShouldHide(builderMock, "wireGuardPrivateKeyLabel", "wireGuardPrivateKeyBox")
+
+	kd.displayWireGuardPrivateKey()
+}
+
+func (s *guiSuite) Test_keyDetails_displayWireGuardPrivateKey_revealsThePrivateKeyWhenAsked() {
+	builderMock := &gtk.MockBuilder{}
+	unlocked := &unlockedPrivateKeyMock{}
+	unlocked.On("PrivateKey").Return(ed25519KeyForTest())
+	key := &unlockablePrivateKeyEntryMock{}
+	key.On("Algorithm").Return(api.Ed25519).Once()
+	key.On("PrivateKeyLocations").Return([]string{"/home/amnesia/.ssh/id_ed25519"}).Once()
+	key.On("IsPasswordProtected").Return(false)
+	key.On("Unlock", []byte(nil)).Return(unlocked, nil).Once()
+
+	kd := &keyDetails{
+		ui:      &ui{gtk: s.gtkMock},
+		builder: &builder{builderMock},
+		key:     key,
+	}
+
+	revealHandler := s.expectClickHandler(s.addButtonToGet(builderMock, "revealWireGuardPrivateKeyButton"))
+
+	kd.displayWireGuardPrivateKey()
+
+	label := s.addLabelToGet(builderMock, "wireGuardPrivateKey")
+	label.On("SetLabel", ed25519KeyForTestWireGuardPrivateKey).Return().Once()
+	s.addLabelsThatShouldHide(builderMock, "revealWireGuardPrivateKeyButton")
+	copyButton := s.addButtonToGet(builderMock, "copyWireGuardPrivateKeyButton")
+	copyButton.On("SetSensitive", true).Return().Once()
+	s.expectClickHandler(s.addButtonToGet(builderMock, "copyWireGuardPrivateKeyButton"))
+
+	(*revealHandler)()
+
+	key.AssertExpectations(s.T())
+	builderMock.AssertExpectations(s.T())
+}
@@ -0,0 +1,57 @@
+// Package testutil has the helpers shared by the tests of the key access providers and the formats they read
+package testutil
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// DecodeHex decodes test data written in hexadecimal
+func DecodeHex(s string) []byte {
+	data, _ := hex.DecodeString(s)
+	return data
+}
+
+// DecodeBase64 decodes test data written in the standard base64 encoding, with padding
+func DecodeBase64(s string) []byte {
+	data, _ := base64.StdEncoding.DecodeString(s)
+	return data
+}
+
+// Uint32 encodes the value as a big endian 32 bit number, the way lengths are written in most binary formats
+func Uint32(v int) []byte {
+	result := make([]byte, 4)
+	binary.BigEndian.PutUint32(result, uint32(v))
+	return result
+}
+
+// NullLogger returns a logger that discards everything, for creating key accesses in tests
+func NullLogger() *logrus.Logger {
+	logger, _ := test.NewNullLogger()
+	return logger
+}
+
+// PasswordProvider gives the passwords in order, and records whether the previous password was incorrect
+// every time it's asked. It returns false when it's asked for more passwords than it has
+type PasswordProvider struct {
+	Passwords []string
+	Asked     []bool
+	// OnAsk is called every time a password is asked for, when it's set
+	OnAsk func()
+}
+
+// PasswordFor implements the api.PasswordProvider interface
+func (p *PasswordProvider) PasswordFor(fileName string, incorrect bool) ([]byte, bool) {
+	p.Asked = append(p.Asked, incorrect)
+	if p.OnAsk != nil {
+		p.OnAsk()
+	}
+	if len(p.Asked) > len(p.Passwords) {
+		return nil, false
+	}
+	return []byte(p.Passwords[len(p.Asked)-1]), true
+}
@@ -1,21 +1,65 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/coyim/gotk3adapter/gdki"
 	"github.com/coyim/gotk3adapter/gioi"
+	"github.com/coyim/gotk3adapter/glibi"
 	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/keymirror/age"
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/gpg"
 	"github.com/digitalautonomy/keymirror/gui"
+	"github.com/digitalautonomy/keymirror/minisign"
+	"github.com/digitalautonomy/keymirror/otr"
 	"github.com/digitalautonomy/keymirror/ssh"
+	"github.com/digitalautonomy/keymirror/wireguard"
+	"github.com/digitalautonomy/keymirror/x509"
 	"github.com/sirupsen/logrus"
 )
 
 var realGTK gtki.Gtk = nil
 var realGDK gdki.Gdk = nil
 var realGIO gioi.Gio = nil
+var realGLib glibi.Glib = nil
 var startGUI = gui.Start
 
+// projectDirectoriesFor returns the working directory when it is inside of the home directory, so the certificates
+// of the project KeyMirror is started from are found. Other directories, like the home directory itself or the
+// root directory used when starting from a desktop environment, would take too long to look through
+func projectDirectoriesFor(workingDirectory, home string) []string {
+	if home == "" || !strings.HasPrefix(workingDirectory, home+string(filepath.Separator)) {
+		return nil
+	}
+	return []string{workingDirectory}
+}
+
+func projectDirectories() []string {
+	wd, _ := os.Getwd()
+	home, _ := os.UserHomeDir()
+	return projectDirectoriesFor(wd, home)
+}
+
+// keySources returns all of the places keys are read from. SSH comes first, since it is
+// the source that generates and imports keys
+func keySources(l logrus.FieldLogger) api.SourcedKeyAccess {
+	projects := projectDirectories()
+	return api.Combine(
+		api.KeySource{Name: "SSH", Access: ssh.Access(l)},
+		api.KeySource{Name: "WireGuard", Access: wireguard.Access(l)},
+		api.KeySource{Name: "GnuPG", Access: gpg.Access(l)},
+		api.KeySource{Name: "X.509", Access: x509.Access(l, projects...)},
+		api.KeySource{Name: "age", Access: age.Access(l, projects...)},
+		api.KeySource{Name: "OTR", Access: otr.Access(l)},
+		api.KeySource{Name: "minisign", Access: minisign.Access(l, projects...)},
+	)
+}
+
 func main() {
 	l := logrus.New()
 	l.Level = logrus.TraceLevel
-	startGUI(realGTK, realGDK, realGIO, l, ssh.Access(l))
+	startGUI(realGTK, realGDK, realGIO, realGLib, l, keySources(l))
 }
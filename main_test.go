@@ -3,9 +3,11 @@ package main
 import (
 	"github.com/coyim/gotk3adapter/gdki"
 	"github.com/coyim/gotk3adapter/gioi"
+	"github.com/coyim/gotk3adapter/glibi"
 	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/coyim/gotk3mocks/gdk"
 	"github.com/coyim/gotk3mocks/gio"
+	"github.com/coyim/gotk3mocks/glib"
 	"github.com/coyim/gotk3mocks/gtk"
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/prashantv/gostub"
@@ -45,15 +47,24 @@ func (s *mainSuite) Test_main_startsTheGuiWithTheRealGTK() {
 	ourGIO := &gio.Mock{}
 	realGIO = ourGIO
 
+	originalGLib := realGLib
+	defer func() {
+		realGLib = originalGLib
+	}()
+	ourGLib := &glib.Mock{}
+	realGLib = ourGLib
+
 	var calledWithGTK gtki.Gtk
 	var calledWithGDK gdki.Gdk
 	var calledWithGIO gioi.Gio
+	var calledWithGLib glibi.Glib
 	var calledWithLog logrus.Ext1FieldLogger
 	var calledWithKeyAccess api.KeyAccess
-	defer gostub.Stub(&startGUI, func(g gtki.Gtk, g2 gdki.Gdk, g3 gioi.Gio, log logrus.Ext1FieldLogger, ka api.KeyAccess) {
+	defer gostub.Stub(&startGUI, func(g gtki.Gtk, g2 gdki.Gdk, g3 gioi.Gio, g4 glibi.Glib, log logrus.Ext1FieldLogger, ka api.KeyAccess) {
 		calledWithGTK = g
 		calledWithGDK = g2
 		calledWithGIO = g3
+		calledWithGLib = g4
 		calledWithLog = log
 		calledWithKeyAccess = ka
 	}).Reset()
@@ -63,7 +74,20 @@ func (s *mainSuite) Test_main_startsTheGuiWithTheRealGTK() {
 	s.Equal(ourGTK, calledWithGTK)
 	s.Equal(ourGDK, calledWithGDK)
 	s.Equal(ourGIO, calledWithGIO)
+	s.Equal(ourGLib, calledWithGLib)
 	s.NotNil(calledWithLog)
 	s.Equal(logrus.TraceLevel, calledWithLog.(*logrus.Logger).Level)
 	s.NotNil(calledWithKeyAccess)
 }
+
+func (s *mainSuite) Test_projectDirectoriesFor_onlyUsesDirectoriesInsideOfTheHomeDirectory() {
+	s.Equal([]string{"/home/alfred/src/batcave"}, projectDirectoriesFor("/home/alfred/src/batcave", "/home/alfred"))
+	s.Nil(projectDirectoriesFor("/home/alfred", "/home/alfred"))
+	s.Nil(projectDirectoriesFor("/home/alfredo/src", "/home/alfred"))
+	s.Nil(projectDirectoriesFor("/", "/home/alfred"))
+	s.Nil(projectDirectoriesFor("/src", ""))
+}
+
+func (s *mainSuite) Test_keySources_listsTheKeysOfAllSources() {
+	s.Equal([]string{"SSH", "WireGuard", "GnuPG", "X.509", "age", "OTR", "minisign"}, keySources(logrus.New()).Sources())
+}
@@ -0,0 +1,159 @@
+package minisign
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+	"github.com/sirupsen/logrus"
+)
+
+// minisign and signify key files are a couple of lines long, so much smaller files are read than for other keys
+const maximumFileSize = 1 << 12
+
+const publicKeyExtension = ".pub"
+
+// the names of the files that can contain keys. Other kinds of keys use the same extensions,
+// so the files that aren't minisign or signify keys are skipped
+var keyFilePatterns = []string{"*" + publicKeyExtension, "*.key", "*.sec"}
+
+// Access returns a key access that lists the minisign and signify keys in the configuration directory
+// of minisign, in the directory OpenBSD keeps its release keys in, and in the given project directories
+func Access(l logrus.FieldLogger, projectDirectories ...string) api.KeyAccess {
+	home, _ := os.UserHomeDir()
+	return &access{
+		log:            l.WithField("component", "minisign"),
+		directories:    append([]string{filepath.Join(home, ".minisign"), "/etc/signify"}, projectDirectories...),
+		knownPasswords: map[string][]byte{},
+		declinedFiles:  map[string]bool{},
+	}
+}
+
+// access remembers the passphrases of encrypted minisign secret keys, and the files the user didn't want
+// to give a passphrase for, so the user isn't asked again every time the keys are listed
+type access struct {
+	log            logrus.Ext1FieldLogger
+	directories    []string
+	passwords      api.PasswordProvider
+	knownPasswords map[string][]byte
+	declinedFiles  map[string]bool
+}
+
+// SetPasswordProvider implements the api.PasswordProviderUser interface
+func (a *access) SetPasswordProvider(p api.PasswordProvider) {
+	a.passwords = p
+}
+
+// keyCollection pairs the public and secret key files with the same key number
+type keyCollection struct {
+	entries     []*keyEntry
+	byKeyNumber map[string]*keyEntry
+}
+
+func (c *keyCollection) entryFor(f *keyFile) *keyEntry {
+	entry, found := c.byKeyNumber[string(f.keyNumber)]
+	if !found {
+		entry = &keyEntry{keyNumber: f.keyNumber, comment: f.comment, signify: f.signify}
+		c.byKeyNumber[string(f.keyNumber)] = entry
+		c.entries = append(c.entries, entry)
+	}
+	if entry.publicKey == nil {
+		entry.publicKey = f.publicKey
+	}
+	return entry
+}
+
+func (c *keyCollection) add(f *keyFile, location string) {
+	entry := c.entryFor(f)
+	if !f.secret {
+		// The comment of the public key is the one shown to the people verifying signatures
+		entry.comment = f.comment
+		entry.publicLocations = append(entry.publicLocations, location)
+		return
+	}
+
+	// Only secret keys tell for sure which tool they belong to
+	entry.signify = f.signify
+	entry.privateLocations = append(entry.privateLocations, location)
+	entry.protected = entry.protected || f.encrypted
+}
+
+// decryptSecretKey tries the passphrase that worked before, and then asks for the passphrase until
+// it is correct. Files the user didn't give a passphrase for are not asked about again
+func (a *access) decryptSecretKey(ctx context.Context, fileName string, f *keyFile) (*keyFile, bool) {
+	if p, ok := a.knownPasswords[fileName]; ok {
+		if decrypted, e := f.decrypt(p); e == nil {
+			return decrypted, true
+		}
+	}
+
+	incorrect := false
+	for a.passwords != nil && !a.declinedFiles[fileName] && ctx.Err() == nil {
+		p, ok := a.passwords.PasswordFor(fileName, incorrect)
+		if !ok {
+			a.declinedFiles[fileName] = true
+			break
+		}
+
+		decrypted, e := f.decrypt(p)
+		if e == nil {
+			a.knownPasswords[fileName] = p
+			return decrypted, true
+		}
+		if e != api.ErrIncorrectPassphrase {
+			a.log.WithError(e).WithField("file", fileName).Warn("couldn't decrypt the minisign secret key")
+			break
+		}
+		incorrect = true
+	}
+	return nil, false
+}
+
+// AllKeys pairs public and secret keys by their key number. The key number of encrypted minisign
+// secret keys is encrypted too, so they are only paired after asking for the passphrase. The ones
+// that stay encrypted are listed on their own
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	c := &keyCollection{byKeyNumber: map[string]*keyEntry{}}
+	locked := []api.KeyEntry{}
+
+	for _, fileName := range files.Matching(a.directories, keyFilePatterns...) {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		content, e := files.ReadSmall(fileName, maximumFileSize)
+		if e != nil {
+			a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the key file")
+			continue
+		}
+
+		f, ok := parseKeyFile(string(content))
+		switch {
+		case !ok:
+			continue
+		case f.keyNumber == nil:
+			if decrypted, ok := a.decryptSecretKey(ctx, fileName, f); ok {
+				c.add(decrypted, fileName)
+			} else {
+				locked = append(locked, &lockedSecretKeyEntry{location: fileName})
+			}
+		default:
+			c.add(f, fileName)
+		}
+	}
+
+	result := []api.KeyEntry{}
+	for _, entry := range c.entries {
+		if entry.publicKey == nil {
+			locked = append(locked, &lockedSecretKeyEntry{location: entry.privateLocations[0], keyNumber: entry.keyNumber, signify: entry.signify})
+			continue
+		}
+		result = append(result, entry)
+	}
+	return append(result, locked...), nil
+}
@@ -0,0 +1,137 @@
+package minisign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *minisignSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(directories ...string) *access {
+	logger := testutil.NullLogger()
+	return &access{log: logger, directories: directories, knownPasswords: map[string][]byte{}, declinedFiles: map[string]bool{}}
+}
+
+func (s *minisignSuite) writeFileForTest(dir, name, content string) string {
+	fileName := filepath.Join(dir, name)
+	s.Require().NoError(os.WriteFile(fileName, []byte(content), 0600))
+	return fileName
+}
+
+func (s *minisignSuite) Test_access_AllKeys_pairsPublicAndSecretKeysByKeyNumber() {
+	config, project := s.T().TempDir(), s.T().TempDir()
+	secretKey := s.writeFileForTest(config, "release.sec", signifySecretKeyForTest)
+	publicKey := s.writeFileForTest(project, "release-2026.pub", signifyPublicKeyForTest)
+	s.writeFileForTest(project, "id_ed25519.pub", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBeJaRIbjtU4jJK5+u7vcyq+0FGMzz/i7ne6BjzlSrT0\n")
+	s.writeFileForTest(project, "release.txt", signifyPublicKeyForTest)
+
+	keys := s.allKeysOf(accessForTest(config, project))
+	s.Require().Len(keys, 1)
+
+	k := keys[0].(api.MinisignKeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{publicKey}, k.PublicKeyLocations())
+	s.Equal([]string{secretKey}, k.PrivateKeyLocations())
+	s.Equal([]string{publicKey, secretKey}, k.Locations())
+	s.Equal(api.Ed25519, k.Algorithm())
+	s.Equal(256, k.Size())
+	s.Equal(signifyKeyIDForTest, FormatKeyID(k.MinisignKeyID()))
+	s.True(k.IsSignifyKey())
+	s.Equal("release-2026 public key", k.(api.PublicKeyEntry).UserID())
+	s.False(k.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.Equal(testutil.DecodeBase64(signifyPublicKeyValueForTest), k.(api.PublicKeyEntry).WithDigestContent(func(b []byte) []byte { return b }))
+	s.Equal(fingerprint.SSHWireFormat(ed25519.PublicKey(testutil.DecodeBase64(signifyPublicKeyValueForTest))), k.(api.SSHPublicKeyEntry).SSHPublicKey())
+}
+
+func (s *minisignSuite) Test_access_AllKeys_decryptsEncryptedMinisignKeysAndPairsThemByKeyNumber() {
+	dir := s.T().TempDir()
+	secretKey := s.writeFileForTest(dir, "signing.key", encryptedSecretKeyForTest("secret"))
+	publicKey := s.writeFileForTest(dir, "minisign.pub", minisignPublicKeyForTest)
+
+	a := accessForTest(dir)
+	p := &testutil.PasswordProvider{Passwords: []string{"wrong", "secret"}}
+	a.SetPasswordProvider(p)
+
+	keys := s.allKeysOf(a)
+	s.Require().Len(keys, 1)
+	s.Equal([]bool{false, true}, p.Asked)
+
+	k := keys[0].(api.MinisignKeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{publicKey}, k.PublicKeyLocations())
+	s.Equal([]string{secretKey}, k.PrivateKeyLocations())
+	s.Equal(minisignKeyIDForTest, FormatKeyID(k.MinisignKeyID()))
+	s.Equal("minisign public key: E06DCAFC176F7A04", k.(api.PublicKeyEntry).UserID())
+	s.False(k.IsSignifyKey())
+	s.True(k.(api.PrivateKeyEntry).IsPasswordProtected())
+
+	s.Len(s.allKeysOf(a), 1)
+	s.Len(p.Asked, 2, "the passphrase that worked is remembered")
+}
+
+func (s *minisignSuite) Test_access_AllKeys_listsEncryptedMinisignKeysThatAreNotDecryptedOnTheirOwn() {
+	dir := s.T().TempDir()
+	secretKey := s.writeFileForTest(dir, "minisign.key", encryptedSecretKeyForTest("secret"))
+	publicKey := s.writeFileForTest(dir, "minisign.pub", minisignPublicKeyForTest)
+
+	a := accessForTest(dir)
+	p := &testutil.PasswordProvider{}
+	a.SetPasswordProvider(p)
+
+	keys := s.allKeysOf(a)
+	s.Require().Len(keys, 2)
+	s.Equal([]bool{false}, p.Asked)
+
+	s.Equal(api.PublicKeyType, keys[0].KeyType())
+	s.Equal([]string{publicKey}, keys[0].Locations())
+
+	locked := keys[1].(api.MinisignKeyEntry)
+	s.Equal(api.PrivateKeyType, locked.KeyType())
+	s.Equal([]string{secretKey}, locked.Locations())
+	s.Nil(locked.MinisignKeyID())
+	s.False(locked.IsSignifyKey())
+	s.True(locked.(api.PrivateKeyEntry).IsPasswordProtected())
+	_, isPublicKeyEntry := locked.(api.PublicKeyEntry)
+	s.False(isPublicKeyEntry)
+
+	s.Len(s.allKeysOf(a), 2)
+	s.Len(p.Asked, 1, "the user isn't asked again after declining")
+	s.Len(s.allKeysOf(accessForTest(dir)), 2, "without a password provider")
+}
+
+func (s *minisignSuite) Test_access_AllKeys_listsEncryptedSignifyKeysWithoutAPublicKeyWithTheirKeyID() {
+	dir := s.T().TempDir()
+	secretKey := s.writeFileForTest(dir, "release.sec", signifyEncryptedSecretKeyForTest)
+	s.writeFileForTest(dir, "minisign.key", minisignSecretKeyForTest)
+
+	keys := s.allKeysOf(accessForTest(dir))
+	s.Require().Len(keys, 2)
+
+	s.Equal(api.PrivateKeyType, keys[0].KeyType())
+	s.Equal(minisignKeyIDForTest, FormatKeyID(keys[0].(api.MinisignKeyEntry).MinisignKeyID()))
+	s.Equal(testutil.DecodeBase64(minisignPublicKeyValueForTest), keys[0].(api.PublicKeyEntry).WithDigestContent(func(b []byte) []byte { return b }))
+
+	locked := keys[1].(api.MinisignKeyEntry)
+	s.Equal([]string{secretKey}, locked.Locations())
+	s.Equal(signifyKeyIDForTest, FormatKeyID(locked.MinisignKeyID()))
+	s.True(locked.IsSignifyKey())
+	s.True(locked.(api.PrivateKeyEntry).IsPasswordProtected())
+}
+
+func (s *minisignSuite) Test_Access_looksInTheMinisignAndSignifyDirectories() {
+	logger := testutil.NullLogger()
+	a := Access(logger, "/src/project").(*access)
+
+	home, _ := os.UserHomeDir()
+	s.Equal([]string{filepath.Join(home, ".minisign"), "/etc/signify", "/src/project"}, a.directories)
+}
@@ -0,0 +1,138 @@
+package minisign
+
+import (
+	"crypto/ed25519"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+)
+
+// keyEntry represents a minisign or signify key, with the files containing its public and secret keys
+type keyEntry struct {
+	keyNumber        []byte
+	publicKey        ed25519.PublicKey
+	comment          string
+	signify          bool
+	publicLocations  []string
+	privateLocations []string
+	protected        bool
+}
+
+// ID implements the api.KeyEntry interface. It's the same as the ID of an SSH key with the same Ed25519 public key
+func (k *keyEntry) ID() string {
+	id, _ := api.PublicKeyID(k.publicKey)
+	return id
+}
+
+func (k *keyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *keyEntry) Locations() []string {
+	return append(append([]string{}, k.publicLocations...), k.privateLocations...)
+}
+
+func (k *keyEntry) PublicKeyLocations() []string {
+	return k.publicLocations
+}
+
+func (k *keyEntry) PrivateKeyLocations() []string {
+	return k.privateLocations
+}
+
+func (k *keyEntry) KeyType() api.KeyType {
+	switch {
+	case len(k.privateLocations) == 0:
+		return api.PublicKeyType
+	case len(k.publicLocations) == 0:
+		return api.PrivateKeyType
+	}
+	return api.PairKeyType
+}
+
+func (k *keyEntry) Size() int {
+	return ed25519.PublicKeySize * 8
+}
+
+func (k *keyEntry) Algorithm() api.Algorithm {
+	return api.Ed25519
+}
+
+// WithDigestContent implements the api.PublicKeyEntry interface. The content is the raw Ed25519 public key
+func (k *keyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.publicKey)
+}
+
+// SSHPublicKey implements the api.SSHPublicKeyEntry interface
+func (k *keyEntry) SSHPublicKey() []byte {
+	return fingerprint.SSHWireFormat(k.publicKey)
+}
+
+// UserID returns the untrusted comment of the key
+func (k *keyEntry) UserID() string {
+	return k.comment
+}
+
+func (k *keyEntry) IsPasswordProtected() bool {
+	return k.protected
+}
+
+func (k *keyEntry) MinisignKeyID() []byte {
+	return k.keyNumber
+}
+
+func (k *keyEntry) IsSignifyKey() bool {
+	return k.signify
+}
+
+// lockedSecretKeyEntry represents an encrypted secret key whose public key wasn't found, or a minisign
+// secret key that wasn't decrypted, so only what is written outside of the encrypted part is known
+type lockedSecretKeyEntry struct {
+	location  string
+	keyNumber []byte
+	signify   bool
+}
+
+func (k *lockedSecretKeyEntry) ID() string {
+	return api.LocationID(k.location)
+}
+
+func (k *lockedSecretKeyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *lockedSecretKeyEntry) Locations() []string {
+	return []string{k.location}
+}
+
+func (k *lockedSecretKeyEntry) PublicKeyLocations() []string {
+	return nil
+}
+
+func (k *lockedSecretKeyEntry) PrivateKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *lockedSecretKeyEntry) KeyType() api.KeyType {
+	return api.PrivateKeyType
+}
+
+func (k *lockedSecretKeyEntry) Size() int {
+	return ed25519.PublicKeySize * 8
+}
+
+func (k *lockedSecretKeyEntry) Algorithm() api.Algorithm {
+	return api.Ed25519
+}
+
+func (k *lockedSecretKeyEntry) IsPasswordProtected() bool {
+	return true
+}
+
+func (k *lockedSecretKeyEntry) MinisignKeyID() []byte {
+	return k.keyNumber
+}
+
+func (k *lockedSecretKeyEntry) IsSignifyKey() bool {
+	return k.signify
+}
@@ -0,0 +1,261 @@
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+// minisign and signify keys are written in two lines, an untrusted comment and the base64 encoding of the key.
+// Public keys are the same in both formats, the algorithm, a random key number and the Ed25519 public key.
+// Secret keys also start with the algorithm, followed by the parameters of the key derivation function used to
+// encrypt them:
+//
+//	signify:  algorithm[2] kdf[2] rounds[4] salt[16] checksum[8] keynum[8] secret[64]
+//	minisign: algorithm[2] kdf[2] checksum algorithm[2] salt[32] opslimit[8] memlimit[8] keynum[8] secret[64] checksum[32]
+//
+// signify encrypts only the secret, while minisign encrypts the key number, the secret and the checksum.
+// minisign derives the key stream with scrypt, from the salt, and libsodium's limits for the number of operations
+// and the memory used, and XORs it with them. The checksum is the BLAKE2b-256 digest of the algorithm, the key
+// number and the secret
+// https://man.openbsd.org/signify
+// https://jedisct1.github.io/minisign/
+
+const untrustedCommentPrefix = "untrusted comment:"
+
+const ed25519Algorithm = "Ed"
+const signifyKDF = "BK"
+const minisignKDF = "Sc"
+const minisignUnencryptedKDF = "\x00\x00"
+const minisignChecksumAlgorithm = "B2"
+
+const keyNumberLength = 8
+
+func init() {
+	api.RegisterWireNames(api.Ed25519, ed25519Algorithm)
+}
+
+const (
+	scryptSaltLength         = 32
+	checksumLength           = blake2b.Size256
+	encryptedKeyNumberLength = keyNumberLength + ed25519.PrivateKeySize + checksumLength
+)
+
+const (
+	publicKeyLength         = 2 + keyNumberLength + ed25519.PublicKeySize
+	signifySecretKeyLength  = 2 + 2 + 4 + 16 + 8 + keyNumberLength + ed25519.PrivateKeySize
+	minisignSecretKeyLength = 2 + 2 + 2 + scryptSaltLength + 8 + 8 + encryptedKeyNumberLength
+)
+
+// maximumScryptCost is the memory minisign uses for deriving the key stream by default, where the
+// parallelization can't be more than one. Files asking for more memory or work are not decrypted, so
+// a crafted key file can't make the key listing use up all the memory or take forever
+const maximumScryptCost = 1 << 30
+
+var errUnsupportedScryptParameters = errors.New("the minisign secret key is encrypted with unsupported scrypt parameters")
+var errInvalidSecretKey = errors.New("the secret key doesn't belong to its public key")
+
+// keyFile is the content of a public or secret key file. The public key is nil for encrypted secret keys,
+// and the key number is also nil for encrypted minisign secret keys, until they are decrypted
+type keyFile struct {
+	comment   string
+	signify   bool
+	secret    bool
+	encrypted bool
+	keyNumber []byte
+	publicKey ed25519.PublicKey
+	scrypt    *scryptEncryption
+}
+
+// scryptEncryption is what is needed to decrypt the key number, the secret and the checksum of an
+// encrypted minisign secret key
+type scryptEncryption struct {
+	salt     []byte
+	opsLimit uint64
+	memLimit uint64
+	data     []byte
+}
+
+func decodeKeyLines(content string) (string, []byte, bool) {
+	lines := strings.SplitN(strings.TrimSpace(content), "\n", 3)
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], untrustedCommentPrefix) {
+		return "", nil, false
+	}
+
+	data, e := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if e != nil || !bytes.HasPrefix(data, []byte(ed25519Algorithm)) {
+		return "", nil, false
+	}
+
+	comment := strings.TrimSpace(strings.TrimPrefix(strings.TrimRight(lines[0], "\r"), untrustedCommentPrefix))
+	return comment, data, true
+}
+
+// publicKeyOfSecret returns the public key that is part of an unencrypted secret key, after
+// checking that it belongs to the seed
+func publicKeyOfSecret(secret []byte) (ed25519.PublicKey, bool) {
+	pub := ed25519.NewKeyFromSeed(secret[:ed25519.SeedSize]).Public().(ed25519.PublicKey)
+	return pub, bytes.Equal(pub, secret[ed25519.SeedSize:])
+}
+
+func parsePublicKey(comment string, data []byte) (*keyFile, bool) {
+	return &keyFile{
+		comment:   comment,
+		signify:   !strings.HasPrefix(comment, "minisign"),
+		keyNumber: data[2 : 2+keyNumberLength],
+		publicKey: data[2+keyNumberLength:],
+	}, true
+}
+
+func parseSignifySecretKey(comment string, data []byte) (*keyFile, bool) {
+	if string(data[2:4]) != signifyKDF {
+		return nil, false
+	}
+
+	result := &keyFile{
+		comment:   comment,
+		signify:   true,
+		secret:    true,
+		encrypted: binary.BigEndian.Uint32(data[4:8]) != 0,
+		keyNumber: data[32:40],
+	}
+	if !result.encrypted {
+		pub, ok := publicKeyOfSecret(data[40:])
+		if !ok {
+			return nil, false
+		}
+		result.publicKey = pub
+	}
+	return result, true
+}
+
+func parseMinisignSecretKey(comment string, data []byte) (*keyFile, bool) {
+	result := &keyFile{comment: comment, secret: true}
+	switch string(data[2:4]) {
+	case minisignKDF:
+		if string(data[4:6]) != minisignChecksumAlgorithm {
+			return nil, false
+		}
+		result.encrypted = true
+		result.scrypt = &scryptEncryption{
+			salt:     data[6:38],
+			opsLimit: binary.LittleEndian.Uint64(data[38:46]),
+			memLimit: binary.LittleEndian.Uint64(data[46:54]),
+			data:     data[54:],
+		}
+		return result, true
+	case minisignUnencryptedKDF:
+		pub, ok := publicKeyOfSecret(data[62:126])
+		if !ok {
+			return nil, false
+		}
+		result.keyNumber = data[54:62]
+		result.publicKey = pub
+		return result, true
+	}
+	return nil, false
+}
+
+// scryptParameters returns the scrypt parameters for the limits of libsodium, calculated the same way
+// crypto_pwhash_scryptsalsa208sha256 calculates them
+func scryptParameters(opsLimit, memLimit uint64) (logN uint, r, p int) {
+	if opsLimit < 32768 {
+		opsLimit = 32768
+	}
+	r = 8
+
+	maxN := memLimit / (uint64(r) * 128)
+	if opsLimit < memLimit/32 {
+		maxN = opsLimit / (uint64(r) * 4)
+	}
+	for logN = 1; logN < 63; logN++ {
+		if uint64(1)<<logN > maxN/2 {
+			break
+		}
+	}
+
+	if opsLimit < memLimit/32 {
+		return logN, r, 1
+	}
+	maxRP := (opsLimit / 4) / (uint64(1) << logN)
+	if maxRP > 0x3fffffff {
+		maxRP = 0x3fffffff
+	}
+	return logN, r, int(maxRP) / r
+}
+
+// checksumOf calculates the checksum minisign writes in secret keys
+func checksumOf(keyNumber, secret []byte) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte(ed25519Algorithm))
+	h.Write(keyNumber)
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+// decrypt returns the key file with the key number and the public key of the secret key. It returns
+// api.ErrIncorrectPassphrase when the checksum doesn't match after decrypting it
+func (f *keyFile) decrypt(passphrase []byte) (*keyFile, error) {
+	logN, r, p := scryptParameters(f.scrypt.opsLimit, f.scrypt.memLimit)
+	if logN > 30 || p < 1 || uint64(128*r*p)<<logN > maximumScryptCost {
+		return nil, errUnsupportedScryptParameters
+	}
+
+	stream, e := scrypt.Key(passphrase, f.scrypt.salt, 1<<logN, r, p, encryptedKeyNumberLength)
+	if e != nil {
+		return nil, e
+	}
+	plain := make([]byte, encryptedKeyNumberLength)
+	for i := range plain {
+		plain[i] = f.scrypt.data[i] ^ stream[i]
+	}
+
+	keyNumber := plain[:keyNumberLength]
+	secret := plain[keyNumberLength : keyNumberLength+ed25519.PrivateKeySize]
+	if subtle.ConstantTimeCompare(checksumOf(keyNumber, secret), plain[keyNumberLength+ed25519.PrivateKeySize:]) != 1 {
+		return nil, api.ErrIncorrectPassphrase
+	}
+
+	pub, ok := publicKeyOfSecret(secret)
+	if !ok {
+		return nil, errInvalidSecretKey
+	}
+	return &keyFile{comment: f.comment, secret: true, encrypted: true, keyNumber: keyNumber, publicKey: pub}, nil
+}
+
+// parseKeyFile returns the key in the content, or false if it isn't a minisign or signify key file.
+// The formats of secret keys are told apart by their length
+func parseKeyFile(content string) (*keyFile, bool) {
+	comment, data, ok := decodeKeyLines(content)
+	if !ok {
+		return nil, false
+	}
+
+	switch len(data) {
+	case publicKeyLength:
+		return parsePublicKey(comment, data)
+	case signifySecretKeyLength:
+		return parseSignifySecretKey(comment, data)
+	case minisignSecretKeyLength:
+		return parseMinisignSecretKey(comment, data)
+	}
+	return nil, false
+}
+
+// FormatKeyID returns the key ID the way minisign shows it, as the hexadecimal
+// representation of the key number read as a little endian number
+func FormatKeyID(id []byte) string {
+	if len(id) != keyNumberLength {
+		return ""
+	}
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id))
+}
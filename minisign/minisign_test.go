@@ -0,0 +1,85 @@
+package minisign
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/scrypt"
+)
+
+type minisignSuite struct {
+	suite.Suite
+}
+
+func TestMinisignSuite(t *testing.T) {
+	suite.Run(t, new(minisignSuite))
+}
+
+// the minisign keys were generated with aead.dev/minisign, which writes the same format as minisign.
+// Like minisign, it writes the same comment for unencrypted secret keys. The encrypted secret key uses the passphrase "secret"
+const minisignPublicKeyForTest = `untrusted comment: minisign public key: E06DCAFC176F7A04
+RWQEem8X/Mpt4OyMHNoEAaiLh1LCLmUWUCpYxorhFYJFGpsNTwQSRLHA
+`
+
+const minisignSecretKeyForTest = `untrusted comment: minisign encrypted secret key
+RWQAAEIyAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABHpvF/zKbeDgjMznKLfIBBvQK1Zi0YqDuPYDIUF2iuxAEiX7Cv57k+yMHNoEAaiLh1LCLmUWUCpYxorhFYJFGpsNTwQSRLHAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+`
+
+const minisignEncryptedSecretKeyForTest = `untrusted comment: minisign encrypted secret key
+RWRTY0IyBoDLRkU5Qvj4U3/rH6HzHf5sni3uoYBscqXanrnXRM4AAAACAAAAAAAAAEAAAAAAF/0XeGkRd6fRQ6Xl5IWTXC/kCCTi5A64MOp2TWQnl0g+ZoZ5dVR7z2ReSpM5E66/b9N34J2/IWhD1b1FUoO6iTsLstKbkLvTY5DDF0lotxgGfwkfq6QKdol5ohxmFcKPCKExci3jWGc=
+`
+
+const minisignKeyIDForTest = "E06DCAFC176F7A04"
+
+const minisignPublicKeyValueForTest = "7Iwc2gQBqIuHUsIuZRZQKljGiuEVgkUamw1PBBJEscA="
+
+// the signify keys are written the way signify -G writes them, with the comment "release-2026". The
+// encrypted secret key uses the passphrase "secret" and 42 rounds
+const signifyPublicKeyForTest = `untrusted comment: release-2026 public key
+RWRxvQEy7jbRLe87KQn6wPUDwPJtUECizEieOLfdQaFDLFbtUk8g0y9l
+`
+
+const signifySecretKeyForTest = `untrusted comment: release-2026 secret key
+RWRCSwAAAAAby0Ztb9Y3qTc/9unnjWmFtI/7F5MjcThxvQEy7jbRLdbxqo8daXDCbkiiQS9ZZB4N8BDdrYvYBs2QqMDpPfP57zspCfrA9QPA8m1QQKLMSJ44t91BoUMsVu1STyDTL2U=
+`
+
+const signifyEncryptedSecretKeyForTest = `untrusted comment: release-2026 secret key
+RWRCSwAAACpQVQS9QkW7ZjN+mjCAujNwtI/7F5MjcThxvQEy7jbRLbRr4s+sWSrE26FqV9ry7iAO+vRFxoI/w4Ev8lbPZdmC681QqUvZBlJbu/eRjhz7x/rtIQbY9a2uip7LUnY9FHQ=
+`
+
+const signifyKeyIDForTest = "2DD136EE3201BD71"
+
+const signifyPublicKeyValueForTest = "7zspCfrA9QPA8m1QQKLMSJ44t91BoUMsVu1STyDTL2U="
+
+func base64ForTest(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// the smallest limits libsodium allows, which give scrypt parameters that are much faster to
+// calculate than the ones minisign uses
+const (
+	opsLimitForTest = 32768
+	memLimitForTest = 1 << 24
+)
+
+// encryptedSecretKeyForTest encrypts the unencrypted minisign secret key with the passphrase the way
+// minisign does, but with the limits for tests
+func encryptedSecretKeyForTest(passphrase string) string {
+	_, data, _ := decodeKeyLines(minisignSecretKeyForTest)
+	data = append([]byte{}, data...)
+
+	plain := append(append([]byte{}, data[54:126]...), checksumOf(data[54:62], data[62:126])...)
+	copy(data[2:6], minisignKDF+minisignChecksumAlgorithm)
+	salt := data[6:38]
+	binary.LittleEndian.PutUint64(data[38:46], opsLimitForTest)
+	binary.LittleEndian.PutUint64(data[46:54], memLimitForTest)
+
+	logN, r, p := scryptParameters(opsLimitForTest, memLimitForTest)
+	stream, _ := scrypt.Key([]byte(passphrase), salt, 1<<logN, r, p, encryptedKeyNumberLength)
+	for i := range plain {
+		data[54+i] = plain[i] ^ stream[i]
+	}
+	return "untrusted comment: minisign encrypted secret key\n" + base64ForTest(data) + "\n"
+}
@@ -0,0 +1,48 @@
+package openpgp
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// ASCII armor is described in RFC 4880, section 6
+// https://www.rfc-editor.org/rfc/rfc4880#section-6
+
+const publicKeyBlock = "PGP PUBLIC KEY BLOCK"
+const privateKeyBlock = "PGP PRIVATE KEY BLOCK"
+
+const armorLineLength = 64
+
+const crc24Init = 0xB704CE
+const crc24Poly = 0x1864CFB
+
+func crc24(data []byte) uint32 {
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+func armor(blockType string, data []byte) []byte {
+	var result strings.Builder
+	result.WriteString("-----BEGIN " + blockType + "-----\n\n")
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > armorLineLength {
+		result.WriteString(encoded[:armorLineLength] + "\n")
+		encoded = encoded[armorLineLength:]
+	}
+	result.WriteString(encoded + "\n")
+
+	crc := crc24(data)
+	result.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	result.WriteString("-----END " + blockType + "-----\n")
+	return []byte(result.String())
+}
@@ -0,0 +1,104 @@
+package openpgp
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+// The curves and their OIDs are described in RFC 6637, section 11, and RFC 9580, section 9.2
+// https://www.rfc-editor.org/rfc/rfc9580#section-9.2
+
+const curve25519KeySize = 256
+
+// the prefix used to mark Curve25519 points as being in native format
+const nativePointPrefix = 0x40
+
+type curve struct {
+	name string
+	oid  []byte
+	size int
+	// ellipticCurve is set for the curves keys can be created with
+	ellipticCurve elliptic.Curve
+}
+
+var ed25519Curve = &curve{name: "Ed25519", oid: []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0xDA, 0x47, 0x0F, 0x01}, size: curve25519KeySize}
+var curve25519Curve = &curve{name: "Curve25519", oid: []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0x97, 0x55, 0x01, 0x05, 0x01}, size: curve25519KeySize}
+
+var curves = []*curve{
+	{name: "NIST P-256", oid: []byte{0x2A, 0x86, 0x48, 0xCE, 0x3D, 0x03, 0x01, 0x07}, size: 256, ellipticCurve: elliptic.P256()},
+	{name: "NIST P-384", oid: []byte{0x2B, 0x81, 0x04, 0x00, 0x22}, size: 384, ellipticCurve: elliptic.P384()},
+	{name: "NIST P-521", oid: []byte{0x2B, 0x81, 0x04, 0x00, 0x23}, size: 521, ellipticCurve: elliptic.P521()},
+	{name: "brainpoolP256r1", oid: []byte{0x2B, 0x24, 0x03, 0x03, 0x02, 0x08, 0x01, 0x01, 0x07}, size: 256},
+	{name: "brainpoolP384r1", oid: []byte{0x2B, 0x24, 0x03, 0x03, 0x02, 0x08, 0x01, 0x01, 0x0B}, size: 384},
+	{name: "brainpoolP512r1", oid: []byte{0x2B, 0x24, 0x03, 0x03, 0x02, 0x08, 0x01, 0x01, 0x0D}, size: 512},
+	{name: "secp256k1", oid: []byte{0x2B, 0x81, 0x04, 0x00, 0x0A}, size: 256},
+	ed25519Curve,
+	curve25519Curve,
+}
+
+func findCurve(matches func(*curve) bool) (*curve, bool) {
+	for _, c := range curves {
+		if matches(c) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func curveWithOID(oid []byte) (*curve, bool) {
+	return findCurve(func(c *curve) bool { return bytes.Equal(c.oid, oid) })
+}
+
+func curveFor(ec elliptic.Curve) (*curve, bool) {
+	return findCurve(func(c *curve) bool { return c.ellipticCurve != nil && c.ellipticCurve == ec })
+}
+
+// The OIDs of Ed25519 and Curve25519 identify the algorithm of the keys on them, since Curve25519 keys are
+// only used for key agreement and Ed25519 keys for signatures. Keys on the other curves can be used for both
+func init() {
+	api.RegisterOIDs(api.Ed25519, ed25519Curve.dottedOID())
+	api.RegisterOIDs(api.X25519, curve25519Curve.dottedOID())
+}
+
+func (c *curve) dottedOID() string {
+	var oid asn1.ObjectIdentifier
+	if _, e := asn1.Unmarshal(append([]byte{asn1.TagOID, byte(len(c.oid))}, c.oid...), &oid); e != nil {
+		return ""
+	}
+	return oid.String()
+}
+
+// algorithm returns the algorithm used with keys on the curve. All keys of algorithms identified by
+// the OID of the curve have the same size
+func (c *curve) algorithm(keyAgreement bool) (api.Algorithm, int) {
+	if a, ok := api.AlgorithmWithOID(c.dottedOID()); ok {
+		return a, 0
+	}
+	if keyAgreement {
+		return api.ECDH, c.size
+	}
+	return api.ECDSA, c.size
+}
+
+// CurveAlgorithm returns the algorithm and size of keys on the curve with the name, like "NIST P-256". The
+// keys used for key agreement are ECDH keys, and the other keys are ECDSA keys, except on Curve25519
+func CurveAlgorithm(name string, keyAgreement bool) (api.Algorithm, int, bool) {
+	c, ok := findCurve(func(c *curve) bool { return c.name == name })
+	if !ok {
+		return nil, 0, false
+	}
+	a, size := c.algorithm(keyAgreement)
+	return a, size, true
+}
+
+// ComparablePublicValue returns the public value of a key in a form that doesn't depend on how it's
+// encoded, so it can be compared with the public values stored elsewhere, like by gpg-agent
+func ComparablePublicValue(v []byte) string {
+	if len(v) == curve25519KeySize/8+1 && v[0] == nativePointPrefix {
+		v = v[1:]
+	}
+	return string(bytes.TrimLeft(v, "\x00"))
+}
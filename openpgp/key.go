@@ -0,0 +1,215 @@
+package openpgp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Public key packets are described in RFC 4880, section 5.5.2, and the
+// elliptic curve variants in RFC 6637 and draft-ietf-openpgp-rfc4880bis
+// https://www.rfc-editor.org/rfc/rfc4880#section-5.5.2
+
+const (
+	v4KeyVersion = 4
+	v5KeyVersion = 5
+	v6KeyVersion = 6
+)
+
+const (
+	rsaPublicKeyAlgorithm            = 1
+	rsaEncryptOnlyPublicKeyAlgorithm = 2
+	rsaSignOnlyPublicKeyAlgorithm    = 3
+	elGamalPublicKeyAlgorithm        = 16
+	dsaPublicKeyAlgorithm            = 17
+	ecdhPublicKeyAlgorithm           = 18
+	ecdsaPublicKeyAlgorithm          = 19
+	eddsaPublicKeyAlgorithm          = 22
+	x25519PublicKeyAlgorithm         = 25
+	ed25519PublicKeyAlgorithm        = 27
+)
+
+// ErrUnsupportedKey is returned when trying to create OpenPGP key material from a key type that is not supported
+var ErrUnsupportedKey = errors.New("only RSA, ECDSA and Ed25519 keys can be converted to OpenPGP keys")
+
+// ErrNoPrivateKey is returned when trying to create secret key material from a key that only has the public part
+var ErrNoPrivateKey = errors.New("the secret key can only be created from a private key")
+
+func oidField(oid []byte) []byte {
+	return append([]byte{byte(len(oid))}, oid...)
+}
+
+// Key contains everything needed to create OpenPGP key material from an existing key.
+// Since the fingerprint of an OpenPGP key depends on its creation time, using the same
+// creation time will always result in the same fingerprint
+type Key struct {
+	public    crypto.PublicKey
+	private   crypto.Signer
+	userID    string
+	created   time.Time
+	algorithm byte
+	material  []byte
+}
+
+func publicKeyMaterial(pub crypto.PublicKey) (algorithm byte, material []byte, e error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return eddsaPublicKeyAlgorithm, concat(oidField(ed25519Curve.oid), mpiFromBytes(append([]byte{nativePointPrefix}, k...))), nil
+	case *rsa.PublicKey:
+		return rsaPublicKeyAlgorithm, concat(mpi(k.N), mpi(big.NewInt(int64(k.E)))), nil
+	case *ecdsa.PublicKey:
+		c, ok := curveFor(k.Curve)
+		if !ok {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return ecdsaPublicKeyAlgorithm, concat(oidField(c.oid), mpiFromBytes(elliptic.Marshal(k.Curve, k.X, k.Y))), nil
+	}
+	return 0, nil, ErrUnsupportedKey
+}
+
+// NewPublicKey creates OpenPGP key material that only contains the public part of the key.
+// Since the user ID can't be certified without the private key, the resulting public key
+// will not have a self signature
+func NewPublicKey(pub crypto.PublicKey, userID string, created time.Time) (*Key, error) {
+	algorithm, material, e := publicKeyMaterial(pub)
+	if e != nil {
+		return nil, e
+	}
+
+	return &Key{
+		public:    pub,
+		userID:    userID,
+		created:   created,
+		algorithm: algorithm,
+		material:  material,
+	}, nil
+}
+
+// NewSecretKey creates OpenPGP key material from a private key
+func NewSecretKey(priv crypto.PrivateKey, userID string, created time.Time) (*Key, error) {
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	k, e := NewPublicKey(signer.Public(), userID, created)
+	if e != nil {
+		return nil, e
+	}
+	k.private = signer
+	return k, nil
+}
+
+func (k *Key) publicKeyBody() []byte {
+	return concat([]byte{v4KeyVersion}, uint32Bytes(uint32(k.created.Unix())), []byte{k.algorithm}, k.material)
+}
+
+// hashedPublicKey returns the public key in the form used when calculating fingerprints and signatures
+func (k *Key) hashedPublicKey() []byte {
+	return hashedKey(v4KeyVersion, k.publicKeyBody())
+}
+
+// Fingerprint returns the V4 fingerprint of the key, as described in RFC 4880, section 12.2
+func (k *Key) Fingerprint() []byte {
+	return fingerprintOf(v4KeyVersion, k.publicKeyBody())
+}
+
+// KeyID returns the 64 bit key ID, which is the low order bits of the fingerprint
+func (k *Key) KeyID() []byte {
+	return keyIDOf(v4KeyVersion, k.Fingerprint())
+}
+
+// HasSecretKey returns true if the key was created from a private key
+func (k *Key) HasSecretKey() bool {
+	return k.private != nil
+}
+
+func secretKeyMaterial(priv crypto.Signer) []byte {
+	switch p := priv.(type) {
+	case ed25519.PrivateKey:
+		return mpiFromBytes(p.Seed())
+	case *rsa.PrivateKey:
+		pp, q := p.Primes[0], p.Primes[1]
+		// OpenPGP requires p < q, and u = p^-1 mod q
+		if pp.Cmp(q) > 0 {
+			pp, q = q, pp
+		}
+		return concat(mpi(p.D), mpi(pp), mpi(q), mpi(new(big.Int).ModInverse(pp, q)))
+	case *ecdsa.PrivateKey:
+		return mpi(p.D)
+	}
+	return nil
+}
+
+func checksum(data []byte) []byte {
+	sum := uint16(0)
+	for _, b := range data {
+		sum += uint16(b)
+	}
+	return uint16Bytes(sum)
+}
+
+// secretKeyBody returns the body of a secret key packet, as described in RFC 4880, section 5.5.3
+// The secret key material is stored unencrypted
+func (k *Key) secretKeyBody() []byte {
+	material := secretKeyMaterial(k.private)
+	return concat(k.publicKeyBody(), []byte{0}, material, checksum(material))
+}
+
+func (k *Key) userIDPackets() ([]byte, error) {
+	uid := newFormatPacket(userIDTag, []byte(k.userID))
+	if !k.HasSecretKey() {
+		return uid, nil
+	}
+
+	sig, e := k.selfSignature()
+	if e != nil {
+		return nil, e
+	}
+	return concat(uid, newFormatPacket(signatureTag, sig)), nil
+}
+
+// PublicKey returns the binary transferable public key, as described in RFC 4880, section 11.1
+func (k *Key) PublicKey() ([]byte, error) {
+	uid, e := k.userIDPackets()
+	if e != nil {
+		return nil, e
+	}
+	return concat(newFormatPacket(publicKeyTag, k.publicKeyBody()), uid), nil
+}
+
+// SecretKey returns the binary transferable secret key, as described in RFC 4880, section 11.2
+func (k *Key) SecretKey() ([]byte, error) {
+	if !k.HasSecretKey() {
+		return nil, ErrNoPrivateKey
+	}
+
+	uid, e := k.userIDPackets()
+	if e != nil {
+		return nil, e
+	}
+	return concat(newFormatPacket(secretKeyTag, k.secretKeyBody()), uid), nil
+}
+
+// ArmoredPublicKey returns the ASCII armored transferable public key
+func (k *Key) ArmoredPublicKey() ([]byte, error) {
+	data, e := k.PublicKey()
+	if e != nil {
+		return nil, e
+	}
+	return armor(publicKeyBlock, data), nil
+}
+
+// ArmoredSecretKey returns the ASCII armored transferable secret key
+func (k *Key) ArmoredSecretKey() ([]byte, error) {
+	data, e := k.SecretKey()
+	if e != nil {
+		return nil, e
+	}
+	return armor(privateKeyBlock, data), nil
+}
@@ -0,0 +1,278 @@
+package openpgp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+// Version 5 keys are described in draft-ietf-openpgp-rfc4880bis, section 5.5.2, and version 6
+// keys, from RFC 9580, use the same layout
+// https://www.rfc-editor.org/rfc/rfc9580#section-5.5.2
+
+// the prefixes used when hashing the public key to calculate the fingerprint
+const (
+	v4FingerprintPrefix = 0x99
+	v5FingerprintPrefix = 0x9A
+	v6FingerprintPrefix = 0x9B
+)
+
+const keyIDLength = 8
+
+var errUnsupportedKeyVersion = errors.New("unsupported OpenPGP key version")
+var errUnsupportedKeyAlgorithm = errors.New("unsupported OpenPGP key algorithm")
+
+// hashedKey returns the body of a public key packet in the form used to calculate the fingerprint
+func hashedKey(version byte, body []byte) []byte {
+	if version == v4KeyVersion {
+		return concat([]byte{v4FingerprintPrefix}, uint16Bytes(uint16(len(body))), body)
+	}
+
+	prefix := byte(v5FingerprintPrefix)
+	if version == v6KeyVersion {
+		prefix = v6FingerprintPrefix
+	}
+	return concat([]byte{prefix}, uint32Bytes(uint32(len(body))), body)
+}
+
+// fingerprintOf returns the SHA-1 fingerprint of V4 keys, as described in RFC 4880, section 12.2, and
+// the SHA-256 fingerprint of newer keys
+func fingerprintOf(version byte, body []byte) []byte {
+	if version == v4KeyVersion {
+		f := sha1.Sum(hashedKey(version, body))
+		return f[:]
+	}
+	f := sha256.Sum256(hashedKey(version, body))
+	return f[:]
+}
+
+// keyIDOf returns the low order bits of the fingerprint for V4 keys, and the high order bits for newer keys
+func keyIDOf(version byte, fingerprint []byte) []byte {
+	if version == v4KeyVersion {
+		return fingerprint[len(fingerprint)-keyIDLength:]
+	}
+	return fingerprint[:keyIDLength]
+}
+
+// KeyPacket contains the public part of a public or secret key packet read from a keyring. The public
+// value is the part of the key material that identifies the key
+type KeyPacket struct {
+	version     byte
+	created     time.Time
+	body        []byte
+	algorithm   api.Algorithm
+	size        int
+	publicValue []byte
+	exponent    *big.Int
+	algorithmID byte
+	curve       *curve
+
+	secret    bool
+	protected bool
+}
+
+func parseKeyPacket(p packet) (*KeyPacket, error) {
+	version, rest, ok := readByte(p.body)
+	if !ok {
+		return nil, errInvalidPacket
+	}
+
+	var material []byte
+	var algorithmID byte
+	k := &KeyPacket{version: version, secret: p.tag == secretKeyTag || p.tag == secretSubkeyTag}
+	switch version {
+	case v4KeyVersion:
+		k.created, algorithmID, rest, ok = readKeyHeader(rest)
+		material = rest
+	case v5KeyVersion, v6KeyVersion:
+		k.created, algorithmID, rest, ok = readKeyHeader(rest)
+		var length uint32
+		if ok {
+			length, rest, ok = readUint32(rest)
+		}
+		if ok {
+			material, rest, ok = readBytes(rest, int(length))
+		}
+	default:
+		return nil, errUnsupportedKeyVersion
+	}
+	if !ok {
+		return nil, errInvalidPacket
+	}
+
+	afterMaterial, e := k.parseMaterial(algorithmID, material)
+	if e != nil {
+		return nil, e
+	}
+	if version == v4KeyVersion {
+		rest = afterMaterial
+	}
+
+	k.body = p.body[:len(p.body)-len(rest)]
+	if k.secret {
+		k.protected = len(rest) > 0 && rest[0] != 0
+	}
+	return k, nil
+}
+
+func readKeyHeader(data []byte) (created time.Time, algorithm byte, rest []byte, ok bool) {
+	var timestamp uint32
+	timestamp, rest, ok = readUint32(data)
+	if !ok {
+		return
+	}
+	algorithm, rest, ok = readByte(rest)
+	return time.Unix(int64(timestamp), 0), algorithm, rest, ok
+}
+
+// parseMaterial reads the algorithm specific fields of the public key, returning what comes after them
+func (k *KeyPacket) parseMaterial(algorithmID byte, material []byte) ([]byte, error) {
+	k.algorithmID = algorithmID
+	switch algorithmID {
+	case rsaPublicKeyAlgorithm, rsaEncryptOnlyPublicKeyAlgorithm, rsaSignOnlyPublicKeyAlgorithm:
+		return k.parseIntegers(material, api.RSA, 2, 0, 0)
+	case dsaPublicKeyAlgorithm:
+		return k.parseIntegers(material, api.DSA, 4, 0, 3)
+	case elGamalPublicKeyAlgorithm:
+		return k.parseIntegers(material, api.ElGamal, 3, 0, 2)
+	case ecdsaPublicKeyAlgorithm, eddsaPublicKeyAlgorithm, ecdhPublicKeyAlgorithm:
+		return k.parseCurvePoint(algorithmID, material)
+	case x25519PublicKeyAlgorithm:
+		return k.parseNativeKey(material, api.X25519)
+	case ed25519PublicKeyAlgorithm:
+		return k.parseNativeKey(material, api.Ed25519)
+	}
+	return nil, errUnsupportedKeyAlgorithm
+}
+
+// parseIntegers reads the given number of MPIs, where the size of the key is the bit length of
+// one of them and the public value is another one
+func (k *KeyPacket) parseIntegers(material []byte, algorithm api.Algorithm, count, sizeIndex, publicIndex int) ([]byte, error) {
+	values, rest, ok := readMPIs(material, count)
+	if !ok {
+		return nil, errInvalidPacket
+	}
+
+	k.algorithm = algorithm
+	k.size = new(big.Int).SetBytes(values[sizeIndex]).BitLen()
+	k.publicValue = values[publicIndex]
+	if algorithm == api.RSA {
+		k.exponent = new(big.Int).SetBytes(values[1])
+	}
+	return rest, nil
+}
+
+func (k *KeyPacket) parseCurvePoint(algorithmID byte, material []byte) ([]byte, error) {
+	oid, rest, ok := readLengthPrefixed(material)
+	if !ok {
+		return nil, errInvalidPacket
+	}
+	point, rest, ok := readMPI(rest)
+	if ok && algorithmID == ecdhPublicKeyAlgorithm {
+		_, rest, ok = readLengthPrefixed(rest)
+	}
+	if !ok {
+		return nil, errInvalidPacket
+	}
+
+	c, known := curveWithOID(oid)
+	if !known {
+		return nil, errUnsupportedKeyAlgorithm
+	}
+	k.algorithm, k.size = c.algorithm(algorithmID == ecdhPublicKeyAlgorithm)
+	k.curve = c
+	k.publicValue = point
+	return rest, nil
+}
+
+func (k *KeyPacket) parseNativeKey(material []byte, algorithm api.Algorithm) ([]byte, error) {
+	key, rest, ok := readBytes(material, curve25519KeySize/8)
+	if !ok {
+		return nil, errInvalidPacket
+	}
+	k.algorithm = algorithm
+	k.publicValue = key
+	return rest, nil
+}
+
+func (k *KeyPacket) Version() int {
+	return int(k.version)
+}
+
+func (k *KeyPacket) Created() time.Time {
+	return k.created
+}
+
+func (k *KeyPacket) Algorithm() api.Algorithm {
+	return k.algorithm
+}
+
+// Size is zero for algorithms where all keys have the same size
+func (k *KeyPacket) Size() int {
+	return k.size
+}
+
+func (k *KeyPacket) PublicValue() []byte {
+	return k.publicValue
+}
+
+// RSAPublicExponent is nil for keys using other algorithms than RSA
+func (k *KeyPacket) RSAPublicExponent() *big.Int {
+	return k.exponent
+}
+
+// PublicKey returns the key in the form of the standard crypto packages, for RSA keys, ECDSA keys on
+// the NIST curves and Ed25519 keys. It returns false for keys of other algorithms and curves
+func (k *KeyPacket) PublicKey() (crypto.PublicKey, bool) {
+	switch k.algorithmID {
+	case rsaPublicKeyAlgorithm, rsaEncryptOnlyPublicKeyAlgorithm, rsaSignOnlyPublicKeyAlgorithm:
+		if !k.exponent.IsInt64() {
+			return nil, false
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(k.publicValue), E: int(k.exponent.Int64())}, true
+	case ecdsaPublicKeyAlgorithm:
+		if k.curve.ellipticCurve == nil {
+			return nil, false
+		}
+		x, y := elliptic.Unmarshal(k.curve.ellipticCurve, k.publicValue)
+		if x == nil {
+			return nil, false
+		}
+		return &ecdsa.PublicKey{Curve: k.curve.ellipticCurve, X: x, Y: y}, true
+	case eddsaPublicKeyAlgorithm:
+		if k.curve != ed25519Curve || len(k.publicValue) != ed25519.PublicKeySize+1 || k.publicValue[0] != nativePointPrefix {
+			return nil, false
+		}
+		return ed25519.PublicKey(k.publicValue[1:]), true
+	case ed25519PublicKeyAlgorithm:
+		return ed25519.PublicKey(k.publicValue), true
+	}
+	return nil, false
+}
+
+// IsProtected is true for secret keys that are encrypted with a passphrase
+func (k *KeyPacket) IsProtected() bool {
+	return k.protected
+}
+
+// HashedKey returns the public key in the form used to calculate the fingerprint
+func (k *KeyPacket) HashedKey() []byte {
+	return hashedKey(k.version, k.body)
+}
+
+func (k *KeyPacket) Fingerprint() []byte {
+	return fingerprintOf(k.version, k.body)
+}
+
+func (k *KeyPacket) KeyID() []byte {
+	return keyIDOf(k.version, k.Fingerprint())
+}
@@ -0,0 +1,177 @@
+package openpgp
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func firstPacketOf(data []byte) packet {
+	packets, _ := readPackets(data)
+	return packets[0]
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_readsAV4Ed25519Key() {
+	k, e := parseKeyPacket(firstPacketOf(aliceKeyForTest()))
+	s.Require().NoError(e)
+
+	s.Equal(byte(v4KeyVersion), k.version)
+	s.Equal(time.Unix(aliceCreated, 0), k.created)
+	s.Equal(api.Ed25519, k.algorithm)
+	s.Equal(0, k.size)
+	s.Equal(alicePublicValue, hex.EncodeToString(k.publicValue))
+	s.Nil(k.exponent)
+	s.False(k.secret)
+	s.Equal(aliceFingerprint, hex.EncodeToString(k.Fingerprint()))
+	s.Equal("3b32be476bb73e49", hex.EncodeToString(k.KeyID()))
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_readsTheSizeAndExponentOfRSAKeys() {
+	priv, _ := rsa.GenerateKey(rand.Reader, 1536)
+	written, _ := NewSecretKey(priv, "someone", time.Unix(1600000000, 0))
+	data, _ := written.SecretKey()
+
+	k, e := parseKeyPacket(firstPacketOf(data))
+	s.Require().NoError(e)
+	s.Equal(api.RSA, k.algorithm)
+	s.Equal(1536, k.size)
+	s.Equal(big.NewInt(65537), k.exponent)
+	s.Equal(priv.N.Bytes(), k.publicValue)
+	s.Equal(written.Fingerprint(), k.Fingerprint())
+	s.True(k.secret)
+	s.False(k.protected)
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_readsTheProtectionOfSecretKeys() {
+	body := []byte{v4KeyVersion, 0, 0, 0, 0, x25519PublicKeyAlgorithm}
+	body = append(body, make([]byte, 32)...)
+	k, e := parseKeyPacket(packet{tag: secretSubkeyTag, body: append(body, 254, 1, 2, 3)})
+	s.Require().NoError(e)
+	s.True(k.protected)
+	s.Equal(body, k.body)
+	s.Equal(api.X25519, k.algorithm)
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_readsV5Keys_withASHA256Fingerprint() {
+	material := []byte{0x00, 0x08, 0x81, 0x00, 0x02, 0x03}
+	body := append([]byte{v5KeyVersion, 0x5C, 0x00, 0x00, 0x00, rsaPublicKeyAlgorithm}, testutil.Uint32(len(material))...)
+	body = append(body, material...)
+
+	k, e := parseKeyPacket(packet{tag: publicKeyTag, body: body})
+	s.Require().NoError(e)
+	s.Equal(8, k.size)
+	s.Equal(big.NewInt(3), k.exponent)
+
+	expected := sha256.Sum256(append(append([]byte{0x9A}, testutil.Uint32(len(body))...), body...))
+	s.Equal(expected[:], k.Fingerprint())
+	s.Equal(expected[:8], k.KeyID())
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_readsECDSAAndECDHKeys_onNISTCurves() {
+	oid := testutil.DecodeHex("2b81040022")
+	point := []byte{0x00, 0x0B, 0x04, 0x01}
+	ecdsa := append(append([]byte{v4KeyVersion, 0, 0, 0, 0, ecdsaPublicKeyAlgorithm, byte(len(oid))}, oid...), point...)
+	k, e := parseKeyPacket(packet{tag: publicKeyTag, body: ecdsa})
+	s.Require().NoError(e)
+	s.Equal(api.ECDSA, k.algorithm)
+	s.Equal(384, k.size)
+
+	ecdh := append(append([]byte{v4KeyVersion, 0, 0, 0, 0, ecdhPublicKeyAlgorithm, byte(len(oid))}, oid...), point...)
+	ecdh = append(ecdh, 0x03, 0x01, 0x09, 0x09)
+	k, e = parseKeyPacket(packet{tag: publicSubkeyTag, body: ecdh})
+	s.Require().NoError(e)
+	s.Equal(api.ECDH, k.algorithm)
+	s.Equal(384, k.size)
+	s.Equal(ecdh, k.body)
+}
+
+func (s *openpgpSuite) Test_parseKeyPacket_failsForUnsupportedKeys() {
+	_, e := parseKeyPacket(packet{tag: publicKeyTag, body: []byte{3, 0, 0, 0, 0, 0, 0, rsaPublicKeyAlgorithm}})
+	s.Equal(errUnsupportedKeyVersion, e)
+
+	_, e = parseKeyPacket(packet{tag: publicKeyTag, body: []byte{v4KeyVersion, 0, 0, 0, 0, 99, 1, 2}})
+	s.Equal(errUnsupportedKeyAlgorithm, e)
+
+	_, e = parseKeyPacket(packet{tag: publicKeyTag, body: []byte{v4KeyVersion, 0, 0, 0, 0, ecdsaPublicKeyAlgorithm, 1, 0x2A, 0x00, 0x01, 0x01}})
+	s.Equal(errUnsupportedKeyAlgorithm, e, "unknown curves are not supported")
+
+	_, e = parseKeyPacket(packet{tag: publicKeyTag, body: []byte{v4KeyVersion, 0, 0}})
+	s.Equal(errInvalidPacket, e)
+}
+
+func (s *openpgpSuite) Test_KeyPacket_PublicKey_returnsTheKeysOfTheStandardCryptoPackages() {
+	k, _ := parseKeyPacket(firstPacketOf(aliceKeyForTest()))
+	pub, ok := k.PublicKey()
+	s.True(ok)
+	s.Equal(ed25519.PublicKey(testutil.DecodeHex(alicePublicValue)[1:]), pub)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	written, _ := NewPublicKey(&rsaKey.PublicKey, "someone", time.Unix(1600000000, 0))
+	data, _ := written.PublicKey()
+	k, _ = parseKeyPacket(firstPacketOf(data))
+	pub, ok = k.PublicKey()
+	s.True(ok)
+	s.Equal(&rsaKey.PublicKey, pub)
+
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	written, _ = NewPublicKey(&ecdsaKey.PublicKey, "someone", time.Unix(1600000000, 0))
+	data, _ = written.PublicKey()
+	k, _ = parseKeyPacket(firstPacketOf(data))
+	pub, ok = k.PublicKey()
+	s.True(ok)
+	s.Equal(&ecdsaKey.PublicKey, pub)
+}
+
+func (s *openpgpSuite) Test_KeyPacket_PublicKey_returnsFalseForOtherKeys() {
+	body := []byte{v4KeyVersion, 0, 0, 0, 0, x25519PublicKeyAlgorithm}
+	k, _ := parseKeyPacket(packet{tag: publicSubkeyTag, body: append(body, make([]byte, 32)...)})
+	_, ok := k.PublicKey()
+	s.False(ok)
+
+	oid := testutil.DecodeHex("2b81040022")
+	ecdsaKey := append(append([]byte{v4KeyVersion, 0, 0, 0, 0, ecdsaPublicKeyAlgorithm, byte(len(oid))}, oid...), 0x00, 0x0B, 0x04, 0x01)
+	k, _ = parseKeyPacket(packet{tag: publicKeyTag, body: ecdsaKey})
+	_, ok = k.PublicKey()
+	s.False(ok, "points that are not on the curve")
+}
+
+func (s *openpgpSuite) Test_ComparablePublicValue_ignoresTheNativePointPrefixAndLeadingZeroes() {
+	s.Equal(ComparablePublicValue(testutil.DecodeHex(aliceSubkeyPublicValue)), ComparablePublicValue(testutil.DecodeHex(aliceSubkeyPublicValue)[1:]))
+	s.Equal(ComparablePublicValue([]byte{0x00, 0x80, 0x01}), ComparablePublicValue([]byte{0x80, 0x01}))
+	s.NotEqual(ComparablePublicValue([]byte{0x40, 0x01}), ComparablePublicValue([]byte{0x01}))
+}
+
+func (s *openpgpSuite) Test_CurveAlgorithm_returnsTheAlgorithmAndSizeOfKeysOnTheCurve() {
+	a, size, ok := CurveAlgorithm("NIST P-384", false)
+	s.True(ok)
+	s.Equal(api.ECDSA, a)
+	s.Equal(384, size)
+
+	a, size, _ = CurveAlgorithm("NIST P-384", true)
+	s.Equal(api.ECDH, a)
+	s.Equal(384, size)
+
+	a, _, _ = CurveAlgorithm("Ed25519", false)
+	s.Equal(api.Ed25519, a)
+
+	a, _, _ = CurveAlgorithm("Curve25519", true)
+	s.Equal(api.X25519, a)
+
+	_, _, ok = CurveAlgorithm("NIST P-192", false)
+	s.False(ok)
+}
+
+func (s *openpgpSuite) Test_curve_dottedOID_returnsTheOIDOfTheCurve() {
+	s.Equal("1.3.6.1.4.1.11591.15.1", ed25519Curve.dottedOID())
+	s.Equal("1.3.6.1.4.1.3029.1.5.1", curve25519Curve.dottedOID())
+	s.Equal("1.2.840.10045.3.1.7", curves[0].dottedOID())
+}
@@ -0,0 +1,203 @@
+package openpgp
+
+import "time"
+
+// Transferable keys are described in RFC 4880, section 11.1. A keyring is a
+// sequence of transferable keys, possibly with trust packets between them
+// https://www.rfc-editor.org/rfc/rfc4880#section-11.1
+
+type userID struct {
+	text          string
+	selfSignature *signature
+}
+
+// Subkey is a subkey of a transferable key, together with the signature binding it to the primary key
+type Subkey struct {
+	key     *KeyPacket
+	binding *signature
+}
+
+// TransferableKey is a primary key together with its user IDs and subkeys. Only the self signatures
+// are kept, since they contain the expiry times and tell which user ID is the primary one
+type TransferableKey struct {
+	primary         *KeyPacket
+	directSignature *signature
+	userIDs         []*userID
+	subkeys         []*Subkey
+}
+
+// primaryUserID chooses the primary user ID the same way GnuPG does. Among the user IDs marked as
+// primary by their self signature, or among all self signed user IDs when none is marked, the one
+// with the newest self signature wins
+func (k *TransferableKey) primaryUserID() *userID {
+	var newest, newestMarked *userID
+	for _, u := range k.userIDs {
+		if u.selfSignature == nil {
+			continue
+		}
+		if newest == nil || u.selfSignature.isNewerThan(newest.selfSignature) {
+			newest = u
+		}
+		if u.selfSignature.primaryUserID && (newestMarked == nil || u.selfSignature.isNewerThan(newestMarked.selfSignature)) {
+			newestMarked = u
+		}
+	}
+
+	switch {
+	case newestMarked != nil:
+		return newestMarked
+	case newest != nil:
+		return newest
+	case len(k.userIDs) > 0:
+		return k.userIDs[0]
+	}
+	return nil
+}
+
+func (k *TransferableKey) Primary() *KeyPacket {
+	return k.primary
+}
+
+// UserIDs returns the user IDs, starting with the primary one
+func (k *TransferableKey) UserIDs() []string {
+	primary := k.primaryUserID()
+	if primary == nil {
+		return nil
+	}
+
+	result := []string{primary.text}
+	for _, u := range k.userIDs {
+		if u != primary {
+			result = append(result, u.text)
+		}
+	}
+	return result
+}
+
+func (k *TransferableKey) Subkeys() []*Subkey {
+	return k.subkeys
+}
+
+// KeyPackets returns the primary key followed by the subkeys
+func (k *TransferableKey) KeyPackets() []*KeyPacket {
+	result := []*KeyPacket{k.primary}
+	for _, s := range k.subkeys {
+		result = append(result, s.key)
+	}
+	return result
+}
+
+func expiryFrom(k *KeyPacket, s *signature) time.Time {
+	if s == nil || s.keyExpiry == 0 {
+		return time.Time{}
+	}
+	return k.created.Add(s.keyExpiry)
+}
+
+// Expiry returns the expiry of the primary key. It comes from the self signature of the primary
+// user ID, or from a direct key signature when the user ID is not self signed. Keys that don't
+// expire have a zero expiry
+func (k *TransferableKey) Expiry() time.Time {
+	if u := k.primaryUserID(); u != nil && u.selfSignature != nil {
+		return expiryFrom(k.primary, u.selfSignature)
+	}
+	return expiryFrom(k.primary, k.directSignature)
+}
+
+func (s *Subkey) Key() *KeyPacket {
+	return s.key
+}
+
+func (s *Subkey) Expiry() time.Time {
+	return expiryFrom(s.key, s.binding)
+}
+
+// keyringReader groups the packets of a keyring into keys. Signatures are attached
+// to the last primary key, user ID or subkey read
+type keyringReader struct {
+	keys          []*TransferableKey
+	current       *TransferableKey
+	currentUserID *userID
+	currentSubkey *Subkey
+}
+
+// ReadKeyring returns the keys in the keyring. Keys with unsupported versions or algorithms
+// are skipped, and the keys read before an invalid packet are returned together with the error
+func ReadKeyring(data []byte) ([]*TransferableKey, error) {
+	packets, e := readPackets(data)
+	r := &keyringReader{keys: []*TransferableKey{}}
+	for _, p := range packets {
+		r.read(p)
+	}
+	return r.keys, e
+}
+
+func (r *keyringReader) read(p packet) {
+	switch p.tag {
+	case publicKeyTag, secretKeyTag:
+		r.readPrimaryKey(p)
+	case publicSubkeyTag, secretSubkeyTag:
+		r.readSubkey(p)
+	case userIDTag:
+		r.readUserID(p)
+	case userAttributeTag:
+		r.currentUserID, r.currentSubkey = nil, nil
+	case signatureTag:
+		r.readSignature(p)
+	}
+}
+
+func (r *keyringReader) readPrimaryKey(p packet) {
+	r.current, r.currentUserID, r.currentSubkey = nil, nil, nil
+	k, e := parseKeyPacket(p)
+	if e != nil {
+		return
+	}
+	r.current = &TransferableKey{primary: k}
+	r.keys = append(r.keys, r.current)
+}
+
+func (r *keyringReader) readSubkey(p packet) {
+	r.currentUserID, r.currentSubkey = nil, nil
+	if r.current == nil {
+		return
+	}
+	if k, e := parseKeyPacket(p); e == nil {
+		r.currentSubkey = &Subkey{key: k}
+		r.current.subkeys = append(r.current.subkeys, r.currentSubkey)
+	}
+}
+
+func (r *keyringReader) readUserID(p packet) {
+	r.currentUserID, r.currentSubkey = nil, nil
+	if r.current == nil {
+		return
+	}
+	r.currentUserID = &userID{text: string(p.body)}
+	r.current.userIDs = append(r.current.userIDs, r.currentUserID)
+}
+
+func (r *keyringReader) readSignature(p packet) {
+	if r.current == nil {
+		return
+	}
+	sig, ok := parseSignature(p.body)
+	if !ok || !sig.isBy(r.current.primary) {
+		return
+	}
+
+	switch {
+	case r.currentUserID != nil:
+		if sig.isCertification() && sig.isNewerThan(r.currentUserID.selfSignature) {
+			r.currentUserID.selfSignature = sig
+		}
+	case r.currentSubkey != nil:
+		if sig.signatureType == subkeyBindingSignature && sig.isNewerThan(r.currentSubkey.binding) {
+			r.currentSubkey.binding = sig
+		}
+	case len(r.current.userIDs) == 0 && len(r.current.subkeys) == 0:
+		if sig.signatureType == directKeySignature && sig.isNewerThan(r.current.directSignature) {
+			r.current.directSignature = sig
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package openpgp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+// aliceKey was exported by GnuPG 2.2 from a key generated with:
+//
+//	gpg --quick-gen-key 'Alice <alice@example.org>' ed25519 cert,sign 2y
+//	gpg --quick-add-key <fingerprint> cv25519 encr 1y
+const aliceKey = "" +
+	"mDMEatVpZxYJKwYBBAHaRw8BAQdAUa0h63+ExUSq233/eBf2szljOvdlg4AA9GYwhGltpVa0GUFs" +
+	"aWNlIDxhbGljZUBleGFtcGxlLm9yZz6IlgQTFggAPhYhBEtK4FnH125gHVRVcjsyvkdrtz5JBQJq" +
+	"1WlnAhsDBQkDwmcABQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEDsyvkdrtz5JigQBAMPe9OIR" +
+	"A6TgcBkFl2vz7axVTKxfIlWyCTzI2VllzztXAP46g+w/kiADNKBCqi0skem208ZIvNDsRA4AoXDc" +
+	"qsoNDLg4BGrVaWcSCisGAQQBl1UBBQEBB0CnFc6kCZkMy1UURBc0FM9u37LsRz9PAv9C8AeHi+BK" +
+	"BgMBCAeIfgQYFggAJhYhBEtK4FnH125gHVRVcjsyvkdrtz5JBQJq1WlnAhsMBQkB4TOAAAoJEDsy" +
+	"vkdrtz5JELoBALhqyC2AxSaWcDb1Nz0OlCOTVkZJXs+7kAUWaMXMgnuzAQDAUmJWiJOB1Ey3Gja+" +
+	"NumrYeRf7MlbjtoYcIkxT11rCg=="
+
+const aliceFingerprint = "4b4ae059c7d76e601d5455723b32be476bb73e49"
+const aliceSubkeyFingerprint = "ca8e01c4d4230c3d415541d1990cf8952335d2d7"
+
+const aliceCreated = 1792371047
+const aliceExpiry = 1855443047
+const aliceSubkeyExpiry = 1823907047
+
+const alicePublicValue = "4051ad21eb7f84c544aadb7dff7817f6b339633af765838000f4663084696da556"
+const aliceSubkeyPublicValue = "40a715cea409990ccb551444173414cf6edfb2ec473f4f02ff42f007878be04a06"
+
+func aliceKeyForTest() []byte {
+	data, _ := base64.StdEncoding.DecodeString(aliceKey)
+	return data
+}
+
+func packetForTest(tag byte, body []byte) []byte {
+	return append([]byte{0xC0 | tag, byte(len(body))}, body...)
+}
+
+func subpacketForTest(subpacketType byte, data ...byte) []byte {
+	return append([]byte{byte(len(data) + 1), subpacketType}, data...)
+}
+
+// signatureForTest creates a V4 signature packet by the key with the given subpackets in the hashed
+// area and the issuer in the unhashed area. The signature itself is made up
+func signatureForTest(signatureType byte, issuer *KeyPacket, hashed ...[]byte) []byte {
+	hashedArea := []byte{}
+	for _, sp := range hashed {
+		hashedArea = append(hashedArea, sp...)
+	}
+	unhashedArea := subpacketForTest(issuerSubpacket, issuer.KeyID()...)
+
+	body := []byte{v4SignatureVersion, signatureType, eddsaPublicKeyAlgorithm, 8, 0, byte(len(hashedArea))}
+	body = append(body, hashedArea...)
+	body = append(body, 0, byte(len(unhashedArea)))
+	body = append(body, unhashedArea...)
+	return packetForTest(signatureTag, append(body, 0xAB, 0xCD))
+}
+
+func creationSubpacketForTest(t int) []byte {
+	return subpacketForTest(signatureCreationTimeSubpacket, testutil.Uint32(t)...)
+}
+
+func alicePrimaryKeyForTest() (*KeyPacket, []byte) {
+	p := firstPacketOf(aliceKeyForTest())
+	k, _ := parseKeyPacket(p)
+	return k, packetForTest(publicKeyTag, p.body)
+}
+
+func (s *openpgpSuite) Test_readKeyring_readsTheUserIDsSubkeysAndExpiryOfKeysExportedByGnuPG() {
+	keys, e := ReadKeyring(aliceKeyForTest())
+	s.Require().NoError(e)
+	s.Require().Len(keys, 1)
+
+	k := keys[0]
+	s.Equal(aliceFingerprint, hex.EncodeToString(k.primary.Fingerprint()))
+	s.Equal([]string{"Alice <alice@example.org>"}, k.UserIDs())
+	s.Equal(time.Unix(aliceExpiry, 0), k.Expiry())
+
+	s.Require().Len(k.subkeys, 1)
+	s.Equal(aliceSubkeyFingerprint, hex.EncodeToString(k.subkeys[0].key.Fingerprint()))
+	s.Equal(api.X25519, k.subkeys[0].key.algorithm)
+	s.Equal(time.Unix(aliceSubkeyExpiry, 0), k.subkeys[0].Expiry())
+}
+
+func (s *openpgpSuite) Test_readKeyring_putsThePrimaryUserIDFirst_andUsesItsExpiry() {
+	primary, primaryPacket := alicePrimaryKeyForTest()
+	data := concatForTest(
+		primaryPacket,
+		packetForTest(userIDTag, []byte("first")),
+		signatureForTest(positiveCertificationSignature, primary, creationSubpacketForTest(aliceCreated)),
+		packetForTest(userIDTag, []byte("second")),
+		signatureForTest(positiveCertificationSignature, primary,
+			creationSubpacketForTest(aliceCreated),
+			subpacketForTest(primaryUserIDSubpacket, 1),
+			subpacketForTest(keyExpirationTimeSubpacket, testutil.Uint32(100)...)),
+		signatureForTest(positiveCertificationSignature, primary,
+			creationSubpacketForTest(aliceCreated+10),
+			subpacketForTest(primaryUserIDSubpacket, 1),
+			subpacketForTest(keyExpirationTimeSubpacket, testutil.Uint32(200)...)),
+		packetForTest(trustTag, []byte{0}),
+	)
+
+	keys, e := ReadKeyring(data)
+	s.Require().NoError(e)
+	s.Require().Len(keys, 1)
+	s.Equal([]string{"second", "first"}, keys[0].UserIDs())
+	s.Equal(time.Unix(aliceCreated+200, 0), keys[0].Expiry(), "the newest self signature is used")
+}
+
+func (s *openpgpSuite) Test_readKeyring_putsTheUserIDWithTheNewestSelfSignatureFirst_whenNoneIsMarkedPrimary() {
+	primary, primaryPacket := alicePrimaryKeyForTest()
+	data := concatForTest(
+		primaryPacket,
+		packetForTest(userIDTag, []byte("first")),
+		signatureForTest(positiveCertificationSignature, primary, creationSubpacketForTest(aliceCreated)),
+		packetForTest(userIDTag, []byte("second")),
+		signatureForTest(positiveCertificationSignature, primary, creationSubpacketForTest(aliceCreated+10)),
+		packetForTest(userIDTag, []byte("unsigned")),
+	)
+
+	keys, _ := ReadKeyring(data)
+	s.Require().Len(keys, 1)
+	s.Equal([]string{"second", "first", "unsigned"}, keys[0].UserIDs())
+}
+
+func (s *openpgpSuite) Test_readKeyring_prefersTheUserIDMarkedPrimary_overNewerSelfSignatures() {
+	primary, primaryPacket := alicePrimaryKeyForTest()
+	data := concatForTest(
+		primaryPacket,
+		packetForTest(userIDTag, []byte("first")),
+		signatureForTest(positiveCertificationSignature, primary,
+			creationSubpacketForTest(aliceCreated),
+			subpacketForTest(primaryUserIDSubpacket, 1)),
+		packetForTest(userIDTag, []byte("second")),
+		signatureForTest(positiveCertificationSignature, primary, creationSubpacketForTest(aliceCreated+10)),
+	)
+
+	keys, _ := ReadKeyring(data)
+	s.Require().Len(keys, 1)
+	s.Equal([]string{"first", "second"}, keys[0].UserIDs())
+}
+
+func (s *openpgpSuite) Test_readKeyring_ignoresSignaturesByOtherKeys() {
+	primary, primaryPacket := alicePrimaryKeyForTest()
+	other := &KeyPacket{version: v4KeyVersion, body: []byte{v4KeyVersion, 1, 2, 3}}
+	data := concatForTest(
+		primaryPacket,
+		signatureForTest(directKeySignature, primary, subpacketForTest(keyExpirationTimeSubpacket, testutil.Uint32(50)...)),
+		packetForTest(userIDTag, []byte("someone")),
+		signatureForTest(positiveCertificationSignature, other, subpacketForTest(primaryUserIDSubpacket, 1)),
+	)
+
+	keys, _ := ReadKeyring(data)
+	s.Require().Len(keys, 1)
+	s.Nil(keys[0].userIDs[0].selfSignature)
+	s.Equal(time.Unix(aliceCreated+50, 0), keys[0].Expiry(), "the direct key signature is used without a self signature")
+}
+
+func (s *openpgpSuite) Test_readKeyring_usesTheDirectKeySignature_forKeysWithoutUserIDs() {
+	primary, primaryPacket := alicePrimaryKeyForTest()
+	data := concatForTest(
+		primaryPacket,
+		signatureForTest(directKeySignature, primary, subpacketForTest(keyExpirationTimeSubpacket, testutil.Uint32(50)...)),
+	)
+
+	keys, _ := ReadKeyring(data)
+	s.Require().Len(keys, 1)
+	s.Nil(keys[0].UserIDs())
+	s.Equal(time.Unix(aliceCreated+50, 0), keys[0].Expiry())
+}
+
+func (s *openpgpSuite) Test_readKeyring_skipsKeysWithUnsupportedAlgorithms() {
+	unsupported := packetForTest(publicKeyTag, []byte{v4KeyVersion, 0, 0, 0, 0, 99})
+	data := concatForTest(
+		unsupported,
+		packetForTest(userIDTag, []byte("unsupported")),
+		aliceKeyForTest(),
+		packetForTest(publicSubkeyTag, []byte{v4KeyVersion, 0, 0, 0, 0, 99}),
+	)
+
+	keys, e := ReadKeyring(data)
+	s.NoError(e)
+	s.Require().Len(keys, 1)
+	s.Equal([]string{"Alice <alice@example.org>"}, keys[0].UserIDs())
+	s.Len(keys[0].subkeys, 1)
+}
+
+func (s *openpgpSuite) Test_readKeyring_returnsTheKeysBeforeAnInvalidPacket() {
+	keys, e := ReadKeyring(append(aliceKeyForTest(), 0x01))
+	s.Equal(errInvalidPacket, e)
+	s.Len(keys, 1)
+}
+
+func concatForTest(parts ...[]byte) []byte {
+	result := []byte{}
+	for _, p := range parts {
+		result = append(result, p...)
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package openpgp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+)
+
+// The packet format is described in RFC 4880, section 4
+// https://www.rfc-editor.org/rfc/rfc4880#section-4
+
+const (
+	signatureTag     = 2
+	secretKeyTag     = 5
+	publicKeyTag     = 6
+	secretSubkeyTag  = 7
+	trustTag         = 12
+	userIDTag        = 13
+	publicSubkeyTag  = 14
+	userAttributeTag = 17
+)
+
+const (
+	packetHeaderFlag        = 0x80
+	newFormatFlag           = 0x40
+	newFormatTagMask        = 0x3F
+	oldFormatTagShift       = 2
+	oldFormatTagMask        = 0x0F
+	oldFormatLengthTypeMask = 0x03
+	indeterminateLengthType = 3
+)
+
+// newFormatPacket wraps the body in a packet using a new format header
+func newFormatPacket(tag byte, body []byte) []byte {
+	result := []byte{packetHeaderFlag | newFormatFlag | tag}
+	result = append(result, newFormatLength(len(body))...)
+	return append(result, body...)
+}
+
+func newFormatLength(l int) []byte {
+	switch {
+	case l < 192:
+		return []byte{byte(l)}
+	case l < 8384:
+		l -= 192
+		return []byte{byte(l>>8) + 192, byte(l)}
+	}
+	return append([]byte{0xFF}, uint32Bytes(uint32(l))...)
+}
+
+func uint16Bytes(v uint16) []byte {
+	result := make([]byte, 2)
+	binary.BigEndian.PutUint16(result, v)
+	return result
+}
+
+func uint32Bytes(v uint32) []byte {
+	result := make([]byte, 4)
+	binary.BigEndian.PutUint32(result, v)
+	return result
+}
+
+// mpiFromBytes encodes the big endian number as a multiprecision integer,
+// as described in RFC 4880, section 3.2
+func mpiFromBytes(b []byte) []byte {
+	b = bytes.TrimLeft(b, "\x00")
+	return append(uint16Bytes(uint16(new(big.Int).SetBytes(b).BitLen())), b...)
+}
+
+func mpi(v *big.Int) []byte {
+	return mpiFromBytes(v.Bytes())
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
@@ -0,0 +1,150 @@
+package openpgp
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errInvalidPacket = errors.New("invalid OpenPGP packet")
+
+type packet struct {
+	tag  byte
+	body []byte
+}
+
+// readPackets splits the data into packets. Partial body lengths are only used for data
+// packets, which never appear in keyrings, so they are treated as invalid. The packets
+// read before an error are returned together with it
+func readPackets(data []byte) ([]packet, error) {
+	result := []packet{}
+	for len(data) > 0 {
+		p, rest, ok := readPacket(data)
+		if !ok {
+			return result, errInvalidPacket
+		}
+		result = append(result, p)
+		data = rest
+	}
+	return result, nil
+}
+
+func readPacket(data []byte) (packet, []byte, bool) {
+	header := data[0]
+	if header&packetHeaderFlag == 0 {
+		return packet{}, nil, false
+	}
+
+	var tag byte
+	var length int
+	var rest []byte
+	var ok bool
+	if header&newFormatFlag != 0 {
+		tag = header & newFormatTagMask
+		length, rest, ok = readNewFormatLength(data[1:])
+	} else {
+		tag = (header >> oldFormatTagShift) & oldFormatTagMask
+		length, rest, ok = readOldFormatLength(header&oldFormatLengthTypeMask, data[1:])
+	}
+
+	if !ok || length > len(rest) {
+		return packet{}, nil, false
+	}
+	return packet{tag: tag, body: rest[:length]}, rest[length:], true
+}
+
+// readNewFormatLength reads a length written by newFormatLength, as described in RFC 4880,
+// section 4.2.2. The same encoding is used for the length of signature subpackets
+func readNewFormatLength(data []byte) (int, []byte, bool) {
+	switch {
+	case len(data) < 1:
+	case data[0] < 192:
+		return int(data[0]), data[1:], true
+	case data[0] < 224:
+		if len(data) >= 2 {
+			return (int(data[0])-192)<<8 + int(data[1]) + 192, data[2:], true
+		}
+	case data[0] == 255:
+		if len(data) >= 5 {
+			return int(binary.BigEndian.Uint32(data[1:5])), data[5:], true
+		}
+	}
+	return 0, nil, false
+}
+
+func readOldFormatLength(lengthType byte, data []byte) (int, []byte, bool) {
+	if lengthType == indeterminateLengthType {
+		return len(data), data, true
+	}
+
+	size := 1 << lengthType
+	if len(data) < size {
+		return 0, nil, false
+	}
+	length := 0
+	for _, b := range data[:size] {
+		length = length<<8 | int(b)
+	}
+	return length, data[size:], true
+}
+
+func readBytes(data []byte, n int) ([]byte, []byte, bool) {
+	if n < 0 || len(data) < n {
+		return nil, nil, false
+	}
+	return data[:n], data[n:], true
+}
+
+func readByte(data []byte) (byte, []byte, bool) {
+	b, rest, ok := readBytes(data, 1)
+	if !ok {
+		return 0, nil, false
+	}
+	return b[0], rest, true
+}
+
+func readUint16(data []byte) (int, []byte, bool) {
+	b, rest, ok := readBytes(data, 2)
+	if !ok {
+		return 0, nil, false
+	}
+	return int(binary.BigEndian.Uint16(b)), rest, true
+}
+
+func readUint32(data []byte) (uint32, []byte, bool) {
+	b, rest, ok := readBytes(data, 4)
+	if !ok {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint32(b), rest, true
+}
+
+// readMPI reads a multiprecision integer written by mpi, returning the bytes of the number
+func readMPI(data []byte) ([]byte, []byte, bool) {
+	bits, rest, ok := readUint16(data)
+	if !ok {
+		return nil, nil, false
+	}
+	return readBytes(rest, (bits+7)/8)
+}
+
+func readMPIs(data []byte, n int) (values [][]byte, rest []byte, ok bool) {
+	rest = data
+	for i := 0; i < n; i++ {
+		var v []byte
+		v, rest, ok = readMPI(rest)
+		if !ok {
+			return nil, nil, false
+		}
+		values = append(values, v)
+	}
+	return values, rest, true
+}
+
+// readLengthPrefixed reads a field that starts with a one octet length, like curve OIDs and KDF parameters
+func readLengthPrefixed(data []byte) ([]byte, []byte, bool) {
+	l, rest, ok := readByte(data)
+	if !ok {
+		return nil, nil, false
+	}
+	return readBytes(rest, int(l))
+}
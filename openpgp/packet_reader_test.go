@@ -0,0 +1,65 @@
+package openpgp
+
+func (s *openpgpSuite) Test_readPackets_readsNewAndOldFormatPackets() {
+	data := []byte{
+		0xCD, 0x03, 'a', 'b', 'c', // new format user ID
+		0xB4, 0x02, 'd', 'e', // old format user ID with one octet length
+		0xB5, 0x00, 0x01, 'f', // old format user ID with two octet length
+		0xB6, 0x00, 0x00, 0x00, 0x01, 'g', // old format user ID with four octet length
+	}
+
+	packets, e := readPackets(data)
+	s.NoError(e)
+	s.Equal([]packet{
+		{tag: userIDTag, body: []byte("abc")},
+		{tag: userIDTag, body: []byte("de")},
+		{tag: userIDTag, body: []byte("f")},
+		{tag: userIDTag, body: []byte("g")},
+	}, packets)
+}
+
+func (s *openpgpSuite) Test_readPackets_readsPacketsWithIndeterminateLength_untilTheEnd() {
+	packets, e := readPackets([]byte{0xB7, 'a', 'b'})
+	s.NoError(e)
+	s.Equal([]packet{{tag: userIDTag, body: []byte("ab")}}, packets)
+}
+
+func (s *openpgpSuite) Test_readPackets_returnsThePacketsBeforeAnInvalidOne() {
+	packets, e := readPackets([]byte{0xCD, 0x01, 'a', 0x0D, 0x01, 'b'})
+	s.Equal(errInvalidPacket, e)
+	s.Equal([]packet{{tag: userIDTag, body: []byte("a")}}, packets)
+
+	_, e = readPackets([]byte{0xCD, 0x05, 'a'})
+	s.Equal(errInvalidPacket, e)
+
+	_, e = readPackets([]byte{0xCD, 0xE1, 'a'})
+	s.Equal(errInvalidPacket, e, "partial body lengths are not supported")
+}
+
+func (s *openpgpSuite) Test_readNewFormatLength_readsAllEncodings() {
+	l, rest, ok := readNewFormatLength([]byte{0x64, 0x01})
+	s.True(ok)
+	s.Equal(100, l)
+	s.Equal([]byte{0x01}, rest)
+
+	l, _, ok = readNewFormatLength([]byte{0xC5, 0xFB})
+	s.True(ok)
+	s.Equal(1723, l)
+
+	l, _, ok = readNewFormatLength([]byte{0xFF, 0x00, 0x01, 0x86, 0xA0})
+	s.True(ok)
+	s.Equal(100000, l)
+
+	_, _, ok = readNewFormatLength([]byte{0xC5})
+	s.False(ok)
+}
+
+func (s *openpgpSuite) Test_readMPI_readsTheBytesOfTheNumber() {
+	v, rest, ok := readMPI([]byte{0x00, 0x09, 0x01, 0xFF, 0x42})
+	s.True(ok)
+	s.Equal([]byte{0x01, 0xFF}, v)
+	s.Equal([]byte{0x42}, rest)
+
+	_, _, ok = readMPI([]byte{0x00, 0x09, 0x01})
+	s.False(ok)
+}
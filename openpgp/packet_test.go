@@ -0,0 +1,47 @@
+package openpgp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type openpgpSuite struct {
+	suite.Suite
+}
+
+func TestOpenpgpSuite(t *testing.T) {
+	suite.Run(t, new(openpgpSuite))
+}
+
+func (s *openpgpSuite) Test_newFormatLength_usesTheShortestEncoding() {
+	s.Equal([]byte{0x64}, newFormatLength(100))
+	s.Equal([]byte{0xC5, 0xFB}, newFormatLength(1723))
+	s.Equal([]byte{0xFF, 0x00, 0x01, 0x86, 0xA0}, newFormatLength(100000))
+}
+
+func (s *openpgpSuite) Test_newFormatPacket_addsTheTagAndLength() {
+	s.Equal([]byte{0xCD, 0x03, 'a', 'b', 'c'}, newFormatPacket(userIDTag, []byte("abc")))
+}
+
+func (s *openpgpSuite) Test_mpi_encodesTheBitLengthAndStripsLeadingZeroes() {
+	s.Equal([]byte{0x00, 0x01, 0x01}, mpi(big.NewInt(1)))
+	s.Equal([]byte{0x00, 0x09, 0x01, 0xFF}, mpi(big.NewInt(511)))
+	s.Equal([]byte{0x00, 0x08, 0xFF}, mpiFromBytes([]byte{0x00, 0x00, 0xFF}))
+}
+
+func (s *openpgpSuite) Test_crc24_hasTheCorrectInitialValue() {
+	s.Equal(uint32(0xB704CE), crc24(nil))
+}
+
+func (s *openpgpSuite) Test_armor_wrapsLinesAndAddsTheChecksum() {
+	data := make([]byte, 60)
+	s.Equal(
+		"-----BEGIN PGP PUBLIC KEY BLOCK-----\n\n"+
+			"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\n"+
+			"AAAAAAAAAAAAAAAA\n"+
+			"=CdAQ\n"+
+			"-----END PGP PUBLIC KEY BLOCK-----\n",
+		string(armor(publicKeyBlock, data)))
+}
@@ -0,0 +1,133 @@
+package openpgp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Signature packets are described in RFC 4880, section 5.2.3
+// https://www.rfc-editor.org/rfc/rfc4880#section-5.2.3
+
+const (
+	v4SignatureVersion = 4
+	v5SignatureVersion = 5
+	v6SignatureVersion = 6
+)
+
+const (
+	genericCertificationSignature  = 0x10
+	positiveCertificationSignature = 0x13
+	subkeyBindingSignature         = 0x18
+	directKeySignature             = 0x1F
+)
+
+const (
+	signatureCreationTimeSubpacket = 2
+	keyExpirationTimeSubpacket     = 9
+	issuerSubpacket                = 16
+	preferredHashSubpacket         = 21
+	primaryUserIDSubpacket         = 25
+	keyFlagsSubpacket              = 27
+	featuresSubpacket              = 30
+	issuerFingerprintSubpacket     = 33
+)
+
+const (
+	certifyKeyFlag = 0x01
+	signDataFlag   = 0x02
+)
+
+const modificationDetectionFeature = 0x01
+
+var hashAlgorithmIDs = map[crypto.Hash]byte{
+	crypto.SHA256: 8,
+	crypto.SHA384: 9,
+	crypto.SHA512: 10,
+}
+
+func subpacket(subpacketType byte, data ...byte) []byte {
+	return concat([]byte{byte(len(data) + 1), subpacketType}, data)
+}
+
+// hashFor returns a hash that is at least as strong as the key it will be used with
+func (k *Key) hashFor() crypto.Hash {
+	if p, ok := k.public.(*ecdsa.PublicKey); ok {
+		switch p.Curve {
+		case elliptic.P384():
+			return crypto.SHA384
+		case elliptic.P521():
+			return crypto.SHA512
+		}
+	}
+	return crypto.SHA256
+}
+
+func (k *Key) hashedSubpackets() []byte {
+	return concat(
+		subpacket(signatureCreationTimeSubpacket, uint32Bytes(uint32(k.created.Unix()))...),
+		subpacket(keyFlagsSubpacket, certifyKeyFlag|signDataFlag),
+		subpacket(preferredHashSubpacket, hashAlgorithmIDs[crypto.SHA512], hashAlgorithmIDs[crypto.SHA384], hashAlgorithmIDs[crypto.SHA256]),
+		subpacket(featuresSubpacket, modificationDetectionFeature),
+		subpacket(issuerFingerprintSubpacket, append([]byte{v4KeyVersion}, k.Fingerprint()...)...),
+	)
+}
+
+func (k *Key) unhashedSubpackets() []byte {
+	return subpacket(issuerSubpacket, k.KeyID()...)
+}
+
+// selfSignature returns the body of a positive certification of the user ID by the key itself.
+// The signature uses the creation time of the key, so the output is stable for deterministic
+// signature algorithms
+func (k *Key) selfSignature() ([]byte, error) {
+	h := k.hashFor()
+	hashed := k.hashedSubpackets()
+	signed := concat(
+		[]byte{v4SignatureVersion, positiveCertificationSignature, k.algorithm, hashAlgorithmIDs[h]},
+		uint16Bytes(uint16(len(hashed))),
+		hashed,
+	)
+	trailer := concat([]byte{v4SignatureVersion, 0xFF}, uint32Bytes(uint32(len(signed))))
+
+	hasher := h.New()
+	hasher.Write(k.hashedPublicKey())
+	hasher.Write(concat([]byte{0xB4}, uint32Bytes(uint32(len(k.userID))), []byte(k.userID)))
+	hasher.Write(signed)
+	hasher.Write(trailer)
+	digest := hasher.Sum(nil)
+
+	sig, e := k.sign(h, digest)
+	if e != nil {
+		return nil, e
+	}
+
+	unhashed := k.unhashedSubpackets()
+	return concat(signed, uint16Bytes(uint16(len(unhashed))), unhashed, digest[:2], sig), nil
+}
+
+func (k *Key) sign(h crypto.Hash, digest []byte) ([]byte, error) {
+	switch p := k.private.(type) {
+	case ed25519.PrivateKey:
+		sig := ed25519.Sign(p, digest)
+		return concat(mpiFromBytes(sig[:32]), mpiFromBytes(sig[32:])), nil
+	case *rsa.PrivateKey:
+		sig, e := rsa.SignPKCS1v15(nil, p, h, digest)
+		if e != nil {
+			return nil, e
+		}
+		return mpiFromBytes(sig), nil
+	case *ecdsa.PrivateKey:
+		r, s, e := ecdsa.Sign(rand.Reader, p, digest)
+		if e != nil {
+			return nil, e
+		}
+		return concat(mpi(r), mpi(s)), nil
+	}
+	return nil, ErrUnsupportedKey
+}
@@ -0,0 +1,115 @@
+package openpgp
+
+import (
+	"bytes"
+	"time"
+)
+
+// Only the subpackets needed to find user IDs and expiry times are read, and signatures are not verified
+
+const subpacketTypeMask = 0x7F
+
+type signature struct {
+	signatureType     byte
+	created           time.Time
+	keyExpiry         time.Duration
+	issuer            []byte
+	issuerFingerprint []byte
+	primaryUserID     bool
+}
+
+func parseSignature(body []byte) (*signature, bool) {
+	version, rest, ok := readByte(body)
+	if !ok || version < v4SignatureVersion || version > v6SignatureVersion {
+		return nil, false
+	}
+
+	header, rest, ok := readBytes(rest, 3)
+	if !ok {
+		return nil, false
+	}
+	sig := &signature{signatureType: header[0]}
+
+	hashed, rest, ok := readSubpacketArea(rest, version)
+	if !ok || !sig.readSubpackets(hashed, true) {
+		return nil, false
+	}
+	unhashed, _, ok := readSubpacketArea(rest, version)
+	if !ok || !sig.readSubpackets(unhashed, false) {
+		return nil, false
+	}
+	return sig, true
+}
+
+// readSubpacketArea reads the subpackets of one area. The length of the area takes four octets in V6 signatures
+func readSubpacketArea(data []byte, version byte) ([]byte, []byte, bool) {
+	if version == v6SignatureVersion {
+		l, rest, ok := readUint32(data)
+		if !ok {
+			return nil, nil, false
+		}
+		return readBytes(rest, int(l))
+	}
+
+	l, rest, ok := readUint16(data)
+	if !ok {
+		return nil, nil, false
+	}
+	return readBytes(rest, l)
+}
+
+// readSubpackets reads the subpackets of one area. The issuer can be in the unhashed area, but
+// everything else is only trusted when it is in the hashed area
+func (s *signature) readSubpackets(data []byte, hashed bool) bool {
+	for len(data) > 0 {
+		l, rest, ok := readNewFormatLength(data)
+		if !ok || l == 0 {
+			return false
+		}
+		var sp []byte
+		sp, data, ok = readBytes(rest, l)
+		if !ok {
+			return false
+		}
+		s.readSubpacket(sp[0]&subpacketTypeMask, sp[1:], hashed)
+	}
+	return true
+}
+
+func (s *signature) readSubpacket(subpacketType byte, value []byte, hashed bool) {
+	switch {
+	case subpacketType == issuerSubpacket:
+		s.issuer = value
+	case subpacketType == issuerFingerprintSubpacket && len(value) > 0:
+		s.issuerFingerprint = value[1:]
+	case !hashed:
+	case subpacketType == signatureCreationTimeSubpacket:
+		if t, _, ok := readUint32(value); ok {
+			s.created = time.Unix(int64(t), 0)
+		}
+	case subpacketType == keyExpirationTimeSubpacket:
+		if t, _, ok := readUint32(value); ok {
+			s.keyExpiry = time.Duration(t) * time.Second
+		}
+	case subpacketType == primaryUserIDSubpacket:
+		s.primaryUserID = len(value) > 0 && value[0] != 0
+	}
+}
+
+func (s *signature) isCertification() bool {
+	return s.signatureType >= genericCertificationSignature && s.signatureType <= positiveCertificationSignature
+}
+
+// isBy returns true if the signature was made by the key
+func (s *signature) isBy(k *KeyPacket) bool {
+	if s.issuerFingerprint != nil {
+		return bytes.Equal(s.issuerFingerprint, k.Fingerprint())
+	}
+	return s.issuer != nil && bytes.Equal(s.issuer, k.KeyID())
+}
+
+// isNewerThan returns true if the signature should replace the other one. Signatures without a creation
+// time are invalid, but they are still used when nothing better is available
+func (s *signature) isNewerThan(other *signature) bool {
+	return other == nil || s.created.After(other.created)
+}
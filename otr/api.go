@@ -0,0 +1,84 @@
+package otr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/sirupsen/logrus"
+)
+
+// store is where an OTR client keeps the private keys of its accounts and the fingerprints of its contacts
+type store struct {
+	privateKeyFile   string
+	fingerprintsFile string
+}
+
+// Access returns a key access that lists the keys of the accounts and the fingerprints of the contacts
+// stored by the OTR plugins of Pidgin and irssi
+func Access(l logrus.FieldLogger) api.KeyAccess {
+	home, _ := os.UserHomeDir()
+	return &access{
+		log: l.WithField("component", "otr"),
+		stores: []store{
+			{filepath.Join(home, ".purple", "otr.private_key"), filepath.Join(home, ".purple", "otr.fingerprints")},
+			{filepath.Join(home, ".irssi", "otr", "otr.key"), filepath.Join(home, ".irssi", "otr", "otr.fp")},
+		},
+	}
+}
+
+type access struct {
+	log    logrus.Ext1FieldLogger
+	stores []store
+}
+
+func (a *access) accountKeysFrom(fileName string) []api.KeyEntry {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the OTR private key file")
+		return nil
+	}
+
+	keys, e := parsePrivateKeyFile(content)
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Warn("couldn't parse the OTR private key file")
+		return nil
+	}
+
+	result := []api.KeyEntry{}
+	for _, k := range keys {
+		result = append(result, &accountKeyEntry{location: fileName, key: k})
+	}
+	return result
+}
+
+func (a *access) peerKeysFrom(fileName string) []api.KeyEntry {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the OTR fingerprints file")
+		return nil
+	}
+
+	result := []api.KeyEntry{}
+	for _, fp := range parseFingerprintsFile(string(content)) {
+		result = append(result, &peerKeyEntry{location: fileName, fingerprint: fp})
+	}
+	return result
+}
+
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	result := []api.KeyEntry{}
+	for _, s := range a.stores {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		result = append(result, a.accountKeysFrom(s.privateKeyFile)...)
+		result = append(result, a.peerKeysFrom(s.fingerprintsFile)...)
+	}
+	return result, nil
+}
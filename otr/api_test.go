@@ -0,0 +1,103 @@
+package otr
+
+import (
+	"context"
+	"crypto/dsa"
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *otrSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(stores ...store) *access {
+	logger := testutil.NullLogger()
+	return &access{log: logger, stores: stores}
+}
+
+func (s *otrSuite) Test_access_AllKeys_listsTheAccountKeysAndTheFingerprintsOfContacts() {
+	dir := s.T().TempDir()
+	privateKeyFile := filepath.Join(dir, "otr.private_key")
+	fingerprintsFile := filepath.Join(dir, "otr.fingerprints")
+	s.Require().NoError(os.WriteFile(privateKeyFile, []byte(privateKeyFileForTest), 0600))
+	s.Require().NoError(os.WriteFile(fingerprintsFile, []byte(fingerprintsFileForTest), 0600))
+
+	keys := s.allKeysOf(accessForTest(
+		store{privateKeyFile, fingerprintsFile},
+		store{filepath.Join(dir, "missing.key"), filepath.Join(dir, "missing.fp")},
+	))
+	s.Require().Len(keys, 4)
+
+	own := keys[0].(api.OTRKeyEntry)
+	s.Equal(api.PairKeyType, own.KeyType())
+	s.Equal([]string{privateKeyFile}, own.Locations())
+	s.Equal([]string{privateKeyFile}, own.PrivateKeyLocations())
+	s.Equal(api.DSA, own.Algorithm())
+	s.Equal(1024, own.Size())
+	s.Equal("alice@example.org", own.OTRAccount())
+	s.Equal("alice@example.org", own.(api.PublicKeyEntry).UserID())
+	s.Equal("prpl-jabber", own.OTRProtocol())
+	s.Equal(privateKeyFingerprintForTest, FormatFingerprint(own.OTRFingerprint()))
+	s.Empty(own.OTRTrust())
+	s.False(own.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.IsType(&dsa.PublicKey{}, own.(api.PublicKeyMaterialEntry).PublicKey())
+	s.Equal(own.OTRFingerprint(), own.(api.PublicKeyEntry).WithDigestContent(func(b []byte) []byte {
+		res := sha1.Sum(b)
+		return res[:]
+	}))
+	s.Equal(fingerprint.SSHWireFormat(own.(api.PublicKeyMaterialEntry).PublicKey()), own.(api.SSHPublicKeyEntry).SSHPublicKey())
+	s.NotNil(own.(api.SSHPublicKeyEntry).SSHPublicKey())
+
+	peer := keys[1].(api.OTRKeyEntry)
+	s.Equal(api.PublicKeyType, peer.KeyType())
+	s.Equal([]string{fingerprintsFile}, peer.Locations())
+	s.Nil(peer.PrivateKeyLocations())
+	s.Equal(api.DSA, peer.Algorithm())
+	s.Zero(peer.Size())
+	s.Equal("bob@example.org", peer.OTRAccount())
+	s.Equal("prpl-jabber", peer.OTRProtocol())
+	s.Equal(testutil.DecodeHex("0123456789abcdef0123456789abcdef01234567"), peer.OTRFingerprint())
+	s.Equal("verified", peer.OTRTrust())
+	_, isPublicKeyEntry := peer.(api.PublicKeyEntry)
+	s.False(isPublicKeyEntry)
+}
+
+func (s *otrSuite) Test_keyEntries_ID_isTheSameForAnAccountKeyAndAFingerprintOfIt() {
+	dir := s.T().TempDir()
+	privateKeyFile := filepath.Join(dir, "otr.private_key")
+	s.Require().NoError(os.WriteFile(privateKeyFile, []byte(privateKeyFileForTest), 0600))
+	own := s.allKeysOf(accessForTest(store{privateKeyFile, filepath.Join(dir, "otr.fingerprints")}))[0].(*accountKeyEntry)
+
+	peer := &peerKeyEntry{fingerprint: &peerFingerprint{fingerprint: own.OTRFingerprint()}}
+	s.Equal(own.ID(), peer.ID())
+
+	other := &peerKeyEntry{fingerprint: &peerFingerprint{fingerprint: testutil.DecodeHex("0123456789abcdef0123456789abcdef01234567")}}
+	s.NotEqual(own.ID(), other.ID())
+}
+
+func (s *otrSuite) Test_access_AllKeys_skipsPrivateKeyFilesThatCanNotBeParsed() {
+	dir := s.T().TempDir()
+	privateKeyFile := filepath.Join(dir, "otr.private_key")
+	s.Require().NoError(os.WriteFile(privateKeyFile, []byte("(privkeys"), 0600))
+
+	s.Empty(s.allKeysOf(accessForTest(store{privateKeyFile, filepath.Join(dir, "otr.fingerprints")})))
+}
+
+func (s *otrSuite) Test_Access_looksInTheStoresOfPidginAndIrssi() {
+	logger := testutil.NullLogger()
+	a := Access(logger).(*access)
+
+	home, _ := os.UserHomeDir()
+	s.Equal([]store{
+		{filepath.Join(home, ".purple", "otr.private_key"), filepath.Join(home, ".purple", "otr.fingerprints")},
+		{filepath.Join(home, ".irssi", "otr", "otr.key"), filepath.Join(home, ".irssi", "otr", "otr.fp")},
+	}, a.stores)
+}
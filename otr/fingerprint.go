@@ -0,0 +1,42 @@
+package otr
+
+import (
+	"crypto/dsa"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// serializePublicKey returns the public key as OTR sends it, without the type, which is what the
+// fingerprint is calculated from. Each parameter is written as an MPI, with a four byte length
+func serializePublicKey(pub *dsa.PublicKey) []byte {
+	result := []byte{}
+	for _, v := range []*big.Int{pub.P, pub.Q, pub.G, pub.Y} {
+		b := v.Bytes()
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(b)))
+		result = append(append(result, length...), b...)
+	}
+	return result
+}
+
+func fingerprintOf(pub *dsa.PublicKey) []byte {
+	fp := sha1.Sum(serializePublicKey(pub))
+	return fp[:]
+}
+
+// FormatFingerprint returns the fingerprint the way OTR clients show it, as five
+// groups of eight hexadecimal digits
+func FormatFingerprint(fp []byte) string {
+	groups := []string{}
+	for i := 0; i < len(fp); i += 4 {
+		end := i + 4
+		if end > len(fp) {
+			end = len(fp)
+		}
+		groups = append(groups, fmt.Sprintf("%X", fp[i:end]))
+	}
+	return strings.Join(groups, " ")
+}
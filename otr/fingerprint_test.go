@@ -0,0 +1,24 @@
+package otr
+
+import "github.com/digitalautonomy/keymirror/internal/testutil"
+
+func (s *otrSuite) Test_fingerprintOf_isTheDigestOfTheSerializedPublicKey() {
+	keys, _ := parsePrivateKeyFile([]byte(privateKeyFileForTest))
+	s.Equal(privateKeyFingerprintForTest, FormatFingerprint(fingerprintOf(&keys[0].key.PublicKey)))
+}
+
+func (s *otrSuite) Test_serializePublicKey_writesTheParametersAsMPIs() {
+	keys, _ := parsePrivateKeyFile([]byte(privateKeyFileForTest))
+	serialized := serializePublicKey(&keys[0].key.PublicKey)
+
+	s.Equal(4+128+4+20+4+128+4+128, len(serialized))
+	s.Equal([]byte{0, 0, 0, 128}, serialized[:4])
+	s.Equal(keys[0].key.P.Bytes(), serialized[4:132])
+	s.Equal([]byte{0, 0, 0, 20}, serialized[132:136])
+}
+
+func (s *otrSuite) Test_FormatFingerprint_groupsTheHexadecimalDigits() {
+	s.Equal("01234567 89ABCDEF 01234567 89ABCDEF 01234567", FormatFingerprint(testutil.DecodeHex("0123456789abcdef0123456789abcdef01234567")))
+	s.Equal("01234567 89", FormatFingerprint(testutil.DecodeHex("0123456789")))
+	s.Equal("", FormatFingerprint(nil))
+}
@@ -0,0 +1,55 @@
+package otr
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// libotr stores the fingerprints of the keys of contacts in a text file, one per line, with
+// tab separated fields: the name of the contact, the account, the protocol, the fingerprint
+// in hexadecimal, and how the fingerprint was verified, if it was
+
+const fingerprintLength = 20
+
+// peerFingerprint is the fingerprint of a contact's key, as seen from one of our accounts
+type peerFingerprint struct {
+	username    string
+	account     string
+	protocol    string
+	fingerprint []byte
+	trust       string
+}
+
+func parseFingerprintLine(line string) (*peerFingerprint, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 4 {
+		return nil, false
+	}
+
+	fp, e := hex.DecodeString(fields[3])
+	if e != nil || len(fp) != fingerprintLength {
+		return nil, false
+	}
+
+	result := &peerFingerprint{
+		username:    fields[0],
+		account:     fields[1],
+		protocol:    fields[2],
+		fingerprint: fp,
+	}
+	if len(fields) > 4 {
+		result.trust = fields[4]
+	}
+	return result, true
+}
+
+// parseFingerprintsFile returns the fingerprints in the file, skipping the lines that can't be read
+func parseFingerprintsFile(content string) []*peerFingerprint {
+	result := []*peerFingerprint{}
+	for _, line := range strings.Split(content, "\n") {
+		if fp, ok := parseFingerprintLine(strings.TrimRight(line, "\r")); ok {
+			result = append(result, fp)
+		}
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package otr
+
+import "github.com/digitalautonomy/keymirror/internal/testutil"
+
+func (s *otrSuite) Test_parseFingerprintsFile_readsTheFingerprintsOfContacts() {
+	fps := parseFingerprintsFile(fingerprintsFileForTest)
+	s.Require().Len(fps, 3)
+
+	s.Equal(&peerFingerprint{
+		username:    "bob@example.org",
+		account:     "alice@example.org",
+		protocol:    "prpl-jabber",
+		fingerprint: testutil.DecodeHex("0123456789abcdef0123456789abcdef01234567"),
+		trust:       "verified",
+	}, fps[0])
+	s.Equal("carol@example.org", fps[1].username)
+	s.Empty(fps[1].trust)
+	s.Equal("eve@example.org", fps[2].username)
+	s.Equal("smp", fps[2].trust)
+}
+
+func (s *otrSuite) Test_parseFingerprintsFile_skipsLinesThatCanNotBeRead() {
+	s.Empty(parseFingerprintsFile("\nbob@example.org\talice@example.org\n" +
+		"bob@example.org\talice@example.org\tprpl-jabber\t0123456789abcdef\n"))
+}
@@ -0,0 +1,145 @@
+package otr
+
+import (
+	"crypto"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+)
+
+// accountKeyEntry represents the key of one of our accounts. The private key file
+// contains both the public and the private parts of the key
+type accountKeyEntry struct {
+	location string
+	key      *accountKey
+}
+
+// ID implements the api.KeyEntry interface. It's derived from the OTR fingerprint, since that is all
+// that is known about the keys of contacts, so our own key has the same ID in the fingerprints of others
+func (k *accountKeyEntry) ID() string {
+	return api.MaterialID("otr", k.OTRFingerprint())
+}
+
+func (k *accountKeyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *accountKeyEntry) Locations() []string {
+	return []string{k.location}
+}
+
+func (k *accountKeyEntry) PublicKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *accountKeyEntry) PrivateKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *accountKeyEntry) KeyType() api.KeyType {
+	return api.PairKeyType
+}
+
+func (k *accountKeyEntry) Size() int {
+	return k.key.key.P.BitLen()
+}
+
+func (k *accountKeyEntry) Algorithm() api.Algorithm {
+	return api.DSA
+}
+
+// WithDigestContent implements the api.PublicKeyEntry interface. The content is the
+// serialized public key, so the SHA-1 digest is the OTR fingerprint
+func (k *accountKeyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(serializePublicKey(&k.key.key.PublicKey))
+}
+
+// SSHPublicKey implements the api.SSHPublicKeyEntry interface
+func (k *accountKeyEntry) SSHPublicKey() []byte {
+	return fingerprint.SSHWireFormat(&k.key.key.PublicKey)
+}
+
+func (k *accountKeyEntry) UserID() string {
+	return k.key.account
+}
+
+// IsPasswordProtected implements the api.PrivateKeyEntry interface. OTR private keys are never protected
+func (k *accountKeyEntry) IsPasswordProtected() bool {
+	return false
+}
+
+// PublicKey implements the api.PublicKeyMaterialEntry interface
+func (k *accountKeyEntry) PublicKey() crypto.PublicKey {
+	return &k.key.key.PublicKey
+}
+
+func (k *accountKeyEntry) OTRAccount() string {
+	return k.key.account
+}
+
+func (k *accountKeyEntry) OTRProtocol() string {
+	return k.key.protocol
+}
+
+func (k *accountKeyEntry) OTRFingerprint() []byte {
+	return fingerprintOf(&k.key.key.PublicKey)
+}
+
+func (k *accountKeyEntry) OTRTrust() string {
+	return ""
+}
+
+// peerKeyEntry represents the key of a contact, of which only the fingerprint is stored
+type peerKeyEntry struct {
+	location    string
+	fingerprint *peerFingerprint
+}
+
+func (k *peerKeyEntry) ID() string {
+	return api.MaterialID("otr", k.fingerprint.fingerprint)
+}
+
+func (k *peerKeyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *peerKeyEntry) Locations() []string {
+	return []string{k.location}
+}
+
+func (k *peerKeyEntry) PublicKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *peerKeyEntry) PrivateKeyLocations() []string {
+	return nil
+}
+
+func (k *peerKeyEntry) KeyType() api.KeyType {
+	return api.PublicKeyType
+}
+
+// Size returns zero, since the size of the key can't be known from its fingerprint
+func (k *peerKeyEntry) Size() int {
+	return 0
+}
+
+func (k *peerKeyEntry) Algorithm() api.Algorithm {
+	return api.DSA
+}
+
+func (k *peerKeyEntry) OTRAccount() string {
+	return k.fingerprint.username
+}
+
+func (k *peerKeyEntry) OTRProtocol() string {
+	return k.fingerprint.protocol
+}
+
+func (k *peerKeyEntry) OTRFingerprint() []byte {
+	return k.fingerprint.fingerprint
+}
+
+func (k *peerKeyEntry) OTRTrust() string {
+	return k.fingerprint.trust
+}
@@ -0,0 +1,41 @@
+package otr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type otrSuite struct {
+	suite.Suite
+}
+
+func TestOtrSuite(t *testing.T) {
+	suite.Run(t, new(otrSuite))
+}
+
+// privateKeyFileForTest is written the way libotr writes private key files, with a DSA key generated
+// by crypto/dsa. The leading zero of p is how libgcrypt writes numbers with the high bit set
+const privateKeyFileForTest = `(privkeys
+ (account
+(name "alice@example.org")
+(protocol prpl-jabber)
+(private-key 
+ (dsa 
+  (p #00C2009EB98723A59C92BFC78B74DD226774D4ED014BD1250ACFC538560CF953EB977CE64283EBF926F118C4A0B99C648480D39C27774E356FA3719251C97D2832F37E0BFE31D95B77920F5663D6D20F5254AED7303298AA7EED4DCD81D223FFF8135DFADEBC9742168132BB6128FC7ECF1209A5A32A5246BEF5B85F02C9A383E7#)
+  (q #00B22E2174F85C186476398934D802B0CF11A5DF57#)
+  (g #6E6FFF280228DF458D0E41ED2A3D0FAA7158CC102AD75F0868FBB169896DC4FC59878ED784AEE8F65DCA8E4F6B6A3DBC8D4CA92DE19AC920AE5F7DF6E5D395AED130D7434B0E9850D95A302482B19D77D1B6A35367E177155B066912A38A25F152FD68771B0AF0B81C75D04F260942359C64F7E3D28F8A35FBC533F8F28F3A81#)
+  (y #00BB956E0202A7406CCC50BFF6E6AC06FEDDC90A7509373AFDE1152A9BE9B7251D4650A0F0A919048B36C8226C28D3E058DF1CC63B999293F4895B0F932DAE77A94BFC906BA30395F470FE1155341393CD501888C019DD2DCFC43F650B80A508191E022012D59A75F66337A519A7825A27202BFF1B7BA68046C2DF40437ECFF766#)
+  (x #17EFB39EBCD96B8D421BF1BA456FA47C06F20051#)
+  )
+ )
+ )
+)
+`
+
+const privateKeyFingerprintForTest = "44967B72 19AA3111 64E7D3F0 6481FCE0 D23D411C"
+
+const fingerprintsFileForTest = "bob@example.org\talice@example.org\tprpl-jabber\t0123456789abcdef0123456789abcdef01234567\tverified\n" +
+	"carol@example.org\talice@example.org\tprpl-jabber\tfedcba9876543210fedcba9876543210fedcba98\t\n" +
+	"dave@example.org\talice@example.org\tprpl-jabber\tnot a fingerprint\n" +
+	"eve@example.org\talice@example.org\tprpl-jabber\t00112233445566778899aabbccddeeff00112233\tsmp\r\n"
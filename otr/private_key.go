@@ -0,0 +1,100 @@
+package otr
+
+import (
+	"crypto/dsa"
+	"errors"
+	"math/big"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/sexp"
+)
+
+// libotr stores the private keys of all accounts in one file, as an S-expression in the advanced format:
+//
+//	(privkeys
+//	 (account
+//	  (name "alice@example.org")
+//	  (protocol prpl-jabber)
+//	  (private-key (dsa (p #00FC...#) (q #...#) (g #...#) (y #...#) (x #...#)))))
+
+var errInvalidPrivateKeyFile = errors.New("invalid OTR private key file")
+
+// dsaKeyName is the name libgcrypt gives to DSA keys, the same one gpg-agent uses
+const dsaKeyName = "dsa"
+
+func init() {
+	api.RegisterWireNames(api.DSA, dsaKeyName)
+}
+
+// accountKey is the long-lived DSA key of one account
+type accountKey struct {
+	account  string
+	protocol string
+	key      *dsa.PrivateKey
+}
+
+func parameterOf(params *sexp.Expression, name string) (*big.Int, bool) {
+	v := params.ValueOf(name)
+	if len(v) == 0 {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(v), true
+}
+
+func parseDSAKey(privateKey *sexp.Expression) (*dsa.PrivateKey, bool) {
+	params := privateKey.Find(dsaKeyName)
+	if params == nil {
+		return nil, false
+	}
+
+	k := &dsa.PrivateKey{}
+	for _, p := range []struct {
+		name  string
+		value **big.Int
+	}{{"p", &k.P}, {"q", &k.Q}, {"g", &k.G}, {"y", &k.Y}, {"x", &k.X}} {
+		v, ok := parameterOf(params, p.name)
+		if !ok {
+			return nil, false
+		}
+		*p.value = v
+	}
+	return k, true
+}
+
+func parseAccount(account *sexp.Expression) (*accountKey, bool) {
+	privateKey := account.Find("private-key")
+	if privateKey == nil {
+		return nil, false
+	}
+
+	key, ok := parseDSAKey(privateKey)
+	if !ok {
+		return nil, false
+	}
+
+	return &accountKey{
+		account:  string(account.ValueOf("name")),
+		protocol: string(account.ValueOf("protocol")),
+		key:      key,
+	}, true
+}
+
+// parsePrivateKeyFile returns the keys of the accounts in the file. Accounts with keys of
+// other algorithms than DSA are skipped
+func parsePrivateKeyFile(data []byte) ([]*accountKey, error) {
+	s, e := sexp.Parse(data)
+	if e != nil {
+		return nil, e
+	}
+	if s.Name() != "privkeys" {
+		return nil, errInvalidPrivateKeyFile
+	}
+
+	result := []*accountKey{}
+	for _, a := range s.FindAll("account") {
+		if k, ok := parseAccount(a); ok {
+			result = append(result, k)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package otr
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/sexp"
+)
+
+func (s *otrSuite) Test_parsePrivateKeyFile_readsTheDSAKeysOfTheAccounts() {
+	keys, e := parsePrivateKeyFile([]byte(privateKeyFileForTest))
+	s.Require().NoError(e)
+	s.Require().Len(keys, 1)
+
+	s.Equal("alice@example.org", keys[0].account)
+	s.Equal("prpl-jabber", keys[0].protocol)
+	s.Equal(1024, keys[0].key.P.BitLen())
+	s.Equal(160, keys[0].key.Q.BitLen())
+	s.Equal(0, keys[0].key.Y.Cmp(new(big.Int).Exp(keys[0].key.G, keys[0].key.X, keys[0].key.P)))
+}
+
+func (s *otrSuite) Test_parsePrivateKeyFile_skipsAccountsWithoutADSAKey() {
+	content := `(privkeys
+ (account (name "bob@example.org") (protocol prpl-irc) (private-key (rsa (n #01#) (e #03#))))
+ (account (name "carol@example.org") (protocol prpl-irc))
+ (account (name "dave@example.org") (protocol prpl-irc) (private-key (dsa (p #01#) (q #02#) (g #03#) (y #04#))))
+` + strings.TrimPrefix(privateKeyFileForTest, "(privkeys")
+
+	keys, e := parsePrivateKeyFile([]byte(content))
+	s.Require().NoError(e)
+	s.Require().Len(keys, 1)
+	s.Equal("alice@example.org", keys[0].account)
+}
+
+func (s *otrSuite) Test_parsePrivateKeyFile_failsForFilesThatAreNotOTRPrivateKeyFiles() {
+	_, e := parsePrivateKeyFile([]byte("(private-key (dsa (p #01#)))"))
+	s.Equal(errInvalidPrivateKeyFile, e)
+
+	_, e = parsePrivateKeyFile([]byte("(privkeys"))
+	s.Equal(sexp.ErrInvalid, e)
+}
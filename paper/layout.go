@@ -0,0 +1,168 @@
+package paper
+
+import (
+	"fmt"
+
+	"rsc.io/qr"
+)
+
+// quietZone is the number of empty modules the QR specification requires around each code
+const quietZone = 4
+
+// metrics describe the page, in the unit of the output format. A height of zero means
+// that everything fits on one page of whatever height is needed
+type metrics struct {
+	width, height float64
+	margin        float64
+	gap           float64
+	maxModuleSize float64
+	lineHeight    float64
+}
+
+type rectangle struct {
+	x, y, width, height float64
+}
+
+type label struct {
+	x, y float64
+	text string
+}
+
+// page is the list of things to draw, with the origin in the top left corner
+type page struct {
+	rectangles []rectangle
+	labels     []label
+	bottom     float64
+}
+
+type layout struct {
+	m     metrics
+	pages []*page
+	y     float64
+}
+
+func (l *layout) current() *page {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *layout) newPage() {
+	l.pages = append(l.pages, &page{})
+	l.y = l.m.margin
+}
+
+// ensureSpace starts a new page unless the given height still fits on the current one
+func (l *layout) ensureSpace(height float64) {
+	if l.m.height > 0 && l.y+height > l.m.height-l.m.margin && l.y > l.m.margin {
+		l.newPage()
+	}
+}
+
+func (l *layout) advance(height float64) {
+	l.y += height
+	if l.y > l.current().bottom {
+		l.current().bottom = l.y
+	}
+}
+
+func (l *layout) text(s string) {
+	l.ensureSpace(l.m.lineHeight)
+	l.current().labels = append(l.current().labels, label{l.m.margin, l.y, s})
+	l.advance(l.m.lineHeight)
+}
+
+// qrCode draws the black modules, joining the ones next to each other on the same row
+func (p *page) qrCode(c *qr.Code, x, y, moduleSize float64) {
+	for row := 0; row < c.Size; row++ {
+		for column := 0; column < c.Size; {
+			if !c.Black(column, row) {
+				column++
+				continue
+			}
+
+			start := column
+			for column < c.Size && c.Black(column, row) {
+				column++
+			}
+			p.rectangles = append(p.rectangles, rectangle{
+				x + float64(start+quietZone)*moduleSize,
+				y + float64(row+quietZone)*moduleSize,
+				float64(column-start) * moduleSize,
+				moduleSize,
+			})
+		}
+	}
+}
+
+func largestCodeSize(codes []*qr.Code) int {
+	result := 0
+	for _, c := range codes {
+		if c.Size > result {
+			result = c.Size
+		}
+	}
+	return result
+}
+
+// qrCodes places the codes in a grid, with the position of each code below it
+func (l *layout) qrCodes(codes []*qr.Code) {
+	available := l.m.width - 2*l.m.margin
+	columns := 2
+	if len(codes) == 1 {
+		columns = 1
+	}
+
+	moduleSize := (available - float64(columns-1)*l.m.gap) / float64(columns) / float64(largestCodeSize(codes)+2*quietZone)
+	if moduleSize > l.m.maxModuleSize {
+		moduleSize = l.m.maxModuleSize
+	}
+	side := float64(largestCodeSize(codes)+2*quietZone) * moduleSize
+
+	for i := 0; i < len(codes); i += columns {
+		l.ensureSpace(side + l.m.lineHeight)
+		for j := i; j < i+columns && j < len(codes); j++ {
+			x := l.m.margin + float64(j-i)*(side+l.m.gap)
+			l.current().qrCode(codes[j], x, l.y, moduleSize)
+			l.current().labels = append(l.current().labels, label{x + quietZone*moduleSize, l.y + side, fmt.Sprintf("QR code %d of %d", j+1, len(codes))})
+		}
+		l.advance(side + l.m.lineHeight + l.m.gap)
+	}
+}
+
+func (b *Backup) title() string {
+	if b.Name == "" {
+		return "KeyMirror paper backup"
+	}
+	return fmt.Sprintf("KeyMirror paper backup of %s", b.Name)
+}
+
+var instructions = []string{
+	"To restore the key, scan the QR codes or type in the lines below,",
+	"and import them from the File menu of KeyMirror. The last two",
+	"characters of each line are a checksum of the line.",
+}
+
+// layoutPages places the title, instructions, QR codes and the human readable block
+func (b *Backup) layoutPages(m metrics) ([]*page, error) {
+	codes, e := b.QRCodes()
+	if e != nil {
+		return nil, e
+	}
+
+	l := &layout{m: m}
+	l.newPage()
+
+	l.text(b.title())
+	l.advance(l.m.lineHeight)
+	for _, s := range instructions {
+		l.text(s)
+	}
+	l.advance(l.m.gap)
+
+	l.qrCodes(codes)
+
+	for _, s := range b.TextLines() {
+		l.text(s)
+	}
+
+	return l.pages, nil
+}
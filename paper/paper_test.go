@@ -0,0 +1,169 @@
+package paper
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type paperSuite struct {
+	suite.Suite
+}
+
+func TestPaperSuite(t *testing.T) {
+	suite.Run(t, new(paperSuite))
+}
+
+func contentForTest(n int) []byte {
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = byte(i * 7)
+	}
+	return result
+}
+
+func (s *paperSuite) Test_Backup_TextLines_groupsTheContentWithChecksums() {
+	b := &Backup{Name: "id_ed25519", Content: []byte("hello world, this is a paper backup")}
+
+	s.Equal([]string{
+		"-----BEGIN KEYMIRROR PAPER BACKUP-----",
+		"Name: id_ed25519",
+		"Length: 35",
+		"Checksum: " + group(b.Checksum()),
+		"",
+		"001 NBSW Y3DP EB3W 64TM MQWC A5DI NFZS A2LT " + lineChecksum(1, []byte("hello world, this is")),
+		"002 EBQS A4DB OBSX EIDC MFRW W5LQ " + lineChecksum(2, []byte(" a paper backup")),
+		"-----END KEYMIRROR PAPER BACKUP-----",
+	}, b.TextLines())
+}
+
+func (s *paperSuite) Test_lineChecksum_dependsOnTheLineNumber() {
+	s.Len(lineChecksum(1, []byte("abc")), 2)
+	s.NotEqual(lineChecksum(1, []byte("abc")), lineChecksum(2, []byte("abc")))
+	s.NotEqual(lineChecksum(1, []byte("abc")), lineChecksum(1, []byte("abd")))
+}
+
+func (s *paperSuite) Test_Decode_restoresTheTextBlock() {
+	b := &Backup{Name: "id_rsa", Content: contentForTest(1000)}
+
+	res, e := Decode(b.TextBlock())
+	s.Require().NoError(e)
+	s.Equal(b, res)
+}
+
+func (s *paperSuite) Test_Decode_acceptsTypedTextInAnyCaseAndOrderWithOtherTextAround() {
+	b := &Backup{Content: contentForTest(50)}
+	lines := b.TextLines()
+
+	typed := strings.Join([]string{
+		"Some instructions from the page",
+		strings.ToLower(lines[6]),
+		lines[2],
+		strings.ReplaceAll(lines[4], " ", "  "),
+		lines[5],
+		lines[1],
+	}, "\n")
+
+	res, e := Decode(typed)
+	s.Require().NoError(e)
+	s.Equal(b.Content, res.Content)
+}
+
+func (s *paperSuite) Test_Decode_pointsToTheLineWithAMistake() {
+	b := &Backup{Content: contentForTest(50)}
+	text := strings.Replace(b.TextBlock(), "002 "+b.TextLines()[5][4:8], "002 AAAA", 1)
+
+	_, e := Decode(text)
+	s.Equal(&LineChecksumError{Line: 2}, e)
+	s.Equal("line 2 doesn't match its checksum", e.Error())
+}
+
+func (s *paperSuite) Test_Decode_detectsMissingOrChangedContent() {
+	b := &Backup{Content: contentForTest(50)}
+	lines := b.TextLines()
+
+	_, e := Decode(strings.Join(append(lines[:4], lines[5:]...), "\n"))
+	s.Equal(errMissingLines, e)
+
+	_, e = Decode(strings.Join(append([]string{}, lines[4:]...), "\n"))
+	s.Equal(errMissingChecksum, e)
+
+	_, e = Decode(strings.Replace(b.TextBlock(), lines[2], "Checksum: AAAA AAAA AAAA AAAA", 1))
+	s.Equal(ErrChecksumMismatch, e)
+
+	_, e = Decode("nothing to see here")
+	s.Equal(ErrNoBackup, e)
+}
+
+func (s *paperSuite) Test_Backup_QRCodeContents_splitsTheContent() {
+	b := &Backup{Content: contentForTest(700)}
+	contents := b.QRCodeContents()
+
+	s.Len(contents, 3)
+	s.True(strings.HasPrefix(contents[0], "KMPB1:"+b.Checksum()+":1/3:"))
+	s.True(strings.HasPrefix(contents[2], "KMPB1:"+b.Checksum()+":3/3:"))
+}
+
+func (s *paperSuite) Test_Decode_restoresTheQRCodeContentsInAnyOrder() {
+	b := &Backup{Content: contentForTest(700)}
+	contents := b.QRCodeContents()
+
+	res, e := Decode(strings.Join([]string{contents[2], contents[0], contents[1]}, "\n"))
+	s.Require().NoError(e)
+	s.Equal(b.Content, res.Content)
+
+	_, e = Decode(strings.Join(contents[:2], "\n"))
+	s.Equal(errMissingQRCodes, e)
+
+	other := (&Backup{Content: contentForTest(10)}).QRCodeContents()
+	_, e = Decode(strings.Join(append(other, contents...), "\n"))
+	s.Equal(errMixedBackups, e)
+
+	_, e = Decode("KMPB1:nonsense")
+	s.Equal(errMalformedQRCode, e)
+}
+
+func (s *paperSuite) Test_Backup_QRCodes_usesTheAlphanumericMode() {
+	b := &Backup{Content: contentForTest(bytesPerQRCode)}
+
+	codes, e := b.QRCodes()
+	s.Require().NoError(e)
+	s.Len(codes, 1)
+	// 480 characters of base32 plus the header fit in version 14 in alphanumeric mode
+	s.Equal(4*14+17, codes[0].Size)
+}
+
+func (s *paperSuite) Test_Backup_PDF_createsOnePageForASmallKey() {
+	b := &Backup{Name: "id_(ed25519)", Content: contentForTest(400)}
+
+	content, e := b.PDF()
+	s.Require().NoError(e)
+
+	s.True(bytes.HasPrefix(content, []byte("%PDF-1.4\n")))
+	s.True(bytes.HasSuffix(content, []byte("%%EOF\n")))
+	s.Contains(string(content), "/Count 1 >>")
+	s.Contains(string(content), `(KeyMirror paper backup of id_\(ed25519\))`)
+}
+
+func (s *paperSuite) Test_Backup_PDF_addsPagesForLargeKeys() {
+	content, e := (&Backup{Content: contentForTest(3400)}).PDF()
+	s.Require().NoError(e)
+	s.Contains(string(content), "/Count 6 >>")
+}
+
+func (s *paperSuite) Test_Backup_PNG_createsAnImageAsTallAsNeeded() {
+	content, e := (&Backup{Content: contentForTest(400)}).PNG()
+	s.Require().NoError(e)
+
+	img, e := png.Decode(bytes.NewReader(content))
+	s.Require().NoError(e)
+	s.Equal(1240, img.Bounds().Dx())
+	s.Greater(img.Bounds().Dy(), 1000)
+}
+
+func (s *paperSuite) Test_pdfString_escapesTheText() {
+	s.Equal(`(a\(b\)c\\d?)`, pdfString("a(b)c\\dé"))
+}
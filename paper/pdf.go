@@ -0,0 +1,111 @@
+package paper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The PDF uses A4 pages, measured in points, and the built-in Courier font,
+// so no fonts have to be embedded
+var pdfMetrics = metrics{
+	width:         595,
+	height:        842,
+	margin:        50,
+	gap:           15,
+	maxModuleSize: 3,
+	lineHeight:    13,
+}
+
+const pdfFontSize = 10
+
+// pdfBaseline places the text inside its line, since PDF draws text from the baseline
+const pdfBaseline = 10
+
+type pdfWriter struct {
+	out     bytes.Buffer
+	offsets []int
+}
+
+// object writes the next object, and returns its number
+func (w *pdfWriter) object(content string) int {
+	w.offsets = append(w.offsets, w.out.Len())
+	fmt.Fprintf(&w.out, "%d 0 obj\n%s\nendobj\n", len(w.offsets), content)
+	return len(w.offsets)
+}
+
+func (w *pdfWriter) stream(content []byte) int {
+	return w.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+}
+
+func (w *pdfWriter) trailer(root int) []byte {
+	xref := w.out.Len()
+	fmt.Fprintf(&w.out, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, o := range w.offsets {
+		fmt.Fprintf(&w.out, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&w.out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, xref)
+	return w.out.Bytes()
+}
+
+// pdfString escapes the text for a PDF string, replacing anything that isn't printable ASCII
+func pdfString(s string) string {
+	result := strings.Builder{}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			result.WriteRune('\\')
+			result.WriteRune(r)
+		case r < ' ' || r > '~':
+			result.WriteRune('?')
+		default:
+			result.WriteRune(r)
+		}
+	}
+	return "(" + result.String() + ")"
+}
+
+func pdfPageContent(p *page, m metrics) []byte {
+	content := bytes.Buffer{}
+	if len(p.rectangles) > 0 {
+		content.WriteString("0 g\n")
+		for _, r := range p.rectangles {
+			fmt.Fprintf(&content, "%.2f %.2f %.2f %.2f re\n", r.x, m.height-r.y-r.height, r.width, r.height)
+		}
+		content.WriteString("f\n")
+	}
+
+	for _, l := range p.labels {
+		fmt.Fprintf(&content, "BT /F1 %d Tf %.2f %.2f Td %s Tj ET\n", pdfFontSize, l.x, m.height-l.y-pdfBaseline, pdfString(l.text))
+	}
+	return content.Bytes()
+}
+
+// PDF renders the backup as a PDF document
+func (b *Backup) PDF() ([]byte, error) {
+	pages, e := b.layoutPages(pdfMetrics)
+	if e != nil {
+		return nil, e
+	}
+
+	w := &pdfWriter{}
+	w.out.WriteString("%PDF-1.4\n")
+
+	// The page tree is written last, since it has to refer to all pages, but the
+	// pages also have to refer to it. That is why its number is decided beforehand
+	pagesObject := 2*len(pages) + 2
+	font := w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+
+	kids := []string{}
+	for _, p := range pages {
+		content := w.stream(pdfPageContent(p, pdfMetrics))
+		page := w.object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObject, pdfMetrics.width, pdfMetrics.height, font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+
+	w.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	root := w.object(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+
+	return w.trailer(root), nil
+}
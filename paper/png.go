@@ -0,0 +1,83 @@
+package paper
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// textScale enlarges the built-in bitmap font, which is too small to be read comfortably on paper
+const textScale = 2
+
+// The image has the width of an A4 page at 150 dots per inch, and as much height as needed
+var pngMetrics = metrics{
+	width:         1240,
+	height:        0,
+	margin:        100,
+	gap:           30,
+	maxModuleSize: 6,
+	lineHeight:    float64(basicfont.Face7x13.Height * textScale),
+}
+
+// drawRectangle rounds the corners, so rectangles next to each other don't leave gaps between them
+func drawRectangle(img *image.Gray, r rectangle) {
+	bounds := image.Rect(int(math.Round(r.x)), int(math.Round(r.y)), int(math.Round(r.x+r.width)), int(math.Round(r.y+r.height)))
+	draw.Draw(img, bounds, image.Black, image.Point{}, draw.Src)
+}
+
+// drawLabel draws the text at its normal size first, and then copies it scaled to the image
+func drawLabel(img *image.Gray, l label) {
+	face := basicfont.Face7x13
+	small := image.NewGray(image.Rect(0, 0, font.MeasureString(face, l.text).Ceil(), face.Height))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+
+	d := &font.Drawer{
+		Dst:  small,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(l.text)
+
+	for y := 0; y < small.Bounds().Dy(); y++ {
+		for x := 0; x < small.Bounds().Dx(); x++ {
+			c := small.GrayAt(x, y)
+			for dy := 0; dy < textScale; dy++ {
+				for dx := 0; dx < textScale; dx++ {
+					img.SetGray(int(l.x)+x*textScale+dx, int(l.y)+y*textScale+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// PNG renders the backup as one grayscale image
+func (b *Backup) PNG() ([]byte, error) {
+	pages, e := b.layoutPages(pngMetrics)
+	if e != nil {
+		return nil, e
+	}
+	p := pages[0]
+
+	img := image.NewGray(image.Rect(0, 0, int(pngMetrics.width), int(p.bottom+pngMetrics.margin)))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, r := range p.rectangles {
+		drawRectangle(img, r)
+	}
+	for _, l := range p.labels {
+		drawLabel(img, l)
+	}
+
+	result := bytes.Buffer{}
+	if e := png.Encode(&result, img); e != nil {
+		return nil, e
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,112 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// qrCodePrefix starts the content of every QR code. The content only uses characters from the
+// alphanumeric mode of QR codes, which fits more data in each code than the byte mode
+const qrCodePrefix = "KMPB1:"
+
+// bytesPerQRCode keeps each code small enough to be scanned reliably from paper
+const bytesPerQRCode = 300
+
+var errMissingQRCodes = errors.New("some QR codes of the backup are missing")
+var errMixedBackups = errors.New("the QR codes belong to different backups")
+var errMalformedQRCode = errors.New("the content of a QR code is malformed")
+
+// QRCodeContents returns the text to put in each QR code. Every code carries the checksum of
+// the backup and its position, so they can be scanned in any order
+func (b *Backup) QRCodeContents() []string {
+	checksum := b.Checksum()
+	total := (len(b.Content) + bytesPerQRCode - 1) / bytesPerQRCode
+
+	result := []string{}
+	for i := 0; i < total; i++ {
+		end := (i + 1) * bytesPerQRCode
+		if end > len(b.Content) {
+			end = len(b.Content)
+		}
+		result = append(result, fmt.Sprintf("%s%s:%d/%d:%s", qrCodePrefix, checksum, i+1, total, encoding.EncodeToString(b.Content[i*bytesPerQRCode:end])))
+	}
+	return result
+}
+
+// QRCodes encodes the contents of the QR codes using medium error correction
+func (b *Backup) QRCodes() ([]*qr.Code, error) {
+	result := []*qr.Code{}
+	for _, c := range b.QRCodeContents() {
+		code, e := qr.Encode(c, qr.M)
+		if e != nil {
+			return nil, e
+		}
+		result = append(result, code)
+	}
+	return result, nil
+}
+
+type qrCodeContent struct {
+	checksum string
+	index    int
+	total    int
+	data     []byte
+}
+
+func parseQRCodeContent(content string) (*qrCodeContent, error) {
+	parts := strings.Split(strings.TrimPrefix(normalizeBase32(content), qrCodePrefix), ":")
+	if len(parts) != 3 {
+		return nil, errMalformedQRCode
+	}
+
+	position := strings.Split(parts[1], "/")
+	if len(position) != 2 {
+		return nil, errMalformedQRCode
+	}
+	index, e1 := strconv.Atoi(position[0])
+	total, e2 := strconv.Atoi(position[1])
+	data, e3 := encoding.DecodeString(parts[2])
+	if e1 != nil || e2 != nil || e3 != nil || index < 1 || index > total {
+		return nil, errMalformedQRCode
+	}
+
+	return &qrCodeContent{parts[0], index, total, data}, nil
+}
+
+func decodeQRCodeContents(contents []string) (*Backup, error) {
+	codes := map[int]*qrCodeContent{}
+	var first *qrCodeContent
+
+	for _, c := range contents {
+		code, e := parseQRCodeContent(c)
+		if e != nil {
+			return nil, e
+		}
+		if first == nil {
+			first = code
+		}
+		if code.checksum != first.checksum || code.total != first.total {
+			return nil, errMixedBackups
+		}
+		codes[code.index] = code
+	}
+
+	content := []byte{}
+	for i := 1; i <= first.total; i++ {
+		code, ok := codes[i]
+		if !ok {
+			return nil, errMissingQRCodes
+		}
+		content = append(content, code.data...)
+	}
+
+	b := &Backup{Content: content}
+	if b.Checksum() != first.checksum {
+		return nil, ErrChecksumMismatch
+	}
+	return b, nil
+}
@@ -0,0 +1,213 @@
+package paper
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const textBlockHeader = "-----BEGIN KEYMIRROR PAPER BACKUP-----"
+const textBlockFooter = "-----END KEYMIRROR PAPER BACKUP-----"
+
+const nameField = "Name:"
+const lengthField = "Length:"
+const checksumField = "Checksum:"
+
+// bytesPerLine gives 32 characters of base32 on each line, which are printed in groups
+// to make it easier to type them back in
+const bytesPerLine = 20
+const charactersPerGroup = 4
+const checksumBytes = 10
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var ErrNoBackup = errors.New("the text doesn't contain a paper backup")
+var ErrChecksumMismatch = errors.New("the restored content doesn't match the checksum of the backup")
+var errMissingLines = errors.New("some lines of the backup are missing")
+var errMissingChecksum = errors.New("the checksum of the backup is missing")
+
+// LineChecksumError points to the line that has to be checked for typing or scanning mistakes
+type LineChecksumError struct {
+	Line int
+}
+
+func (e *LineChecksumError) Error() string {
+	return fmt.Sprintf("line %d doesn't match its checksum", e.Line)
+}
+
+// Backup is the content of a paper backup, usually an OpenSSH private key file
+type Backup struct {
+	Name    string
+	Content []byte
+}
+
+// Checksum identifies the content of the backup. It is printed grouped, in the same way as the lines
+func (b *Backup) Checksum() string {
+	sum := sha256.Sum256(b.Content)
+	return encoding.EncodeToString(sum[:checksumBytes])
+}
+
+func group(s string) string {
+	groups := []string{}
+	for len(s) > charactersPerGroup {
+		groups = append(groups, s[:charactersPerGroup])
+		s = s[charactersPerGroup:]
+	}
+	return strings.Join(append(groups, s), " ")
+}
+
+// lineChecksum includes the number of the line, so lines typed in the wrong order are also detected
+func lineChecksum(number int, data []byte) string {
+	h := crc32.NewIEEE()
+	_ = binary.Write(h, binary.BigEndian, uint32(number))
+	_, _ = h.Write(data)
+	sum := h.Sum32()
+	return encoding.EncodeToString([]byte{byte(sum >> 8), byte(sum)})[:2]
+}
+
+func textLine(number int, data []byte) string {
+	return fmt.Sprintf("%03d %s %s", number, group(encoding.EncodeToString(data)), lineChecksum(number, data))
+}
+
+// TextLines returns the human readable block, one line at a time
+func (b *Backup) TextLines() []string {
+	result := []string{textBlockHeader}
+	if b.Name != "" {
+		result = append(result, fmt.Sprintf("%s %s", nameField, b.Name))
+	}
+	result = append(result,
+		fmt.Sprintf("%s %d", lengthField, len(b.Content)),
+		fmt.Sprintf("%s %s", checksumField, group(b.Checksum())),
+		"",
+	)
+
+	for i := 0; i*bytesPerLine < len(b.Content); i++ {
+		end := (i + 1) * bytesPerLine
+		if end > len(b.Content) {
+			end = len(b.Content)
+		}
+		result = append(result, textLine(i+1, b.Content[i*bytesPerLine:end]))
+	}
+
+	return append(result, textBlockFooter)
+}
+
+// TextBlock returns the human readable block as text
+func (b *Backup) TextBlock() string {
+	return strings.Join(b.TextLines(), "\n") + "\n"
+}
+
+func normalizeBase32(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// parseTextLine ignores lines that don't start with a line number, like the instructions
+// printed around the block
+func parseTextLine(line string) (number int, data []byte, ok bool, e error) {
+	fields := strings.Fields(line)
+	number, e = strconv.Atoi(fields[0])
+	if e != nil {
+		return 0, nil, false, nil
+	}
+
+	if len(fields) > 2 {
+		data, e = encoding.DecodeString(normalizeBase32(strings.Join(fields[1:len(fields)-1], "")))
+	}
+	if len(fields) < 3 || e != nil || lineChecksum(number, data) != normalizeBase32(fields[len(fields)-1]) {
+		return number, nil, false, &LineChecksumError{number}
+	}
+	return number, data, true, nil
+}
+
+type textBlock struct {
+	name     string
+	length   int
+	checksum string
+	lines    map[int][]byte
+}
+
+func (tb *textBlock) parseField(line string) bool {
+	switch {
+	case strings.HasPrefix(line, nameField):
+		tb.name = strings.TrimSpace(strings.TrimPrefix(line, nameField))
+	case strings.HasPrefix(line, lengthField):
+		tb.length, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, lengthField)))
+	case strings.HasPrefix(line, checksumField):
+		tb.checksum = normalizeBase32(strings.TrimPrefix(line, checksumField))
+	default:
+		return false
+	}
+	return true
+}
+
+func (tb *textBlock) parseLine(line string) error {
+	if tb.parseField(line) || line == textBlockHeader || line == textBlockFooter {
+		return nil
+	}
+
+	number, data, ok, e := parseTextLine(line)
+	if ok {
+		tb.lines[number] = data
+	}
+	return e
+}
+
+func (tb *textBlock) backup() (*Backup, error) {
+	if tb.checksum == "" {
+		return nil, errMissingChecksum
+	}
+
+	numbers := []int{}
+	for n := range tb.lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	content := []byte{}
+	for i, n := range numbers {
+		if n != i+1 {
+			return nil, errMissingLines
+		}
+		content = append(content, tb.lines[n]...)
+	}
+
+	b := &Backup{Name: tb.name, Content: content}
+	if (tb.length != 0 && len(content) != tb.length) || b.Checksum() != tb.checksum {
+		return nil, ErrChecksumMismatch
+	}
+	return b, nil
+}
+
+// Decode reassembles a backup from scanned or typed text. The text can be either the human
+// readable block, or the content of all the QR codes, one per line, in any order
+func Decode(text string) (*Backup, error) {
+	tb := &textBlock{lines: map[int][]byte{}}
+	codes := []string{}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(strings.ToUpper(line), qrCodePrefix):
+			codes = append(codes, line)
+		default:
+			if e := tb.parseLine(line); e != nil {
+				return nil, e
+			}
+		}
+	}
+
+	if len(codes) > 0 {
+		return decodeQRCodeContents(codes)
+	}
+	if len(tb.lines) == 0 {
+		return nil, ErrNoBackup
+	}
+	return tb.backup()
+}
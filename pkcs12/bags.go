@@ -0,0 +1,124 @@
+package pkcs12
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"unicode/utf16"
+)
+
+var (
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+
+	oidX509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidFriendlyName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+)
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue `asn1:"tag:0,explicit"`
+	Attributes []attribute   `asn1:"set,optional"`
+}
+
+type attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// decodeBMPString decodes the UTF-16 strings used for friendly names
+func decodeBMPString(data []byte) string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+	}
+	return string(utf16.Decode(chars))
+}
+
+// firstValueOf returns the first value of the attribute. Attributes can have more than one
+// value, but the ones used by PKCS#12 only ever have one
+func (b *safeBag) firstValueOf(id asn1.ObjectIdentifier) (asn1.RawValue, bool) {
+	for _, a := range b.Attributes {
+		if a.ID.Equal(id) {
+			v := asn1.RawValue{}
+			if _, e := asn1.Unmarshal(a.Values.Bytes, &v); e == nil {
+				return v, true
+			}
+		}
+	}
+	return asn1.RawValue{}, false
+}
+
+func (b *safeBag) friendlyName() string {
+	v, ok := b.firstValueOf(oidFriendlyName)
+	if !ok {
+		return ""
+	}
+	if v.Tag == asn1.TagBMPString {
+		return decodeBMPString(v.Bytes)
+	}
+	return string(v.Bytes)
+}
+
+func (b *safeBag) localKeyID() []byte {
+	v, ok := b.firstValueOf(oidLocalKeyID)
+	if !ok || v.Tag != asn1.TagOctetString {
+		return nil
+	}
+	return v.Bytes
+}
+
+func (b *safeBag) privateKeyInfo(p *passwordEncodings) ([]byte, error) {
+	if b.ID.Equal(oidKeyBag) {
+		return b.Value.Bytes, nil
+	}
+
+	info := encryptedPrivateKeyInfo{}
+	if e := unmarshalAll(b.Value.Bytes, &info); e != nil {
+		return nil, e
+	}
+	return decrypt(info.Algorithm, p, info.EncryptedData)
+}
+
+// add adds the content of the bag. Keys and certificates that can't be parsed, and other kinds of
+// bags, like the ones for secrets and revocation lists, are ignored
+func (c *Contents) add(b safeBag, p *passwordEncodings) error {
+	switch {
+	case b.ID.Equal(oidKeyBag), b.ID.Equal(oidPKCS8ShroudedKeyBag):
+		der, e := b.privateKeyInfo(p)
+		if e != nil {
+			return e
+		}
+		if k, e := x509.ParsePKCS8PrivateKey(der); e == nil {
+			if signer, ok := k.(crypto.Signer); ok {
+				c.PrivateKeys = append(c.PrivateKeys, &PrivateKey{signer, b.friendlyName(), b.localKeyID()})
+			}
+		}
+	case b.ID.Equal(oidCertBag):
+		cb := certBag{}
+		if e := unmarshalAll(b.Value.Bytes, &cb); e != nil {
+			return e
+		}
+		if !cb.ID.Equal(oidX509Certificate) {
+			return nil
+		}
+		if cert, e := x509.ParseCertificate(cb.Data); e == nil {
+			c.Certificates = append(c.Certificates, &Certificate{cert, b.friendlyName(), b.localKeyID()})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package pkcs12
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd128BitRC2CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHAAnd40BitRC2CBC      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+)
+
+// the hash functions used for the integrity check
+var hashesByOID = map[string]func() hash.Hash{
+	oidSHA1.String():   sha1.New,
+	oidSHA256.String(): sha256.New,
+	oidSHA384.String(): sha512.New384,
+	oidSHA512.String(): sha512.New,
+}
+
+// the pseudorandom functions PBKDF2 can use. SHA-1 is the default when none is given
+var pseudorandomFunctionsByOID = map[string]func() hash.Hash{
+	oidHMACWithSHA1.String():   sha1.New,
+	oidHMACWithSHA256.String(): sha256.New,
+	oidHMACWithSHA384.String(): sha512.New384,
+	oidHMACWithSHA512.String(): sha512.New,
+}
+
+// blockCipher describes a cipher used in CBC mode, with keys of a fixed size
+type blockCipher struct {
+	keySize   int
+	newCipher func(key []byte) (cipher.Block, error)
+}
+
+func newRC2(effectiveBits int) func([]byte) (cipher.Block, error) {
+	return func(key []byte) (cipher.Block, error) {
+		return newRC2Cipher(key, effectiveBits), nil
+	}
+}
+
+// the ciphers of the encryption schemes PBES2 can use
+var pbes2CiphersByOID = map[string]blockCipher{
+	oidAES128CBC.String():  {16, aes.NewCipher},
+	oidAES192CBC.String():  {24, aes.NewCipher},
+	oidAES256CBC.String():  {32, aes.NewCipher},
+	oidDESEDE3CBC.String(): {24, des.NewTripleDESCipher},
+}
+
+// the ciphers of the password based encryption schemes from RFC 7292, appendix C, used by legacy files
+var pkcs12CiphersByOID = map[string]blockCipher{
+	oidPBEWithSHAAnd3KeyTripleDESCBC.String(): {24, des.NewTripleDESCipher},
+	oidPBEWithSHAAnd128BitRC2CBC.String():     {16, newRC2(128)},
+	oidPBEWithSHAAnd40BitRC2CBC.String():      {5, newRC2(40)},
+}
+
+type pbeParameters struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pbes2Parameters struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Parameters struct {
+	Salt                 []byte
+	Iterations           int
+	KeyLength            int                      `asn1:"optional"`
+	PseudorandomFunction pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// passwordEncodings keeps the password in the two encodings used by the different schemes. PBES2 uses
+// the password as is, while the older schemes use the encoding of the PKCS#12 key derivation function
+type passwordEncodings struct {
+	raw []byte
+	bmp []byte
+}
+
+func unmarshalParameters(algorithm pkix.AlgorithmIdentifier, v interface{}) error {
+	rest, e := asn1.Unmarshal(algorithm.Parameters.FullBytes, v)
+	if e != nil || len(rest) != 0 {
+		return errMalformedFile
+	}
+	return nil
+}
+
+func pkcs12DecryptionCipher(algorithm pkix.AlgorithmIdentifier, p *passwordEncodings) (cipher.BlockMode, error) {
+	c, ok := pkcs12CiphersByOID[algorithm.Algorithm.String()]
+	if !ok {
+		return nil, errUnsupportedAlgorithm
+	}
+
+	params := pbeParameters{}
+	if e := unmarshalParameters(algorithm, &params); e != nil {
+		return nil, e
+	}
+
+	key := deriveKey(sha1.New, p.bmp, params.Salt, encryptionKeyID, params.Iterations, c.keySize)
+	block, e := c.newCipher(key)
+	if e != nil {
+		return nil, e
+	}
+	iv := deriveKey(sha1.New, p.bmp, params.Salt, ivID, params.Iterations, block.BlockSize())
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
+
+func pbes2DecryptionCipher(algorithm pkix.AlgorithmIdentifier, p *passwordEncodings) (cipher.BlockMode, error) {
+	params := pbes2Parameters{}
+	if e := unmarshalParameters(algorithm, &params); e != nil {
+		return nil, e
+	}
+
+	c, ok := pbes2CiphersByOID[params.EncryptionScheme.Algorithm.String()]
+	if !ok || !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errUnsupportedAlgorithm
+	}
+
+	kdfParams := pbkdf2Parameters{}
+	if e := unmarshalParameters(params.KeyDerivationFunc, &kdfParams); e != nil {
+		return nil, e
+	}
+
+	prf := sha1.New
+	if kdfParams.PseudorandomFunction.Algorithm != nil {
+		if prf, ok = pseudorandomFunctionsByOID[kdfParams.PseudorandomFunction.Algorithm.String()]; !ok {
+			return nil, errUnsupportedAlgorithm
+		}
+	}
+
+	var iv []byte
+	if e := unmarshalParameters(params.EncryptionScheme, &iv); e != nil {
+		return nil, e
+	}
+
+	key := pbkdf2.Key(p.raw, kdfParams.Salt, kdfParams.Iterations, c.keySize, prf)
+	block, e := c.newCipher(key)
+	if e != nil {
+		return nil, e
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, errMalformedFile
+	}
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
+
+// removePadding returns false when the padding isn't valid, which almost always means that the
+// data was decrypted with the wrong password
+func removePadding(data []byte, blockSize int) ([]byte, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, false
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, false
+		}
+	}
+	return data[:len(data)-n], true
+}
+
+func decrypt(algorithm pkix.AlgorithmIdentifier, p *passwordEncodings, data []byte) ([]byte, error) {
+	var mode cipher.BlockMode
+	var e error
+	if algorithm.Algorithm.Equal(oidPBES2) {
+		mode, e = pbes2DecryptionCipher(algorithm, p)
+	} else {
+		mode, e = pkcs12DecryptionCipher(algorithm, p)
+	}
+	if e != nil {
+		return nil, e
+	}
+
+	if len(data) == 0 || len(data)%mode.BlockSize() != 0 {
+		return nil, errMalformedFile
+	}
+	result := make([]byte, len(data))
+	mode.CryptBlocks(result, data)
+
+	result, ok := removePadding(result, mode.BlockSize())
+	if !ok {
+		return nil, ErrIncorrectPassword
+	}
+	return result, nil
+}
+
+// verifyMAC checks the integrity of the content with the key derived from the password, as
+// described in RFC 7292, appendix B
+func verifyMAC(m *macData, content []byte, p *passwordEncodings) (bool, error) {
+	newHash, ok := hashesByOID[m.MAC.Algorithm.Algorithm.String()]
+	if !ok {
+		return false, errUnsupportedAlgorithm
+	}
+
+	key := deriveKey(newHash, p.bmp, m.Salt, macKeyID, m.Iterations, newHash().Size())
+	mac := hmac.New(newHash, key)
+	mac.Write(content)
+	return hmac.Equal(mac.Sum(nil), m.MAC.Digest), nil
+}
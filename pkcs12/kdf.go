@@ -0,0 +1,74 @@
+package pkcs12
+
+import (
+	"bytes"
+	"hash"
+	"unicode/utf16"
+)
+
+// the purposes of the key derivation function, from RFC 7292, appendix B.3
+const (
+	encryptionKeyID = 1
+	ivID            = 2
+	macKeyID        = 3
+)
+
+// bmpPassword encodes the password the way the PKCS#12 key derivation function expects, as UTF-16
+// with a terminating null character
+func bmpPassword(password []byte) []byte {
+	result := []byte{}
+	for _, c := range utf16.Encode([]rune(string(password))) {
+		result = append(result, byte(c>>8), byte(c))
+	}
+	return append(result, 0, 0)
+}
+
+// fill repeats the value until its length is a multiple of the block size. Empty values stay empty
+func fill(value []byte, blockSize int) []byte {
+	if len(value) == 0 {
+		return nil
+	}
+	length := blockSize * ((len(value) + blockSize - 1) / blockSize)
+	result := bytes.Repeat(value, (length+len(value)-1)/len(value))
+	return result[:length]
+}
+
+// addBlock sets the block to block + b + 1, modulo 2 to the power of the block size in bits
+func addBlock(block, b []byte) {
+	carry := 1
+	for i := len(block) - 1; i >= 0; i-- {
+		carry += int(block[i]) + int(b[i])
+		block[i] = byte(carry)
+		carry >>= 8
+	}
+}
+
+// deriveKey implements the key derivation function of RFC 7292, appendix B.2. The password
+// must already be encoded with bmpPassword
+func deriveKey(newHash func() hash.Hash, password, salt []byte, id byte, iterations, size int) []byte {
+	h := newHash()
+	u, v := h.Size(), h.BlockSize()
+
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(fill(salt, v), fill(password, v)...)
+
+	result := []byte{}
+	for len(result) < size {
+		h.Reset()
+		h.Write(d)
+		h.Write(i)
+		a := h.Sum(nil)
+		for j := 1; j < iterations; j++ {
+			h.Reset()
+			h.Write(a)
+			a = h.Sum(nil)
+		}
+		result = append(result, a...)
+
+		b := fill(a[:u], v)
+		for j := 0; j < len(i); j += v {
+			addBlock(i[j:j+v], b)
+		}
+	}
+	return result[:size]
+}
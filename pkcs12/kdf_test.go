@@ -0,0 +1,30 @@
+package pkcs12
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *pkcs12Suite) Test_bmpPassword_encodesThePasswordAsNullTerminatedUTF16() {
+	s.Equal([]byte{0, 'a', 0, 'b', 0, 0}, bmpPassword([]byte("ab")))
+	s.Equal([]byte{0, 0xe5, 0xd8, 0x3d, 0xdd, 0x11, 0, 0}, bmpPassword([]byte("å🔑")))
+	s.Equal([]byte{0, 0}, bmpPassword(nil))
+}
+
+func (s *pkcs12Suite) Test_fill_repeatsTheValueToAMultipleOfTheBlockSize() {
+	s.Equal([]byte{1, 2, 3, 1}, fill([]byte{1, 2, 3}, 4))
+	s.Equal([]byte{1, 2, 3, 4, 5, 1, 2, 3}, fill([]byte{1, 2, 3, 4, 5}, 4))
+	s.Nil(fill(nil, 4))
+}
+
+func (s *pkcs12Suite) Test_deriveKey_derivesKeysLongerThanTheHash() {
+	key := deriveKey(sha1.New, bmpPassword([]byte("sesame")), testutil.DecodeHex("ffffffffffffffff"), encryptionKeyID, 2048, 24)
+	s.Equal("7cd9fd3e2b3be7691a44e3bef0f9ea0fb9b897d4e325d9d1", hex.EncodeToString(key))
+}
+
+func (s *pkcs12Suite) Test_deriveKey_keepsLeadingZerosOfTheIntermediateValues() {
+	key := deriveKey(sha1.New, []byte{0, 0}, testutil.DecodeHex("f37e05b518324b4b"), encryptionKeyID, 2048, 24)
+	s.Equal("00f759ff47d14dd03665d5943cb3c4a39a2555c02aed66e1", hex.EncodeToString(key))
+}
@@ -0,0 +1,185 @@
+// Package pkcs12 reads the certificates and private keys in PKCS#12 files, also known as .p12 or .pfx
+// files. Both the current format, encrypted with PBES2 and AES, and the legacy format, encrypted with
+// 3DES and RC2, are supported. Files must be DER encoded, which is what almost all software creates
+package pkcs12
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+)
+
+// ErrIncorrectPassword is returned when the file can't be read with the given password
+var ErrIncorrectPassword = errors.New("the password is incorrect")
+
+var errMalformedFile = errors.New("the file is not a valid PKCS#12 file")
+var errUnsupportedAlgorithm = errors.New("the file is protected with an unsupported algorithm")
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+)
+
+// Certificate is a certificate in the file, with the attributes of its bag
+type Certificate struct {
+	Certificate  *x509.Certificate
+	FriendlyName string
+	LocalKeyID   []byte
+}
+
+// PrivateKey is a private key in the file, with the attributes of its bag. The local key ID
+// is usually the same as the one of the certificate for the key
+type PrivateKey struct {
+	Key          crypto.Signer
+	FriendlyName string
+	LocalKeyID   []byte
+}
+
+// Contents holds the certificates and private keys of a file, in the order they are stored
+type Contents struct {
+	Certificates []*Certificate
+	PrivateKeys  []*PrivateKey
+}
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MACData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type macData struct {
+	MAC        digestInfo
+	Salt       []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+func (m *macData) isPresent() bool {
+	return m.MAC.Algorithm.Algorithm != nil
+}
+
+func unmarshalAll(data []byte, v interface{}) error {
+	rest, e := asn1.Unmarshal(data, v)
+	if e != nil || len(rest) != 0 {
+		return errMalformedFile
+	}
+	return nil
+}
+
+// passwordCandidates returns the encodings to try. An empty password can either be encoded as
+// an empty string or not be given at all, and different software does different things
+func passwordCandidates(password []byte) []*passwordEncodings {
+	result := []*passwordEncodings{{raw: password, bmp: bmpPassword(password)}}
+	if len(password) == 0 {
+		result = append(result, &passwordEncodings{})
+	}
+	return result
+}
+
+// Decode reads the certificates and private keys of the file. ErrIncorrectPassword is returned
+// when the integrity check or the decryption fails because of the password
+func Decode(data, password []byte) (*Contents, error) {
+	pfx := &pfxPDU{}
+	if e := unmarshalAll(data, pfx); e != nil {
+		return nil, e
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, errUnsupportedAlgorithm
+	}
+
+	var authSafe []byte
+	if e := unmarshalAll(pfx.AuthSafe.Content.Bytes, &authSafe); e != nil {
+		return nil, e
+	}
+
+	for _, p := range passwordCandidates(password) {
+		if pfx.MACData.isPresent() {
+			ok, e := verifyMAC(&pfx.MACData, authSafe, p)
+			if e != nil {
+				return nil, e
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		result, e := decodeAuthSafe(authSafe, p)
+		if e != ErrIncorrectPassword {
+			return result, e
+		}
+	}
+	return nil, ErrIncorrectPassword
+}
+
+func decodeAuthSafe(authSafe []byte, p *passwordEncodings) (*Contents, error) {
+	contentInfos := []contentInfo{}
+	if e := unmarshalAll(authSafe, &contentInfos); e != nil {
+		return nil, e
+	}
+
+	result := &Contents{}
+	for _, ci := range contentInfos {
+		bags, e := safeBagsIn(ci, p)
+		if e != nil {
+			return nil, e
+		}
+		for _, b := range bags {
+			if e := result.add(b, p); e != nil {
+				return nil, e
+			}
+		}
+	}
+	return result, nil
+}
+
+// safeBagsIn returns the bags of content that is either not encrypted or encrypted with the password.
+// Content encrypted for a public key isn't supported, since the private key for it isn't available
+func safeBagsIn(ci contentInfo, p *passwordEncodings) ([]safeBag, error) {
+	var content []byte
+	switch {
+	case ci.ContentType.Equal(oidDataContentType):
+		if e := unmarshalAll(ci.Content.Bytes, &content); e != nil {
+			return nil, e
+		}
+	case ci.ContentType.Equal(oidEncryptedDataContentType):
+		ed := encryptedData{}
+		if e := unmarshalAll(ci.Content.Bytes, &ed); e != nil {
+			return nil, e
+		}
+		decrypted, e := decrypt(ed.EncryptedContentInfo.ContentEncryptionAlgorithm, p, ed.EncryptedContentInfo.EncryptedContent)
+		if e != nil {
+			return nil, e
+		}
+		content = decrypted
+	default:
+		return nil, errUnsupportedAlgorithm
+	}
+
+	bags := []safeBag{}
+	if e := unmarshalAll(content, &bags); e != nil {
+		return nil, e
+	}
+	return bags, nil
+}
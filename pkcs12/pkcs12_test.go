@@ -0,0 +1,168 @@
+package pkcs12
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type pkcs12Suite struct {
+	suite.Suite
+}
+
+func TestPkcs12Suite(t *testing.T) {
+	suite.Run(t, new(pkcs12Suite))
+}
+
+// modernFile was created by OpenSSL 3.0 with the default settings, for a self-signed Ed25519
+// certificate for "Alice Client", with the friendly name "Alice's key" and the password "secret":
+//
+//	openssl pkcs12 -export -inkey ed.key -in ed.crt -name "Alice's key" -passout pass:secret
+const modernFile = "" +
+	"MIID0AIBAzCCA4YGCSqGSIb3DQEHAaCCA3cEggNzMIIDbzCCAlIGCSqGSIb3DQEHBqCCAkMwggI/" +
+	"AgEAMIICOAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAiu711pvU0A" +
+	"MAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEPJWOSFOzELmvAdQ+aJ5HCKAggHQZanG" +
+	"kybNo0KtOW/+9z+vxH4nsawdLlnDTxxbpLb3WREwaa9FHnduL6I01N5Q2THd92al0LobeVrTRNvZ" +
+	"36YMmoGkK4CFHLOROBqapvV5WEpY88mM0sRtti58rkJb4T8PpFzCw5LVO5inXKioOIfUQzpwnt68" +
+	"y733SgIC6UVGi7WOWw5bD4UPWVRC4visORk5MUdYtP1Wvo9rtHQWf9oqlGyNO9Avkazmxjten/hW" +
+	"6lANKzYp8GDzSbuWBl2KCVXIhhn0XgGmop71xy1VlLlj1C1BEwUaQcBwxEcOZWHJRV3KnXubguK5" +
+	"lfPOc7+IHUlSrT/hUCPnhFiJbBghazWc/ofhFPck4jlKDS2NIGyoq4raAJU5ArhOs6tXZTpHFN1W" +
+	"4uEbv+F9ZfTDGZ/c1MmJk2hyPgMK+HdEVtqJlwMJSbT0evpXhHYSxGe9bd8fpefL/DC5y81Xb47C" +
+	"w8O+rl6pGu2DqeDAXPtfbH2o4izwml/W/iTGbg8RY/UMEA8QUoTc1S4DHAPC0ku1u6Xl3Brw9TBR" +
+	"oL+/OGIqGvsOteU3b/1AMz5J0AGXFeC6Regnimdr9PhySvfRhRV8EcXEEo8c9FgxfSGTN28EYQPZ" +
+	"X6dEpy8wggEVBgkqhkiG9w0BBwGgggEGBIIBAjCB/zCB/AYLKoZIhvcNAQwKAQKggZ4wgZswVwYJ" +
+	"KoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwECLN7C2LIGacDAgIIADAMBggqhkiG9w0CCQUAMB0G" +
+	"CWCGSAFlAwQBKgQQl3XlRb98sc2SuXniscIVQQRA3BY9kukBYemLzX/nESpU9K8I6D0uzZyYkQwg" +
+	"sRRSRgUlWHEND8cB5S5nKZmsZqrCZm3XpMocPHut3GBnGIqYvzFMMCMGCSqGSIb3DQEJFTEWBBTs" +
+	"NU0xup7k2ytWL52uVFMxfwRvUDAlBgkqhkiG9w0BCRQxGB4WAEEAbABpAGMAZQAnAHMAIABrAGUA" +
+	"eTBBMDEwDQYJYIZIAWUDBAIBBQAEIGGm1s3+epA+fQ/0gl5IvnnHxWVzivFjkohELu3cBqn0BAhl" +
+	"mRwKiUOyzQICCAA="
+
+const modernCertificateFingerprint = "a3b4d5a34c0794c521ef948b1a7cce59be96f879e153a79bdf5b4b45f9a4f384"
+const modernLocalKeyID = "ec354d31ba9ee4db2b562f9dae5453317f046f50"
+
+// legacyFile was created in the same way, with the -legacy option, for a self-signed ECDSA
+// certificate for "Bob". The certificate is encrypted with RC2 and the key with 3DES
+const legacyFile = "" +
+	"MIIDpQIBAzCCA2sGCSqGSIb3DQEHAaCCA1wEggNYMIIDVDCCAi8GCSqGSIb3DQEHBqCCAiAwggIc" +
+	"AgEAMIICFQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQIhz2r5sPCNmUCAggAgIIB6Cr3Qqd0" +
+	"waj3+4eI1MQu6ZU3hOUXXAayQ22wwJlqH18UTdgm+9Vny6WW9Oj/ICc9G2ssnji5yM+0YmmjMHtQ" +
+	"nRCcoYXa+nhbpEvc5SpnnxMGJX35Xeida8MG+Hfjb/eTKumnOs1Hje9YLZZ44/8eZ54lnJN3oCWU" +
+	"FJqvIRzropVixzNh2TeSe7oHce5ogirsFgkwAaXS80h1JknT+gT4pLOBBRVAwhq0MTGrPeh9wlBj" +
+	"O2OTJSs9TYLWu0ZQVpwQLcI6KOcrS1AZ75Y4u++mctpfrsahrfVKJnMDda8IMY7ASoqJ75POh1wh" +
+	"PPlpXoXj/h2jBDFnGUsT+IAsc83L0Y7Fy+qHRo8wamh4/18PfwYq+rpd2CL8o7opBol+2RtHlKY+" +
+	"5ApXZSsI/RCADb0V6pwQIjv4S35CS8+bnNxtETVMSaUaWoqKHEd+FWIHC+RzEumq/oR1niED7qVj" +
+	"AUZBXcEbiLoSoVddoPbzo7FOeCfLl3RGTqf21ixns0XSp/BQrCQ+gC5XAS4HZvuhm+IiZGU/HosA" +
+	"PDSTfcGRn7yu31GB2x3SykuUB/W4rABSAs9fDHQBxtfk4EqdVlHzW0E5VwHhd0URySQ7nDLwp020" +
+	"yaup0SfAUqlAbt4CQQsDg86CKMQeAXQd3DD0MIIBHQYJKoZIhvcNAQcBoIIBDgSCAQowggEGMIIB" +
+	"AgYLKoZIhvcNAQwKAQKggbQwgbEwHAYKKoZIhvcNAQwBAzAOBAj+djvbnOccYAICCAAEgZBcuc1l" +
+	"KmWTqwBb+fHn5TWk3soh845ZnXpZPDuiPHDn6m7+ZyvMCPkhU1f6Jv84vwln95DwoAKot1ZBWtmO" +
+	"JaJa70HNM0JtuielG/n0XgRlCc2Y8Xc3NPyQgxrrhCiYtuWDn8OjRpqJCmhMoClJLD0buWOs6FUN" +
+	"IVd+3FlL3KK6OayQkk5Ug08QdrSNmMpMggsxPDAVBgkqhkiG9w0BCRQxCB4GAEIAbwBiMCMGCSqG" +
+	"SIb3DQEJFTEWBBSXemI6aVbdPnGnFXuXzWZkhGIm8DAxMCEwCQYFKw4DAhoFAAQUhJqTj4ujqXgs" +
+	"c6fyeMETHnJTrpQECFhnBVGh0YrbAgIIAA=="
+
+const legacyCertificateFingerprint = "451fc7da5057d7621005233fe9586053cfd18c0d5330a5bf4b5f760c507553d5"
+
+// emptyPasswordFile contains only the certificate of legacyFile, and has an empty password
+const emptyPasswordFile = "" +
+	"MIIClwIBAzCCAk0GCSqGSIb3DQEHAaCCAj4EggI6MIICNjCCAjIGCSqGSIb3DQEHBqCCAiMwggIf" +
+	"AgEAMIICGAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAhhEPMWtVqH" +
+	"uAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEELlNNkbVhJ4kzYW9IUwlXNmAggGwhdOq" +
+	"7BSF5BTltG9TcpjaOl5lIB8NZUP5pKybJ5Jl7p0hyOlXXEIjpc8l5z7NTCvP/wN43IZETSmwNuzF" +
+	"4tAVGSN+CaY9YCM8Y2urbP2Nrg4285vnxsSVv9GkxJitsAQY72BCN3+tk65W/5BXYJM9MOtU/VXt" +
+	"hk7/UQX0GHOIKrxOclRyJMw7K6Tnx2A5Tx7Ih7poog1Jj97VAXpsoqw42ItQ4M3GslluC/7P0i66" +
+	"X3Y60DHCamETO1G4Lt0iHiyjdg8Tl1HPkVvNs4rKzEss2g84pTpmYAnLnrWlv0vx2LVfZw+LBM0Y" +
+	"v/VI/wF5cE2OnpMVWpyNFX/8frh1aBxw7OC5c2q3lwv/Jhms8sISBHWC2pvw2PFldlFP+hhZoDOr" +
+	"TxdOQVgbVRTrQ+FjiEoun/BKQko2DgkMSziYkZVqYMcFgRcHfZtn/pDV9TO1vQe2YiXqtmTK6C9Q" +
+	"tJIxAjXJaN3/ASzMFhw3DCWBEGMEVcEK9NrqtRczVra/gp5gb63YPZ2naSc5GNtREYEhHRz8mYlI" +
+	"3q7gIyYOtAv5yPZBiw/oqyoO2MHgLi7j+e71Th1LMEEwMTANBglghkgBZQMEAgEFAAQgguImRmrd" +
+	"U1I07cTGPwiJ5M1ZlNhLQVEjcJNi0fv/490ECN+uWW8abmJEAgIIAA=="
+
+func sha256Of(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
+
+func (s *pkcs12Suite) Test_Decode_readsTheCertificateAndKeyOfAFileInTheCurrentFormat() {
+	c, e := Decode(testutil.DecodeBase64(modernFile), []byte("secret"))
+	s.Require().NoError(e)
+
+	s.Require().Len(c.Certificates, 1)
+	cert := c.Certificates[0]
+	s.Equal("Alice Client", cert.Certificate.Subject.CommonName)
+	s.Equal(modernCertificateFingerprint, hex.EncodeToString(sha256Of(cert.Certificate.Raw)))
+	s.Equal("Alice's key", cert.FriendlyName)
+	s.Equal(modernLocalKeyID, hex.EncodeToString(cert.LocalKeyID))
+
+	s.Require().Len(c.PrivateKeys, 1)
+	key := c.PrivateKeys[0]
+	s.IsType(ed25519.PrivateKey{}, key.Key)
+	s.Equal(cert.Certificate.PublicKey, key.Key.Public())
+	s.Equal("Alice's key", key.FriendlyName)
+	s.Equal(modernLocalKeyID, hex.EncodeToString(key.LocalKeyID))
+}
+
+func (s *pkcs12Suite) Test_Decode_readsTheCertificateAndKeyOfALegacyFile() {
+	c, e := Decode(testutil.DecodeBase64(legacyFile), []byte("secret"))
+	s.Require().NoError(e)
+
+	s.Require().Len(c.Certificates, 1)
+	s.Equal(legacyCertificateFingerprint, hex.EncodeToString(sha256Of(c.Certificates[0].Certificate.Raw)))
+	s.Equal("Bob", c.Certificates[0].FriendlyName)
+
+	s.Require().Len(c.PrivateKeys, 1)
+	s.IsType(&ecdsa.PrivateKey{}, c.PrivateKeys[0].Key)
+	s.Equal(c.Certificates[0].Certificate.PublicKey, c.PrivateKeys[0].Key.Public())
+	s.Equal(c.Certificates[0].LocalKeyID, c.PrivateKeys[0].LocalKeyID)
+}
+
+func (s *pkcs12Suite) Test_Decode_readsAFileWithAnEmptyPassword() {
+	c, e := Decode(testutil.DecodeBase64(emptyPasswordFile), nil)
+	s.Require().NoError(e)
+
+	s.Require().Len(c.Certificates, 1)
+	s.Equal(legacyCertificateFingerprint, hex.EncodeToString(sha256Of(c.Certificates[0].Certificate.Raw)))
+	s.Empty(c.Certificates[0].FriendlyName)
+	s.Empty(c.PrivateKeys)
+}
+
+func (s *pkcs12Suite) Test_Decode_returnsAnErrorForAnIncorrectPassword() {
+	_, e := Decode(testutil.DecodeBase64(modernFile), []byte("not the secret"))
+	s.Equal(ErrIncorrectPassword, e)
+
+	_, e = Decode(testutil.DecodeBase64(legacyFile), nil)
+	s.Equal(ErrIncorrectPassword, e)
+}
+
+func (s *pkcs12Suite) Test_Decode_returnsAnErrorForContentThatIsNotPKCS12() {
+	_, e := Decode([]byte("-----BEGIN CERTIFICATE-----"), nil)
+	s.Equal(errMalformedFile, e)
+
+	data := testutil.DecodeBase64(modernFile)
+	_, e = Decode(append(data, 0), []byte("secret"))
+	s.Equal(errMalformedFile, e)
+}
+
+func (s *pkcs12Suite) Test_removePadding_checksThePadding() {
+	data, ok := removePadding([]byte{1, 2, 3, 3, 3, 3}, 8)
+	s.True(ok)
+	s.Equal([]byte{1, 2, 3}, data)
+
+	_, ok = removePadding([]byte{1, 2, 3, 2, 3, 3}, 8)
+	s.False(ok)
+
+	_, ok = removePadding([]byte{1, 2, 3, 0}, 8)
+	s.False(ok)
+
+	_, ok = removePadding([]byte{9, 9, 9, 9, 9, 9, 9, 9, 9}, 8)
+	s.False(ok)
+
+	_, ok = removePadding(nil, 8)
+	s.False(ok)
+}
@@ -0,0 +1,35 @@
+package pkcs12
+
+import (
+	"encoding/hex"
+
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+// the test vectors from RFC 2268, section 5
+var rc2TestVectors = []struct {
+	key, plaintext, ciphertext string
+	effectiveBits              int
+}{
+	{"0000000000000000", "0000000000000000", "ebb773f993278eff", 63},
+	{"ffffffffffffffff", "ffffffffffffffff", "278b27e42e2f0d49", 64},
+	{"3000000000000000", "1000000000000001", "30649edf9be7d2c2", 64},
+	{"88", "0000000000000000", "61a8a244adacccf0", 64},
+	{"88bca90e90875a", "0000000000000000", "6ccf4308974c267f", 64},
+	{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "1a807d272bbe5db1", 64},
+	{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "2269552ab0f85ca6", 128},
+	{"88bca90e90875a7f0f79c384627bafb216f80a6f85920584c42fceb0be255daf1e", "0000000000000000", "5b78d3a43dfff1f1", 129},
+}
+
+func (s *pkcs12Suite) Test_rc2Cipher_encryptsAndDecryptsTheTestVectors() {
+	for _, v := range rc2TestVectors {
+		c := newRC2Cipher(testutil.DecodeHex(v.key), v.effectiveBits)
+		result := make([]byte, rc2BlockSize)
+
+		c.Encrypt(result, testutil.DecodeHex(v.plaintext))
+		s.Equal(v.ciphertext, hex.EncodeToString(result), v.key)
+
+		c.Decrypt(result, testutil.DecodeHex(v.ciphertext))
+		s.Equal(v.plaintext, hex.EncodeToString(result), v.key)
+	}
+}
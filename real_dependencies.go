@@ -5,6 +5,7 @@ package main
 import (
 	"github.com/coyim/gotk3adapter/gdka"
 	"github.com/coyim/gotk3adapter/gioa"
+	"github.com/coyim/gotk3adapter/gliba"
 	"github.com/coyim/gotk3adapter/gtka"
 )
 
@@ -12,4 +13,5 @@ func init() {
 	realGTK = gtka.Real
 	realGDK = gdka.Real
 	realGIO = gioa.Real
+	realGLib = gliba.Real
 }
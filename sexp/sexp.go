@@ -0,0 +1,213 @@
+// Package sexp reads S-expressions, either in the canonical format or in the advanced, human
+// readable, format. Both are described in the sexp-format of Ron Rivest
+// https://people.csail.mit.edu/rivest/Sexp.txt
+package sexp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned when the data is not a valid S-expression
+var ErrInvalid = errors.New("invalid S-expression")
+
+// Expression is either an atom with a value, or a list of S-expressions
+type Expression struct {
+	Value []byte
+	List  []*Expression
+}
+
+func (s *Expression) IsList() bool {
+	return s.List != nil
+}
+
+// Name returns the value of the first element of a list
+func (s *Expression) Name() string {
+	if len(s.List) == 0 || s.List[0].IsList() {
+		return ""
+	}
+	return string(s.List[0].Value)
+}
+
+// Find returns the first element that is a list with the given name
+func (s *Expression) Find(name string) *Expression {
+	for _, e := range s.List {
+		if e.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// FindAll returns all the elements that are lists with the given name
+func (s *Expression) FindAll(name string) []*Expression {
+	result := []*Expression{}
+	for _, e := range s.List {
+		if e.Name() == name {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// ValueOf returns the value of the second element of the list with the given name, which is
+// how parameters like (n #00E2...#) are written
+func (s *Expression) ValueOf(name string) []byte {
+	e := s.Find(name)
+	if e == nil || len(e.List) < 2 || e.List[1].IsList() {
+		return nil
+	}
+	return e.List[1].Value
+}
+
+type parser struct {
+	data string
+	pos  int
+}
+
+// Parse reads a single S-expression from the data
+func Parse(data []byte) (*Expression, error) {
+	p := &parser{data: string(data)}
+	result, ok := p.parse()
+	if !ok {
+		return nil, ErrInvalid
+	}
+	return result, nil
+}
+
+func isWhitespace(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\v", c) != -1
+}
+
+func isTokenCharacter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-./_:*+=", c) != -1
+}
+
+func (p *parser) skipWhitespace() {
+	for p.pos < len(p.data) && isWhitespace(p.data[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) parse() (*Expression, bool) {
+	p.skipWhitespace()
+	if p.pos >= len(p.data) {
+		return nil, false
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '(':
+		return p.parseList()
+	case c >= '0' && c <= '9':
+		return p.parseLengthPrefixed()
+	case c == '#':
+		return p.parseEncoded('#', hex.DecodeString)
+	case c == '|':
+		return p.parseEncoded('|', base64.StdEncoding.DecodeString)
+	case c == '"':
+		return p.parseQuoted()
+	case isTokenCharacter(c):
+		return p.parseToken()
+	}
+	return nil, false
+}
+
+func (p *parser) parseList() (*Expression, bool) {
+	p.pos++
+	result := &Expression{List: []*Expression{}}
+	for {
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return nil, false
+		}
+		if p.data[p.pos] == ')' {
+			p.pos++
+			return result, true
+		}
+
+		e, ok := p.parse()
+		if !ok {
+			return nil, false
+		}
+		result.List = append(result.List, e)
+	}
+}
+
+// parseLengthPrefixed parses the verbatim values of the canonical format, like 3:rsa. Tokens
+// starting with a digit are also allowed in the advanced format
+func (p *parser) parseLengthPrefixed() (*Expression, bool) {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+		p.pos = start
+		return p.parseToken()
+	}
+
+	l, e := strconv.Atoi(p.data[start:p.pos])
+	p.pos++
+	if e != nil || p.pos+l > len(p.data) {
+		return nil, false
+	}
+	result := &Expression{Value: []byte(p.data[p.pos : p.pos+l])}
+	p.pos += l
+	return result, true
+}
+
+func (p *parser) parseEncoded(delimiter byte, decode func(string) ([]byte, error)) (*Expression, bool) {
+	end := strings.IndexByte(p.data[p.pos+1:], delimiter)
+	if end == -1 {
+		return nil, false
+	}
+
+	encoded := strings.Join(strings.Fields(p.data[p.pos+1:p.pos+1+end]), "")
+	p.pos += end + 2
+	value, e := decode(encoded)
+	if e != nil {
+		return nil, false
+	}
+	return &Expression{Value: value}, true
+}
+
+func (p *parser) parseQuoted() (*Expression, bool) {
+	var result strings.Builder
+	for p.pos++; p.pos < len(p.data); p.pos++ {
+		switch c := p.data[p.pos]; c {
+		case '"':
+			p.pos++
+			return &Expression{Value: []byte(result.String())}, true
+		case '\\':
+			p.pos++
+			if p.pos < len(p.data) {
+				result.WriteByte(unescaped(p.data[p.pos]))
+			}
+		default:
+			result.WriteByte(c)
+		}
+	}
+	return nil, false
+}
+
+func unescaped(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	}
+	return c
+}
+
+func (p *parser) parseToken() (*Expression, bool) {
+	start := p.pos
+	for p.pos < len(p.data) && isTokenCharacter(p.data[p.pos]) {
+		p.pos++
+	}
+	return &Expression{Value: []byte(p.data[start:p.pos])}, true
+}
@@ -0,0 +1,63 @@
+package sexp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type sexpSuite struct {
+	suite.Suite
+}
+
+func TestSexpSuite(t *testing.T) {
+	suite.Run(t, new(sexpSuite))
+}
+
+func (s *sexpSuite) Test_Parse_readsTheCanonicalFormat() {
+	result, e := Parse([]byte("(3:rsa(1:n3:\x00\x01\x02)(1:e1:\x03))"))
+	s.Require().NoError(e)
+	s.Equal("rsa", result.Name())
+	s.Equal([]byte{0x00, 0x01, 0x02}, result.ValueOf("n"))
+	s.Equal([]byte{0x03}, result.ValueOf("e"))
+	s.Nil(result.ValueOf("d"))
+}
+
+func (s *sexpSuite) Test_Parse_readsTheAdvancedFormat() {
+	result, e := Parse([]byte(`(key (curve "NIST P-256")
+  (q #0401
+      02#) (d |AQID|) (flags eddsa) (empty) (nested (a b)))`))
+	s.Require().NoError(e)
+	s.Equal("key", result.Name())
+	s.Equal([]byte("NIST P-256"), result.ValueOf("curve"))
+	s.Equal([]byte{0x04, 0x01, 0x02}, result.ValueOf("q"))
+	s.Equal([]byte{0x01, 0x02, 0x03}, result.ValueOf("d"))
+	s.Equal([]byte("eddsa"), result.ValueOf("flags"))
+	s.NotNil(result.Find("empty"))
+	s.Nil(result.ValueOf("empty"))
+	s.Nil(result.ValueOf("nested"))
+}
+
+func (s *sexpSuite) Test_Expression_FindAll_returnsAllTheListsWithTheName() {
+	result, e := Parse([]byte(`(keys (account (name a)) (other) (account (name b)) account)`))
+	s.Require().NoError(e)
+
+	accounts := result.FindAll("account")
+	s.Require().Len(accounts, 2)
+	s.Equal([]byte("a"), accounts[0].ValueOf("name"))
+	s.Equal([]byte("b"), accounts[1].ValueOf("name"))
+	s.Empty(result.FindAll("missing"))
+}
+
+func (s *sexpSuite) Test_Parse_readsEscapesInQuotedStrings() {
+	result, e := Parse([]byte(`(key (comment "a \"quoted\"\nvalue"))`))
+	s.Require().NoError(e)
+	s.Equal([]byte("a \"quoted\"\nvalue"), result.ValueOf("comment"))
+}
+
+func (s *sexpSuite) Test_Parse_failsForInvalidExpressions() {
+	for _, invalid := range []string{"", "(rsa", "(n #0G#)", "(n 10:abc)", "(n \"abc)", "(n |***|)", "(n #01)", "(n {})"} {
+		_, e := Parse([]byte(invalid))
+		s.Equal(ErrInvalid, e, invalid)
+	}
+}
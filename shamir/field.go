@@ -0,0 +1,62 @@
+package shamir
+
+// The shares are calculated byte by byte in GF(2^8), using the same reducing
+// polynomial as AES, x^8 + x^4 + x^3 + x + 1. Multiplication and division use
+// logarithm tables based on the generator 3
+
+var expTable, logTable = generateTables()
+
+func generateTables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		x = multiplyWithoutTables(x, 3)
+	}
+	return exp, log
+}
+
+func multiplyWithoutTables(a, b byte) byte {
+	result := byte(0)
+	for b > 0 {
+		if b&1 == 1 {
+			result ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return result
+}
+
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+func multiply(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// divide should never be called with zero as the divisor
+func divide(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// evaluate uses Horner's method, with the coefficients starting from the constant term
+func evaluate(coefficients []byte, x byte) byte {
+	result := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = add(multiply(result, x), coefficients[i])
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const keyShareHeader = "-----BEGIN KEYMIRROR KEY SHARE-----"
+const keyShareFooter = "-----END KEYMIRROR KEY SHARE-----"
+
+const shareField = "Share:"
+const thresholdField = "Threshold:"
+const fingerprintField = "Fingerprint:"
+const userIDField = "User ID:"
+
+const lineLength = 64
+
+// checksumLength bytes of the SHA-256 of the key are split together with it, so a
+// wrong combination of shares is detected without revealing anything about the key
+const checksumLength = 8
+
+var ErrUnsupportedKey = errors.New("only RSA, ECDSA and Ed25519 keys can be split into shares")
+var ErrChecksumMismatch = errors.New("the shares don't restore the key they were created from")
+var ErrNoShares = errors.New("the text doesn't contain any key shares")
+var errMalformedShare = errors.New("a key share is malformed")
+
+// KeyShare is one share of a private key, with the information needed to know which key it
+// belongs to, and how many shares are needed to restore it
+type KeyShare struct {
+	Share
+	Total       int
+	Threshold   int
+	Fingerprint string
+	UserID      string
+}
+
+// SplitKey splits the private key into shares. The fingerprint and user ID are written on
+// every share, so custodians can tell which key their share belongs to
+func SplitKey(priv crypto.PrivateKey, fingerprint, userID string, total, threshold int) ([]*KeyShare, error) {
+	der, e := x509.MarshalPKCS8PrivateKey(priv)
+	if e != nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	sum := sha256.Sum256(der)
+	shares, e := Split(append(der, sum[:checksumLength]...), total, threshold)
+	if e != nil {
+		return nil, e
+	}
+
+	result := []*KeyShare{}
+	for _, s := range shares {
+		result = append(result, &KeyShare{
+			Share:       s,
+			Total:       total,
+			Threshold:   threshold,
+			Fingerprint: fingerprint,
+			UserID:      userID,
+		})
+	}
+	return result, nil
+}
+
+// CombineKey restores the private key, once there are at least as many shares as the threshold
+func CombineKey(shares []*KeyShare) (crypto.PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	parts := []Share{}
+	for _, s := range shares {
+		if s.Total != shares[0].Total || s.Threshold != shares[0].Threshold || s.Fingerprint != shares[0].Fingerprint {
+			return nil, errInconsistentShares
+		}
+		parts = append(parts, s.Share)
+	}
+
+	if len(parts) < shares[0].Threshold {
+		return nil, ErrNotEnoughShares
+	}
+
+	secret, e := Combine(parts)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(secret) < checksumLength {
+		return nil, ErrChecksumMismatch
+	}
+	der, checksum := secret[:len(secret)-checksumLength], secret[len(secret)-checksumLength:]
+	sum := sha256.Sum256(der)
+	if !bytes.Equal(sum[:checksumLength], checksum) {
+		return nil, ErrChecksumMismatch
+	}
+
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+// Armor returns the share as a block of text, with the index stored together with the value,
+// so a share keeps working even if the headers are changed
+func (s *KeyShare) Armor() string {
+	lines := []string{
+		keyShareHeader,
+		fmt.Sprintf("%s %d of %d", shareField, s.Index, s.Total),
+		fmt.Sprintf("%s %d", thresholdField, s.Threshold),
+		fmt.Sprintf("%s %s", fingerprintField, s.Fingerprint),
+	}
+	if s.UserID != "" {
+		lines = append(lines, fmt.Sprintf("%s %s", userIDField, s.UserID))
+	}
+	lines = append(lines, "")
+
+	body := base64.StdEncoding.EncodeToString(append([]byte{s.Index}, s.Value...))
+	for len(body) > lineLength {
+		lines = append(lines, body[:lineLength])
+		body = body[lineLength:]
+	}
+
+	return strings.Join(append(lines, body, keyShareFooter), "\n") + "\n"
+}
+
+func (s *KeyShare) parseField(line string) bool {
+	switch {
+	case strings.HasPrefix(line, shareField):
+		position := strings.Fields(strings.TrimPrefix(line, shareField))
+		if len(position) == 3 {
+			s.Total, _ = strconv.Atoi(position[2])
+		}
+	case strings.HasPrefix(line, thresholdField):
+		s.Threshold, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, thresholdField)))
+	case strings.HasPrefix(line, fingerprintField):
+		s.Fingerprint = strings.TrimSpace(strings.TrimPrefix(line, fingerprintField))
+	case strings.HasPrefix(line, userIDField):
+		s.UserID = strings.TrimSpace(strings.TrimPrefix(line, userIDField))
+	default:
+		return false
+	}
+	return true
+}
+
+func parseKeyShare(lines []string) (*KeyShare, error) {
+	s := &KeyShare{}
+	body := ""
+	for _, line := range lines {
+		if !s.parseField(line) {
+			body += line
+		}
+	}
+
+	value, e := base64.StdEncoding.DecodeString(body)
+	if e != nil || len(value) < 2 || value[0] == 0 || s.Total < 2 || s.Threshold < 2 {
+		return nil, errMalformedShare
+	}
+
+	s.Index, s.Value = value[0], value[1:]
+	return s, nil
+}
+
+// ParseKeyShares finds all armored key shares in the text
+func ParseKeyShares(text string) ([]*KeyShare, error) {
+	result := []*KeyShare{}
+	var current []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == keyShareHeader:
+			current = []string{}
+		case line == keyShareFooter && current != nil:
+			s, e := parseKeyShare(current)
+			if e != nil {
+				return nil, e
+			}
+			result = append(result, s)
+			current = nil
+		case current != nil:
+			current = append(current, line)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoShares
+	}
+	return result, nil
+}
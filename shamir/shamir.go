@@ -0,0 +1,85 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// MaxShares is limited by the number of non-zero elements in the field
+const MaxShares = 255
+
+var ErrInvalidThreshold = errors.New("the threshold has to be at least 2, and not more than the number of shares")
+var ErrNotEnoughShares = errors.New("there are not enough shares to restore the secret")
+var errDuplicateShare = errors.New("the same share was given more than once")
+var errInconsistentShares = errors.New("the shares don't belong to the same secret")
+
+// Share is one of the parts a secret is split into. The index is the point where
+// the polynomials were evaluated, and is never zero
+type Share struct {
+	Index byte
+	Value []byte
+}
+
+// Split divides the secret into the given number of shares, so that any threshold of them
+// can restore it, while fewer reveal nothing about it
+func Split(secret []byte, total, threshold int) ([]Share, error) {
+	if threshold < 2 || threshold > total || total > MaxShares {
+		return nil, ErrInvalidThreshold
+	}
+
+	shares := make([]Share, total)
+	for i := range shares {
+		shares[i] = Share{Index: byte(i + 1), Value: make([]byte, len(secret))}
+	}
+
+	coefficients := make([]byte, threshold)
+	for j, b := range secret {
+		coefficients[0] = b
+		if _, e := rand.Read(coefficients[1:]); e != nil {
+			return nil, e
+		}
+
+		for i := range shares {
+			shares[i].Value[j] = evaluate(coefficients, shares[i].Index)
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine restores the secret using Lagrange interpolation at zero. It can't know the threshold,
+// so with too few shares it returns a wrong secret, which has to be detected by the caller
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrNotEnoughShares
+	}
+
+	seen := map[byte]bool{}
+	for _, s := range shares {
+		if s.Index == 0 || len(s.Value) != len(shares[0].Value) {
+			return nil, errInconsistentShares
+		}
+		if seen[s.Index] {
+			return nil, errDuplicateShare
+		}
+		seen[s.Index] = true
+	}
+
+	secret := make([]byte, len(shares[0].Value))
+	for i, si := range shares {
+		// The basis polynomial of this share, evaluated at zero, is the product of x_j / (x_j - x_i).
+		// Subtraction is the same as addition in this field
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = multiply(basis, divide(sj.Index, add(sj.Index, si.Index)))
+			}
+		}
+
+		for k := range secret {
+			secret[k] = add(secret[k], multiply(si.Value[k], basis))
+		}
+	}
+
+	return secret, nil
+}
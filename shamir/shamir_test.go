@@ -0,0 +1,157 @@
+package shamir
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type shamirSuite struct {
+	suite.Suite
+}
+
+func TestShamirSuite(t *testing.T) {
+	suite.Run(t, new(shamirSuite))
+}
+
+func ed25519KeyForTest() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+}
+
+const fingerprintForTest = "SHA256:O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik"
+
+func (s *shamirSuite) Test_field_divisionUndoesMultiplication() {
+	for a := 0; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			s.Equal(byte(a), divide(multiply(byte(a), byte(b)), byte(b)))
+		}
+		s.Equal(multiplyWithoutTables(byte(a), 0x53), multiply(byte(a), 0x53))
+	}
+	// The example from FIPS 197
+	s.Equal(byte(0xc1), multiply(0x57, 0x83))
+}
+
+func (s *shamirSuite) Test_Combine_restoresTheSecretFromAnyThresholdOfShares() {
+	secret := []byte("the secret of the batcave")
+	shares, e := Split(secret, 5, 3)
+	s.Require().NoError(e)
+	s.Len(shares, 5)
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		parts := []Share{}
+		for _, i := range subset {
+			parts = append(parts, shares[i])
+		}
+		res, e := Combine(parts)
+		s.Require().NoError(e)
+		s.Equal(secret, res)
+	}
+
+	res, _ := Combine(shares[:2])
+	s.NotEqual(secret, res)
+}
+
+func (s *shamirSuite) Test_Split_validatesTheThreshold() {
+	for _, c := range [][2]int{{5, 1}, {3, 4}, {256, 3}} {
+		_, e := Split([]byte("secret"), c[0], c[1])
+		s.Equal(ErrInvalidThreshold, e)
+	}
+}
+
+func (s *shamirSuite) Test_Combine_refusesInvalidCombinations() {
+	shares, _ := Split([]byte("secret"), 3, 2)
+
+	_, e := Combine(shares[:1])
+	s.Equal(ErrNotEnoughShares, e)
+
+	_, e = Combine([]Share{shares[0], shares[0]})
+	s.Equal(errDuplicateShare, e)
+
+	_, e = Combine([]Share{shares[0], {Index: 2, Value: []byte("x")}})
+	s.Equal(errInconsistentShares, e)
+}
+
+func (s *shamirSuite) Test_CombineKey_restoresTheSplitKeys() {
+	rsaKey, e := rsa.GenerateKey(rand.Reader, 1024)
+	s.Require().NoError(e)
+	ecdsaKey, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(e)
+
+	for _, priv := range []crypto.PrivateKey{ed25519KeyForTest(), rsaKey, ecdsaKey} {
+		shares, e := SplitKey(priv, fingerprintForTest, "batman@gotham", 4, 2)
+		s.Require().NoError(e)
+
+		res, e := CombineKey([]*KeyShare{shares[3], shares[1]})
+		s.Require().NoError(e)
+		// The precomputed values of RSA keys can be encoded differently, so the keys are compared by value
+		s.True(priv.(interface{ Equal(crypto.PrivateKey) bool }).Equal(res), "the restored key is the split key")
+	}
+}
+
+func (s *shamirSuite) Test_CombineKey_detectsProblems() {
+	shares, _ := SplitKey(ed25519KeyForTest(), fingerprintForTest, "", 5, 3)
+
+	_, e := CombineKey(nil)
+	s.Equal(ErrNoShares, e)
+
+	_, e = CombineKey(shares[:2])
+	s.Equal(ErrNotEnoughShares, e)
+
+	other, _ := SplitKey(ed25519KeyForTest(), "SHA256:other", "", 5, 3)
+	_, e = CombineKey([]*KeyShare{shares[0], shares[1], other[2]})
+	s.Equal(errInconsistentShares, e)
+
+	other[2].Fingerprint = fingerprintForTest
+	_, e = CombineKey([]*KeyShare{shares[0], shares[1], other[2]})
+	s.Equal(ErrChecksumMismatch, e)
+}
+
+func (s *shamirSuite) Test_SplitKey_refusesUnsupportedKeys() {
+	_, e := SplitKey("not a key", fingerprintForTest, "", 3, 2)
+	s.Equal(ErrUnsupportedKey, e)
+}
+
+func (s *shamirSuite) Test_KeyShare_Armor_showsTheShareInformation() {
+	share := &KeyShare{
+		Share:       Share{Index: 2, Value: []byte("the value of the share, long enough to need more than one line of text")},
+		Total:       5,
+		Threshold:   3,
+		Fingerprint: fingerprintForTest,
+		UserID:      "batman@gotham",
+	}
+
+	s.Equal(`-----BEGIN KEYMIRROR KEY SHARE-----
+Share: 2 of 5
+Threshold: 3
+Fingerprint: SHA256:O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik
+User ID: batman@gotham
+
+AnRoZSB2YWx1ZSBvZiB0aGUgc2hhcmUsIGxvbmcgZW5vdWdoIHRvIG5lZWQgbW9y
+ZSB0aGFuIG9uZSBsaW5lIG9mIHRleHQ=
+-----END KEYMIRROR KEY SHARE-----
+`, share.Armor())
+}
+
+func (s *shamirSuite) Test_ParseKeyShares_findsAllSharesInTheText() {
+	shares, _ := SplitKey(ed25519KeyForTest(), fingerprintForTest, "batman@gotham", 5, 3)
+
+	text := "Here are my shares:\n" + shares[4].Armor() + "\n  and another one\n" + strings.ReplaceAll(shares[0].Armor(), "\n", "  \r\n")
+	parsed, e := ParseKeyShares(text)
+	s.Require().NoError(e)
+	s.Equal([]*KeyShare{shares[4], shares[0]}, parsed)
+}
+
+func (s *shamirSuite) Test_ParseKeyShares_reportsProblems() {
+	_, e := ParseKeyShares("nothing here")
+	s.Equal(ErrNoShares, e)
+
+	_, e = ParseKeyShares(keyShareHeader + "\nShare: 1 of 3\nThreshold: 2\n\n!!!\n" + keyShareFooter)
+	s.Equal(errMalformedShare, e)
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"context"
+
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/sirupsen/logrus"
 )
@@ -13,8 +15,32 @@ type access struct {
 	log logrus.Ext1FieldLogger
 }
 
-func (a *access) AllKeys() []api.KeyEntry {
-	files := a.listFilesInHomeSSHDirectory()
-	return partitionKeyEntries(a.privateKeyRepresentationsFrom(files),
-		publicKeyRepresentationsFrom(files))
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	result := []api.KeyEntry{}
+	if e := a.StreamKeys(ctx, func(k api.KeyEntry) {
+		result = append(result, k)
+	}); e != nil {
+		return nil, e
+	}
+	return result, nil
+}
+
+// StreamKeys reads the public keys first, so each private key can be given together with its public
+// key as soon as its file is read. The public keys without a private key are given at the end
+func (a *access) StreamKeys(ctx context.Context, found func(api.KeyEntry)) error {
+	files, e := a.listFilesInHomeSSHDirectory()
+	if e != nil {
+		return e
+	}
+
+	p := &keyEntryPartitioner{found: found}
+	p.initializePublicKeyCache(publicKeyRepresentationsFrom(files))
+	for _, f := range files {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		p.processPrivateKeys(a.privateKeyRepresentationsFrom([]string{f}))
+	}
+	p.appendRemainingPublicKeys()
+	return nil
 }
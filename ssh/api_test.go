@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"context"
 	"fmt"
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/prashantv/gostub"
@@ -9,10 +10,16 @@ import (
 	"path"
 )
 
+func (s *sshSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
 func (s *sshSuite) Test_access_AllKeys_ReturnsAnEmptyKeyEntryListIfCanNotFindSSHDirectory() {
 	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
 	a, _ := accessWithTestLogging()
-	keys := a.AllKeys()
+	keys := s.allKeysOf(a)
 
 	s.Empty(keys)
 }
@@ -21,7 +28,7 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAnEmptyKeyEntryListIfSSHDirectoryH
 	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
 	s.Nil(os.Mkdir(path.Join(s.tdir, ".ssh"), 0755))
 	a, _ := accessWithTestLogging()
-	keys := a.AllKeys()
+	keys := s.allKeysOf(a)
 
 	s.Empty(keys)
 }
@@ -37,11 +44,22 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAnEmptyKeyEntryListIfSSHDirectoryH
 	}
 	s.createEmptyFile(sshDirectory, "empty-file")
 	a, _ := accessWithTestLogging()
-	keys := a.AllKeys()
+	keys := s.allKeysOf(a)
 
 	s.Empty(keys)
 }
 
+func (s *sshSuite) Test_access_AllKeys_skipsTheBackupsOfReplacedKeys() {
+	sshDirectory := path.Join(s.tdir, ".ssh")
+	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
+	s.Nil(os.Mkdir(sshDirectory, 0755))
+	s.createFileWithContent(sshDirectory, "id_ed25519.bak", ed25519UnprotectedPrivateKey)
+	s.createFileWithContent(sshDirectory, "id_ed25519.bak.1", ed25519UnprotectedPrivateKey)
+	a, _ := accessWithTestLogging()
+
+	s.Empty(s.allKeysOf(a))
+}
+
 func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListOfPrivateKeysIfSSHDirectoryHasOnlyPrivateKeyFiles() {
 	sshDirectory := path.Join(s.tdir, ".ssh")
 	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
@@ -62,7 +80,7 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListOfPrivateKeysIfSSHDir
 		path.Join(sshDirectory, privateKeyFile2),
 		path.Join(sshDirectory, privateKeyFile3),
 	})
-	s.ElementsMatch(p, a.AllKeys())
+	s.ElementsMatch(p, s.allKeysOf(a))
 }
 
 func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListOfPublicKeysIfSSHDirectoryHasOnlyPublicKeyFiles() {
@@ -87,7 +105,7 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListOfPublicKeysIfSSHDire
 		path.Join(sshDirectory, publicKeyFile2),
 		path.Join(sshDirectory, publicKeyFile3),
 	})
-	s.ElementsMatch([]api.KeyEntry{p[0], p[1], p[2]}, a.AllKeys())
+	s.ElementsMatch([]api.KeyEntry{p[0], p[1], p[2]}, s.allKeysOf(a))
 }
 
 func createPublicKeyRepresentationForTest(path, key string) *publicKeyRepresentation {
@@ -133,7 +151,7 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListOfKeypairsIfSSHDirect
 		createKeypairRepresentation(privateKeys[0], publicKeys[0]),
 		createKeypairRepresentation(privateKeys[1], publicKeys[1]),
 		createKeypairRepresentation(privateKeys[2], publicKeys[2]),
-	}, a.AllKeys())
+	}, s.allKeysOf(a))
 }
 
 func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListIfSSHDirectoryPublicAndPrivateKeys() {
@@ -166,5 +184,51 @@ func (s *sshSuite) Test_access_AllKeys_ReturnsAKeyEntryListIfSSHDirectoryPublicA
 		privateKeys[0],
 		publicKeys[0],
 		createKeypairRepresentation(privateKeys[1], publicKeys[1]),
-	}, a.AllKeys())
+	}, s.allKeysOf(a))
+}
+
+func (s *sshSuite) Test_access_AllKeys_FailsIfTheSSHDirectoryCanNotBeRead() {
+	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
+	s.createFileWithContent(s.tdir, ".ssh", "not a directory")
+	a, _ := accessWithTestLogging()
+
+	keys, e := a.AllKeys(context.Background())
+
+	s.Nil(keys)
+	s.Error(e)
+}
+
+func (s *sshSuite) Test_access_AllKeys_StopsWhenTheContextIsCancelled() {
+	sshDirectory := path.Join(s.tdir, ".ssh")
+	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
+	s.Nil(os.Mkdir(sshDirectory, 0755))
+	s.createFileWithContent(sshDirectory, "id_ed25519", correctEd25519PrivateKey)
+	a, _ := accessWithTestLogging()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	keys, e := a.AllKeys(ctx)
+
+	s.Nil(keys)
+	s.Equal(context.Canceled, e)
+}
+
+func (s *sshSuite) Test_access_StreamKeys_GivesKeyPairsBeforeThePublicKeysWithoutPrivateKeys() {
+	sshDirectory := path.Join(s.tdir, ".ssh")
+	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
+	s.Nil(os.Mkdir(sshDirectory, 0755))
+	s.createFileWithContent(sshDirectory, "a-lonely-key.pub", "ssh-rsa AAAA robin@debian")
+	s.createFileWithContent(sshDirectory, "id_ed25519", correctEd25519PrivateKey)
+	s.createFileWithContent(sshDirectory, "id_ed25519.pub", "ssh-ed25519 CCCC alfred@debian")
+	a, _ := accessWithTestLogging()
+
+	found := []api.KeyEntry{}
+	e := a.StreamKeys(context.Background(), func(k api.KeyEntry) {
+		found = append(found, k)
+	})
+
+	s.NoError(e)
+	s.Len(found, 2)
+	s.Equal(api.PairKeyType, found[0].KeyType())
+	s.Equal([]string{path.Join(sshDirectory, "a-lonely-key.pub")}, found[1].Locations())
 }
@@ -0,0 +1,82 @@
+package ssh
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// This is an implementation of the bcrypt_pbkdf key derivation function
+// used by OpenSSH to protect private keys, as described in
+// https://flak.tedunangst.com/post/bcrypt-pbkdf
+
+const bcryptPBKDFBlockSize = 32
+
+var bcryptPBKDFMagic = []byte("OxychromaticBlowfishSwatDynamite")
+
+var errInvalidBcryptPBKDFParameters = errors.New("invalid parameters for bcrypt_pbkdf")
+
+func bcryptPBKDF(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 || len(password) == 0 || len(salt) == 0 || len(salt) > 1<<20 || keyLen < 1 || keyLen > 1024 {
+		return nil, errInvalidBcryptPBKDFParameters
+	}
+
+	numberOfBlocks := (keyLen + bcryptPBKDFBlockSize - 1) / bcryptPBKDFBlockSize
+	key := make([]byte, numberOfBlocks*bcryptPBKDFBlockSize)
+
+	h := sha512.New()
+	h.Write(password)
+	hashedPassword := h.Sum(nil)
+
+	hashedSalt := make([]byte, 0, sha512.Size)
+	counter := make([]byte, 4)
+	tmp := make([]byte, bcryptPBKDFBlockSize)
+	for block := 1; block <= numberOfBlocks; block++ {
+		h.Reset()
+		h.Write(salt)
+		counter[0] = byte(block >> 24)
+		counter[1] = byte(block >> 16)
+		counter[2] = byte(block >> 8)
+		counter[3] = byte(block)
+		h.Write(counter)
+		bcryptHash(tmp, hashedPassword, h.Sum(hashedSalt))
+
+		out := make([]byte, bcryptPBKDFBlockSize)
+		copy(out, tmp)
+		for i := 2; i <= rounds; i++ {
+			h.Reset()
+			h.Write(tmp)
+			bcryptHash(tmp, hashedPassword, h.Sum(hashedSalt))
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+
+		for i, v := range out {
+			key[i*numberOfBlocks+(block-1)] = v
+		}
+	}
+
+	return key[:keyLen], nil
+}
+
+func bcryptHash(out, hashedPassword, hashedSalt []byte) {
+	c, _ := blowfish.NewSaltedCipher(hashedPassword, hashedSalt)
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(hashedSalt, c)
+		blowfish.ExpandKey(hashedPassword, c)
+	}
+
+	copy(out, bcryptPBKDFMagic)
+	for i := 0; i < len(out); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(out[i:i+8], out[i:i+8])
+		}
+	}
+
+	// The output is expected in little endian order
+	for i := 0; i < len(out); i += 4 {
+		out[i+3], out[i+2], out[i+1], out[i] = out[i], out[i+1], out[i+2], out[i+3]
+	}
+}
@@ -0,0 +1,44 @@
+package ssh
+
+import "strings"
+
+var ecdsaKeySizes = map[string]int{
+	"nistp256": 256,
+	"nistp384": 384,
+	"nistp521": 521,
+}
+
+func isECDSAAlgorithm(algo string) bool {
+	return strings.HasPrefix(algo, ecdsaAlgorithmPrefix)
+}
+
+func (k *publicKey) isECDSA() bool {
+	return isECDSAAlgorithm(k.algorithm)
+}
+
+func (k *privateKey) isECDSA() bool {
+	return isECDSAAlgorithm(k.algorithm)
+}
+
+func extractSizeFromECDSAPublicKey(key []byte) (int, bool) {
+	algo, rest, ok := readLengthBytes(key)
+	if !ok || !isECDSAAlgorithm(string(algo)) {
+		return 0, false
+	}
+
+	curve, _, ok := readLengthBytes(rest)
+	if !ok {
+		return 0, false
+	}
+
+	size, ok := ecdsaKeySizes[string(curve)]
+	return size, ok
+}
+
+func ecdsaPublicKeysFrom(fileNameList []string) []*publicKey {
+	return filter(transform(fileNameList, publicKeyFromFile), both(not(isNil[publicKey]), (*publicKey).isECDSA))
+}
+
+func (a *access) ecdsaPrivateKeysFrom(fileNameList []string) []*privateKey {
+	return filter(transform(fileNameList, a.privateKeyFromFile), both(not(isNil[privateKey]), (*privateKey).isECDSA))
+}
@@ -18,10 +18,19 @@ func withoutFileName(targetFileNamesList []string, fileNameToDelete string) []st
 	return filter(targetFileNamesList, not(isEqualTo(fileNameToDelete)))
 }
 
-func (a *access) listFilesInHomeSSHDirectory() []string {
-	sshDirectory := path.Join(os.Getenv("HOME"), ".ssh")
+func homeSSHDirectory() string {
+	return path.Join(os.Getenv("HOME"), ".ssh")
+}
+
+func (a *access) listFilesInHomeSSHDirectory() ([]string, error) {
+	sshDirectory := homeSSHDirectory()
 	a.log.WithField("ssh directory", sshDirectory).Debug("listing files in users .ssh home directory")
-	result := transform(listFilesIn(sshDirectory), func(file string) string {
+	files, e := listFilesInExistingDirectory(sshDirectory)
+	if e != nil {
+		a.log.WithError(e).WithField("ssh directory", sshDirectory).Warn("couldn't list the files in the directory")
+		return nil, e
+	}
+	result := transform(filter(files, not(isBackupFileName)), func(file string) string {
 		return path.Join(sshDirectory, file)
 	})
 	msg := "found these files in the directory"
@@ -30,7 +39,7 @@ func (a *access) listFilesInHomeSSHDirectory() []string {
 	}
 	a.log.WithField("ssh files", result).Debug(msg)
 
-	return result
+	return result, nil
 }
 
 func createPublicKeyRepresentationsFromPublicKeys(input []*publicKey) []*publicKeyRepresentation {
@@ -48,9 +57,13 @@ func (a *access) privateKeyRepresentationsFrom(input []string) []*privateKeyRepr
 	ed25519Keys := a.ed25519PrivateKeyFrom(input)
 	ed25519KeyRepresentations := createPrivateKeyRepresentationFromPrivateKeys(ed25519Keys)
 
+	ecdsaKeys := a.ecdsaPrivateKeysFrom(input)
+	ecdsaKeyRepresentations := createPrivateKeyRepresentationFromPrivateKeys(ecdsaKeys)
+
 	return concat(
 		rsaKeyRepresentations,
 		ed25519KeyRepresentations,
+		ecdsaKeyRepresentations,
 	)
 }
 
@@ -61,7 +74,11 @@ func publicKeyRepresentationsFrom(input []string) []*publicKeyRepresentation {
 	ed25519Keys := ed25519PublicKeyFrom(input)
 	ed25519KeyRepresentations := createPublicKeyRepresentationsFromPublicKeys(ed25519Keys)
 
+	ecdsaKeys := ecdsaPublicKeysFrom(input)
+	ecdsaKeyRepresentations := createPublicKeyRepresentationsFromPublicKeys(ecdsaKeys)
+
 	return concat(
 		rsaKeyRepresentations,
-		ed25519KeyRepresentations)
+		ed25519KeyRepresentations,
+		ecdsaKeyRepresentations)
 }
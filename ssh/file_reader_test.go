@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/digitalautonomy/keymirror/api"
 	"github.com/sirupsen/logrus/hooks/test"
+	"math/big"
 	"math/rand"
 	"os"
 	"path"
@@ -313,7 +314,8 @@ func accessWithTestLogging() (*access, *test.Hook) {
 func (s *sshSuite) Test_listFilesInHomeSSHDirectory_ReturnsAnEmptyListIfTheDotSSHDirectoryDoesNotExistInTheUsersHomeDirectory() {
 	defer gostub.New().SetEnv("HOME", s.tdir).Reset()
 	a, _ := accessWithTestLogging()
-	files := a.listFilesInHomeSSHDirectory()
+	files, e := a.listFilesInHomeSSHDirectory()
+	s.NoError(e)
 
 	s.Empty(files)
 }
@@ -323,7 +325,8 @@ func (s *sshSuite) Test_listFilesInHomeSSHDirectory_ReturnsAnEmptyListIfTheDotSS
 	s.Nil(os.Mkdir(path.Join(s.tdir, ".ssh"), 0755))
 
 	a, _ := accessWithTestLogging()
-	files := a.listFilesInHomeSSHDirectory()
+	files, e := a.listFilesInHomeSSHDirectory()
+	s.NoError(e)
 
 	s.Empty(files)
 }
@@ -344,7 +347,8 @@ func (s *sshSuite) Test_listFilesInHomeSSHDirectory_ReturnsAListOfFilesIfTheDotS
 	})
 
 	a, _ := accessWithTestLogging()
-	files := a.listFilesInHomeSSHDirectory()
+	files, e := a.listFilesInHomeSSHDirectory()
+	s.NoError(e)
 
 	s.Equal(expected, files)
 }
@@ -386,11 +390,27 @@ func (s *sshSuite) Test_privateKeyEntriesFrom_ReturnsAListOfPrivateKeyEntriesFro
 	}
 
 	l = a.privateKeyRepresentationsFrom(paths)
-	s.Equal([]*privateKeyRepresentation{
-		{filepath.Join(s.tdir, privateRSAKeyFile1), false, 3072, api.RSA},
-		{filepath.Join(s.tdir, privateRSAKeyFile2), false, 3072, api.RSA},
-		{filepath.Join(s.tdir, privateRSAKeyFile3Protected), true, 3072, api.RSA},
-	}, l)
+	s.Require().Len(l, 3)
+	expected := []struct {
+		path      string
+		protected bool
+		content   string
+	}{
+		{filepath.Join(s.tdir, privateRSAKeyFile1), false, correctRSASSHPrivateKey},
+		{filepath.Join(s.tdir, privateRSAKeyFile2), false, correctRSASSHPrivateKeyOther},
+		{filepath.Join(s.tdir, privateRSAKeyFile3Protected), true, correctRSAPasswordProtectedKey},
+	}
+	for i, ex := range expected {
+		f, e := decodeOpensshPrivateKeyFile([]byte(ex.content))
+		s.Require().NoError(e)
+
+		s.Equal(ex.path, l[i].path)
+		s.Equal(ex.protected, l[i].passwordProtected)
+		s.Equal(3072, l[i].size)
+		s.Equal(api.RSA, l[i].algorithm)
+		s.Equal(f.publicKey, l[i].publicKey)
+		s.Equal(big.NewInt(65537), l[i].RSAPublicExponent())
+	}
 }
 
 func (s *sshSuite) Test_publicKeyEntriesFrom_ReturnsAListOfPublicKeyEntriesFromAllTheProvidedPaths() {
@@ -441,50 +461,64 @@ func createPrivateKeyRepresentationForTest(path string) *privateKeyRepresentatio
 	})
 }
 
-func (s *sshSuite) Test_partitionKeyEntries_ReturnsAListOfKeyEntriesWithPublicPrivateAndKeyPairsFromPublicAndPrivateKeyRepresentations() {
-	// Both privates and publics are empty
-	privates := []*privateKeyRepresentation{}
-	publics := []*publicKeyRepresentation{}
-	l := partitionKeyEntries(privates, publics)
-	s.Empty(l)
-
-	// Only privates and no publics
-	privates = []*privateKeyRepresentation{
-		createPrivateKeyRepresentationForTest("exclusively"),
-		createPrivateKeyRepresentationForTest("privates"),
-	}
-	publics = []*publicKeyRepresentation{}
-	l = partitionKeyEntries(privates, publics)
-	s.ElementsMatch([]api.KeyEntry{
-		createPrivateKeyRepresentationForTest("exclusively"),
-		createPrivateKeyRepresentationForTest("privates"),
-	}, l)
-
-	// Only publics and no privates
-	privates = []*privateKeyRepresentation{}
-	publics = []*publicKeyRepresentation{
-		createPublicKeyRepresentationForTest("exclusively.pub", ""),
-		createPublicKeyRepresentationForTest("publics.pub", ""),
+func (s *sshSuite) Test_keyEntryPartitioner_FindsPublicPrivateAndKeyPairsFromPublicAndPrivateKeyRepresentations() {
+	cases := []struct {
+		name     string
+		privates []*privateKeyRepresentation
+		publics  []*publicKeyRepresentation
+		expected []api.KeyEntry
+	}{
+		{name: "both privates and publics are empty"},
+		{
+			name: "only privates and no publics",
+			privates: []*privateKeyRepresentation{
+				createPrivateKeyRepresentationForTest("exclusively"),
+				createPrivateKeyRepresentationForTest("privates"),
+			},
+			expected: []api.KeyEntry{
+				createPrivateKeyRepresentationForTest("exclusively"),
+				createPrivateKeyRepresentationForTest("privates"),
+			},
+		},
+		{
+			name: "only publics and no privates",
+			publics: []*publicKeyRepresentation{
+				createPublicKeyRepresentationForTest("exclusively.pub", ""),
+				createPublicKeyRepresentationForTest("publics.pub", ""),
+			},
+			expected: []api.KeyEntry{
+				createPublicKeyRepresentationForTest("exclusively.pub", ""),
+				createPublicKeyRepresentationForTest("publics.pub", ""),
+			},
+		},
+		{
+			name: "one pair, one lonely public and one lonely private",
+			privates: []*privateKeyRepresentation{
+				createPrivateKeyRepresentationForTest("matching pair"),
+				createPrivateKeyRepresentationForTest("lonely private"),
+			},
+			publics: []*publicKeyRepresentation{
+				createPublicKeyRepresentationForTest("matching pair.pub", ""),
+				createPublicKeyRepresentationForTest("lonely public.pub", ""),
+			},
+			expected: []api.KeyEntry{
+				createKeypairRepresentation(createPrivateKeyRepresentationForTest("matching pair"), createPublicKeyRepresentationForTest("matching pair.pub", "")),
+				createPrivateKeyRepresentationForTest("lonely private"),
+				createPublicKeyRepresentationForTest("lonely public.pub", ""),
+			},
+		},
 	}
-	l = partitionKeyEntries(privates, publics)
-	s.ElementsMatch([]api.KeyEntry{
-		createPublicKeyRepresentationForTest("exclusively.pub", ""),
-		createPublicKeyRepresentationForTest("publics.pub", ""),
-	}, l)
-
-	// One pair, one lonely public and one lonely private
-	privates = []*privateKeyRepresentation{
-		createPrivateKeyRepresentationForTest("matching pair"),
-		createPrivateKeyRepresentationForTest("lonely private"),
-	}
-	publics = []*publicKeyRepresentation{
-		createPublicKeyRepresentationForTest("matching pair.pub", ""),
-		createPublicKeyRepresentationForTest("lonely public.pub", ""),
+
+	for _, c := range cases {
+		found := []api.KeyEntry{}
+		p := &keyEntryPartitioner{found: func(k api.KeyEntry) {
+			found = append(found, k)
+		}}
+
+		p.initializePublicKeyCache(c.publics)
+		p.processPrivateKeys(c.privates)
+		p.appendRemainingPublicKeys()
+
+		s.ElementsMatch(c.expected, found, c.name)
 	}
-	l = partitionKeyEntries(privates, publics)
-	s.ElementsMatch([]api.KeyEntry{
-		createKeypairRepresentation(createPrivateKeyRepresentationForTest("matching pair"), createPublicKeyRepresentationForTest("matching pair.pub", "")),
-		createPrivateKeyRepresentationForTest("lonely private"),
-		createPublicKeyRepresentationForTest("lonely public.pub", ""),
-	}, l)
 }
@@ -0,0 +1,72 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/files"
+)
+
+const backupSuffix = ".bak"
+
+// availableBackupFileName returns the first of file.bak, file.bak.1, file.bak.2... that doesn't exist yet
+func availableBackupFileName(fileName string) string {
+	candidate := fileName + backupSuffix
+	for i := 1; fileExists(candidate); i++ {
+		candidate = fmt.Sprintf("%s%s.%d", fileName, backupSuffix, i)
+	}
+	return candidate
+}
+
+func fileExists(fileName string) bool {
+	_, e := os.Lstat(fileName)
+	return !errors.Is(e, fs.ErrNotExist)
+}
+
+// isBackupFileName tells whether the file is a backup written by replaceFileKeepingBackup, named either
+// file.bak or file.bak.N. Backups aren't listed, so the previous version of a key isn't shown as a separate key
+func isBackupFileName(fileName string) bool {
+	name := filepath.Base(fileName)
+	if strings.HasSuffix(name, backupSuffix) {
+		return len(name) > len(backupSuffix)
+	}
+	i := strings.LastIndex(name, backupSuffix+".")
+	return i > 0 && isBackupCounter(name[i+len(backupSuffix)+1:])
+}
+
+func isBackupCounter(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceFileKeepingBackup atomically replaces the content of the file, after saving the previous
+// content to a new backup file with the same permissions. It returns the name of the backup file
+func replaceFileKeepingBackup(fileName string, content []byte) (string, error) {
+	info, e := os.Stat(fileName)
+	if e != nil {
+		return "", e
+	}
+
+	original, e := os.ReadFile(fileName)
+	if e != nil {
+		return "", e
+	}
+
+	backup := availableBackupFileName(fileName)
+	if e := files.WriteAtomically(backup, original, info.Mode().Perm()); e != nil {
+		return "", e
+	}
+
+	return backup, files.WriteAtomically(fileName, content, info.Mode().Perm())
+}
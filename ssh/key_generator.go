@@ -0,0 +1,391 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+)
+
+const publicKeyFileSuffix = ".pub"
+
+// minimumRSAKeySize is the smallest RSA key size ssh-keygen will generate
+const minimumRSAKeySize = 1024
+
+var errFileAlreadyExists = errors.New("a file with that name already exists")
+var errUnsupportedKeySize = errors.New("the key size is not supported for this algorithm")
+var errInvalidKeyFileName = errors.New("the key file name must be an absolute path or a name without directories")
+
+// keyFileName resolves the name of a key file that is going to be written. A plain name is put in
+// the .ssh directory, as ssh-keygen does, while other relative paths are refused, so that a name
+// like ../../key can't end up somewhere unexpected
+func keyFileName(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name), nil
+	}
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name || strings.ContainsRune(name, '/') {
+		return "", errInvalidKeyFileName
+	}
+	return filepath.Join(homeSSHDirectory(), name), nil
+}
+
+var ecdsaCurveNames = map[int]string{
+	256: "nistp256",
+	384: "nistp384",
+	521: "nistp521",
+}
+
+func generatePrivateKey(algorithm api.Algorithm, size int) (crypto.Signer, error) {
+	switch algorithm {
+	case api.Ed25519:
+		_, priv, e := ed25519.GenerateKey(rand.Reader)
+		return priv, e
+	case api.RSA:
+		if size < minimumRSAKeySize {
+			return nil, errUnsupportedKeySize
+		}
+		return rsa.GenerateKey(rand.Reader, size)
+	case api.ECDSA:
+		curveName, ok := ecdsaCurveNames[size]
+		if !ok {
+			return nil, errUnsupportedKeySize
+		}
+		return ecdsa.GenerateKey(ecdsaCurves[curveName], rand.Reader)
+	}
+	return nil, errUnsupportedKeyAlgorithm
+}
+
+// mpIntBytes encodes the number in the mpint format from RFC 4251, section 5
+func mpIntBytes(v *big.Int) []byte {
+	b := v.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return lengthBytes(b)
+}
+
+func ecdsaCurveNameOf(k *ecdsa.PublicKey) string {
+	return ecdsaCurveNames[k.Curve.Params().BitSize]
+}
+
+// publicKeyBlob returns the public key in the wire format used inside of public and private key files
+func publicKeyBlob(pub crypto.PublicKey) ([]byte, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return concat(lengthBytes([]byte(ed25519Algorithm)), lengthBytes(k)), nil
+	case *rsa.PublicKey:
+		return concat(lengthBytes([]byte(rsaAlgorithm)), mpIntBytes(big.NewInt(int64(k.E))), mpIntBytes(k.N)), nil
+	case *ecdsa.PublicKey:
+		curveName := ecdsaCurveNameOf(k)
+		return concat(
+			lengthBytes([]byte(ecdsaAlgorithmPrefix+curveName)),
+			lengthBytes([]byte(curveName)),
+			lengthBytes(elliptic.Marshal(k.Curve, k.X, k.Y)),
+		), nil
+	}
+	return nil, errUnsupportedKeyAlgorithm
+}
+
+// privateKeyPayload returns the private key and comment in the format used inside of the
+// private section of OpenSSH private key files, as described in PROTOCOL.key
+func privateKeyPayload(priv crypto.Signer, comment string) ([]byte, error) {
+	var key []byte
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		key = concat(
+			lengthBytes([]byte(ed25519Algorithm)),
+			lengthBytes(k.Public().(ed25519.PublicKey)),
+			lengthBytes(k),
+		)
+	case *rsa.PrivateKey:
+		key = concat(
+			lengthBytes([]byte(rsaAlgorithm)),
+			mpIntBytes(k.N),
+			mpIntBytes(big.NewInt(int64(k.E))),
+			mpIntBytes(k.D),
+			mpIntBytes(k.Precomputed.Qinv),
+			mpIntBytes(k.Primes[0]),
+			mpIntBytes(k.Primes[1]),
+		)
+	case *ecdsa.PrivateKey:
+		curveName := ecdsaCurveNameOf(&k.PublicKey)
+		key = concat(
+			lengthBytes([]byte(ecdsaAlgorithmPrefix+curveName)),
+			lengthBytes([]byte(curveName)),
+			lengthBytes(elliptic.Marshal(k.Curve, k.X, k.Y)),
+			mpIntBytes(k.D),
+		)
+	default:
+		return nil, errUnsupportedKeyAlgorithm
+	}
+
+	return concat(key, lengthBytes([]byte(comment))), nil
+}
+
+// writeNewFile will fail instead of overwriting an existing file
+func writeNewFile(fileName string, content []byte, perm os.FileMode) error {
+	f, e := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if e != nil {
+		return e
+	}
+	return files.WriteAndClose(f, content, perm)
+}
+
+func generateKeyFiles(o api.KeyGenerationOptions) error {
+	if fileExists(o.FileName) || fileExists(o.FileName+publicKeyFileSuffix) {
+		return errFileAlreadyExists
+	}
+
+	if e := validateUserID(o.UserID); e != nil {
+		return e
+	}
+
+	priv, e := generatePrivateKey(o.Algorithm, o.Size)
+	if e != nil {
+		return e
+	}
+
+	return writeKeyFiles(priv, o.UserID, o.FileName, o.Passphrase)
+}
+
+// writeKeyFiles writes the private key in the OpenSSH format, protected with the passphrase unless it
+// is empty, and the public key to the same name with .pub added
+func opensshPrivateKeyFileFor(priv crypto.Signer, pub []byte, userID string, passphrase []byte) (*opensshPrivateKeyFile, error) {
+	payload, e := privateKeyPayload(priv, userID)
+	if e != nil {
+		return nil, e
+	}
+
+	return newOpensshPrivateKeyFile(pub, payload, passphrase, api.PrivateKeyProtection{
+		Cipher: defaultPrivateKeyCipher,
+		Rounds: api.DefaultKDFRounds,
+	})
+}
+
+// PrivateKeyFileContent returns the content of an OpenSSH private key file for a key that comes from
+// another format. The key is protected with the passphrase in the same way as generated keys, unless it is empty
+func PrivateKeyFileContent(priv crypto.PrivateKey, userID string, passphrase []byte) ([]byte, error) {
+	if e := validateUserID(userID); e != nil {
+		return nil, e
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errUnsupportedKeyAlgorithm
+	}
+
+	pub, e := publicKeyBlob(signer.Public())
+	if e != nil {
+		return nil, e
+	}
+
+	f, e := opensshPrivateKeyFileFor(signer, pub, userID, passphrase)
+	if e != nil {
+		return nil, e
+	}
+	return f.encode(), nil
+}
+
+func writeKeyFiles(priv crypto.Signer, userID, fileName string, passphrase []byte) error {
+	privateFile, publicFile := fileName, fileName+publicKeyFileSuffix
+	if fileExists(privateFile) || fileExists(publicFile) {
+		return errFileAlreadyExists
+	}
+
+	pub, e := publicKeyBlob(priv.Public())
+	if e != nil {
+		return e
+	}
+
+	f, e := opensshPrivateKeyFileFor(priv, pub, userID, passphrase)
+	if e != nil {
+		return e
+	}
+
+	publicContent, e := publicKeyFileContent(pub, userID)
+	if e != nil {
+		return e
+	}
+
+	if e := os.MkdirAll(filepath.Dir(privateFile), 0700); e != nil {
+		return e
+	}
+
+	if e := writeNewFile(privateFile, f.encode(), 0600); e != nil {
+		return e
+	}
+
+	if e := writeNewFile(publicFile, publicContent, 0644); e != nil {
+		_ = os.Remove(privateFile)
+		return e
+	}
+
+	return nil
+}
+
+// keypairFromFile reads the key files that were just written
+func (a *access) keypairFromFile(fileName string) (api.KeyEntry, error) {
+	private := a.privateKeyFromFile(fileName)
+	public := publicKeyFromFile(fileName + publicKeyFileSuffix)
+	if private == nil || public == nil {
+		return nil, errMalformedPrivateKey
+	}
+
+	return createKeypairRepresentation(
+		createPrivateKeyRepresentationFromPrivateKey(private),
+		createPublicKeyRepresentationFromPublicKey(public),
+	), nil
+}
+
+// GenerateKey implements the api.KeyGenerator interface. It writes the private key in the
+// OpenSSH format to the given file name, and the public key to the same name with .pub added.
+// File names are resolved with keyFileName
+func (a *access) GenerateKey(o api.KeyGenerationOptions) (api.KeyEntry, error) {
+	fileName, e := keyFileName(o.FileName)
+	if e == nil {
+		o.FileName = fileName
+		e = generateKeyFiles(o)
+	}
+	if e != nil {
+		a.log.WithError(e).WithField("file", o.FileName).Error("couldn't generate a new key")
+		return nil, e
+	}
+
+	return a.keypairFromFile(o.FileName)
+}
+
+// ImportPublicKey implements the api.PublicKeyImporter interface. It writes the public key in the
+// same format ssh-keygen uses, refusing to overwrite an existing file
+func (a *access) ImportPublicKey(pub crypto.PublicKey, userID, fileName string) (api.KeyEntry, error) {
+	fileName, e := keyFileName(fileName)
+	if e == nil {
+		e = importPublicKey(pub, userID, fileName)
+	}
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Error("couldn't import the public key")
+		return nil, e
+	}
+
+	public := publicKeyFromFile(fileName)
+	if public == nil {
+		return nil, errMalformedPublicKey
+	}
+	return createPublicKeyRepresentationFromPublicKey(public), nil
+}
+
+func importPublicKey(pub crypto.PublicKey, userID, fileName string) error {
+	if e := validateUserID(userID); e != nil {
+		return e
+	}
+
+	blob, e := publicKeyBlob(pub)
+	if e != nil {
+		return e
+	}
+
+	content, e := publicKeyFileContent(blob, userID)
+	if e != nil {
+		return e
+	}
+
+	if e := os.MkdirAll(filepath.Dir(fileName), 0700); e != nil {
+		return e
+	}
+	return writeNewFile(fileName, content, 0644)
+}
+
+// ImportPrivateKey implements the api.PrivateKeyImporter interface. It writes the OpenSSH private key
+// file as is, together with its public key, refusing to overwrite existing files
+func (a *access) ImportPrivateKey(content []byte, fileName string) (api.KeyEntry, error) {
+	fileName, e := keyFileName(fileName)
+	if e == nil {
+		e = importPrivateKey(content, fileName)
+	}
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Error("couldn't import the private key")
+		return nil, e
+	}
+
+	return a.keypairFromFile(fileName)
+}
+
+// importPrivateKey can only recover the user ID of keys that are not protected by a passphrase,
+// since it is stored in the encrypted part of the file
+func importPrivateKey(content []byte, fileName string) error {
+	privateFile, publicFile := fileName, fileName+publicKeyFileSuffix
+	if fileExists(privateFile) || fileExists(publicFile) {
+		return errFileAlreadyExists
+	}
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	if e != nil {
+		return e
+	}
+
+	userID := ""
+	if !f.isEncrypted() {
+		unlocked, e := f.unlock(nil)
+		if e != nil {
+			return e
+		}
+		userID = unlocked.UserID()
+	}
+
+	publicContent, e := publicKeyFileContent(f.publicKey, userID)
+	if e != nil {
+		return e
+	}
+
+	if e := os.MkdirAll(filepath.Dir(privateFile), 0700); e != nil {
+		return e
+	}
+
+	if e := writeNewFile(privateFile, content, 0600); e != nil {
+		return e
+	}
+
+	if e := writeNewFile(publicFile, publicContent, 0644); e != nil {
+		_ = os.Remove(privateFile)
+		return e
+	}
+
+	return nil
+}
+
+// ImportPrivateKeyMaterial implements the api.PrivateKeyMaterialImporter interface. It writes the key files
+// in the same way as GenerateKey does for new keys
+func (a *access) ImportPrivateKeyMaterial(priv crypto.PrivateKey, userID, fileName string, passphrase []byte) (api.KeyEntry, error) {
+	fileName, e := keyFileName(fileName)
+	if e == nil {
+		e = importPrivateKeyMaterial(priv, userID, fileName, passphrase)
+	}
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Error("couldn't import the private key")
+		return nil, e
+	}
+
+	return a.keypairFromFile(fileName)
+}
+
+func importPrivateKeyMaterial(priv crypto.PrivateKey, userID, fileName string, passphrase []byte) error {
+	if e := validateUserID(userID); e != nil {
+		return e
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return errUnsupportedKeyAlgorithm
+	}
+
+	return writeKeyFiles(signer, userID, fileName, passphrase)
+}
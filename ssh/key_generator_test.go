@@ -0,0 +1,242 @@
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/prashantv/gostub"
+)
+
+func (s *sshSuite) Test_mpIntBytes_addsALeadingZeroWhenTheHighBitIsSet() {
+	s.Equal([]byte{0x00, 0x00, 0x00, 0x01, 0x7f}, mpIntBytes(big.NewInt(0x7f)))
+	s.Equal([]byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x80}, mpIntBytes(big.NewInt(0x80)))
+	s.Equal([]byte{0x00, 0x00, 0x00, 0x00}, mpIntBytes(big.NewInt(0)))
+}
+
+func (s *sshSuite) Test_generatePrivateKey_rejectsUnsupportedSizesAndAlgorithms() {
+	_, e := generatePrivateKey(api.RSA, 512)
+	s.Equal(errUnsupportedKeySize, e)
+
+	_, e = generatePrivateKey(api.ECDSA, 224)
+	s.Equal(errUnsupportedKeySize, e)
+
+	_, e = generatePrivateKey(api.DSA, 1024)
+	s.Equal(errUnsupportedKeyAlgorithm, e)
+}
+
+func (s *sshSuite) Test_access_GenerateKey_writesAnEd25519KeypairThatCanBeRead() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "keys", "id_ed25519")
+
+	k, e := a.GenerateKey(api.KeyGenerationOptions{
+		Algorithm:  api.Ed25519,
+		UserID:     "alfred@batcave",
+		FileName:   fileName,
+		Passphrase: []byte("correct horse"),
+	})
+	s.Require().NoError(e)
+
+	s.Equal(api.Ed25519, k.Algorithm())
+	s.Equal("alfred@batcave", k.(api.PublicKeyEntry).UserID())
+	s.Equal([]string{fileName, fileName + ".pub"}, k.Locations())
+
+	info, _ := os.Stat(fileName)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+	info, _ = os.Stat(fileName + ".pub")
+	s.Equal(os.FileMode(0644), info.Mode().Perm())
+	info, _ = os.Stat(filepath.Dir(fileName))
+	s.Equal(os.FileMode(0700), info.Mode().Perm())
+
+	unlocked, e := k.(api.UnlockablePrivateKeyEntry).Unlock([]byte("correct horse"))
+	s.Require().NoError(e)
+	priv := unlocked.PrivateKey().(ed25519.PrivateKey)
+	s.Equal(priv.Public(), k.(api.PublicKeyMaterialEntry).PublicKey())
+}
+
+func (s *sshSuite) Test_access_GenerateKey_writesRSAAndECDSAKeys() {
+	a, _ := accessWithTestLogging()
+
+	k, e := a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.RSA, Size: 2048, FileName: filepath.Join(s.tdir, "id_rsa")})
+	s.Require().NoError(e)
+	s.Equal(api.RSA, k.Algorithm())
+	s.Equal(2048, k.Size())
+	unlocked, e := k.(api.UnlockablePrivateKeyEntry).Unlock(nil)
+	s.Require().NoError(e)
+	s.NoError(unlocked.PrivateKey().(*rsa.PrivateKey).Validate())
+
+	for _, size := range []int{256, 384, 521} {
+		k, e = a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.ECDSA, Size: size, FileName: filepath.Join(s.tdir, "id_ecdsa", big.NewInt(int64(size)).String())})
+		s.Require().NoError(e)
+		s.Equal(api.ECDSA, k.Algorithm())
+		s.Equal(size, k.Size())
+		unlocked, e = k.(api.UnlockablePrivateKeyEntry).Unlock(nil)
+		s.Require().NoError(e)
+		s.Equal(size, unlocked.PrivateKey().(*ecdsa.PrivateKey).Curve.Params().BitSize)
+	}
+}
+
+func (s *sshSuite) Test_access_GenerateKey_refusesToOverwriteExistingFiles() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+	s.createFileWithContent(s.tdir, "id_ed25519.pub", "existing")
+
+	_, e := a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.Ed25519, FileName: fileName})
+	s.Equal(errFileAlreadyExists, e)
+
+	s.NoFileExists(fileName)
+	content, _ := os.ReadFile(fileName + ".pub")
+	s.Equal("existing", string(content))
+}
+
+func (s *sshSuite) Test_keyFileName_putsPlainNamesInTheSSHDirectory() {
+	defer gostub.New().SetEnv("HOME", "/home/amnesia").Reset()
+
+	name, e := keyFileName("id_work")
+	s.NoError(e)
+	s.Equal("/home/amnesia/.ssh/id_work", name)
+
+	name, e = keyFileName("/tmp/keys/../id_work")
+	s.NoError(e)
+	s.Equal("/tmp/id_work", name)
+}
+
+func (s *sshSuite) Test_keyFileName_refusesRelativePathsWithDirectories() {
+	for _, name := range []string{"", ".", "..", "../../escape", "keys/id_work", "./id_work", "id_work/"} {
+		_, e := keyFileName(name)
+		s.Equal(errInvalidKeyFileName, e, name)
+	}
+}
+
+func (s *sshSuite) Test_access_GenerateKey_doesNotWriteOutsideOfTheSSHDirectoryForRelativeNames() {
+	home := filepath.Join(s.tdir, "home", "amnesia")
+	defer gostub.New().SetEnv("HOME", home).Reset()
+	a, _ := accessWithTestLogging()
+
+	_, e := a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.Ed25519, FileName: "../../escape"})
+	s.Equal(errInvalidKeyFileName, e)
+	s.NoFileExists(filepath.Join(s.tdir, "escape"))
+
+	k, e := a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.Ed25519, FileName: "id_work"})
+	s.Require().NoError(e)
+	s.Equal([]string{filepath.Join(home, ".ssh", "id_work"), filepath.Join(home, ".ssh", "id_work.pub")}, k.Locations())
+}
+
+func (s *sshSuite) Test_access_GenerateKey_rejectsInvalidUserIDs() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+
+	_, e := a.GenerateKey(api.KeyGenerationOptions{Algorithm: api.Ed25519, UserID: "two\nlines", FileName: fileName})
+	s.Equal(errInvalidUserID, e)
+	s.NoFileExists(fileName)
+}
+
+func (s *sshSuite) Test_access_ImportPublicKey_writesAPublicKeyFile() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "imported.pub")
+	pub, _ := parsePublicKey(ed25519UnprotectedPrivateKeyPublic)
+	material, _ := parsePublicKeyMaterial(pub.key)
+
+	k, e := a.ImportPublicKey(material, "imported@example.org", fileName)
+	s.Require().NoError(e)
+	s.Equal(api.PublicKeyType, k.KeyType())
+	s.Equal([]string{fileName}, k.Locations())
+	s.Equal(material, k.(api.PublicKeyMaterialEntry).PublicKey())
+
+	content, _ := os.ReadFile(fileName)
+	s.Equal("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb imported@example.org\n", string(content))
+
+	_, e = a.ImportPublicKey(material, "", fileName)
+	s.True(os.IsExist(e))
+}
+
+func (s *sshSuite) Test_access_ImportPrivateKey_writesThePrivateAndPublicKeyFiles() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "restored", "id_ed25519")
+
+	k, e := a.ImportPrivateKey([]byte(ed25519UnprotectedPrivateKey), fileName)
+	s.Require().NoError(e)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{fileName, fileName + ".pub"}, k.Locations())
+
+	content, _ := os.ReadFile(fileName)
+	s.Equal(ed25519UnprotectedPrivateKey, string(content))
+	info, _ := os.Stat(fileName)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	content, _ = os.ReadFile(fileName + ".pub")
+	s.Equal(ed25519UnprotectedPrivateKeyPublic+"\n", string(content))
+
+	_, e = a.ImportPrivateKey([]byte(ed25519UnprotectedPrivateKey), fileName)
+	s.Equal(errFileAlreadyExists, e)
+}
+
+func (s *sshSuite) Test_access_ImportPrivateKey_keepsProtectedKeysProtected() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+
+	k, e := a.ImportPrivateKey([]byte(ed25519ProtectedPrivateKey), fileName)
+	s.Require().NoError(e)
+	s.True(k.(api.PrivateKeyEntry).IsPasswordProtected())
+
+	content, _ := os.ReadFile(fileName + ".pub")
+	s.Equal(strings.TrimSuffix(ed25519ProtectedPrivateKeyPublic, " robin@gotham")+"\n", string(content))
+}
+
+func (s *sshSuite) Test_access_ImportPrivateKey_refusesContentThatIsNotAPrivateKey() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+
+	_, e := a.ImportPrivateKey([]byte(ed25519UnprotectedPrivateKeyPublic), fileName)
+	s.Equal(errNotAnOpensshPrivateKey, e)
+	s.NoFileExists(fileName)
+}
+
+func (s *sshSuite) Test_access_ImportPrivateKeyMaterial_writesProtectedKeyFiles() {
+	a, _ := accessWithTestLogging()
+	fileName := filepath.Join(s.tdir, "id_restored")
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+	k, e := a.ImportPrivateKeyMaterial(priv, "restored@example.org", fileName, []byte("secret"))
+	s.Require().NoError(e)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.True(k.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.Equal("restored@example.org", k.(api.PublicKeyEntry).UserID())
+
+	unlocked, e := k.(api.UnlockablePrivateKeyEntry).Unlock([]byte("secret"))
+	s.Require().NoError(e)
+	s.Equal(priv, unlocked.PrivateKey())
+
+	_, e = a.ImportPrivateKeyMaterial(priv, "", fileName, nil)
+	s.Equal(errFileAlreadyExists, e)
+}
+
+func (s *sshSuite) Test_access_ImportPrivateKeyMaterial_refusesKeysThatCanNotSign() {
+	a, _ := accessWithTestLogging()
+
+	_, e := a.ImportPrivateKeyMaterial("not a key", "", filepath.Join(s.tdir, "id_restored"), nil)
+	s.Equal(errUnsupportedKeyAlgorithm, e)
+}
+
+func (s *sshSuite) Test_PrivateKeyFileContent_returnsAnOpenSSHPrivateKeyFile() {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+	content, e := PrivateKeyFileContent(priv, "extracted@example.org", nil)
+	s.Require().NoError(e)
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	s.Require().NoError(e)
+	s.False(f.isEncrypted())
+	unlocked, e := f.unlock(nil)
+	s.Require().NoError(e)
+	s.Equal(priv, unlocked.PrivateKey())
+	s.Equal("extracted@example.org", unlocked.UserID())
+
+	_, e = PrivateKeyFileContent("not a key", "", nil)
+	s.Equal(errUnsupportedKeyAlgorithm, e)
+}
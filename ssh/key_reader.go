@@ -3,6 +3,7 @@ package ssh
 import (
 	"io/fs"
 	"io/ioutil"
+	"os"
 )
 
 func listFilesIn(dir string) []string {
@@ -11,6 +12,16 @@ func listFilesIn(dir string) []string {
 	return transform(files, (fs.FileInfo).Name)
 }
 
+// listFilesInExistingDirectory is like listFilesIn, but fails when the directory exists and can't be read
+func listFilesInExistingDirectory(dir string) ([]string, error) {
+	files, e := ioutil.ReadDir(dir)
+	if e != nil && !os.IsNotExist(e) {
+		return nil, e
+	}
+
+	return transform(files, (fs.FileInfo).Name), nil
+}
+
 type publicKey struct {
 	location  string
 	algorithm string
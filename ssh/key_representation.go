@@ -1,6 +1,9 @@
 package ssh
 
 import (
+	"crypto"
+	"math/big"
+
 	"github.com/digitalautonomy/keymirror/api"
 )
 
@@ -9,6 +12,7 @@ type privateKeyRepresentation struct {
 	passwordProtected bool
 	size              int
 	algorithm         api.Algorithm
+	publicKey         []byte
 }
 
 type publicKeyRepresentation struct {
@@ -34,13 +38,19 @@ func createPublicKeyRepresentationFromPublicKey(key *publicKey) *publicKeyRepres
 	}
 }
 
-var algorithms = map[string]api.Algorithm{
-	rsaAlgorithm:     api.RSA,
-	ed25519Algorithm: api.Ed25519,
+func init() {
+	api.RegisterWireNames(api.RSA, rsaAlgorithm)
+	api.RegisterWireNames(api.Ed25519, ed25519Algorithm)
+	api.RegisterWireNames(api.DSA, dsaAlgorithm)
+	api.RegisterWireNames(api.ECDSA,
+		ecdsaAlgorithmPrefix+"nistp256",
+		ecdsaAlgorithmPrefix+"nistp384",
+		ecdsaAlgorithmPrefix+"nistp521")
 }
 
 func translateSshAlgorithmToExternalAlgorithm(algo string) api.Algorithm {
-	return algorithms[algo]
+	a, _ := api.AlgorithmWithWireName(algo)
+	return a
 }
 
 func createPrivateKeyRepresentationFromPrivateKey(key *privateKey) *privateKeyRepresentation {
@@ -49,6 +59,7 @@ func createPrivateKeyRepresentationFromPrivateKey(key *privateKey) *privateKeyRe
 		passwordProtected: key.passwordProtected,
 		size:              key.size,
 		algorithm:         translateSshAlgorithmToExternalAlgorithm(key.algorithm),
+		publicKey:         key.publicKey,
 	}
 }
 
@@ -75,6 +86,30 @@ func nilOrStringSlice(s string) []string {
 	return []string{s}
 }
 
+// keyIDFrom returns the ID of the key with the public key in the SSH wire format. Keys that can be parsed get
+// the same ID as the same public key stored in other formats
+func keyIDFrom(publicKey []byte) string {
+	if pub, ok := parsePublicKeyMaterial(publicKey); ok {
+		if id, ok := api.PublicKeyID(pub); ok {
+			return id
+		}
+	}
+	return api.MaterialID("ssh", publicKey)
+}
+
+// ID implements the KeyEntry interface, using the public key stored in the private key file when there is one
+func (k *privateKeyRepresentation) ID() string {
+	if len(k.publicKey) == 0 {
+		return api.LocationID(k.path)
+	}
+	return keyIDFrom(k.publicKey)
+}
+
+// Same implements the KeyEntry interface
+func (k *privateKeyRepresentation) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
 // Locations implement the KeyEntry interface
 func (k *privateKeyRepresentation) Locations() []string {
 	return nilOrStringSlice(k.path)
@@ -97,6 +132,42 @@ func (k *privateKeyRepresentation) IsPasswordProtected() bool {
 	return k.passwordProtected
 }
 
+// Unlock implements the UnlockablePrivateKeyEntry interface
+func (k *privateKeyRepresentation) Unlock(passphrase []byte) (api.UnlockedPrivateKey, error) {
+	unlocked, e := unlockPrivateKeyFile(k.path, passphrase)
+	if e != nil {
+		return nil, e
+	}
+	return unlocked, nil
+}
+
+// SupportedCiphers implements the PassphraseChangeablePrivateKeyEntry interface
+func (k *privateKeyRepresentation) SupportedCiphers() []string {
+	return supportedPrivateKeyCipherNames()
+}
+
+// ChangePassphrase implements the PassphraseChangeablePrivateKeyEntry interface
+func (k *privateKeyRepresentation) ChangePassphrase(oldPassphrase, newPassphrase []byte, protection api.PrivateKeyProtection) (string, error) {
+	backup, e := changePrivateKeyFilePassphrase(k.path, oldPassphrase, newPassphrase, protection)
+	if e == nil {
+		k.passwordProtected = len(newPassphrase) > 0
+	}
+	return backup, e
+}
+
+// SetUserID implements the UserIDEditableKeyEntry interface
+func (k *privateKeyRepresentation) SetUserID(userID string, passphrase []byte) error {
+	if e := validateUserID(userID); e != nil {
+		return e
+	}
+
+	r, e := k.userIDReplacement(userID, passphrase)
+	if e != nil {
+		return e
+	}
+	return replaceFilesTogether(r)
+}
+
 func (k *privateKeyRepresentation) Size() int {
 	return k.size
 }
@@ -105,6 +176,21 @@ func (k *privateKeyRepresentation) Algorithm() api.Algorithm {
 	return k.algorithm
 }
 
+// RSAPublicExponent implements the RSAKeyEntry interface, using the public key stored in the private key file
+func (k *privateKeyRepresentation) RSAPublicExponent() *big.Int {
+	return rsaPublicExponentFrom(k.publicKey)
+}
+
+// ID implements the KeyEntry interface
+func (k *publicKeyRepresentation) ID() string {
+	return keyIDFrom(k.key)
+}
+
+// Same implements the KeyEntry interface
+func (k *publicKeyRepresentation) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
 // Locations implement the KeyEntry interface
 func (k *publicKeyRepresentation) Locations() []string {
 	return nilOrStringSlice(k.path)
@@ -127,10 +213,26 @@ func (k *publicKeyRepresentation) WithDigestContent(f func([]byte) []byte) []byt
 	return f(k.key)
 }
 
+// SSHPublicKey implements the api.SSHPublicKeyEntry interface
+func (k *publicKeyRepresentation) SSHPublicKey() []byte {
+	return k.key
+}
+
+// PublicKey implements the PublicKeyMaterialEntry interface
+func (k *publicKeyRepresentation) PublicKey() crypto.PublicKey {
+	pub, _ := parsePublicKeyMaterial(k.key)
+	return pub
+}
+
 func (k *publicKeyRepresentation) Size() int {
 	return k.size
 }
 
+// RSAPublicExponent implements the RSAKeyEntry interface
+func (k *publicKeyRepresentation) RSAPublicExponent() *big.Int {
+	return rsaPublicExponentFrom(k.key)
+}
+
 func (k *publicKeyRepresentation) Algorithm() api.Algorithm {
 	return k.algorithm
 }
@@ -139,6 +241,35 @@ func (k *publicKeyRepresentation) UserID() string {
 	return k.userID
 }
 
+// SetUserID implements the UserIDEditableKeyEntry interface. Since the public key file
+// is not protected, the passphrase will be ignored
+func (k *publicKeyRepresentation) SetUserID(userID string, _ []byte) error {
+	if e := validateUserID(userID); e != nil {
+		return e
+	}
+
+	r, e := k.userIDReplacement(userID)
+	if e != nil {
+		return e
+	}
+
+	if e := replaceFilesTogether(r); e != nil {
+		return e
+	}
+	k.userID = userID
+	return nil
+}
+
+// ID implements the KeyEntry interface
+func (k *keypairRepresentation) ID() string {
+	return k.public.ID()
+}
+
+// Same implements the KeyEntry interface
+func (k *keypairRepresentation) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
 // Locations implement the KeyEntry interface
 func (k *keypairRepresentation) Locations() []string {
 	return append(k.private.Locations(), k.public.Locations()...)
@@ -161,6 +292,54 @@ func (k *keypairRepresentation) WithDigestContent(f func([]byte) []byte) []byte
 	return k.public.WithDigestContent(f)
 }
 
+func (k *keypairRepresentation) SSHPublicKey() []byte {
+	return k.public.SSHPublicKey()
+}
+
+func (k *keypairRepresentation) PublicKey() crypto.PublicKey {
+	return k.public.PublicKey()
+}
+
+func (k *keypairRepresentation) Unlock(passphrase []byte) (api.UnlockedPrivateKey, error) {
+	return k.private.Unlock(passphrase)
+}
+
+func (k *keypairRepresentation) SupportedCiphers() []string {
+	return k.private.SupportedCiphers()
+}
+
+func (k *keypairRepresentation) ChangePassphrase(oldPassphrase, newPassphrase []byte, protection api.PrivateKeyProtection) (string, error) {
+	return k.private.ChangePassphrase(oldPassphrase, newPassphrase, protection)
+}
+
+// SetUserID will update the comment of both the public and the private key files, or none of them
+func (k *keypairRepresentation) SetUserID(userID string, passphrase []byte) error {
+	if e := validateUserID(userID); e != nil {
+		return e
+	}
+
+	publicReplacement, e := k.public.userIDReplacement(userID)
+	if e != nil {
+		return e
+	}
+	replacements := []fileReplacement{publicReplacement}
+
+	// Private keys in the older PEM formats don't contain a comment, so only the public key has to change
+	privateReplacement, e := k.private.userIDReplacement(userID, passphrase)
+	if e != nil && e != errNotAnOpensshPrivateKey {
+		return e
+	}
+	if e == nil {
+		replacements = append(replacements, privateReplacement)
+	}
+
+	if e := replaceFilesTogether(replacements...); e != nil {
+		return e
+	}
+	k.public.userID = userID
+	return nil
+}
+
 func (k *keypairRepresentation) IsPasswordProtected() bool {
 	return k.private.passwordProtected
 }
@@ -169,6 +348,11 @@ func (k *keypairRepresentation) Size() int {
 	return k.public.size
 }
 
+// RSAPublicExponent implements the RSAKeyEntry interface
+func (k *keypairRepresentation) RSAPublicExponent() *big.Int {
+	return k.public.RSAPublicExponent()
+}
+
 func (k *keypairRepresentation) Algorithm() api.Algorithm {
 	return k.public.Algorithm()
 }
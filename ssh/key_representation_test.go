@@ -326,3 +326,44 @@ func (s *sshSuite) Test_keypairRepresentation_WithDigestContent_returnsTheFinger
 		keyPair.WithDigestContent(identity[[]byte]),
 		keyPair.public.WithDigestContent(identity[[]byte]))
 }
+
+func (s *sshSuite) Test_publicKeyRepresentation_ID_isTheSameForTheSamePublicKeyInAnyFormat() {
+	pub := createPublicKeyRepresentationForTest("id_ed25519.pub", "AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb")
+	parsed, _ := parsePublicKeyMaterial(pub.key)
+	expected, _ := api.PublicKeyID(parsed)
+
+	s.Equal(expected, pub.ID())
+	s.Equal(expected, createPublicKeyRepresentationForTest("elsewhere.pub", "AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb").ID())
+	s.NotEqual(expected, createPublicKeyRepresentationForTest("id_rsa.pub", originalKey).ID())
+}
+
+func (s *sshSuite) Test_privateKeyRepresentation_ID_usesThePublicKeyWhenItIsKnown() {
+	pub := createPublicKeyRepresentationForTest("id_ed25519.pub", "AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb")
+	priv := &privateKeyRepresentation{path: "/home/amnesia/.ssh/id_ed25519", publicKey: pub.key}
+
+	s.Equal(pub.ID(), priv.ID())
+	s.Equal(pub.ID(), createKeypairRepresentation(priv, pub).ID())
+
+	priv = &privateKeyRepresentation{path: "/home/amnesia/.ssh/id_ed25519"}
+	s.Equal(api.LocationID("/home/amnesia/.ssh/id_ed25519"), priv.ID())
+}
+
+func (s *sshSuite) Test_Same_isTrueForTheEntriesOfTheSameKey() {
+	pub := createPublicKeyRepresentationForTest("id_ed25519.pub", "AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb")
+	priv := &privateKeyRepresentation{path: "/home/amnesia/.ssh/id_ed25519", publicKey: pub.key}
+
+	s.True(pub.Same(createPublicKeyRepresentationForTest("elsewhere.pub", "AAAAC3NzaC1lZDI1NTE5AAAAIA6NuKf4xYX0Ddrcx1bSSao2xBCS/9JMv005Me6mFqfb")))
+	s.True(priv.Same(pub))
+	s.True(createKeypairRepresentation(priv, pub).Same(priv))
+	s.False(pub.Same(createPublicKeyRepresentationForTest("id_rsa.pub", originalKey)))
+	s.False(pub.Same(&privateKeyRepresentation{path: "/home/amnesia/.ssh/id_ed25519"}))
+	s.False(pub.Same(nil))
+}
+
+func (s *sshSuite) Test_translateSshAlgorithmToExternalAlgorithm_usesTheRegisteredWireNames() {
+	s.Equal(api.RSA, translateSshAlgorithmToExternalAlgorithm("ssh-rsa"))
+	s.Equal(api.DSA, translateSshAlgorithmToExternalAlgorithm("ssh-dss"))
+	s.Equal(api.ECDSA, translateSshAlgorithmToExternalAlgorithm("ecdsa-sha2-nistp384"))
+	s.Contains(api.Ed25519.WireNames(), "ssh-ed25519")
+	s.Nil(translateSshAlgorithmToExternalAlgorithm("ssh-unknown"))
+}
@@ -0,0 +1,303 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+const opensshPrivateKeyPEMType = "OPENSSH PRIVATE KEY"
+const bcryptKDF = "bcrypt"
+
+var errNotAnOpensshPrivateKey = errors.New("not an OpenSSH private key")
+var errUnsupportedCipher = errors.New("the cipher used to protect the private key is not supported")
+var errUnsupportedKDF = errors.New("the key derivation function used to protect the private key is not supported")
+var errMalformedPrivateKey = errors.New("the private key is malformed")
+var errUnsupportedKeyAlgorithm = errors.New("the key algorithm is not supported")
+
+// opensshPrivateKeyFile contains all the fields of the openssh-key-v1 format
+// as described in https://cvsweb.openbsd.org/src/usr.bin/ssh/PROTOCOL.key
+// The private section will be encrypted unless the cipher is "none"
+type opensshPrivateKeyFile struct {
+	cipherName     string
+	kdfName        string
+	kdfOptions     []byte
+	publicKey      []byte
+	privateSection []byte
+	tag            []byte
+}
+
+func decodeOpensshPrivateKeyFile(content []byte) (*opensshPrivateKeyFile, error) {
+	b, _ := pem.Decode(content)
+	if b == nil || b.Type != opensshPrivateKeyPEMType {
+		return nil, errNotAnOpensshPrivateKey
+	}
+
+	rest, ok := readOpensshPrivateKeyAuthMagic(b.Bytes)
+	if !ok {
+		return nil, errNotAnOpensshPrivateKey
+	}
+
+	cipherName, rest, ok1 := readLengthBytes(rest)
+	kdfName, rest, ok2 := readLengthBytes(rest)
+	kdfOptions, rest, ok3 := readLengthBytes(rest)
+	numberOfKeys, rest, ok4 := read32BitNumber(rest)
+	ok5 := numberOfKeys == 1
+	publicKey, rest, ok6 := readLengthBytes(rest)
+	privateSection, rest, ok7 := readLengthBytes(rest)
+	if !allOK(ok1, ok2, ok3, ok4, ok5, ok6, ok7) {
+		return nil, errMalformedPrivateKey
+	}
+
+	return &opensshPrivateKeyFile{
+		cipherName:     string(cipherName),
+		kdfName:        string(kdfName),
+		kdfOptions:     kdfOptions,
+		publicKey:      publicKey,
+		privateSection: privateSection,
+		tag:            rest,
+	}, nil
+}
+
+func (f *opensshPrivateKeyFile) isEncrypted() bool {
+	return f.cipherName != noCipher
+}
+
+func readBcryptKDFOptions(options []byte) (salt []byte, rounds uint32, ok bool) {
+	salt, rest, ok1 := readLengthBytes(options)
+	rounds, _, ok2 := read32BitNumber(rest)
+	return salt, rounds, allOK(ok1, ok2)
+}
+
+func (f *opensshPrivateKeyFile) decryptedPrivateSection(passphrase []byte) ([]byte, error) {
+	if !f.isEncrypted() {
+		return f.privateSection, nil
+	}
+
+	c, ok := privateKeyCiphers[f.cipherName]
+	if !ok {
+		return nil, errUnsupportedCipher
+	}
+
+	if f.kdfName != bcryptKDF {
+		return nil, errUnsupportedKDF
+	}
+
+	salt, rounds, ok := readBcryptKDFOptions(f.kdfOptions)
+	if !ok || len(f.tag) < c.tagLen || len(f.privateSection)%c.blockSize != 0 {
+		return nil, errMalformedPrivateKey
+	}
+
+	if len(passphrase) == 0 {
+		return nil, api.ErrIncorrectPassphrase
+	}
+
+	keyAndIV, e := bcryptPBKDF(passphrase, salt, int(rounds), c.keyLen+c.ivLen)
+	if e != nil {
+		return nil, e
+	}
+
+	decrypted, ok := c.decrypt(keyAndIV[:c.keyLen], keyAndIV[c.keyLen:], f.privateSection, f.tag[:c.tagLen])
+	if !ok {
+		return nil, api.ErrIncorrectPassphrase
+	}
+	return decrypted, nil
+}
+
+// unlockedPrivateKey implements the api.UnlockedPrivateKey interface
+type unlockedPrivateKey struct {
+	algorithm string
+	key       crypto.PrivateKey
+	comment   string
+}
+
+func (k *unlockedPrivateKey) PrivateKey() crypto.PrivateKey {
+	return k.key
+}
+
+func (k *unlockedPrivateKey) UserID() string {
+	return k.comment
+}
+
+func (f *opensshPrivateKeyFile) unlock(passphrase []byte) (*unlockedPrivateKey, error) {
+	k, _, e := f.unlockWithPayload(passphrase)
+	return k, e
+}
+
+// unlockWithPayload returns the unlocked private key together with the decrypted private key
+// and comment exactly as they were stored in the file - without the check integers and padding
+func (f *opensshPrivateKeyFile) unlockWithPayload(passphrase []byte) (*unlockedPrivateKey, []byte, error) {
+	section, e := f.decryptedPrivateSection(passphrase)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	check1, rest, ok1 := read32BitNumber(section)
+	check2, rest, ok2 := read32BitNumber(rest)
+	if !allOK(ok1, ok2) {
+		return nil, nil, errMalformedPrivateKey
+	}
+
+	if check1 != check2 {
+		if f.isEncrypted() {
+			return nil, nil, api.ErrIncorrectPassphrase
+		}
+		return nil, nil, errMalformedPrivateKey
+	}
+
+	k, padding, e := parseUnlockedPrivateKey(rest)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	return k, rest[:len(rest)-len(padding)], nil
+}
+
+func unlockPrivateKeyFile(fileName string, passphrase []byte) (*unlockedPrivateKey, error) {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		return nil, e
+	}
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	if e != nil {
+		return nil, e
+	}
+
+	return f.unlock(passphrase)
+}
+
+func readMPInt(input []byte) (value *big.Int, rest []byte, ok bool) {
+	b, rest, ok := readLengthBytes(input)
+	if !ok || (len(b) > 0 && b[0]&0x80 != 0) {
+		return nil, nil, false
+	}
+	return new(big.Int).SetBytes(b), rest, true
+}
+
+func readMPInts(input []byte, n int) (values []*big.Int, rest []byte, ok bool) {
+	rest = input
+	for i := 0; i < n; i++ {
+		var v *big.Int
+		v, rest, ok = readMPInt(rest)
+		if !ok {
+			return nil, nil, false
+		}
+		values = append(values, v)
+	}
+	return values, rest, true
+}
+
+var ecdsaCurves = map[string]elliptic.Curve{
+	"nistp256": elliptic.P256(),
+	"nistp384": elliptic.P384(),
+	"nistp521": elliptic.P521(),
+}
+
+const ecdsaAlgorithmPrefix = "ecdsa-sha2-"
+const dsaAlgorithm = "ssh-dss"
+
+func parseUnlockedPrivateKey(input []byte) (*unlockedPrivateKey, []byte, error) {
+	algorithm, rest, ok := readLengthBytes(input)
+	if !ok {
+		return nil, nil, errMalformedPrivateKey
+	}
+
+	var key crypto.PrivateKey
+	switch string(algorithm) {
+	case ed25519Algorithm:
+		key, rest, ok = readEd25519PrivateKey(rest)
+	case rsaAlgorithm:
+		key, rest, ok = readRSAPrivateKey(rest)
+	case dsaAlgorithm:
+		key, rest, ok = readDSAPrivateKey(rest)
+	case ecdsaAlgorithmPrefix + "nistp256", ecdsaAlgorithmPrefix + "nistp384", ecdsaAlgorithmPrefix + "nistp521":
+		key, rest, ok = readECDSAPrivateKey(rest)
+	default:
+		return nil, nil, errUnsupportedKeyAlgorithm
+	}
+
+	comment, rest, ok2 := readLengthBytes(rest)
+	if !allOK(ok, ok2) {
+		return nil, nil, errMalformedPrivateKey
+	}
+
+	return &unlockedPrivateKey{
+		algorithm: string(algorithm),
+		key:       key,
+		comment:   string(comment),
+	}, rest, nil
+}
+
+func readEd25519PrivateKey(input []byte) (crypto.PrivateKey, []byte, bool) {
+	_, rest, ok1 := readLengthBytes(input)
+	priv, rest, ok2 := readLengthBytes(rest)
+	if !allOK(ok1, ok2) || len(priv) != ed25519.PrivateKeySize {
+		return nil, nil, false
+	}
+	return ed25519.PrivateKey(priv), rest, true
+}
+
+func readRSAPrivateKey(input []byte) (crypto.PrivateKey, []byte, bool) {
+	v, rest, ok := readMPInts(input, 6)
+	if !ok {
+		return nil, nil, false
+	}
+
+	n, e, d, p, q := v[0], v[1], v[2], v[4], v[5]
+	if !e.IsInt64() {
+		return nil, nil, false
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	key.Precompute()
+	return key, rest, true
+}
+
+func readDSAPrivateKey(input []byte) (crypto.PrivateKey, []byte, bool) {
+	v, rest, ok := readMPInts(input, 5)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return &dsa.PrivateKey{
+		PublicKey: dsa.PublicKey{
+			Parameters: dsa.Parameters{P: v[0], Q: v[1], G: v[2]},
+			Y:          v[3],
+		},
+		X: v[4],
+	}, rest, true
+}
+
+func readECDSAPrivateKey(input []byte) (crypto.PrivateKey, []byte, bool) {
+	curveName, rest, ok1 := readLengthBytes(input)
+	point, rest, ok2 := readLengthBytes(rest)
+	d, rest, ok3 := readMPInt(rest)
+	curve, ok4 := ecdsaCurves[string(curveName)]
+	if !allOK(ok1, ok2, ok3, ok4) {
+		return nil, nil, false
+	}
+
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, nil, false
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         d,
+	}, rest, true
+}
@@ -0,0 +1,156 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"os"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+const noKDF = "none"
+const bcryptSaltLength = 16
+
+// unencryptedBlockSize is the block size OpenSSH uses to pad the private section when no cipher is used
+const unencryptedBlockSize = 8
+
+func uint32Bytes(v uint32) []byte {
+	result := make([]byte, 4)
+	binary.BigEndian.PutUint32(result, v)
+	return result
+}
+
+func lengthBytes(v []byte) []byte {
+	return concat(uint32Bytes(uint32(len(v))), v)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	result := make([]byte, n)
+	_, e := io.ReadFull(rand.Reader, result)
+	return result, e
+}
+
+// withPadding adds the deterministic padding 1, 2, 3, ... that OpenSSH uses for the private section
+func withPadding(section []byte, blockSize int) []byte {
+	for i := byte(1); len(section)%blockSize != 0; i++ {
+		section = append(section, i)
+	}
+	return section
+}
+
+// newOpensshPrivateKeyFile creates a new private key file from the public key and the private key payload,
+// which consists of the private key and comment. If the passphrase is empty, the private key
+// will not be encrypted
+func newOpensshPrivateKeyFile(publicKey, payload, passphrase []byte, protection api.PrivateKeyProtection) (*opensshPrivateKeyFile, error) {
+	checkInt, e := randomBytes(4)
+	if e != nil {
+		return nil, e
+	}
+	section := concat(checkInt, checkInt, payload)
+
+	if len(passphrase) == 0 {
+		return &opensshPrivateKeyFile{
+			cipherName:     noCipher,
+			kdfName:        noKDF,
+			kdfOptions:     []byte{},
+			publicKey:      publicKey,
+			privateSection: withPadding(section, unencryptedBlockSize),
+		}, nil
+	}
+
+	c, ok := privateKeyCiphers[protection.Cipher]
+	if !ok {
+		return nil, errUnsupportedCipher
+	}
+	section = withPadding(section, c.blockSize)
+
+	salt, e := randomBytes(bcryptSaltLength)
+	if e != nil {
+		return nil, e
+	}
+
+	keyAndIV, e := bcryptPBKDF(passphrase, salt, protection.Rounds, c.keyLen+c.ivLen)
+	if e != nil {
+		return nil, e
+	}
+
+	encrypted := c.encrypt(keyAndIV[:c.keyLen], keyAndIV[c.keyLen:], section)
+
+	return &opensshPrivateKeyFile{
+		cipherName:     c.name,
+		kdfName:        bcryptKDF,
+		kdfOptions:     concat(lengthBytes(salt), uint32Bytes(uint32(protection.Rounds))),
+		publicKey:      publicKey,
+		privateSection: encrypted[:len(section)],
+		tag:            encrypted[len(section):],
+	}, nil
+}
+
+func (f *opensshPrivateKeyFile) encode() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type: opensshPrivateKeyPEMType,
+		Bytes: concat(
+			privateKeyAuthMagicWithTerminator,
+			lengthBytes([]byte(f.cipherName)),
+			lengthBytes([]byte(f.kdfName)),
+			lengthBytes(f.kdfOptions),
+			uint32Bytes(1),
+			lengthBytes(f.publicKey),
+			lengthBytes(f.privateSection),
+			f.tag,
+		),
+	})
+}
+
+// changePrivateKeyFilePassphrase re-encrypts the private key file in place, after keeping
+// a backup of the original file. It returns the name of the backup file. When a passphrase is added to
+// an unprotected key, the backup would leave the key unprotected, so it's removed as soon as the new
+// file is known to be unlockable, and an empty name is returned
+func changePrivateKeyFilePassphrase(fileName string, oldPassphrase, newPassphrase []byte, protection api.PrivateKeyProtection) (string, error) {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		return "", e
+	}
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	if e != nil {
+		return "", e
+	}
+
+	_, payload, e := f.unlockWithPayload(oldPassphrase)
+	if e != nil {
+		return "", e
+	}
+
+	nf, e := newOpensshPrivateKeyFile(f.publicKey, payload, newPassphrase, protection)
+	if e != nil {
+		return "", e
+	}
+
+	backup, e := replaceFileKeepingBackup(fileName, nf.encode())
+	if e != nil || f.isEncrypted() || len(newPassphrase) == 0 {
+		return backup, e
+	}
+
+	if e := verifyPrivateKeyFilePassphrase(fileName, newPassphrase); e != nil {
+		return backup, e
+	}
+	return "", os.Remove(backup)
+}
+
+func verifyPrivateKeyFilePassphrase(fileName string, passphrase []byte) error {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		return e
+	}
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	if e != nil {
+		return e
+	}
+
+	_, e = f.unlock(passphrase)
+	return e
+}
@@ -0,0 +1,133 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+func (s *sshSuite) Test_withPadding_addsIncreasingBytesUntilTheBlockSize() {
+	s.Equal([]byte{9, 9, 9, 1, 2, 3, 4, 5}, withPadding([]byte{9, 9, 9}, 8))
+	s.Equal([]byte{9, 9, 9, 9, 9, 9, 9, 9}, withPadding([]byte{9, 9, 9, 9, 9, 9, 9, 9}, 8))
+}
+
+func (s *sshSuite) Test_newOpensshPrivateKeyFile_canBeDecryptedWithTheNewPassphraseForAllCiphers() {
+	original, _ := decodeOpensshPrivateKeyFile([]byte(ed25519UnprotectedPrivateKey))
+	_, payload, e := original.unlockWithPayload(nil)
+	s.Require().NoError(e)
+
+	for _, c := range supportedPrivateKeyCipherNames() {
+		f, e := newOpensshPrivateKeyFile(original.publicKey, payload, []byte("new pass"), api.PrivateKeyProtection{Cipher: c, Rounds: 1})
+		s.Require().NoError(e)
+
+		decoded, e := decodeOpensshPrivateKeyFile(f.encode())
+		s.Require().NoError(e)
+		s.Equal(c, decoded.cipherName)
+		s.Equal(bcryptKDF, decoded.kdfName)
+
+		_, e = decoded.unlock([]byte("old pass"))
+		s.Equal(api.ErrIncorrectPassphrase, e)
+
+		k, e := decoded.unlock([]byte("new pass"))
+		s.Require().NoError(e)
+		s.Equal("batman@gotham", k.UserID())
+	}
+}
+
+func (s *sshSuite) Test_newOpensshPrivateKeyFile_doesNotEncryptWithAnEmptyPassphrase() {
+	original, _ := decodeOpensshPrivateKeyFile([]byte(ed25519ProtectedPrivateKey))
+	_, payload, e := original.unlockWithPayload([]byte("correct horse"))
+	s.Require().NoError(e)
+
+	f, e := newOpensshPrivateKeyFile(original.publicKey, payload, nil, api.PrivateKeyProtection{Cipher: "aes256-ctr", Rounds: 16})
+	s.Require().NoError(e)
+	s.False(f.isEncrypted())
+
+	k, e := f.unlock(nil)
+	s.NoError(e)
+	s.Equal("robin@gotham", k.UserID())
+}
+
+func (s *sshSuite) Test_newOpensshPrivateKeyFile_failsForUnknownCiphersAndRounds() {
+	_, e := newOpensshPrivateKeyFile(nil, nil, []byte("pass"), api.PrivateKeyProtection{Cipher: "rot13", Rounds: 16})
+	s.Equal(errUnsupportedCipher, e)
+
+	_, e = newOpensshPrivateKeyFile(nil, nil, []byte("pass"), api.PrivateKeyProtection{Cipher: "aes256-ctr", Rounds: 0})
+	s.Equal(errInvalidBcryptPBKDFParameters, e)
+}
+
+func (s *sshSuite) Test_supportedPrivateKeyCipherNames_startsWithTheDefault() {
+	names := supportedPrivateKeyCipherNames()
+	s.Equal("aes256-ctr", names[0])
+	s.Len(names, len(privateKeyCiphers))
+}
+
+func (s *sshSuite) Test_privateKeyRepresentation_ChangePassphrase_replacesTheFileAndKeepsABackup() {
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+	s.Require().NoError(os.WriteFile(fileName, []byte(ed25519ProtectedPrivateKey), 0600))
+	k := createPrivateKeyRepresentationForTest(fileName)
+	k.passwordProtected = true
+
+	_, e := k.ChangePassphrase([]byte("wrong"), []byte("new"), api.PrivateKeyProtection{Cipher: "aes256-ctr", Rounds: 2})
+	s.Equal(api.ErrIncorrectPassphrase, e)
+	s.False(fileExists(fileName + ".bak"))
+
+	backup, e := k.ChangePassphrase([]byte("correct horse"), []byte("new"), api.PrivateKeyProtection{Cipher: "aes256-gcm@openssh.com", Rounds: 2})
+	s.Require().NoError(e)
+	s.Equal(fileName+".bak", backup)
+	s.True(k.IsPasswordProtected())
+
+	backupContent, _ := os.ReadFile(backup)
+	s.Equal(ed25519ProtectedPrivateKey, string(backupContent))
+
+	info, _ := os.Stat(fileName)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	unlocked, e := k.Unlock([]byte("new"))
+	s.Require().NoError(e)
+	s.Equal("robin@gotham", unlocked.UserID())
+
+	backup, e = k.ChangePassphrase([]byte("new"), nil, api.PrivateKeyProtection{})
+	s.Require().NoError(e)
+	s.Equal(fileName+".bak.1", backup)
+	s.False(k.IsPasswordProtected())
+
+	_, e = k.Unlock(nil)
+	s.NoError(e)
+}
+
+func (s *sshSuite) Test_privateKeyRepresentation_ChangePassphrase_removesTheBackupWhenAddingAPassphrase() {
+	fileName := filepath.Join(s.tdir, "id_ed25519")
+	s.Require().NoError(os.WriteFile(fileName, []byte(ed25519UnprotectedPrivateKey), 0600))
+	k := createPrivateKeyRepresentationForTest(fileName)
+
+	backup, e := k.ChangePassphrase(nil, []byte("new"), api.PrivateKeyProtection{Cipher: "aes256-ctr", Rounds: 2})
+	s.Require().NoError(e)
+	s.Empty(backup, "an unprotected backup would leave the key unprotected")
+	s.Equal([]string{"id_ed25519"}, listFilesIn(s.tdir))
+
+	unlocked, e := k.Unlock([]byte("new"))
+	s.Require().NoError(e)
+	s.Equal("batman@gotham", unlocked.UserID())
+}
+
+func (s *sshSuite) Test_isBackupFileName_recognizesTheBackupsOfReplacedFiles() {
+	s.True(isBackupFileName("id_ed25519.bak"))
+	s.True(isBackupFileName("id_ed25519.bak.12"))
+	s.False(isBackupFileName("id_ed25519"))
+	s.False(isBackupFileName("id_ed25519.bak.pub"))
+	s.False(isBackupFileName("backup_key"))
+}
+
+func (s *sshSuite) Test_isBackupFileName_doesNotMatchNamesThatOnlyLookLikeBackups() {
+	s.False(isBackupFileName("id_rsa.bak1"))
+	s.False(isBackupFileName("foo.bak7"))
+	s.False(isBackupFileName("id_rsa.bak."))
+	s.False(isBackupFileName("id_rsa.bak.-1"))
+	s.False(isBackupFileName("id_rsa.bak.1a"))
+	s.False(isBackupFileName("id_rsa.bakery"))
+	s.False(isBackupFileName(".bak"))
+	s.False(isBackupFileName("/home/amnesia/.ssh/.bak"))
+	s.True(isBackupFileName("/home/amnesia/.ssh/id_rsa.bak.3"))
+}
@@ -6,7 +6,7 @@ import (
 )
 
 type keyEntryPartitioner struct {
-	result     []api.KeyEntry
+	found      func(api.KeyEntry)
 	publicKeys map[string]*publicKeyRepresentation
 }
 
@@ -21,7 +21,7 @@ func (p *keyEntryPartitioner) publicKeyNameFor(priv *privateKeyRepresentation) s
 }
 
 func (p *keyEntryPartitioner) addResult(r api.KeyEntry) {
-	p.result = append(p.result, r)
+	p.found(r)
 }
 
 func (p *keyEntryPartitioner) addPublicKeyResult(r *publicKeyRepresentation) {
@@ -57,11 +57,3 @@ func (p *keyEntryPartitioner) processPrivateKeys(privates []*privateKeyRepresent
 func (p *keyEntryPartitioner) appendRemainingPublicKeys() {
 	foreachValue(p.publicKeys, p.addPublicKeyResult)
 }
-
-func partitionKeyEntries(privates []*privateKeyRepresentation, publics []*publicKeyRepresentation) []api.KeyEntry {
-	p := &keyEntryPartitioner{}
-	p.initializePublicKeyCache(publics)
-	p.processPrivateKeys(privates)
-	p.appendRemainingPublicKeys()
-	return p.result
-}
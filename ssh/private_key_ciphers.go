@@ -0,0 +1,158 @@
+package ssh
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"sort"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/poly1305"
+)
+
+// privateKeyCipher describes one of the ciphers OpenSSH can use to protect the private
+// section of a private key file. The decrypt function will return false if the
+// content could not be authenticated, which normally means the passphrase was wrong
+type privateKeyCipher struct {
+	name      string
+	keyLen    int
+	ivLen     int
+	blockSize int
+	tagLen    int
+	encrypt   func(key, iv, plain []byte) []byte
+	decrypt   func(key, iv, encrypted, tag []byte) ([]byte, bool)
+}
+
+const noCipher = "none"
+const defaultPrivateKeyCipher = "aes256-ctr"
+
+var privateKeyCiphers = map[string]*privateKeyCipher{
+	"aes128-ctr":                    aesCTRCipher("aes128-ctr", 16),
+	"aes192-ctr":                    aesCTRCipher("aes192-ctr", 24),
+	"aes256-ctr":                    aesCTRCipher("aes256-ctr", 32),
+	"aes128-cbc":                    aesCBCCipher("aes128-cbc", 16),
+	"aes192-cbc":                    aesCBCCipher("aes192-cbc", 24),
+	"aes256-cbc":                    aesCBCCipher("aes256-cbc", 32),
+	"aes128-gcm@openssh.com":        aesGCMCipher("aes128-gcm@openssh.com", 16),
+	"aes256-gcm@openssh.com":        aesGCMCipher("aes256-gcm@openssh.com", 32),
+	"chacha20-poly1305@openssh.com": chacha20Poly1305Cipher(),
+}
+
+// supportedPrivateKeyCipherNames returns the names of all supported ciphers, starting with the default one
+func supportedPrivateKeyCipherNames() []string {
+	result := []string{}
+	for name := range privateKeyCiphers {
+		if name != defaultPrivateKeyCipher {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return append([]string{defaultPrivateKeyCipher}, result...)
+}
+
+func aesCTRCipher(name string, keyLen int) *privateKeyCipher {
+	crypt := func(key, iv, in []byte) []byte {
+		b, _ := aes.NewCipher(key)
+		out := make([]byte, len(in))
+		cipher.NewCTR(b, iv).XORKeyStream(out, in)
+		return out
+	}
+
+	return &privateKeyCipher{
+		name:      name,
+		keyLen:    keyLen,
+		ivLen:     aes.BlockSize,
+		blockSize: aes.BlockSize,
+		encrypt:   crypt,
+		decrypt: func(key, iv, encrypted, _ []byte) ([]byte, bool) {
+			return crypt(key, iv, encrypted), true
+		},
+	}
+}
+
+func aesCBCCipher(name string, keyLen int) *privateKeyCipher {
+	return &privateKeyCipher{
+		name:      name,
+		keyLen:    keyLen,
+		ivLen:     aes.BlockSize,
+		blockSize: aes.BlockSize,
+		encrypt: func(key, iv, plain []byte) []byte {
+			b, _ := aes.NewCipher(key)
+			out := make([]byte, len(plain))
+			cipher.NewCBCEncrypter(b, iv).CryptBlocks(out, plain)
+			return out
+		},
+		decrypt: func(key, iv, encrypted, _ []byte) ([]byte, bool) {
+			if len(encrypted)%aes.BlockSize != 0 {
+				return nil, false
+			}
+			b, _ := aes.NewCipher(key)
+			out := make([]byte, len(encrypted))
+			cipher.NewCBCDecrypter(b, iv).CryptBlocks(out, encrypted)
+			return out, true
+		},
+	}
+}
+
+func aesGCMCipher(name string, keyLen int) *privateKeyCipher {
+	gcm := func(key []byte) cipher.AEAD {
+		b, _ := aes.NewCipher(key)
+		g, _ := cipher.NewGCM(b)
+		return g
+	}
+
+	return &privateKeyCipher{
+		name:      name,
+		keyLen:    keyLen,
+		ivLen:     12,
+		blockSize: aes.BlockSize,
+		tagLen:    16,
+		encrypt: func(key, iv, plain []byte) []byte {
+			return gcm(key).Seal(nil, iv, plain, nil)
+		},
+		decrypt: func(key, iv, encrypted, tag []byte) ([]byte, bool) {
+			out, e := gcm(key).Open(nil, iv, append(append([]byte{}, encrypted...), tag...), nil)
+			return out, e == nil
+		},
+	}
+}
+
+// chacha20Poly1305Cipher implements the OpenSSH variant of ChaCha20-Poly1305. Only the first
+// half of the key is used for private key files, since there is no length to protect
+func chacha20Poly1305Cipher() *privateKeyCipher {
+	streamAndPolyKey := func(key []byte) (cipher.Stream, *[32]byte) {
+		s, _ := chacha20.NewUnauthenticatedCipher(key[:32], make([]byte, chacha20.NonceSize))
+		polyKey := [32]byte{}
+		s.XORKeyStream(polyKey[:], polyKey[:])
+		discard := [32]byte{}
+		s.XORKeyStream(discard[:], discard[:])
+		return s, &polyKey
+	}
+
+	return &privateKeyCipher{
+		name:      "chacha20-poly1305@openssh.com",
+		keyLen:    64,
+		ivLen:     0,
+		blockSize: 8,
+		tagLen:    poly1305.TagSize,
+		encrypt: func(key, _, plain []byte) []byte {
+			s, polyKey := streamAndPolyKey(key)
+			out := make([]byte, len(plain), len(plain)+poly1305.TagSize)
+			s.XORKeyStream(out, plain)
+			tag := [poly1305.TagSize]byte{}
+			poly1305.Sum(&tag, out, polyKey)
+			return append(out, tag[:]...)
+		},
+		decrypt: func(key, _, encrypted, tag []byte) ([]byte, bool) {
+			s, polyKey := streamAndPolyKey(key)
+			expectedTag := [poly1305.TagSize]byte{}
+			poly1305.Sum(&expectedTag, encrypted, polyKey)
+			if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+				return nil, false
+			}
+			out := make([]byte, len(encrypted))
+			s.XORKeyStream(out, encrypted)
+			return out, true
+		},
+	}
+}
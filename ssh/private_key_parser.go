@@ -11,6 +11,7 @@ type privateKey struct {
 	algorithm         string
 	passwordProtected bool
 	size              int
+	publicKey         []byte
 }
 
 func (k *privateKey) isAlgorithm(algo string) bool {
@@ -72,8 +73,7 @@ func createPrivateKeyFrom(input []byte) (privateKey, bool) {
 	pubValue, rest, ok6 := readLengthBytes(rest) // reads the public key
 	privValue, _, ok7 := readLengthBytes(rest)   // reads the private key block
 
-	l, _ := extractByteLengthFromRSAPublicKey(pubValue)
-	size := canonicalizeRSAKeyLength(l) * 8
+	size := keySizeFrom(pubValue)
 
 	if hasNoAlgorithm(cipherName) {
 		_, rest, ok8 := extractDummyCheckSum(privValue)
@@ -82,6 +82,7 @@ func createPrivateKeyFrom(input []byte) (privateKey, bool) {
 			algorithm:         algorithm,
 			passwordProtected: false,
 			size:              size,
+			publicKey:         pubValue,
 		}, allOK(ok1, ok2, ok3, ok4, ok5, ok6, ok7, ok8, ok9)
 	}
 
@@ -90,6 +91,7 @@ func createPrivateKeyFrom(input []byte) (privateKey, bool) {
 		algorithm:         algorithm,
 		passwordProtected: true,
 		size:              size,
+		publicKey:         pubValue,
 	}, allOK(ok1, ok2, ok3, ok4, ok5, ok6, ok7, ok8)
 }
 
@@ -0,0 +1,75 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"strings"
+)
+
+// parsePublicKeyMaterial parses the wire format of an SSH public key, as found base64 encoded
+// inside of public key files, into one of the key types from the standard crypto packages
+func parsePublicKeyMaterial(key []byte) (crypto.PublicKey, bool) {
+	algorithm, rest, ok := readLengthBytes(key)
+	if !ok {
+		return nil, false
+	}
+
+	switch algo := string(algorithm); {
+	case algo == ed25519Algorithm:
+		return readEd25519PublicKey(rest)
+	case algo == rsaAlgorithm:
+		return readRSAPublicKey(rest)
+	case algo == dsaAlgorithm:
+		return readDSAPublicKey(rest)
+	case strings.HasPrefix(algo, ecdsaAlgorithmPrefix):
+		return readECDSAPublicKey(rest)
+	}
+
+	return nil, false
+}
+
+func readEd25519PublicKey(input []byte) (crypto.PublicKey, bool) {
+	pub, _, ok := readLengthBytes(input)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return nil, false
+	}
+	return ed25519.PublicKey(pub), true
+}
+
+func readRSAPublicKey(input []byte) (crypto.PublicKey, bool) {
+	v, _, ok := readMPInts(input, 2)
+	if !ok || !v[0].IsInt64() {
+		return nil, false
+	}
+	return &rsa.PublicKey{N: v[1], E: int(v[0].Int64())}, true
+}
+
+func readDSAPublicKey(input []byte) (crypto.PublicKey, bool) {
+	v, _, ok := readMPInts(input, 4)
+	if !ok {
+		return nil, false
+	}
+	return &dsa.PublicKey{
+		Parameters: dsa.Parameters{P: v[0], Q: v[1], G: v[2]},
+		Y:          v[3],
+	}, true
+}
+
+func readECDSAPublicKey(input []byte) (crypto.PublicKey, bool) {
+	curveName, rest, ok1 := readLengthBytes(input)
+	point, _, ok2 := readLengthBytes(rest)
+	curve, ok3 := ecdsaCurves[string(curveName)]
+	if !allOK(ok1, ok2, ok3) {
+		return nil, false
+	}
+
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, false
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, true
+}
@@ -22,52 +22,22 @@ func (p *publicKeyParser) initializeFields(k string) {
 	p.fields = whitespace.Split(strings.TrimSpace(k), 3)
 }
 
-var commonRSAKeySizes = map[int]int{
-	// 1024 bits
-	127: 128,
-	128: 128,
-	129: 128,
-
-	// 2048 bits
-	255: 256,
-	256: 256,
-	257: 256,
-
-	// 3072 bits
-	383: 384,
-	384: 384,
-	385: 384,
-
-	// 4096 bits
-	511: 512,
-	512: 512,
-	513: 512,
-}
-
-func canonicalizeRSAKeyLength(p int) int {
-	res, ok := commonRSAKeySizes[p]
-	if !ok {
-		return p
-	}
-	return res
-}
-
-func extractByteLengthFromRSAPublicKey(key []byte) (int, bool) {
-	algo, rest, ok := readLengthBytes(key)
-	if !ok || string(algo) != rsaAlgorithm {
-		return 0, false
+// keySizeFrom returns the number of bits of the RSA modulus or of the ECDSA curve, and zero for
+// algorithms where all keys have the same size
+func keySizeFrom(key []byte) int {
+	algo, ok := extractKeyAlgorithm(key)
+	switch {
+	case !ok:
+	case algo == rsaAlgorithm:
+		if _, modulus, ok := rsaPublicKeyParameters(key); ok {
+			return modulus.BitLen()
+		}
+	case isECDSAAlgorithm(algo):
+		if size, ok := extractSizeFromECDSAPublicKey(key); ok {
+			return size
+		}
 	}
-
-	_, rest, ok = readLengthBytes(rest)
-	if !ok {
-		return 0, false
-	}
-
-	product, _, ok := readLengthBytes(rest)
-	if !ok {
-		return 0, false
-	}
-	return len(product), true
+	return 0
 }
 
 func (p *publicKeyParser) parse() (publicKey, bool) {
@@ -80,19 +50,11 @@ func (p *publicKeyParser) parse() (publicKey, bool) {
 		return publicKey{}, false
 	}
 
-	size := 0
-
-	if l, ok2 := extractByteLengthFromRSAPublicKey(key); ok2 {
-		size = canonicalizeRSAKeyLength(l) * 8
-	} else {
-		size = 0
-	}
-
 	return publicKey{
 		algorithm: p.algorithm(),
 		key:       key,
 		comment:   p.potentialComment(),
-		size:      size,
+		size:      keySizeFrom(key),
 	}, true
 }
 
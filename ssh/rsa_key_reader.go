@@ -1,7 +1,31 @@
 package ssh
 
+import "math/big"
+
 const rsaAlgorithm = "ssh-rsa"
 
+// rsaPublicKeyParameters reads the public exponent and the modulus of an RSA public key in the SSH wire format
+func rsaPublicKeyParameters(key []byte) (exponent, modulus *big.Int, ok bool) {
+	algo, rest, ok := readLengthBytes(key)
+	if !ok || string(algo) != rsaAlgorithm {
+		return nil, nil, false
+	}
+
+	v, _, ok := readMPInts(rest, 2)
+	if !ok {
+		return nil, nil, false
+	}
+	return v[0], v[1], true
+}
+
+func rsaPublicExponentFrom(key []byte) *big.Int {
+	exponent, _, ok := rsaPublicKeyParameters(key)
+	if !ok {
+		return nil
+	}
+	return exponent
+}
+
 func (k *publicKey) isRSA() bool {
 	return k.isAlgorithm(rsaAlgorithm)
 }
@@ -0,0 +1,60 @@
+package ssh
+
+import (
+	"encoding/base64"
+	"math/big"
+)
+
+func mpintBytes(v *big.Int) []byte {
+	b := v.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return lengthBytes(b)
+}
+
+// rsaPublicKeyForTest creates a public key with a modulus of exactly the given number of bits.
+// The modulus is not the product of two primes, but that doesn't matter for parsing
+func rsaPublicKeyForTest(bits int, exponent int64) []byte {
+	modulus := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	modulus.Add(modulus, big.NewInt(1))
+	key := lengthBytes([]byte(rsaAlgorithm))
+	key = append(key, mpintBytes(big.NewInt(exponent))...)
+	return append(key, mpintBytes(modulus)...)
+}
+
+func (s *sshSuite) Test_keySizeFrom_returnsTheExactBitLengthOfTheRSAModulus() {
+	for _, bits := range []int{1024, 1536, 2047, 2048, 3071, 4096, 8192} {
+		s.Equal(bits, keySizeFrom(rsaPublicKeyForTest(bits, 65537)), bits)
+	}
+}
+
+func (s *sshSuite) Test_keySizeFrom_returnsZeroForKeysWithoutSize() {
+	s.Equal(0, keySizeFrom(nil))
+	s.Equal(0, keySizeFrom(lengthBytes([]byte(rsaAlgorithm))))
+	s.Equal(0, keySizeFrom(append(lengthBytes([]byte(ed25519Algorithm)), lengthBytes(make([]byte, 32))...)))
+}
+
+func (s *sshSuite) Test_rsaPublicKeyParameters_readsTheExponentAndModulus() {
+	exponent, modulus, ok := rsaPublicKeyParameters(rsaPublicKeyForTest(2048, 3))
+	s.True(ok)
+	s.Equal(big.NewInt(3), exponent)
+	s.Equal(2048, modulus.BitLen())
+
+	_, _, ok = rsaPublicKeyParameters(append(lengthBytes([]byte(rsaAlgorithm)), lengthBytes([]byte{0x80})...))
+	s.False(ok, "negative numbers are not valid")
+
+	s.Nil(rsaPublicExponentFrom(lengthBytes([]byte(ed25519Algorithm))))
+	s.Equal(big.NewInt(65537), rsaPublicExponentFrom(rsaPublicKeyForTest(4096, 65537)))
+}
+
+func (s *sshSuite) Test_parsePublicKey_usesTheExactSizeOfRSAKeys() {
+	k := "ssh-rsa " + base64.StdEncoding.EncodeToString(rsaPublicKeyForTest(1536, 65537)) + " batman@debian"
+	pub, ok := parsePublicKey(k)
+	s.True(ok)
+	s.Equal(1536, pub.size)
+
+	r := createPublicKeyRepresentationFromPublicKey(&pub)
+	s.Equal(1536, r.Size())
+	s.Equal(big.NewInt(65537), r.RSAPublicExponent())
+}
@@ -0,0 +1,128 @@
+package ssh
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+)
+
+var errInvalidUserID = errors.New("the user ID can't contain line breaks")
+var errMalformedPublicKey = errors.New("the public key is malformed")
+
+func validateUserID(userID string) error {
+	if strings.ContainsAny(userID, "\r\n") {
+		return errInvalidUserID
+	}
+	return nil
+}
+
+// publicKeyFileContent generates the content of a public key file in the same format ssh-keygen uses
+func publicKeyFileContent(key []byte, comment string) ([]byte, error) {
+	algorithm, _, ok := readLengthBytes(key)
+	if !ok {
+		return nil, errMalformedPublicKey
+	}
+
+	fields := []string{string(algorithm), base64.StdEncoding.EncodeToString(key)}
+	if comment != "" {
+		fields = append(fields, comment)
+	}
+	return []byte(strings.Join(fields, " ") + "\n"), nil
+}
+
+// protection returns the cipher and key derivation rounds currently used by the file
+func (f *opensshPrivateKeyFile) protection() api.PrivateKeyProtection {
+	_, rounds, _ := readBcryptKDFOptions(f.kdfOptions)
+	return api.PrivateKeyProtection{Cipher: f.cipherName, Rounds: int(rounds)}
+}
+
+// withComment returns a new private key file with the same key and protection, but a different comment
+func (f *opensshPrivateKeyFile) withComment(comment string, passphrase []byte) (*opensshPrivateKeyFile, error) {
+	k, payload, e := f.unlockWithPayload(passphrase)
+	if e != nil {
+		return nil, e
+	}
+
+	keyWithoutComment := payload[:len(payload)-len(lengthBytes([]byte(k.comment)))]
+	return newOpensshPrivateKeyFile(f.publicKey, concat(keyWithoutComment, lengthBytes([]byte(comment))), passphrase, f.protection())
+}
+
+type fileReplacement struct {
+	fileName string
+	content  []byte
+}
+
+type originalFile struct {
+	content []byte
+	perm    os.FileMode
+}
+
+func readOriginalFile(fileName string) (*originalFile, error) {
+	info, e := os.Stat(fileName)
+	if e != nil {
+		return nil, e
+	}
+
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		return nil, e
+	}
+
+	return &originalFile{content, info.Mode().Perm()}, nil
+}
+
+// replaceFilesTogether atomically replaces each one of the files. If one of them fails, the
+// files already replaced will be restored, so that all of them stay consistent with each other
+func replaceFilesTogether(replacements ...fileReplacement) error {
+	originals := []*originalFile{}
+	for _, r := range replacements {
+		o, e := readOriginalFile(r.fileName)
+		if e != nil {
+			return e
+		}
+		originals = append(originals, o)
+	}
+
+	for i, r := range replacements {
+		if e := files.WriteAtomically(r.fileName, r.content, originals[i].perm); e != nil {
+			restoreFiles(replacements[:i], originals)
+			return e
+		}
+	}
+
+	return nil
+}
+
+func restoreFiles(replaced []fileReplacement, originals []*originalFile) {
+	for i, r := range replaced {
+		_ = files.WriteAtomically(r.fileName, originals[i].content, originals[i].perm)
+	}
+}
+
+func (k *publicKeyRepresentation) userIDReplacement(userID string) (fileReplacement, error) {
+	content, e := publicKeyFileContent(k.key, userID)
+	return fileReplacement{k.path, content}, e
+}
+
+func (k *privateKeyRepresentation) userIDReplacement(userID string, passphrase []byte) (fileReplacement, error) {
+	content, e := os.ReadFile(k.path)
+	if e != nil {
+		return fileReplacement{}, e
+	}
+
+	f, e := decodeOpensshPrivateKeyFile(content)
+	if e != nil {
+		return fileReplacement{}, e
+	}
+
+	nf, e := f.withComment(userID, passphrase)
+	if e != nil {
+		return fileReplacement{}, e
+	}
+
+	return fileReplacement{k.path, nf.encode()}, nil
+}
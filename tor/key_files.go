@@ -0,0 +1,74 @@
+package tor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/digitalautonomy/keymirror/edwards"
+)
+
+const keyFileHeaderLength = 32
+
+var secretKeyFileHeader = keyFileHeader("== ed25519v1-secret: type0 ==")
+var publicKeyFileHeader = keyFileHeader("== ed25519v1-public: type0 ==")
+
+var errInvalidSecretKeyFile = errors.New("not a Tor ed25519 secret key file")
+var errInvalidPublicKeyFile = errors.New("not a Tor ed25519 public key file")
+
+// keyFileHeader pads the tag with zeroes, the same way Tor does
+func keyFileHeader(tag string) []byte {
+	result := make([]byte, keyFileHeaderLength)
+	copy(result, tag)
+	return result
+}
+
+// ExpandedSecretKey is the format Tor uses for Ed25519 secret keys. It contains the clamped
+// scalar followed by the prefix used for generating nonces, both derived from the hash of the seed.
+// Since the seed can't be recovered from it, these keys can't be written as OpenSSH private keys
+type ExpandedSecretKey []byte
+
+// ExpandSecretKey derives the expanded secret key in the same way as RFC 8032, section 5.1.5
+func ExpandSecretKey(priv ed25519.PrivateKey) ExpandedSecretKey {
+	return ExpandedSecretKey(edwards.ExpandSeed(priv))
+}
+
+// PublicKey returns the public key that corresponds to the secret scalar
+func (k ExpandedSecretKey) PublicKey() ed25519.PublicKey {
+	return edwards.PublicKeyOfScalar(k[:edwards.ScalarSize])
+}
+
+// SecretKeyFileContent returns the content of the hs_ed25519_secret_key file for the private key
+func SecretKeyFileContent(priv ed25519.PrivateKey) []byte {
+	return append(append([]byte{}, secretKeyFileHeader...), ExpandSecretKey(priv)...)
+}
+
+// PublicKeyFileContent returns the content of the hs_ed25519_public_key file for the public key
+func PublicKeyFileContent(pub ed25519.PublicKey) []byte {
+	return append(append([]byte{}, publicKeyFileHeader...), pub...)
+}
+
+func withoutHeader(content, header []byte, size int) ([]byte, bool) {
+	if len(content) != len(header)+size || !bytes.HasPrefix(content, header) {
+		return nil, false
+	}
+	return content[len(header):], true
+}
+
+// ParseSecretKeyFile reads the content of a hs_ed25519_secret_key file
+func ParseSecretKeyFile(content []byte) (ExpandedSecretKey, error) {
+	key, ok := withoutHeader(content, secretKeyFileHeader, edwards.ExpandedSecretKeySize)
+	if !ok {
+		return nil, errInvalidSecretKeyFile
+	}
+	return ExpandedSecretKey(append([]byte{}, key...)), nil
+}
+
+// ParsePublicKeyFile reads the content of a hs_ed25519_public_key file
+func ParsePublicKeyFile(content []byte) (ed25519.PublicKey, error) {
+	key, ok := withoutHeader(content, publicKeyFileHeader, ed25519.PublicKeySize)
+	if !ok {
+		return nil, errInvalidPublicKeyFile
+	}
+	return ed25519.PublicKey(append([]byte{}, key...)), nil
+}
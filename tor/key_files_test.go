@@ -0,0 +1,45 @@
+package tor
+
+import (
+	"crypto/ed25519"
+)
+
+func (s *torSuite) Test_ExpandedSecretKey_PublicKey_matchesTheKeyFromTheSeed() {
+	for _, priv := range []ed25519.PrivateKey{rfc8032Key(), randomKeyForTest(), randomKeyForTest()} {
+		s.Equal(priv.Public(), ExpandSecretKey(priv).PublicKey())
+	}
+}
+
+func (s *torSuite) Test_SecretKeyFileContent_hasTheTorHeaderAndExpandedKey() {
+	content := SecretKeyFileContent(rfc8032Key())
+
+	s.Len(content, 96)
+	s.Equal("== ed25519v1-secret: type0 ==\x00\x00\x00", string(content[:32]))
+
+	k, e := ParseSecretKeyFile(content)
+	s.NoError(e)
+	s.Equal(ExpandSecretKey(rfc8032Key()), k)
+	s.Equal(byte(0), k[0]&7)
+	s.Equal(byte(0x40), k[31]&0xC0)
+}
+
+func (s *torSuite) Test_PublicKeyFileContent_canBeParsed() {
+	pub := rfc8032Key().Public().(ed25519.PublicKey)
+	content := PublicKeyFileContent(pub)
+
+	s.Equal("== ed25519v1-public: type0 ==\x00\x00\x00", string(content[:32]))
+	res, e := ParsePublicKeyFile(content)
+	s.NoError(e)
+	s.Equal(pub, res)
+}
+
+func (s *torSuite) Test_ParseKeyFiles_failForOtherContent() {
+	_, e := ParseSecretKeyFile(PublicKeyFileContent(rfc8032Key().Public().(ed25519.PublicKey)))
+	s.Equal(errInvalidSecretKeyFile, e)
+
+	_, e = ParsePublicKeyFile(SecretKeyFileContent(rfc8032Key()))
+	s.Equal(errInvalidPublicKeyFile, e)
+
+	_, e = ParsePublicKeyFile(publicKeyFileHeader)
+	s.Equal(errInvalidPublicKeyFile, e)
+}
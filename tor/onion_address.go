@@ -0,0 +1,55 @@
+package tor
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const onionAddressVersion = 3
+const onionAddressChecksumPrefix = ".onion checksum"
+const onionAddressSuffix = ".onion"
+
+// ErrUnsupportedKey is returned when trying to use a key that is not an Ed25519 key
+var ErrUnsupportedKey = errors.New("only Ed25519 keys can be used for onion services")
+
+var errInvalidOnionAddress = errors.New("invalid v3 onion address")
+
+func onionAddressChecksum(pub ed25519.PublicKey) []byte {
+	h := sha3.New256()
+	_, _ = h.Write([]byte(onionAddressChecksumPrefix))
+	_, _ = h.Write(pub)
+	_, _ = h.Write([]byte{onionAddressVersion})
+	return h.Sum(nil)[:2]
+}
+
+// OnionAddress returns the v3 onion address for the public key, as described in
+// section 6 of rend-spec-v3.txt
+func OnionAddress(pub crypto.PublicKey) (string, error) {
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok || len(edPub) != ed25519.PublicKeySize {
+		return "", ErrUnsupportedKey
+	}
+
+	data := append(append(append([]byte{}, edPub...), onionAddressChecksum(edPub)...), onionAddressVersion)
+	return strings.ToLower(base32.StdEncoding.EncodeToString(data)) + onionAddressSuffix, nil
+}
+
+// PublicKeyFromOnionAddress returns the public key in a v3 onion address, after checking its checksum
+func PublicKeyFromOnionAddress(address string) (ed25519.PublicKey, error) {
+	encoded := strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(address), onionAddressSuffix))
+	data, e := base32.StdEncoding.DecodeString(encoded)
+	if e != nil || len(data) != ed25519.PublicKeySize+3 || data[len(data)-1] != onionAddressVersion {
+		return nil, errInvalidOnionAddress
+	}
+
+	pub := ed25519.PublicKey(data[:ed25519.PublicKeySize])
+	if string(onionAddressChecksum(pub)) != string(data[ed25519.PublicKeySize:ed25519.PublicKeySize+2]) {
+		return nil, errInvalidOnionAddress
+	}
+	return pub, nil
+}
@@ -0,0 +1,32 @@
+package tor
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+)
+
+func (s *torSuite) Test_OnionAddress_returnsTheV3AddressOfTheKey() {
+	address, e := OnionAddress(rfc8032Key().Public())
+	s.NoError(e)
+	s.Equal("25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion", address)
+}
+
+func (s *torSuite) Test_OnionAddress_failsForOtherKeys() {
+	_, e := OnionAddress(&ecdsa.PublicKey{})
+	s.Equal(ErrUnsupportedKey, e)
+
+	_, e = OnionAddress(ed25519.PublicKey{0x01})
+	s.Equal(ErrUnsupportedKey, e)
+}
+
+func (s *torSuite) Test_PublicKeyFromOnionAddress_checksTheChecksum() {
+	pub, e := PublicKeyFromOnionAddress("25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion")
+	s.NoError(e)
+	s.Equal(rfc8032Key().Public(), pub)
+
+	_, e = PublicKeyFromOnionAddress("25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sie.onion")
+	s.Equal(errInvalidOnionAddress, e)
+
+	_, e = PublicKeyFromOnionAddress("duckduckgo.onion")
+	s.Equal(errInvalidOnionAddress, e)
+}
@@ -0,0 +1,151 @@
+package tor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const secretKeyFileName = "hs_ed25519_secret_key"
+const publicKeyFileName = "hs_ed25519_public_key"
+const hostnameFileName = "hostname"
+
+// ErrNotAnOnionServiceDirectory is returned when a directory has neither a secret nor a public key file
+var ErrNotAnOnionServiceDirectory = errors.New("the directory doesn't contain onion service keys")
+
+var errMismatchedKeys = errors.New("the secret and public key files of the onion service don't match")
+var errMismatchedHostname = errors.New("the hostname file doesn't match the keys of the onion service")
+var errServiceFilesExist = errors.New("the directory already contains onion service files")
+
+// OnionService contains the keys found in an onion service directory. The secret key
+// is nil when the directory only has the public key
+type OnionService struct {
+	Directory string
+	PublicKey ed25519.PublicKey
+	SecretKey ExpandedSecretKey
+	Address   string
+}
+
+func readOptionalFile(name string) ([]byte, bool, error) {
+	content, e := os.ReadFile(name)
+	if errors.Is(e, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	return content, e == nil, e
+}
+
+func (s *OnionService) readSecretKey() error {
+	content, ok, e := readOptionalFile(filepath.Join(s.Directory, secretKeyFileName))
+	if !ok {
+		return e
+	}
+	s.SecretKey, e = ParseSecretKeyFile(content)
+	return e
+}
+
+func (s *OnionService) readPublicKey() error {
+	content, ok, e := readOptionalFile(filepath.Join(s.Directory, publicKeyFileName))
+	if !ok {
+		return e
+	}
+	s.PublicKey, e = ParsePublicKeyFile(content)
+	return e
+}
+
+// checkKeys derives the public key when only the secret key is available
+func (s *OnionService) checkKeys() error {
+	switch {
+	case s.SecretKey == nil && s.PublicKey == nil:
+		return ErrNotAnOnionServiceDirectory
+	case s.SecretKey == nil:
+		return nil
+	case s.PublicKey == nil:
+		s.PublicKey = s.SecretKey.PublicKey()
+		return nil
+	case !bytes.Equal(s.PublicKey, s.SecretKey.PublicKey()):
+		return errMismatchedKeys
+	}
+	return nil
+}
+
+func (s *OnionService) checkHostname() error {
+	content, ok, e := readOptionalFile(filepath.Join(s.Directory, hostnameFileName))
+	if !ok {
+		return e
+	}
+	if strings.TrimSpace(string(content)) != s.Address {
+		return errMismatchedHostname
+	}
+	return nil
+}
+
+// ReadServiceDirectory reads the keys of an onion service from a directory like the ones
+// configured with HiddenServiceDir, checking that the files in it agree with each other
+func ReadServiceDirectory(dir string) (*OnionService, error) {
+	s := &OnionService{Directory: dir}
+
+	for _, f := range []func() error{s.readSecretKey, s.readPublicKey, s.checkKeys} {
+		if e := f(); e != nil {
+			return nil, e
+		}
+	}
+
+	s.Address, _ = OnionAddress(s.PublicKey)
+	if e := s.checkHostname(); e != nil {
+		return nil, e
+	}
+
+	return s, nil
+}
+
+func writeNewFile(name string, content []byte) error {
+	f, e := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if e != nil {
+		return e
+	}
+	_, e = f.Write(content)
+	if e2 := f.Close(); e == nil {
+		e = e2
+	}
+	return e
+}
+
+// WriteServiceDirectory writes the secret key, public key and hostname files Tor expects in
+// an onion service directory. It will not overwrite the files of an existing onion service
+func WriteServiceDirectory(dir string, priv ed25519.PrivateKey) error {
+	pub := priv.Public().(ed25519.PublicKey)
+	address, e := OnionAddress(pub)
+	if e != nil {
+		return e
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{secretKeyFileName, SecretKeyFileContent(priv)},
+		{publicKeyFileName, PublicKeyFileContent(pub)},
+		{hostnameFileName, []byte(address + "\n")},
+	}
+
+	for _, f := range files {
+		if _, e := os.Stat(filepath.Join(dir, f.name)); e == nil {
+			return errServiceFilesExist
+		}
+	}
+
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return e
+	}
+
+	for _, f := range files {
+		if e := writeNewFile(filepath.Join(dir, f.name), f.content); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package tor
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func (s *torSuite) Test_WriteServiceDirectory_writesFilesThatCanBeReadBack() {
+	dir := filepath.Join(s.T().TempDir(), "hidden_service")
+
+	s.Require().NoError(WriteServiceDirectory(dir, rfc8032Key()))
+
+	for _, name := range []string{"hs_ed25519_secret_key", "hs_ed25519_public_key", "hostname"} {
+		info, e := os.Stat(filepath.Join(dir, name))
+		s.Require().NoError(e)
+		s.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+	hostname, _ := os.ReadFile(filepath.Join(dir, "hostname"))
+	s.Equal("25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion\n", string(hostname))
+
+	service, e := ReadServiceDirectory(dir)
+	s.Require().NoError(e)
+	s.Equal(rfc8032Key().Public(), service.PublicKey)
+	s.Equal(ExpandSecretKey(rfc8032Key()), service.SecretKey)
+	s.Equal("25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion", service.Address)
+}
+
+func (s *torSuite) Test_WriteServiceDirectory_doesNotOverwriteExistingServices() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "hostname"), []byte("existing\n"), 0600))
+
+	s.Equal(errServiceFilesExist, WriteServiceDirectory(dir, rfc8032Key()))
+	s.NoFileExists(filepath.Join(dir, "hs_ed25519_secret_key"))
+}
+
+func (s *torSuite) Test_ReadServiceDirectory_derivesThePublicKeyFromTheSecretKey() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "hs_ed25519_secret_key"), SecretKeyFileContent(rfc8032Key()), 0600))
+
+	service, e := ReadServiceDirectory(dir)
+	s.Require().NoError(e)
+	s.Equal(rfc8032Key().Public(), service.PublicKey)
+}
+
+func (s *torSuite) Test_ReadServiceDirectory_canReadOnlyThePublicKey() {
+	dir := s.T().TempDir()
+	s.Require().NoError(WriteServiceDirectory(dir, rfc8032Key()))
+	s.Require().NoError(os.Remove(filepath.Join(dir, "hs_ed25519_secret_key")))
+
+	service, e := ReadServiceDirectory(dir)
+	s.Require().NoError(e)
+	s.Nil(service.SecretKey)
+	s.Equal(rfc8032Key().Public(), service.PublicKey)
+}
+
+func (s *torSuite) Test_ReadServiceDirectory_checksThatTheFilesAgree() {
+	_, e := ReadServiceDirectory(s.T().TempDir())
+	s.Equal(ErrNotAnOnionServiceDirectory, e)
+
+	dir := s.T().TempDir()
+	s.Require().NoError(WriteServiceDirectory(dir, rfc8032Key()))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "hs_ed25519_secret_key"), SecretKeyFileContent(randomKeyForTest()), 0600))
+	_, e = ReadServiceDirectory(dir)
+	s.Equal(errMismatchedKeys, e)
+
+	dir = s.T().TempDir()
+	s.Require().NoError(WriteServiceDirectory(dir, rfc8032Key()))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "hostname"), []byte("duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion\n"), 0600))
+	_, e = ReadServiceDirectory(dir)
+	s.Equal(errMismatchedHostname, e)
+}
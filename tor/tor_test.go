@@ -0,0 +1,29 @@
+package tor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type torSuite struct {
+	suite.Suite
+}
+
+func TestTorSuite(t *testing.T) {
+	suite.Run(t, new(torSuite))
+}
+
+// rfc8032Key is the key from the first test vector in RFC 8032, section 7.1
+func rfc8032Key() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func randomKeyForTest() ed25519.PrivateKey {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	return priv
+}
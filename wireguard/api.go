@@ -0,0 +1,72 @@
+package wireguard
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/sirupsen/logrus"
+)
+
+const configFilePattern = "wg*.conf"
+const configFileExtension = ".conf"
+
+// Access returns a key access that lists the keys in the WireGuard configuration files
+// of the system and of the current user
+func Access(l logrus.FieldLogger) api.KeyAccess {
+	home, _ := os.UserHomeDir()
+	return &access{
+		log: l.WithField("component", "wireguard"),
+		directories: []string{
+			"/etc/wireguard",
+			filepath.Join(home, ".config", "wireguard"),
+		},
+	}
+}
+
+type access struct {
+	log         logrus.Ext1FieldLogger
+	directories []string
+}
+
+func (a *access) configFiles() []string {
+	result := []string{}
+	for _, dir := range a.directories {
+		files, _ := filepath.Glob(filepath.Join(dir, configFilePattern))
+		a.log.WithField("directory", dir).WithField("files", files).Debug("found WireGuard configuration files")
+		result = append(result, files...)
+	}
+	return result
+}
+
+func (a *access) keysFrom(fileName string) []api.KeyEntry {
+	content, e := os.ReadFile(fileName)
+	if e != nil {
+		a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the WireGuard configuration file")
+		return nil
+	}
+
+	interfaceName := strings.TrimSuffix(filepath.Base(fileName), configFileExtension)
+	result := []api.KeyEntry{}
+	for _, k := range parseConfig(string(content), interfaceName) {
+		result = append(result, &keyEntry{location: fileName, key: k})
+	}
+	return result
+}
+
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	result := []api.KeyEntry{}
+	for _, f := range a.configFiles() {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		result = append(result, a.keysFrom(f)...)
+	}
+	return result, nil
+}
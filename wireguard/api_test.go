@@ -0,0 +1,61 @@
+package wireguard
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *wireguardSuite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(directories ...string) *access {
+	logger := testutil.NullLogger()
+	return &access{log: logger, directories: directories}
+}
+
+func (s *wireguardSuite) Test_access_AllKeys_listsTheKeysInConfigurationFiles() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "wg0.conf"), []byte(exampleConfig), 0600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "other.conf"), []byte(exampleConfig), 0600))
+
+	keys := s.allKeysOf(accessForTest(dir, filepath.Join(dir, "missing")))
+	s.Require().Len(keys, 3)
+
+	location := filepath.Join(dir, "wg0.conf")
+	interfaceKey := keys[0].(api.X25519PublicKeyEntry)
+	s.Equal([]string{location}, interfaceKey.Locations())
+	s.Equal([]string{location}, interfaceKey.PrivateKeyLocations())
+	s.Equal(api.PairKeyType, interfaceKey.KeyType())
+	s.Equal(api.X25519, interfaceKey.Algorithm())
+	s.Equal("wg0", interfaceKey.UserID())
+	s.Equal(testutil.DecodeBase64(rfc8032KeyWireGuardPublic), interfaceKey.X25519PublicKey())
+	s.False(interfaceKey.(api.PrivateKeyEntry).IsPasswordProtected())
+
+	peerKey := keys[1].(api.PublicKeyEntry)
+	s.Equal(api.PublicKeyType, peerKey.KeyType())
+	s.Nil(peerKey.PrivateKeyLocations())
+	s.Equal("Batcave gateway", peerKey.UserID())
+	expected := sha256.Sum256(testutil.DecodeBase64("xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="))
+	s.Equal(expected[:], peerKey.WithDigestContent(func(b []byte) []byte {
+		res := sha256.Sum256(b)
+		return res[:]
+	}))
+	_, isSSH := peerKey.(api.SSHPublicKeyEntry)
+	s.False(isSSH, "WireGuard keys can't be used with SSH")
+}
+
+func (s *wireguardSuite) Test_Access_looksInTheSystemAndUserDirectories() {
+	logger := testutil.NullLogger()
+	a := Access(logger).(*access)
+
+	home, _ := os.UserHomeDir()
+	s.Equal([]string{"/etc/wireguard", filepath.Join(home, ".config", "wireguard")}, a.directories)
+}
@@ -0,0 +1,123 @@
+package wireguard
+
+import (
+	"bufio"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+const interfaceSection = "interface"
+const peerSection = "peer"
+
+// configKey is a key found in a configuration file. Keys from the Interface section
+// have a private key, while keys from Peer sections only have the public key
+type configKey struct {
+	private     []byte
+	public      []byte
+	description string
+}
+
+// configSection collects the values of one section, together with the comments right before it
+type configSection struct {
+	name     string
+	values   map[string]string
+	comments []string
+}
+
+func (s *configSection) description() string {
+	for _, candidate := range []string{strings.Join(s.comments, " "), s.values["endpoint"], s.values["allowedips"]} {
+		if candidate != "" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// parseConfigSections follows the format accepted by wg-quick: INI-like sections with case
+// insensitive keys, where everything after a # is a comment
+func parseConfigSections(content string) []*configSection {
+	result := []*configSection{}
+	var current *configSection
+	comments := []string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment := strings.Index(line, "#"); comment != -1 {
+			if text := strings.TrimSpace(line[comment+1:]); comment == 0 && text != "" {
+				comments = append(comments, text)
+			}
+			line = strings.TrimSpace(line[:comment])
+		}
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = &configSection{
+				name:     strings.ToLower(strings.TrimSpace(line[1 : len(line)-1])),
+				values:   map[string]string{},
+				comments: comments,
+			}
+			result = append(result, current)
+			comments = []string{}
+		case current != nil:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				current.values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+			comments = []string{}
+		}
+	}
+
+	return result
+}
+
+func decodeKey(value string) ([]byte, bool) {
+	k, e := base64.StdEncoding.DecodeString(value)
+	return k, e == nil && len(k) == curve25519.ScalarSize
+}
+
+func keyFromInterface(s *configSection, interfaceName string) (*configKey, bool) {
+	private, ok := decodeKey(s.values["privatekey"])
+	if !ok {
+		return nil, false
+	}
+
+	public, e := curve25519.X25519(private, curve25519.Basepoint)
+	if e != nil {
+		return nil, false
+	}
+
+	return &configKey{private: private, public: public, description: interfaceName}, true
+}
+
+func keyFromPeer(s *configSection) (*configKey, bool) {
+	public, ok := decodeKey(s.values["publickey"])
+	if !ok {
+		return nil, false
+	}
+
+	return &configKey{public: public, description: s.description()}, true
+}
+
+// parseConfig returns the keys of the interface and peers in the configuration, ignoring
+// sections without valid keys. The interface name is used to describe the interface key
+func parseConfig(content, interfaceName string) []*configKey {
+	result := []*configKey{}
+	for _, s := range parseConfigSections(content) {
+		var k *configKey
+		ok := false
+		switch s.name {
+		case interfaceSection:
+			k, ok = keyFromInterface(s, interfaceName)
+		case peerSection:
+			k, ok = keyFromPeer(s)
+		}
+
+		if ok {
+			result = append(result, k)
+		}
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package wireguard
+
+import (
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+const exampleConfig = `[Interface]
+# The address of this machine
+Address = 10.0.0.2/24
+PrivateKey = MHyDhk8oM8tCei7xwAoBPP3/J2jZgMCjpSDwBpBN6U8=
+ListenPort = 51820
+
+# Batcave gateway
+[Peer]
+PublicKey = xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+Endpoint = batcave.example:51820
+AllowedIPs = 10.0.0.0/24
+
+[peer]
+publickey=TrMvSoP4jYQlY6RIzBgbssQqY3vxI2Pi+y71lOWWXX0= # inline comments are ignored
+AllowedIPs = 10.0.0.3/32
+
+[Peer]
+PublicKey = not a key
+`
+
+func (s *wireguardSuite) Test_parseConfig_returnsTheInterfaceAndPeerKeys() {
+	keys := parseConfig(exampleConfig, "wg0")
+
+	s.Require().Len(keys, 3)
+
+	s.Equal(testutil.DecodeBase64(rfc8032KeyWireGuardPrivate), keys[0].private)
+	s.Equal(testutil.DecodeBase64(rfc8032KeyWireGuardPublic), keys[0].public)
+	s.Equal("wg0", keys[0].description)
+
+	s.Nil(keys[1].private)
+	s.Equal(testutil.DecodeBase64("xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="), keys[1].public)
+	s.Equal("Batcave gateway", keys[1].description)
+
+	s.Nil(keys[2].private)
+	s.Equal(testutil.DecodeBase64("TrMvSoP4jYQlY6RIzBgbssQqY3vxI2Pi+y71lOWWXX0="), keys[2].public)
+	s.Equal("10.0.0.3/32", keys[2].description)
+}
+
+func (s *wireguardSuite) Test_parseConfig_ignoresContentThatIsNotAConfiguration() {
+	s.Empty(parseConfig("PrivateKey = MHyDhk8oM8tCei7xwAoBPP3/J2jZgMCjpSDwBpBN6U8=\n", "wg0"))
+	s.Empty(parseConfig("[Interface]\nPrivateKey = AAAA\n", "wg0"))
+	s.Empty(parseConfig("", "wg0"))
+}
@@ -0,0 +1,44 @@
+package wireguard
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+
+	"github.com/digitalautonomy/keymirror/edwards"
+)
+
+// ErrUnsupportedKey is returned when trying to convert a key that is not an Ed25519 key
+var ErrUnsupportedKey = errors.New("only Ed25519 keys can be converted to WireGuard keys")
+
+func encodeKey(k []byte) string {
+	return base64.StdEncoding.EncodeToString(k)
+}
+
+// PublicKeyFrom returns the WireGuard public key, in the base64 format used by wg, that
+// corresponds to the given Ed25519 public key
+func PublicKeyFrom(pub crypto.PublicKey) (string, error) {
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	u, e := edwards.PublicKeyToX25519(edPub)
+	if e != nil {
+		return "", e
+	}
+	return encodeKey(u), nil
+}
+
+// PrivateKeyFrom returns the WireGuard private key, in the base64 format used by wg, that
+// corresponds to the given Ed25519 private key. The result of PublicKeyFrom for the public
+// part of the same key is its public key
+func PrivateKeyFrom(priv crypto.PrivateKey) (string, error) {
+	edPriv, ok := priv.(ed25519.PrivateKey)
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	return encodeKey(edwards.PrivateKeyToX25519(edPriv)), nil
+}
@@ -0,0 +1,42 @@
+package wireguard
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+func (s *wireguardSuite) Test_PublicKeyFrom_returnsTheBase64X25519PublicKey() {
+	res, e := PublicKeyFrom(rfc8032Key().Public())
+	s.NoError(e)
+	s.Equal(rfc8032KeyWireGuardPublic, res)
+}
+
+func (s *wireguardSuite) Test_PrivateKeyFrom_returnsTheBase64X25519PrivateKey() {
+	res, e := PrivateKeyFrom(rfc8032Key())
+	s.NoError(e)
+	s.Equal(rfc8032KeyWireGuardPrivate, res)
+}
+
+func (s *wireguardSuite) Test_PrivateKeyFrom_andPublicKeyFrom_giveAMatchingKeyPair() {
+	for i := 0; i < 5; i++ {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+		wgPrivate, _ := PrivateKeyFrom(priv)
+		wgPublic, _ := PublicKeyFrom(pub)
+
+		scalar, _ := base64.StdEncoding.DecodeString(wgPrivate)
+		expected, _ := curve25519.X25519(scalar, curve25519.Basepoint)
+		s.Equal(base64.StdEncoding.EncodeToString(expected), wgPublic)
+	}
+}
+
+func (s *wireguardSuite) Test_conversions_failForOtherKeys() {
+	_, e := PublicKeyFrom(&ecdsa.PublicKey{})
+	s.Equal(ErrUnsupportedKey, e)
+
+	_, e = PrivateKeyFrom(&ecdsa.PrivateKey{})
+	s.Equal(ErrUnsupportedKey, e)
+}
@@ -0,0 +1,71 @@
+package wireguard
+
+import (
+	"github.com/digitalautonomy/keymirror/api"
+)
+
+const x25519KeySize = 256
+
+// keyEntry represents a key from a WireGuard configuration file. The public key of
+// interfaces is derived from the private key, so both are in the same file
+type keyEntry struct {
+	location string
+	key      *configKey
+}
+
+// ID implements the api.KeyEntry interface. It's the same as the ID of an age key with the same X25519 public key
+func (k *keyEntry) ID() string {
+	return api.MaterialID("x25519", k.key.public)
+}
+
+func (k *keyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+func (k *keyEntry) Locations() []string {
+	return []string{k.location}
+}
+
+func (k *keyEntry) PublicKeyLocations() []string {
+	return []string{k.location}
+}
+
+func (k *keyEntry) PrivateKeyLocations() []string {
+	if k.key.private == nil {
+		return nil
+	}
+	return []string{k.location}
+}
+
+func (k *keyEntry) KeyType() api.KeyType {
+	if k.key.private == nil {
+		return api.PublicKeyType
+	}
+	return api.PairKeyType
+}
+
+func (k *keyEntry) Size() int {
+	return x25519KeySize
+}
+
+func (k *keyEntry) Algorithm() api.Algorithm {
+	return api.X25519
+}
+
+func (k *keyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.key.public)
+}
+
+func (k *keyEntry) UserID() string {
+	return k.key.description
+}
+
+// IsPasswordProtected implements the api.PrivateKeyEntry interface. WireGuard keys are never protected
+func (k *keyEntry) IsPasswordProtected() bool {
+	return false
+}
+
+// X25519PublicKey implements the api.X25519PublicKeyEntry interface
+func (k *keyEntry) X25519PublicKey() []byte {
+	return k.key.public
+}
@@ -0,0 +1,26 @@
+package wireguard
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type wireguardSuite struct {
+	suite.Suite
+}
+
+func TestWireguardSuite(t *testing.T) {
+	suite.Run(t, new(wireguardSuite))
+}
+
+// rfc8032Key is the key from the first test vector in RFC 8032, section 7.1
+func rfc8032Key() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+const rfc8032KeyWireGuardPublic = "2F4H7CKwrYgVN8L0TWYtGhQ8+DDFespDBdhcepD2ti4="
+const rfc8032KeyWireGuardPrivate = "MHyDhk8oM8tCei7xwAoBPP3/J2jZgMCjpSDwBpBN6U8="
@@ -0,0 +1,127 @@
+package x509
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+	"github.com/sirupsen/logrus"
+)
+
+// Access returns a key access that lists the certificates and private keys in ~/.pki and ~/certs,
+// and in the given project directories. Certificates and keys for the same public key are listed together
+func Access(l logrus.FieldLogger, projectDirectories ...string) api.KeyAccess {
+	home, _ := os.UserHomeDir()
+	return &access{
+		log: l.WithField("component", "x509"),
+		directories: append([]string{
+			filepath.Join(home, ".pki"),
+			filepath.Join(home, "certs"),
+		}, projectDirectories...),
+		knownPasswords: map[string][]byte{},
+		declinedFiles:  map[string]bool{},
+	}
+}
+
+// access remembers the passwords of PKCS#12 files, and the files the user didn't want to give
+// a password for, so the user isn't asked again every time the keys are listed
+type access struct {
+	log            logrus.Ext1FieldLogger
+	directories    []string
+	passwords      api.PasswordProvider
+	knownPasswords map[string][]byte
+	declinedFiles  map[string]bool
+}
+
+// keyCollection puts the certificates and the private key for the same public key together
+type keyCollection struct {
+	entries     []*keyEntry
+	byPublicKey map[string]*keyEntry
+}
+
+func (c *keyCollection) entryFor(pub crypto.PublicKey, publicKeyInfo []byte) (*keyEntry, bool) {
+	if _, ok := keySizeOf(pub); !ok {
+		return nil, false
+	}
+	if _, ok := algorithmOf(publicKeyInfo); !ok {
+		return nil, false
+	}
+
+	entry, found := c.byPublicKey[string(publicKeyInfo)]
+	if !found {
+		entry = &keyEntry{public: pub, publicKeyInfo: publicKeyInfo}
+		c.byPublicKey[string(publicKeyInfo)] = entry
+		c.entries = append(c.entries, entry)
+	}
+	return entry, true
+}
+
+func (c *keyCollection) add(content *fileContent, location string) {
+	for _, cert := range content.certificates {
+		if entry, ok := c.entryFor(cert.PublicKey, cert.RawSubjectPublicKeyInfo); ok {
+			entry.addCertificate(cert, location)
+		}
+	}
+
+	for _, k := range content.privateKeys {
+		publicKeyInfo, e := x509.MarshalPKIXPublicKey(k.Public())
+		if e != nil {
+			continue
+		}
+		if entry, ok := c.entryFor(k.Public(), publicKeyInfo); ok {
+			entry.addPrivateKey(k, location)
+		}
+	}
+}
+
+func (a *access) candidateFiles() []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, dir := range a.directories {
+		files := candidateFilesIn(dir)
+		a.log.WithField("directory", dir).WithField("files", files).Debug("found possible certificate and key files")
+		for _, f := range files {
+			if !seen[f] {
+				seen[f] = true
+				result = append(result, f)
+			}
+		}
+	}
+	return result
+}
+
+func (a *access) AllKeys(ctx context.Context) ([]api.KeyEntry, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	c := &keyCollection{byPublicKey: map[string]*keyEntry{}}
+	for _, f := range a.candidateFiles() {
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		content, e := files.ReadSmall(f, files.MaximumFileSize)
+		if e != nil {
+			a.log.WithError(e).WithField("file", f).Debug("couldn't read the certificate or key file")
+			continue
+		}
+
+		if isPKCS12File(f) {
+			if p, ok := a.decodePKCS12(ctx, f, content); ok {
+				c.addPKCS12(p, f)
+			}
+			continue
+		}
+		c.add(parseCertificatesAndKeys(content), f)
+	}
+
+	result := []api.KeyEntry{}
+	for _, entry := range c.entries {
+		result = append(result, entry.asAPIEntry())
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package x509
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/files"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+func (s *x509Suite) allKeysOf(a api.KeyAccess) []api.KeyEntry {
+	keys, e := a.AllKeys(context.Background())
+	s.NoError(e)
+	return keys
+}
+
+func accessForTest(directories ...string) *access {
+	logger := testutil.NullLogger()
+	return &access{
+		log:            logger,
+		directories:    directories,
+		knownPasswords: map[string][]byte{},
+		declinedFiles:  map[string]bool{},
+	}
+}
+
+func (s *x509Suite) writeFileForTest(dir, name string, content []byte) string {
+	fileName := filepath.Join(dir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(fileName), 0700))
+	s.Require().NoError(os.WriteFile(fileName, content, 0600))
+	return fileName
+}
+
+func (s *x509Suite) Test_access_AllKeys_pairsCertificatesWithTheirKeys() {
+	dir := s.T().TempDir()
+	other := s.T().TempDir()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	certificate := s.certificateForTest("localhost", ed25519KeyForTest())
+	certFile := s.writeFileForTest(dir, "tls/localhost.crt", certificate)
+	keyFile := s.writeFileForTest(other, "localhost.key", s.pkcs8ForTest(ed25519KeyForTest()))
+	renewedFile := s.writeFileForTest(other, "renewed.pem", append(certificate, s.certificateForTest("renewed", ed25519KeyForTest())...))
+	rsaFile := s.writeFileForTest(other, "rsa.pem", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+
+	keys := s.allKeysOf(accessForTest(dir, other, dir))
+	s.Require().Len(keys, 2)
+
+	k := keys[0].(api.CertificateKeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{certFile, renewedFile}, k.PublicKeyLocations())
+	s.Equal([]string{keyFile}, k.PrivateKeyLocations())
+	s.Equal([]string{certFile, renewedFile, keyFile}, k.Locations())
+	s.Equal(api.Ed25519, k.Algorithm())
+	s.Equal("localhost", k.UserID())
+	s.Nil(k.(api.RSAKeyEntry).RSAPublicExponent())
+	s.False(k.(api.PrivateKeyEntry).IsPasswordProtected())
+	s.Equal(ed25519KeyForTest().Public(), k.(api.PublicKeyMaterialEntry).PublicKey())
+
+	certs := k.Certificates()
+	s.Require().Len(certs, 2)
+	certificateFingerprint := sha256.Sum256(s.decodePEM(certificate, "CERTIFICATE"))
+	s.Equal(api.Certificate{
+		Subject:                 "CN=localhost",
+		Issuer:                  "CN=localhost",
+		SubjectAlternativeNames: []string{"localhost"},
+		NotBefore:               time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:                time.Date(2022, 7, 1, 1, 0, 0, 0, time.UTC),
+		SHA256Fingerprint:       certificateFingerprint[:],
+	}, certs[0])
+	s.Equal("CN=renewed", certs[1].Subject)
+
+	publicKeyInfo, _ := x509.MarshalPKIXPublicKey(ed25519KeyForTest().Public())
+	expected := sha256.Sum256(publicKeyInfo)
+	s.Equal(expected[:], k.WithDigestContent(func(b []byte) []byte {
+		res := sha256.Sum256(b)
+		return res[:]
+	}))
+	s.Equal(fingerprint.SSHWireFormat(ed25519KeyForTest().Public()), k.(api.SSHPublicKeyEntry).SSHPublicKey())
+
+	r := keys[1].(api.CertificateKeyEntry)
+	s.Equal(api.PrivateKeyType, r.KeyType())
+	s.Equal([]string{rsaFile}, r.Locations())
+	s.Equal(api.RSA, r.Algorithm())
+	s.Equal(1024, r.Size())
+	s.Equal(big.NewInt(65537), r.(api.RSAKeyEntry).RSAPublicExponent())
+	s.Empty(r.Certificates())
+	s.Equal("", r.UserID())
+}
+
+func (s *x509Suite) Test_access_AllKeys_listsCertificatesWithoutKeysAsPublicKeys() {
+	dir := s.T().TempDir()
+	chain := append(s.certificateForTest("leaf.example", ed25519KeyForTest()), s.certificateForTest("Batcave CA", mustGenerateRSAKey())...)
+	chainFile := s.writeFileForTest(dir, "chain.pem", chain)
+
+	keys := s.allKeysOf(accessForTest(dir))
+	s.Require().Len(keys, 2)
+	s.Equal(api.PublicKeyType, keys[0].KeyType())
+	s.Equal([]string{chainFile}, keys[1].Locations())
+	s.Equal("Batcave CA", keys[1].(api.PublicKeyEntry).UserID())
+}
+
+func (s *x509Suite) Test_algorithmOf_findsTheAlgorithmByTheOIDOfThePublicKeyInfo() {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	for _, c := range []struct {
+		pub       interface{}
+		algorithm api.Algorithm
+	}{
+		{&rsaKey.PublicKey, api.RSA},
+		{ed25519KeyForTest().Public(), api.Ed25519},
+	} {
+		info, _ := x509.MarshalPKIXPublicKey(c.pub)
+		a, ok := algorithmOf(info)
+		s.True(ok)
+		s.Equal(c.algorithm, a)
+	}
+
+	_, ok := algorithmOf([]byte("not a public key info"))
+	s.False(ok)
+
+	a, ok := api.AlgorithmWithOID("1.2.840.10045.2.1")
+	s.True(ok)
+	s.Equal(api.ECDSA, a)
+	a, ok = api.AlgorithmWithOID("1.2.840.10040.4.1")
+	s.True(ok)
+	s.Equal(api.DSA, a)
+}
+
+func (s *x509Suite) Test_access_AllKeys_skipsFilesThatAreTooBig() {
+	dir := s.T().TempDir()
+	content := append(s.certificateForTest("big", ed25519KeyForTest()), make([]byte, files.MaximumFileSize)...)
+	s.writeFileForTest(dir, "big.pem", content)
+
+	s.Empty(s.allKeysOf(accessForTest(dir)))
+}
+
+func (s *x509Suite) Test_Access_looksInThePKIAndCertificateDirectories_andTheProjectDirectories() {
+	logger := testutil.NullLogger()
+	a := Access(logger, "/src/project").(*access)
+
+	home, _ := os.UserHomeDir()
+	s.Equal([]string{filepath.Join(home, ".pki"), filepath.Join(home, "certs"), "/src/project"}, a.directories)
+}
+
+func mustGenerateRSAKey() *rsa.PrivateKey {
+	k, _ := rsa.GenerateKey(rand.Reader, 1024)
+	return k
+}
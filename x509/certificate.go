@@ -0,0 +1,271 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+const certificatePEMType = "CERTIFICATE"
+const certificateRequestPEMType = "CERTIFICATE REQUEST"
+
+// ErrUnsupportedKey is returned when trying to use a key that is not an RSA, ECDSA or Ed25519 key
+var ErrUnsupportedKey = errors.New("only RSA, ECDSA and Ed25519 keys can be used for certificates")
+
+// ErrNoSubject is returned when neither a common name nor any alternative names are given
+var ErrNoSubject = errors.New("a common name or at least one alternative name is required")
+
+var errInvalidValidity = errors.New("the validity period has to be positive")
+
+// KeyUsage is a set of the purposes a certificate can be used for
+type KeyUsage int
+
+const (
+	DigitalSignature KeyUsage = 1 << iota
+	KeyEncipherment
+	CertificateSigning
+	ServerAuthentication
+	ClientAuthentication
+)
+
+// Has returns true if all the usages given are part of this set
+func (u KeyUsage) Has(usage KeyUsage) bool {
+	return u&usage == usage
+}
+
+var keyUsages = map[KeyUsage]x509.KeyUsage{
+	DigitalSignature:   x509.KeyUsageDigitalSignature,
+	KeyEncipherment:    x509.KeyUsageKeyEncipherment,
+	CertificateSigning: x509.KeyUsageCertSign,
+}
+
+var extendedKeyUsages = map[KeyUsage]x509.ExtKeyUsage{
+	ServerAuthentication: x509.ExtKeyUsageServerAuth,
+	ClientAuthentication: x509.ExtKeyUsageClientAuth,
+}
+
+var extendedKeyUsageOIDs = map[x509.ExtKeyUsage]asn1.ObjectIdentifier{
+	x509.ExtKeyUsageServerAuth: {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	x509.ExtKeyUsageClientAuth: {1, 3, 6, 1, 5, 5, 7, 3, 2},
+}
+
+var keyUsageExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 15}
+var extendedKeyUsageExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 37}
+
+func (u KeyUsage) keyUsage() x509.KeyUsage {
+	result := x509.KeyUsage(0)
+	for usage, ku := range keyUsages {
+		if u.Has(usage) {
+			result |= ku
+		}
+	}
+	return result
+}
+
+// extendedKeyUsage returns the usages in a stable order, so the results can be compared
+func (u KeyUsage) extendedKeyUsage() []x509.ExtKeyUsage {
+	result := []x509.ExtKeyUsage{}
+	for _, usage := range []KeyUsage{ServerAuthentication, ClientAuthentication} {
+		if u.Has(usage) {
+			result = append(result, extendedKeyUsages[usage])
+		}
+	}
+	return result
+}
+
+// CertificateOptions describes the content of a new certificate or certificate signing request.
+// The validity period is ignored for certificate signing requests
+type CertificateOptions struct {
+	Subject        pkix.Name
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	NotBefore      time.Time
+	Validity       time.Duration
+	KeyUsage       KeyUsage
+}
+
+func (o CertificateOptions) hasSubject() bool {
+	return o.Subject.CommonName != "" || len(o.DNSNames)+len(o.IPAddresses)+len(o.EmailAddresses) > 0
+}
+
+// WithSubjectAlternativeNames adds the names in the given text, separated by commas or spaces.
+// Names with an @ are taken as email addresses, and anything that is not an IP address as a DNS name
+func (o CertificateOptions) WithSubjectAlternativeNames(names string) CertificateOptions {
+	for _, name := range strings.FieldsFunc(names, func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch ip := net.ParseIP(name); {
+		case ip != nil:
+			o.IPAddresses = append(o.IPAddresses, ip)
+		case strings.Contains(name, "@"):
+			o.EmailAddresses = append(o.EmailAddresses, name)
+		default:
+			o.DNSNames = append(o.DNSNames, name)
+		}
+	}
+	return o
+}
+
+func signerFrom(priv crypto.PrivateKey) (crypto.Signer, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodePEM(pemType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der})
+}
+
+// SelfSignedCertificate returns a PEM encoded certificate for the public key of the given private key, signed by itself
+func SelfSignedCertificate(priv crypto.PrivateKey, o CertificateOptions) ([]byte, error) {
+	signer, e := signerFrom(priv)
+	if e != nil {
+		return nil, e
+	}
+
+	if !o.hasSubject() {
+		return nil, ErrNoSubject
+	}
+
+	if o.Validity <= 0 {
+		return nil, errInvalidValidity
+	}
+
+	serial, e := randomSerialNumber()
+	if e != nil {
+		return nil, e
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               o.Subject,
+		DNSNames:              o.DNSNames,
+		IPAddresses:           o.IPAddresses,
+		EmailAddresses:        o.EmailAddresses,
+		NotBefore:             o.NotBefore,
+		NotAfter:              o.NotBefore.Add(o.Validity),
+		KeyUsage:              o.KeyUsage.keyUsage(),
+		ExtKeyUsage:           o.KeyUsage.extendedKeyUsage(),
+		BasicConstraintsValid: true,
+		IsCA:                  o.KeyUsage.Has(CertificateSigning),
+	}
+
+	der, e := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if e != nil {
+		return nil, e
+	}
+
+	return encodePEM(certificatePEMType, der), nil
+}
+
+// reverseBits is needed because ASN.1 bit strings start with the most significant bit
+func reverseBits(b byte) byte {
+	result := byte(0)
+	for i := 0; i < 8; i++ {
+		result = result<<1 | b>>i&1
+	}
+	return result
+}
+
+// keyUsageExtension encodes the key usage the same way as certificates do, as described in RFC 5280, section 4.2.1.3
+func keyUsageExtension(ku x509.KeyUsage) (pkix.Extension, error) {
+	bits := []byte{reverseBits(byte(ku)), reverseBits(byte(ku >> 8))}
+	if bits[1] == 0 {
+		bits = bits[:1]
+	}
+
+	bitLength := len(bits) * 8
+	for last := bits[len(bits)-1]; bitLength > 0 && last&1 == 0; last >>= 1 {
+		bitLength--
+	}
+
+	value, e := asn1.Marshal(asn1.BitString{Bytes: bits, BitLength: bitLength})
+	return pkix.Extension{Id: keyUsageExtensionOID, Critical: true, Value: value}, e
+}
+
+func extendedKeyUsageExtension(usages []x509.ExtKeyUsage) (pkix.Extension, error) {
+	oids := []asn1.ObjectIdentifier{}
+	for _, u := range usages {
+		oids = append(oids, extendedKeyUsageOIDs[u])
+	}
+
+	value, e := asn1.Marshal(oids)
+	return pkix.Extension{Id: extendedKeyUsageExtensionOID, Value: value}, e
+}
+
+// requestedExtensions returns the key usage extensions to ask for, since certificate requests
+// don't have fields for them
+func (u KeyUsage) requestedExtensions() ([]pkix.Extension, error) {
+	result := []pkix.Extension{}
+
+	if ku := u.keyUsage(); ku != 0 {
+		ext, e := keyUsageExtension(ku)
+		if e != nil {
+			return nil, e
+		}
+		result = append(result, ext)
+	}
+
+	if eku := u.extendedKeyUsage(); len(eku) > 0 {
+		ext, e := extendedKeyUsageExtension(eku)
+		if e != nil {
+			return nil, e
+		}
+		result = append(result, ext)
+	}
+
+	return result, nil
+}
+
+// CertificateSigningRequest returns a PEM encoded PKCS#10 certificate request for the public key of
+// the given private key, to be signed by a certificate authority
+func CertificateSigningRequest(priv crypto.PrivateKey, o CertificateOptions) ([]byte, error) {
+	signer, e := signerFrom(priv)
+	if e != nil {
+		return nil, e
+	}
+
+	if !o.hasSubject() {
+		return nil, ErrNoSubject
+	}
+
+	extensions, e := o.KeyUsage.requestedExtensions()
+	if e != nil {
+		return nil, e
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:         o.Subject,
+		DNSNames:        o.DNSNames,
+		IPAddresses:     o.IPAddresses,
+		EmailAddresses:  o.EmailAddresses,
+		ExtraExtensions: extensions,
+	}
+
+	der, e := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	if e != nil {
+		return nil, e
+	}
+
+	return encodePEM(certificateRequestPEMType, der), nil
+}
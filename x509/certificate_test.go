@@ -0,0 +1,144 @@
+package x509
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type x509Suite struct {
+	suite.Suite
+}
+
+func TestX509Suite(t *testing.T) {
+	suite.Run(t, new(x509Suite))
+}
+
+func ed25519KeyForTest() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+}
+
+func (s *x509Suite) decodePEM(content []byte, expectedType string) []byte {
+	block, rest := pem.Decode(content)
+	s.Require().NotNil(block)
+	s.Equal(expectedType, block.Type)
+	s.Empty(rest)
+	return block.Bytes
+}
+
+func (s *x509Suite) Test_CertificateOptions_WithSubjectAlternativeNames_recognizesTheKindOfName() {
+	o := CertificateOptions{}.WithSubjectAlternativeNames("example.com, 192.0.2.1 alfred@batcave.example,,::1 www.example.com")
+
+	s.Equal([]string{"example.com", "www.example.com"}, o.DNSNames)
+	s.Equal([]net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("::1")}, o.IPAddresses)
+	s.Equal([]string{"alfred@batcave.example"}, o.EmailAddresses)
+}
+
+func (s *x509Suite) Test_SelfSignedCertificate_createsACertificateSignedByTheKey() {
+	priv := ed25519KeyForTest()
+	notBefore := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	content, e := SelfSignedCertificate(priv, CertificateOptions{
+		Subject:   pkix.Name{CommonName: "localhost", Organization: []string{"Digital Autonomy"}},
+		NotBefore: notBefore,
+		Validity:  365 * 24 * time.Hour,
+		KeyUsage:  DigitalSignature | ServerAuthentication | ClientAuthentication,
+	}.WithSubjectAlternativeNames("localhost, 127.0.0.1"))
+	s.Require().NoError(e)
+
+	cert, e := x509.ParseCertificate(s.decodePEM(content, "CERTIFICATE"))
+	s.Require().NoError(e)
+
+	s.NoError(cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature))
+	s.Equal(priv.Public(), cert.PublicKey)
+	s.Equal("localhost", cert.Subject.CommonName)
+	s.Equal("localhost", cert.Issuer.CommonName)
+	s.Equal([]string{"Digital Autonomy"}, cert.Subject.Organization)
+	s.Equal([]string{"localhost"}, cert.DNSNames)
+	s.True(cert.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	s.Equal(notBefore, cert.NotBefore)
+	s.Equal(notBefore.AddDate(1, 0, 0), cert.NotAfter)
+	s.Equal(x509.KeyUsageDigitalSignature, cert.KeyUsage)
+	s.Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	s.False(cert.IsCA)
+}
+
+func (s *x509Suite) Test_SelfSignedCertificate_marksCertificatesThatCanSignAsAuthorities() {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	content, e := SelfSignedCertificate(priv, CertificateOptions{
+		Subject:  pkix.Name{CommonName: "Batcave CA"},
+		Validity: time.Hour,
+		KeyUsage: CertificateSigning | DigitalSignature,
+	})
+	s.Require().NoError(e)
+
+	cert, e := x509.ParseCertificate(s.decodePEM(content, "CERTIFICATE"))
+	s.Require().NoError(e)
+	s.True(cert.IsCA)
+	s.Equal(x509.KeyUsageCertSign|x509.KeyUsageDigitalSignature, cert.KeyUsage)
+}
+
+func (s *x509Suite) Test_SelfSignedCertificate_validatesTheOptions() {
+	_, e := SelfSignedCertificate(&dsa.PrivateKey{}, CertificateOptions{})
+	s.Equal(ErrUnsupportedKey, e)
+
+	_, e = SelfSignedCertificate(ed25519KeyForTest(), CertificateOptions{Validity: time.Hour})
+	s.Equal(ErrNoSubject, e)
+
+	_, e = SelfSignedCertificate(ed25519KeyForTest(), CertificateOptions{Subject: pkix.Name{CommonName: "localhost"}})
+	s.Equal(errInvalidValidity, e)
+}
+
+func (s *x509Suite) Test_CertificateSigningRequest_requestsTheSubjectNamesAndKeyUsage() {
+	priv := ed25519KeyForTest()
+
+	content, e := CertificateSigningRequest(priv, CertificateOptions{
+		Subject:  pkix.Name{CommonName: "alfred"},
+		KeyUsage: DigitalSignature | KeyEncipherment | ClientAuthentication,
+	}.WithSubjectAlternativeNames("alfred@batcave.example"))
+	s.Require().NoError(e)
+
+	csr, e := x509.ParseCertificateRequest(s.decodePEM(content, "CERTIFICATE REQUEST"))
+	s.Require().NoError(e)
+
+	s.NoError(csr.CheckSignature())
+	s.Equal(priv.Public(), csr.PublicKey)
+	s.Equal("alfred", csr.Subject.CommonName)
+	s.Equal([]string{"alfred@batcave.example"}, csr.EmailAddresses)
+
+	serial, _ := randomSerialNumber()
+	template := &x509.Certificate{SerialNumber: serial, ExtraExtensions: csr.Extensions}
+	signed, e := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	s.Require().NoError(e)
+	cert, e := x509.ParseCertificate(signed)
+	s.Require().NoError(e)
+	s.Equal(x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, cert.KeyUsage)
+	s.Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+}
+
+func (s *x509Suite) Test_CertificateSigningRequest_validatesTheOptions() {
+	_, e := CertificateSigningRequest(&dsa.PrivateKey{}, CertificateOptions{})
+	s.Equal(ErrUnsupportedKey, e)
+
+	_, e = CertificateSigningRequest(ed25519KeyForTest(), CertificateOptions{})
+	s.Equal(ErrNoSubject, e)
+}
+
+func (s *x509Suite) Test_keyUsageExtension_usesTheShortestBitString() {
+	ext, _ := keyUsageExtension(x509.KeyUsageDigitalSignature)
+	s.Equal([]byte{0x03, 0x02, 0x07, 0x80}, ext.Value)
+
+	ext, _ = keyUsageExtension(x509.KeyUsageDecipherOnly)
+	s.Equal([]byte{0x03, 0x03, 0x07, 0x00, 0x80}, ext.Value)
+}
@@ -0,0 +1,126 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+const privateKeyPEMType = "PRIVATE KEY"
+const rsaPrivateKeyPEMType = "RSA PRIVATE KEY"
+const ecPrivateKeyPEMType = "EC PRIVATE KEY"
+
+const procTypeHeader = "Proc-Type"
+const encryptedProcType = "ENCRYPTED"
+
+// the extensions of the files that are read when looking for certificates and keys
+var discoverableExtensions = map[string]bool{
+	".pem":  true,
+	".crt":  true,
+	".cer":  true,
+	".cert": true,
+	".der":  true,
+	".key":  true,
+	".p12":  true,
+	".pfx":  true,
+}
+
+// the number of directory levels to look into, below each of the directories
+const maximumDiscoveryDepth = 3
+
+// fileContent holds the certificates and private keys found in a file. Private keys encrypted with
+// a passphrase are skipped, since their public key can't be known without decrypting them
+type fileContent struct {
+	certificates []*x509.Certificate
+	privateKeys  []crypto.Signer
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, bool) {
+	if k, e := x509.ParsePKCS8PrivateKey(der); e == nil {
+		signer, ok := k.(crypto.Signer)
+		return signer, ok
+	}
+	if k, e := x509.ParsePKCS1PrivateKey(der); e == nil {
+		return k, true
+	}
+	if k, e := x509.ParseECPrivateKey(der); e == nil {
+		return k, true
+	}
+	return nil, false
+}
+
+func isEncrypted(block *pem.Block) bool {
+	return strings.Contains(block.Headers[procTypeHeader], encryptedProcType)
+}
+
+func (c *fileContent) addPEMBlock(block *pem.Block) {
+	switch block.Type {
+	case certificatePEMType:
+		if cert, e := x509.ParseCertificate(block.Bytes); e == nil {
+			c.certificates = append(c.certificates, cert)
+		}
+	case privateKeyPEMType, rsaPrivateKeyPEMType, ecPrivateKeyPEMType:
+		if isEncrypted(block) {
+			return
+		}
+		if k, ok := parsePrivateKey(block.Bytes); ok {
+			c.privateKeys = append(c.privateKeys, k)
+		}
+	}
+}
+
+// parseCertificatesAndKeys reads PEM files, that can contain a whole certificate chain, and DER files,
+// that contain either a certificate chain or a single private key
+func parseCertificatesAndKeys(content []byte) *fileContent {
+	result := &fileContent{}
+	if block, rest := pem.Decode(content); block != nil {
+		for ; block != nil; block, rest = pem.Decode(rest) {
+			result.addPEMBlock(block)
+		}
+		return result
+	}
+
+	if certs, e := x509.ParseCertificates(content); e == nil {
+		result.certificates = certs
+	} else if k, ok := parsePrivateKey(content); ok {
+		result.privateKeys = []crypto.Signer{k}
+	}
+	return result
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func depthOf(root, path string) int {
+	rel, e := filepath.Rel(root, path)
+	if e != nil || rel == "." {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// candidateFilesIn returns the files that might contain certificates or keys. Hidden directories
+// below the given directory are skipped, since they usually belong to other tools
+func candidateFilesIn(dir string) []string {
+	result := []string{}
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, e error) error {
+		switch {
+		case e != nil:
+			if d != nil && d.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+		case d.IsDir():
+			if path != dir && (isHidden(d.Name()) || depthOf(dir, path) > maximumDiscoveryDepth) {
+				return fs.SkipDir
+			}
+		case discoverableExtensions[strings.ToLower(filepath.Ext(path))]:
+			result = append(result, path)
+		}
+		return nil
+	})
+	return result
+}
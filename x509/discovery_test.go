@@ -0,0 +1,96 @@
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (s *x509Suite) certificateForTest(commonName string, priv interface{}) []byte {
+	content, e := SelfSignedCertificate(priv, CertificateOptions{
+		Subject:   pkix.Name{CommonName: commonName},
+		NotBefore: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		Validity:  time.Hour,
+	}.WithSubjectAlternativeNames(commonName))
+	s.Require().NoError(e)
+	return content
+}
+
+func (s *x509Suite) pkcs8ForTest(priv interface{}) []byte {
+	der, e := x509.MarshalPKCS8PrivateKey(priv)
+	s.Require().NoError(e)
+	return der
+}
+
+func (s *x509Suite) Test_parseCertificatesAndKeys_readsChainsAndKeysInPEMFiles() {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+	content := append(s.certificateForTest("first", ed25519KeyForTest()), s.certificateForTest("second", ecKey)...)
+	content = append(content, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: s.pkcs8ForTest(ed25519KeyForTest())})...)
+	content = append(content, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER})...)
+	content = append(content, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("ignored")})...)
+
+	result := parseCertificatesAndKeys(content)
+	s.Require().Len(result.certificates, 2)
+	s.Equal("first", result.certificates[0].Subject.CommonName)
+	s.Equal("second", result.certificates[1].Subject.CommonName)
+	s.Require().Len(result.privateKeys, 2)
+	s.Equal(ed25519KeyForTest().Public(), result.privateKeys[0].Public())
+	s.True(ecKey.PublicKey.Equal(result.privateKeys[1].Public()))
+}
+
+func (s *x509Suite) Test_parseCertificatesAndKeys_skipsEncryptedKeys() {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	block := &pem.Block{
+		Type:    "RSA PRIVATE KEY",
+		Headers: map[string]string{"Proc-Type": "4,ENCRYPTED", "DEK-Info": "AES-128-CBC,00000000000000000000000000000000"},
+		Bytes:   x509.MarshalPKCS1PrivateKey(rsaKey),
+	}
+	encrypted := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte("secret")})
+
+	result := parseCertificatesAndKeys(append(pem.EncodeToMemory(block), encrypted...))
+	s.Empty(result.privateKeys)
+	s.Empty(result.certificates)
+}
+
+func (s *x509Suite) Test_parseCertificatesAndKeys_readsDERFiles() {
+	certDER := s.decodePEM(s.certificateForTest("der", ed25519KeyForTest()), "CERTIFICATE")
+	result := parseCertificatesAndKeys(certDER)
+	s.Len(result.certificates, 1)
+	s.Empty(result.privateKeys)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	result = parseCertificatesAndKeys(x509.MarshalPKCS1PrivateKey(rsaKey))
+	s.Empty(result.certificates)
+	s.Require().Len(result.privateKeys, 1)
+	s.True(rsaKey.PublicKey.Equal(result.privateKeys[0].Public()))
+
+	result = parseCertificatesAndKeys([]byte("not a key"))
+	s.Empty(result.certificates)
+	s.Empty(result.privateKeys)
+}
+
+func (s *x509Suite) Test_candidateFilesIn_findsFilesWithKnownExtensions_inVisibleDirectories() {
+	dir := s.T().TempDir()
+	for _, f := range []string{
+		"server.pem", "client.CRT", "notes.txt", "a/b/ca.der", "a/b/c/too-deep/key.pem", ".git/objects/key.pem", "nssdb/cert9.db",
+	} {
+		fileName := filepath.Join(dir, f)
+		s.Require().NoError(os.MkdirAll(filepath.Dir(fileName), 0700))
+		s.Require().NoError(os.WriteFile(fileName, []byte{}, 0600))
+	}
+
+	s.Equal([]string{
+		filepath.Join(dir, "a/b/ca.der"),
+		filepath.Join(dir, "client.CRT"),
+		filepath.Join(dir, "server.pem"),
+	}, candidateFilesIn(dir))
+	s.Empty(candidateFilesIn(filepath.Join(dir, "missing")))
+}
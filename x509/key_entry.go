@@ -0,0 +1,239 @@
+package x509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/fingerprint"
+)
+
+// keyEntry represents a public key with the certificates issued for it, and the files
+// containing its private key. The key is identified by its DER encoded public key info.
+// The friendly name and local key ID are only set for keys found in PKCS#12 files
+type keyEntry struct {
+	public           crypto.PublicKey
+	publicKeyInfo    []byte
+	certificates     []*x509.Certificate
+	private          crypto.Signer
+	publicLocations  []string
+	privateLocations []string
+
+	inPKCS12File bool
+	friendlyName string
+	localKeyID   []byte
+}
+
+func appendIfMissing(l []string, v string) []string {
+	for _, e := range l {
+		if e == v {
+			return l
+		}
+	}
+	return append(l, v)
+}
+
+func (k *keyEntry) addCertificate(cert *x509.Certificate, location string) {
+	k.publicLocations = appendIfMissing(k.publicLocations, location)
+	for _, c := range k.certificates {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return
+		}
+	}
+	k.certificates = append(k.certificates, cert)
+}
+
+func (k *keyEntry) addPrivateKey(private crypto.Signer, location string) {
+	k.private = private
+	k.privateLocations = appendIfMissing(k.privateLocations, location)
+}
+
+// addPKCS12Attributes keeps the first friendly name and local key ID found, since the
+// certificate and the private key in a file usually have the same ones
+func (k *keyEntry) addPKCS12Attributes(friendlyName string, localKeyID []byte) {
+	k.inPKCS12File = true
+	if k.friendlyName == "" {
+		k.friendlyName = friendlyName
+	}
+	if k.localKeyID == nil {
+		k.localKeyID = localKeyID
+	}
+}
+
+// asAPIEntry returns the entry as the type that implements the interfaces for what is known about the key
+func (k *keyEntry) asAPIEntry() api.KeyEntry {
+	if k.inPKCS12File {
+		return &pkcs12KeyEntry{k}
+	}
+	return k
+}
+
+// ID implements the api.KeyEntry interface. The certificates and the private key have the same public key, so
+// it's the same as the ID of the public key stored in other formats, like SSH
+func (k *keyEntry) ID() string {
+	if id, ok := api.PublicKeyID(k.public); ok {
+		return id
+	}
+	return api.MaterialID("x509", k.publicKeyInfo)
+}
+
+func (k *keyEntry) Same(other api.KeyEntry) bool {
+	return other != nil && other.ID() == k.ID()
+}
+
+// Locations returns each file only once, even when it contains both the certificate and the private key
+func (k *keyEntry) Locations() []string {
+	result := append([]string{}, k.publicLocations...)
+	for _, l := range k.privateLocations {
+		result = appendIfMissing(result, l)
+	}
+	return result
+}
+
+func (k *keyEntry) PublicKeyLocations() []string {
+	return k.publicLocations
+}
+
+func (k *keyEntry) PrivateKeyLocations() []string {
+	return k.privateLocations
+}
+
+func (k *keyEntry) KeyType() api.KeyType {
+	switch {
+	case len(k.privateLocations) == 0:
+		return api.PublicKeyType
+	case len(k.publicLocations) == 0:
+		return api.PrivateKeyType
+	}
+	return api.PairKeyType
+}
+
+// the OIDs identifying the algorithms of subject public key infos, from RFC 3279 and RFC 8410
+func init() {
+	api.RegisterOIDs(api.RSA, "1.2.840.113549.1.1.1")
+	api.RegisterOIDs(api.DSA, "1.2.840.10040.4.1")
+	api.RegisterOIDs(api.ECDSA, "1.2.840.10045.2.1")
+	api.RegisterOIDs(api.Ed25519, "1.3.101.112")
+}
+
+// algorithmOf returns the algorithm of the OID in the DER encoded subject public key info
+func algorithmOf(publicKeyInfo []byte) (api.Algorithm, bool) {
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, e := asn1.Unmarshal(publicKeyInfo, &info); e != nil {
+		return nil, false
+	}
+	return api.AlgorithmWithOID(info.Algorithm.Algorithm.String())
+}
+
+// keySizeOf returns false for keys of other types, like the ones from the crypto/ecdh package
+func keySizeOf(pub crypto.PublicKey) (int, bool) {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		return p.N.BitLen(), true
+	case *ecdsa.PublicKey:
+		return p.Curve.Params().BitSize, true
+	case ed25519.PublicKey:
+		return 0, true
+	case *dsa.PublicKey:
+		return p.P.BitLen(), true
+	}
+	return 0, false
+}
+
+func (k *keyEntry) Size() int {
+	size, _ := keySizeOf(k.public)
+	return size
+}
+
+func (k *keyEntry) Algorithm() api.Algorithm {
+	algorithm, _ := algorithmOf(k.publicKeyInfo)
+	return algorithm
+}
+
+// WithDigestContent implements the api.PublicKeyEntry interface. The content is the DER encoded public key
+// info, so the SHA-256 digest is the same as the one used for public key pinning
+func (k *keyEntry) WithDigestContent(f func([]byte) []byte) []byte {
+	return f(k.publicKeyInfo)
+}
+
+// SSHPublicKey implements the api.SSHPublicKeyEntry interface
+func (k *keyEntry) SSHPublicKey() []byte {
+	return fingerprint.SSHWireFormat(k.public)
+}
+
+// UserID returns the common name of the first certificate, or its first alternative name
+func (k *keyEntry) UserID() string {
+	if len(k.certificates) == 0 {
+		return ""
+	}
+
+	cert := k.certificates[0]
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if names := subjectAlternativeNames(cert); len(names) > 0 {
+		return names[0]
+	}
+	return cert.Subject.String()
+}
+
+func (k *keyEntry) PublicKey() crypto.PublicKey {
+	return k.public
+}
+
+// IsPasswordProtected implements the api.PrivateKeyEntry interface. Encrypted private keys are not listed
+func (k *keyEntry) IsPasswordProtected() bool {
+	return false
+}
+
+// RSAPublicExponent implements the api.RSAKeyEntry interface
+func (k *keyEntry) RSAPublicExponent() *big.Int {
+	if p, ok := k.public.(*rsa.PublicKey); ok {
+		return big.NewInt(int64(p.E))
+	}
+	return nil
+}
+
+func subjectAlternativeNames(cert *x509.Certificate) []string {
+	result := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		result = append(result, ip.String())
+	}
+	result = append(result, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		result = append(result, uri.String())
+	}
+	return result
+}
+
+func describeCertificate(cert *x509.Certificate) api.Certificate {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return api.Certificate{
+		Subject:                 cert.Subject.String(),
+		Issuer:                  cert.Issuer.String(),
+		SubjectAlternativeNames: subjectAlternativeNames(cert),
+		NotBefore:               cert.NotBefore,
+		NotAfter:                cert.NotAfter,
+		SHA256Fingerprint:       fingerprint[:],
+	}
+}
+
+func (k *keyEntry) Certificates() []api.Certificate {
+	result := []api.Certificate{}
+	for _, c := range k.certificates {
+		result = append(result, describeCertificate(c))
+	}
+	return result
+}
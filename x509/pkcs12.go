@@ -0,0 +1,123 @@
+package x509
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/pkcs12"
+)
+
+// the extensions of PKCS#12 files, that are read with a password instead of being parsed as PEM or DER
+var pkcs12Extensions = map[string]bool{
+	".p12": true,
+	".pfx": true,
+}
+
+func isPKCS12File(fileName string) bool {
+	return pkcs12Extensions[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// SetPasswordProvider implements the api.PasswordProviderUser interface
+func (a *access) SetPasswordProvider(p api.PasswordProvider) {
+	a.passwords = p
+}
+
+// decodePKCS12 tries the password that worked before, or an empty password, and then asks for the password
+// until it is correct. Files the user didn't give a password for are not asked about again
+func (a *access) decodePKCS12(ctx context.Context, fileName string, content []byte) (*pkcs12.Contents, bool) {
+	password := a.knownPasswords[fileName]
+	incorrect := false
+	for {
+		c, e := pkcs12.Decode(content, password)
+		if e == nil {
+			a.knownPasswords[fileName] = password
+			return c, true
+		}
+		if e != pkcs12.ErrIncorrectPassword {
+			a.log.WithError(e).WithField("file", fileName).Debug("couldn't read the PKCS#12 file")
+			return nil, false
+		}
+
+		if a.passwords == nil || a.declinedFiles[fileName] || ctx.Err() != nil {
+			return nil, false
+		}
+
+		p, ok := a.passwords.PasswordFor(fileName, incorrect)
+		if !ok {
+			a.declinedFiles[fileName] = true
+			return nil, false
+		}
+		password, incorrect = p, true
+	}
+}
+
+func (c *keyCollection) addPKCS12(content *pkcs12.Contents, location string) {
+	for _, cert := range content.Certificates {
+		if entry, ok := c.entryFor(cert.Certificate.PublicKey, cert.Certificate.RawSubjectPublicKeyInfo); ok {
+			entry.addCertificate(cert.Certificate, location)
+			entry.addPKCS12Attributes(cert.FriendlyName, cert.LocalKeyID)
+		}
+	}
+
+	for _, k := range content.PrivateKeys {
+		publicKeyInfo, e := x509.MarshalPKIXPublicKey(k.Key.Public())
+		if e != nil {
+			continue
+		}
+		if entry, ok := c.entryFor(k.Key.Public(), publicKeyInfo); ok {
+			entry.addPrivateKey(k.Key, location)
+			entry.addPKCS12Attributes(k.FriendlyName, k.LocalKeyID)
+		}
+	}
+}
+
+// pkcs12KeyEntry is a key entry with certificates or a private key from a PKCS#12 file
+type pkcs12KeyEntry struct {
+	*keyEntry
+}
+
+// UserID falls back to the friendly name for private keys without a certificate
+func (k *pkcs12KeyEntry) UserID() string {
+	if id := k.keyEntry.UserID(); id != "" {
+		return id
+	}
+	return k.friendlyName
+}
+
+// FriendlyName implements the api.PKCS12KeyEntry interface
+func (k *pkcs12KeyEntry) FriendlyName() string {
+	return k.friendlyName
+}
+
+// LocalKeyID implements the api.PKCS12KeyEntry interface
+func (k *pkcs12KeyEntry) LocalKeyID() []byte {
+	return k.localKeyID
+}
+
+// PEM implements the api.ExtractableKeyEntry interface. The private key is written in the PKCS#8 format,
+// without a passphrase, the same as openssl pkcs12 -nodes does
+func (k *pkcs12KeyEntry) PEM() ([]byte, error) {
+	result := []byte{}
+	for _, c := range k.certificates {
+		result = append(result, pem.EncodeToMemory(&pem.Block{Type: certificatePEMType, Bytes: c.Raw})...)
+	}
+
+	if k.private != nil {
+		der, e := x509.MarshalPKCS8PrivateKey(k.private)
+		if e != nil {
+			return nil, e
+		}
+		result = append(result, pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: der})...)
+	}
+	return result, nil
+}
+
+// ExtractPrivateKey implements the api.ExtractableKeyEntry interface
+func (k *pkcs12KeyEntry) ExtractPrivateKey() (crypto.PrivateKey, bool) {
+	return k.private, k.private != nil
+}
@@ -0,0 +1,135 @@
+package x509
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+
+	"github.com/digitalautonomy/keymirror/api"
+	"github.com/digitalautonomy/keymirror/internal/testutil"
+)
+
+// pkcs12FileForTest is the same file the pkcs12 package is tested with. It was created by OpenSSL 3.0 with the default settings, for a self-signed Ed25519
+// certificate for "Alice Client", with the friendly name "Alice's key" and the password "secret":
+//
+//	openssl pkcs12 -export -inkey ed.key -in ed.crt -name "Alice's key" -passout pass:secret
+const pkcs12FileForTest = "" +
+	"MIID0AIBAzCCA4YGCSqGSIb3DQEHAaCCA3cEggNzMIIDbzCCAlIGCSqGSIb3DQEHBqCCAkMwggI/" +
+	"AgEAMIICOAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAiu711pvU0A" +
+	"MAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEPJWOSFOzELmvAdQ+aJ5HCKAggHQZanG" +
+	"kybNo0KtOW/+9z+vxH4nsawdLlnDTxxbpLb3WREwaa9FHnduL6I01N5Q2THd92al0LobeVrTRNvZ" +
+	"36YMmoGkK4CFHLOROBqapvV5WEpY88mM0sRtti58rkJb4T8PpFzCw5LVO5inXKioOIfUQzpwnt68" +
+	"y733SgIC6UVGi7WOWw5bD4UPWVRC4visORk5MUdYtP1Wvo9rtHQWf9oqlGyNO9Avkazmxjten/hW" +
+	"6lANKzYp8GDzSbuWBl2KCVXIhhn0XgGmop71xy1VlLlj1C1BEwUaQcBwxEcOZWHJRV3KnXubguK5" +
+	"lfPOc7+IHUlSrT/hUCPnhFiJbBghazWc/ofhFPck4jlKDS2NIGyoq4raAJU5ArhOs6tXZTpHFN1W" +
+	"4uEbv+F9ZfTDGZ/c1MmJk2hyPgMK+HdEVtqJlwMJSbT0evpXhHYSxGe9bd8fpefL/DC5y81Xb47C" +
+	"w8O+rl6pGu2DqeDAXPtfbH2o4izwml/W/iTGbg8RY/UMEA8QUoTc1S4DHAPC0ku1u6Xl3Brw9TBR" +
+	"oL+/OGIqGvsOteU3b/1AMz5J0AGXFeC6Regnimdr9PhySvfRhRV8EcXEEo8c9FgxfSGTN28EYQPZ" +
+	"X6dEpy8wggEVBgkqhkiG9w0BBwGgggEGBIIBAjCB/zCB/AYLKoZIhvcNAQwKAQKggZ4wgZswVwYJ" +
+	"KoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwECLN7C2LIGacDAgIIADAMBggqhkiG9w0CCQUAMB0G" +
+	"CWCGSAFlAwQBKgQQl3XlRb98sc2SuXniscIVQQRA3BY9kukBYemLzX/nESpU9K8I6D0uzZyYkQwg" +
+	"sRRSRgUlWHEND8cB5S5nKZmsZqrCZm3XpMocPHut3GBnGIqYvzFMMCMGCSqGSIb3DQEJFTEWBBTs" +
+	"NU0xup7k2ytWL52uVFMxfwRvUDAlBgkqhkiG9w0BCRQxGB4WAEEAbABpAGMAZQAnAHMAIABrAGUA" +
+	"eTBBMDEwDQYJYIZIAWUDBAIBBQAEIGGm1s3+epA+fQ/0gl5IvnnHxWVzivFjkohELu3cBqn0BAhl" +
+	"mRwKiUOyzQICCAA="
+
+func (s *x509Suite) writePKCS12FileForTest(dir string) string {
+	content, _ := base64.StdEncoding.DecodeString(pkcs12FileForTest)
+	return s.writeFileForTest(dir, "client/alice.p12", content)
+}
+
+func (s *x509Suite) Test_access_AllKeys_readsPKCS12FilesWithThePasswordFromTheProvider() {
+	dir := s.T().TempDir()
+	fileName := s.writePKCS12FileForTest(dir)
+	a := accessForTest(dir)
+	p := &testutil.PasswordProvider{Passwords: []string{"not the secret", "secret"}}
+	a.SetPasswordProvider(p)
+
+	keys := s.allKeysOf(a)
+	s.Equal([]bool{false, true}, p.Asked)
+	s.Require().Len(keys, 1)
+
+	k := keys[0].(api.PKCS12KeyEntry)
+	s.Equal(api.PairKeyType, k.KeyType())
+	s.Equal([]string{fileName}, k.Locations())
+	s.Equal(api.Ed25519, k.Algorithm())
+	s.Equal("Alice's key", k.FriendlyName())
+	s.Equal("ec354d31ba9ee4db2b562f9dae5453317f046f50", hex.EncodeToString(k.LocalKeyID()))
+	s.Equal("Alice Client", k.(api.PublicKeyEntry).UserID())
+	s.Len(k.(api.CertificateKeyEntry).Certificates(), 1)
+
+	s.Len(s.allKeysOf(a), 1)
+	s.Len(p.Asked, 2, "the password should be remembered")
+}
+
+func (s *x509Suite) Test_access_AllKeys_doesNotAskAgainForFilesTheUserDidNotGiveAPasswordFor() {
+	dir := s.T().TempDir()
+	s.writePKCS12FileForTest(dir)
+	a := accessForTest(dir)
+
+	s.Empty(s.allKeysOf(a), "files needing a password are skipped without a provider")
+
+	p := &testutil.PasswordProvider{}
+	a.SetPasswordProvider(p)
+	s.Empty(s.allKeysOf(a))
+	s.Empty(s.allKeysOf(a))
+	s.Equal([]bool{false}, p.Asked)
+}
+
+func (s *x509Suite) Test_access_AllKeys_doesNotAskForMorePasswordsWhenTheContextIsCancelled() {
+	dir := s.T().TempDir()
+	s.writePKCS12FileForTest(dir)
+	content, _ := base64.StdEncoding.DecodeString(pkcs12FileForTest)
+	s.writeFileForTest(dir, "client/bob.p12", content)
+	a := accessForTest(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &testutil.PasswordProvider{Passwords: []string{"not the secret", "secret"}, OnAsk: cancel}
+	a.SetPasswordProvider(p)
+
+	keys, e := a.AllKeys(ctx)
+
+	s.Nil(keys)
+	s.Equal(context.Canceled, e)
+	s.Equal([]bool{false}, p.Asked)
+}
+
+func (s *x509Suite) Test_pkcs12KeyEntry_extractsTheCertificateAndThePrivateKey() {
+	dir := s.T().TempDir()
+	s.writePKCS12FileForTest(dir)
+	a := accessForTest(dir)
+	a.SetPasswordProvider(&testutil.PasswordProvider{Passwords: []string{"secret"}})
+
+	k := s.allKeysOf(a)[0].(api.ExtractableKeyEntry)
+	priv, ok := k.ExtractPrivateKey()
+	s.Require().True(ok)
+	s.Equal(k.(api.PublicKeyMaterialEntry).PublicKey(), priv.(ed25519.PrivateKey).Public())
+
+	content, e := k.PEM()
+	s.Require().NoError(e)
+
+	block, rest := pem.Decode(content)
+	s.Require().NotNil(block)
+	s.Equal("CERTIFICATE", block.Type)
+	cert, e := x509.ParseCertificate(block.Bytes)
+	s.Require().NoError(e)
+	s.Equal("Alice Client", cert.Subject.CommonName)
+
+	block, rest = pem.Decode(rest)
+	s.Require().NotNil(block)
+	s.Equal("PRIVATE KEY", block.Type)
+	parsed, e := x509.ParsePKCS8PrivateKey(block.Bytes)
+	s.Require().NoError(e)
+	s.Equal(priv, parsed)
+	s.Empty(rest)
+}
+
+func (s *x509Suite) Test_pkcs12KeyEntry_UserID_fallsBackToTheFriendlyName() {
+	k := &pkcs12KeyEntry{&keyEntry{friendlyName: "my key"}}
+	s.Equal("my key", k.UserID())
+
+	_, ok := k.ExtractPrivateKey()
+	s.False(ok)
+}